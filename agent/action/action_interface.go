@@ -22,3 +22,21 @@ type Action interface {
 	Resume() (interface{}, error)
 	Cancel() error
 }
+
+// ProgressReporter is implemented by asynchronous actions
+// which report their progress while their task is running
+type ProgressReporter interface {
+	Progress() interface{}
+}
+
+// ConcurrentAction is implemented by asynchronous actions whose
+// tasks do not have to wait for the tasks of other actions
+type ConcurrentAction interface {
+	IsConcurrent() bool
+}
+
+// Instantiator is implemented by actions keeping state of a single run,
+// such as how to cancel it, so that each request gets its own instance
+type Instantiator interface {
+	NewInstance() Action
+}
@@ -1,20 +1,28 @@
 package action
 
 import (
+	"code.cloudfoundry.org/clock"
+
 	boshappl "github.com/cloudfoundry/bosh-agent/v2/agent/applier"
 	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
 	boshagentblob "github.com/cloudfoundry/bosh-agent/v2/agent/blobstore"
 	boshcomp "github.com/cloudfoundry/bosh-agent/v2/agent/compiler"
 	blobdelegator "github.com/cloudfoundry/bosh-agent/v2/agent/httpblobprovider/blobstore_delegator"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/logtail"
 	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
 	boshtask "github.com/cloudfoundry/bosh-agent/v2/agent/task"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/utils"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
 	boshnotif "github.com/cloudfoundry/bosh-agent/v2/notification"
 	boshplatform "github.com/cloudfoundry/bosh-agent/v2/platform"
+	boshvitals "github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
 	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
 )
 
 type concreteFactory struct {
@@ -35,11 +43,18 @@ func NewFactory(
 	specService boshas.V1Service,
 	jobScriptProvider boshscript.JobScriptProvider,
 	logger boshlog.Logger,
-	blobstoreDelegator blobdelegator.BlobstoreDelegator) (factory Factory) {
+	blobstoreDelegator blobdelegator.BlobstoreDelegator,
+	vitalsHistory boshvitals.History,
+	probeManager probe.Manager,
+	cgroupManager cgroup.Manager,
+) (factory Factory) {
 	dirProvider := platform.GetDirProvider()
 	vitalsService := platform.GetVitalsService()
 	certManager := platform.GetCertManager()
 	logsTarProvider := platform.GetLogsTarProvider()
+	scriptRunner := cmd.NewRunner(platform.GetRunner(), cgroupManager, clock.NewClock(), logger)
+	errands := NewErrandRegistry(platform.GetFs(), clock.NewClock())
+	logTailer := logtail.NewTailer(platform.GetFs(), dirProvider.LogsDir(), clock.NewClock(), logger)
 
 	return concreteFactory{
 		availableActions: map[string]Action{
@@ -56,19 +71,27 @@ func NewFactory(
 			"bundle_logs":                NewBundleLogs(logsTarProvider, platform.GetFs()),
 			"fetch_logs":                 NewFetchLogs(logsTarProvider, blobstoreDelegator),
 			"fetch_logs_with_signed_url": NewFetchLogsWithSignedURLAction(logsTarProvider, blobstoreDelegator),
+			"tail_logs":                  NewTailLogs(logTailer, notifier, settingsService, boshuuid.NewGenerator(), clock.NewClock(), logger),
 			"update_settings":            NewUpdateSettings(settingsService, platform, certManager, logger, utils.NewAgentKiller()),
 			"shutdown":                   NewShutdown(platform),
 			"remove_file":                NewRemoveFile(platform.GetFs()),
 
 			// Job management
-			"prepare":    NewPrepare(applier),
-			"apply":      NewApply(applier, specService, settingsService, dirProvider, platform.GetFs()),
-			"start":      NewStart(jobSupervisor, applier, specService),
-			"stop":       NewStop(jobSupervisor),
-			"drain":      NewDrain(notifier, specService, jobScriptProvider, jobSupervisor, logger),
-			"get_state":  NewGetState(settingsService, specService, jobSupervisor, vitalsService),
-			"run_errand": NewRunErrand(specService, dirProvider.JobsDir(), platform.GetRunner(), logger),
-			"run_script": NewRunScript(jobScriptProvider, specService, logger),
+			"prepare":            NewPrepare(applier),
+			"apply":              NewApply(applier, specService, settingsService, dirProvider, platform.GetFs()),
+			"start":              NewStart(jobSupervisor, applier, specService),
+			"stop":               NewStop(jobSupervisor, specService, jobScriptProvider, clock.NewClock(), logger),
+			"start_job":          NewStartJob(jobSupervisor),
+			"stop_job":           NewStopJob(jobSupervisor, specService, jobScriptProvider, logger),
+			"restart_job":        NewRestartJob(NewStopJob(jobSupervisor, specService, jobScriptProvider, logger), jobSupervisor),
+			"drain":              NewDrain(notifier, specService, jobScriptProvider, jobSupervisor, clock.NewClock(), logger),
+			"preview_drain":      NewPreviewDrain(specService, jobScriptProvider),
+			"get_state":          NewGetState(settingsService, specService, jobSupervisor, vitalsService, probeManager, cgroupManager),
+			"get_health":         NewGetHealth(platform.GetFs(), dirProvider),
+			"get_vitals_history": NewGetVitalsHistory(vitalsHistory),
+			"run_errand":         NewRunErrand(specService, dirProvider, platform.GetFs(), scriptRunner, jobScriptProvider, blobstoreDelegator, errands, clock.NewClock(), logger),
+			"list_errands":       NewListErrands(errands),
+			"run_script":         NewRunScript(jobScriptProvider, specService, logger),
 
 			// Compilation
 			"compile_package":                 NewCompilePackage(compiler),
@@ -101,5 +124,9 @@ func (f concreteFactory) Create(method string) (Action, error) {
 		return nil, bosherr.Errorf("Could not create action with method %s", method)
 	}
 
+	if instantiator, ok := action.(Instantiator); ok {
+		return instantiator.NewInstance(), nil
+	}
+
 	return action, nil
 }
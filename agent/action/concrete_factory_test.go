@@ -4,8 +4,11 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"code.cloudfoundry.org/clock"
+
 	"github.com/cloudfoundry/bosh-agent/v2/agent/script/scriptfakes"
 	"github.com/cloudfoundry/bosh-agent/v2/platform/platformfakes"
+	"github.com/cloudfoundry/bosh-agent/v2/platform/vitals/vitalsfakes"
 
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 
@@ -21,7 +24,9 @@ import (
 	fakecomp "github.com/cloudfoundry/bosh-agent/v2/agent/compiler/fakes"
 	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/v2/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
 	faketask "github.com/cloudfoundry/bosh-agent/v2/agent/task/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup/cgroupfakes"
 	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe/probefakes"
 	fakenotif "github.com/cloudfoundry/bosh-agent/v2/notification/fakes"
 	fakesettings "github.com/cloudfoundry/bosh-agent/v2/settings/fakes"
 )
@@ -44,6 +49,9 @@ var _ = Describe("concreteFactory", func() {
 		logger            boshlog.Logger
 		fileSystem        *fakesys.FakeFileSystem
 		blobDelegator     *fakeblobdelegator.FakeBlobstoreDelegator
+		vitalsHistory     *vitalsfakes.FakeHistory
+		probeManager      *probefakes.FakeManager
+		cgroupManager     *cgroupfakes.FakeManager
 	)
 
 	BeforeEach(func() {
@@ -64,6 +72,9 @@ var _ = Describe("concreteFactory", func() {
 		jobScriptProvider = &scriptfakes.FakeJobScriptProvider{}
 		logger = boshlog.NewLogger(boshlog.LevelNone)
 		blobDelegator = &fakeblobdelegator.FakeBlobstoreDelegator{}
+		vitalsHistory = &vitalsfakes.FakeHistory{}
+		probeManager = &probefakes.FakeManager{}
+		cgroupManager = &cgroupfakes.FakeManager{}
 
 		factory = boshaction.NewFactory(
 			settingsService,
@@ -78,6 +89,9 @@ var _ = Describe("concreteFactory", func() {
 			jobScriptProvider,
 			logger,
 			blobDelegator,
+			vitalsHistory,
+			probeManager,
+			cgroupManager,
 		)
 	})
 
@@ -112,6 +126,12 @@ var _ = Describe("concreteFactory", func() {
 		Expect(action).To(Equal(boshaction.NewFetchLogs(platform.GetLogsTarProvider(), blobDelegator)))
 	})
 
+	It("tail_logs", func() {
+		action, err := factory.Create("tail_logs")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(BeAssignableToTypeOf(boshaction.TailLogsAction{}))
+	})
+
 	It("fetch_logs_with_signed_url", func() {
 		action, err := factory.Create("fetch_logs_with_signed_url")
 		Expect(err).ToNot(HaveOccurred())
@@ -145,7 +165,19 @@ var _ = Describe("concreteFactory", func() {
 	It("get_state", func() {
 		action, err := factory.Create("get_state")
 		Expect(err).ToNot(HaveOccurred())
-		Expect(action).To(Equal(boshaction.NewGetState(settingsService, specService, jobSupervisor, platform.GetVitalsService())))
+		Expect(action).To(Equal(boshaction.NewGetState(settingsService, specService, jobSupervisor, platform.GetVitalsService(), probeManager, cgroupManager)))
+	})
+
+	It("get_health", func() {
+		action, err := factory.Create("get_health")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(boshaction.NewGetHealth(platform.GetFs(), platform.GetDirProvider())))
+	})
+
+	It("get_vitals_history", func() {
+		action, err := factory.Create("get_vitals_history")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(boshaction.NewGetVitalsHistory(vitalsHistory)))
 	})
 
 	It("list_disk", func() {
@@ -193,7 +225,25 @@ var _ = Describe("concreteFactory", func() {
 	It("stop", func() {
 		action, err := factory.Create("stop")
 		Expect(err).ToNot(HaveOccurred())
-		Expect(action).To(Equal(boshaction.NewStop(jobSupervisor)))
+		Expect(action).To(Equal(boshaction.NewStop(jobSupervisor, specService, jobScriptProvider, clock.NewClock(), logger)))
+	})
+
+	It("start_job", func() {
+		action, err := factory.Create("start_job")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(boshaction.NewStartJob(jobSupervisor)))
+	})
+
+	It("stop_job", func() {
+		action, err := factory.Create("stop_job")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(boshaction.NewStopJob(jobSupervisor, specService, jobScriptProvider, logger)))
+	})
+
+	It("restart_job", func() {
+		action, err := factory.Create("restart_job")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(boshaction.NewRestartJob(boshaction.NewStopJob(jobSupervisor, specService, jobScriptProvider, logger), jobSupervisor)))
 	})
 
 	It("remove_persistent_disk", func() {
@@ -228,6 +278,18 @@ var _ = Describe("concreteFactory", func() {
 		Expect(action).To(BeAssignableToTypeOf(boshaction.RunErrandAction{}))
 	})
 
+	It("list_errands", func() {
+		action, err := factory.Create("list_errands")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(BeAssignableToTypeOf(boshaction.ListErrandsAction{}))
+	})
+
+	It("preview_drain", func() {
+		action, err := factory.Create("preview_drain")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(boshaction.NewPreviewDrain(specService, jobScriptProvider)))
+	})
+
 	It("run_script", func() {
 		action, err := factory.Create("run_script")
 		Expect(err).ToNot(HaveOccurred())
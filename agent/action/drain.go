@@ -2,8 +2,15 @@ package action
 
 import (
 	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
 
 	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/applier/models"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
 	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
 	boshdrain "github.com/cloudfoundry/bosh-agent/v2/agent/script/drain"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
@@ -18,9 +25,51 @@ type DrainAction struct {
 	specService       boshas.V1Service
 	jobSupervisor     boshjobsuper.JobSupervisor
 
-	logTag   string
-	logger   boshlog.Logger
-	cancelCh chan struct{}
+	logTag      string
+	logger      boshlog.Logger
+	cancelCh    chan struct{}
+	state       *drainState
+	timeService clock.Clock
+}
+
+const (
+	DrainGroupPending  = "pending"
+	DrainGroupRunning  = "running"
+	DrainGroupDone     = "done"
+	DrainGroupFailed   = "failed"
+	DrainGroupCanceled = "canceled"
+)
+
+// DrainProgress is reported as the progress of the drain task
+type DrainProgress struct {
+	Groups []DrainGroupProgress          `json:"groups"`
+	Jobs   map[string]boshdrain.Progress `json:"jobs,omitempty"`
+
+	// Where the drain script of each job logged the output of its last run
+	Outputs map[string]outputlog.Output `json:"outputs,omitempty"`
+}
+
+type DrainGroupProgress struct {
+	Group int      `json:"group"`
+	Jobs  []string `json:"jobs"`
+	State string   `json:"state"`
+
+	// Seconds it took to drain the group
+	Duration float64 `json:"duration"`
+}
+
+type drainGroup struct {
+	number  int
+	jobs    []string
+	scripts []boshscript.Script
+}
+
+// drainState is shared by all copies of the action
+// as the progress is asked for while it is running
+type drainState struct {
+	lock   sync.Mutex
+	groups []drainGroup
+	states []DrainGroupProgress
 }
 
 type DrainType string
@@ -36,6 +85,7 @@ func NewDrain(
 	specService boshas.V1Service,
 	jobScriptProvider boshscript.JobScriptProvider,
 	jobSupervisor boshjobsuper.JobSupervisor,
+	timeService clock.Clock,
 	logger boshlog.Logger,
 ) DrainAction {
 	return DrainAction{
@@ -44,9 +94,11 @@ func NewDrain(
 		jobScriptProvider: jobScriptProvider,
 		jobSupervisor:     jobSupervisor,
 
-		logTag:   "Drain Action",
-		logger:   logger,
-		cancelCh: make(chan struct{}, 1),
+		logTag:      "Drain Action",
+		logger:      logger,
+		cancelCh:    make(chan struct{}, 1),
+		state:       &drainState{},
+		timeService: timeService,
 	}
 }
 
@@ -81,24 +133,71 @@ func (a DrainAction) Run(drainType DrainType, newSpecs ...boshas.V1ApplySpec) (i
 	}
 	// TODO write health.json
 
-	scripts := make([]boshscript.Script, 0, len(currentSpec.Jobs()))
-	for _, job := range currentSpec.Jobs() {
-		script := a.jobScriptProvider.NewDrainScript(job.BundleName(), params)
-		scripts = append(scripts, script)
+	groups := a.drainGroups(currentSpec.Jobs(), params)
+
+	a.state.start(groups)
+
+	for i, group := range groups {
+		a.logger.Debug(a.logTag, "Draining group %d with jobs %v", group.number, group.jobs)
+
+		script := a.jobScriptProvider.NewParallelScript("drain", group.scripts)
+
+		a.state.update(i, DrainGroupRunning, 0)
+		startedAt := a.timeService.Now()
+
+		resultsCh := make(chan error, 1)
+		go func() { resultsCh <- script.Run() }()
+		select {
+		case result := <-resultsCh:
+			a.logger.Debug(a.logTag, "Got a result")
+
+			duration := a.timeService.Since(startedAt)
+			a.logger.Info(a.logTag, "Drained group %d with jobs %v in %s", group.number, group.jobs, duration)
+
+			if result != nil {
+				a.state.update(i, DrainGroupFailed, duration)
+				return 0, result
+			}
+
+			a.state.update(i, DrainGroupDone, duration)
+		case <-a.cancelCh:
+			a.logger.Debug(a.logTag, "Got a cancel request")
+
+			// Groups which already drained are not affected by cancelling
+			a.state.cancel(i, a.timeService.Since(startedAt))
+			return 0, script.Cancel()
+		}
 	}
 
-	script := a.jobScriptProvider.NewParallelScript("drain", scripts)
+	return 0, nil
+}
 
-	resultsCh := make(chan error, 1)
-	go func() { resultsCh <- script.Run() }()
-	select {
-	case result := <-resultsCh:
-		a.logger.Debug(a.logTag, "Got a result")
-		return 0, result
-	case <-a.cancelCh:
-		a.logger.Debug(a.logTag, "Got a cancel request")
-		return 0, script.Cancel()
+// drainGroups groups the drain scripts of the jobs by their drain group in
+// the order the groups are drained. Jobs without a drain group are in group 0.
+func (a DrainAction) drainGroups(jobs []models.Job, params boshdrain.ScriptParams) []drainGroup {
+	byNumber := map[int]*drainGroup{}
+	numbers := []int{}
+
+	for _, job := range jobs {
+		group, found := byNumber[job.DrainGroup]
+		if !found {
+			group = &drainGroup{number: job.DrainGroup}
+			byNumber[job.DrainGroup] = group
+			numbers = append(numbers, job.DrainGroup)
+		}
+
+		group.jobs = append(group.jobs, job.BundleName())
+		group.scripts = append(group.scripts, a.jobScriptProvider.NewDrainScript(job.BundleName(), params))
 	}
+
+	sort.Ints(numbers)
+
+	groups := []drainGroup{}
+	for _, number := range numbers {
+		groups = append(groups, *byNumber[number])
+	}
+
+	return groups
 }
 
 func (a DrainAction) determineParams(drainType DrainType, currentSpec boshas.V1ApplySpec, newSpecs []boshas.V1ApplySpec) (boshdrain.ScriptParams, error) {
@@ -134,6 +233,83 @@ func (a DrainAction) determineParams(drainType DrainType, currentSpec boshas.V1A
 	return params, nil
 }
 
+// Progress reports the state of each drain group, what the drain scripts
+// using the JSON protocol reported on their last run and where the
+// scripts logged their output
+func (a DrainAction) Progress() interface{} {
+	progress, started := a.state.progress()
+	if !started {
+		return nil
+	}
+
+	return progress
+}
+
+func (s *drainState) start(groups []drainGroup) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.groups = groups
+	s.states = make([]DrainGroupProgress, len(groups))
+
+	for i, group := range groups {
+		s.states[i] = DrainGroupProgress{Group: group.number, Jobs: group.jobs, State: DrainGroupPending}
+	}
+}
+
+func (s *drainState) update(i int, state string, duration time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.states[i].State = state
+	s.states[i].Duration = duration.Seconds()
+}
+
+// cancel marks the running group and the groups after it as canceled
+func (s *drainState) cancel(i int, duration time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.states[i].Duration = duration.Seconds()
+
+	for ; i < len(s.states); i++ {
+		s.states[i].State = DrainGroupCanceled
+	}
+}
+
+func (s *drainState) progress() (DrainProgress, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.states == nil {
+		return DrainProgress{}, false
+	}
+
+	progress := DrainProgress{
+		Groups:  append([]DrainGroupProgress{}, s.states...),
+		Jobs:    map[string]boshdrain.Progress{},
+		Outputs: map[string]outputlog.Output{},
+	}
+
+	for _, group := range s.groups {
+		for _, script := range group.scripts {
+			if reporter, ok := script.(boshdrain.ProgressReporter); ok {
+				if scriptProgress, reported := reporter.Progress(); reported {
+					progress.Jobs[script.Tag()] = scriptProgress
+				}
+			}
+
+			if loggingScript, ok := script.(boshscript.LoggingScript); ok {
+				if output, ran := loggingScript.Output(); ran {
+					progress.Outputs[script.Tag()] = output
+				}
+			}
+		}
+	}
+
+	return progress, true
+}
+
 func (a DrainAction) Resume() (interface{}, error) {
 	return nil, errors.New("not supported")
 }
@@ -2,13 +2,16 @@ package action_test
 
 import (
 	"errors"
+	"time"
 
+	"code.cloudfoundry.org/clock/fakeclock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
 	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
 	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
 	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
 	boshdrain "github.com/cloudfoundry/bosh-agent/v2/agent/script/drain"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/script/scriptfakes"
@@ -26,6 +29,7 @@ var _ = Describe("DrainAction", func() {
 		fakeScripts       map[string]*scriptfakes.FakeCancellableScript
 		jobSupervisor     *fakejobsuper.FakeJobSupervisor
 		drainAction       action.DrainAction
+		timeService       *fakeclock.FakeClock
 		logger            boshlog.Logger
 	)
 
@@ -36,7 +40,8 @@ var _ = Describe("DrainAction", func() {
 		specService = fakeas.NewFakeV1Service()
 		jobScriptProvider = &scriptfakes.FakeJobScriptProvider{}
 		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
-		drainAction = action.NewDrain(notifier, specService, jobScriptProvider, jobSupervisor, logger)
+		timeService = fakeclock.NewFakeClock(time.Now())
+		drainAction = action.NewDrain(notifier, specService, jobScriptProvider, jobSupervisor, timeService, logger)
 	})
 
 	BeforeEach(func() {
@@ -336,6 +341,168 @@ var _ = Describe("DrainAction", func() {
 		})
 	})
 
+	Describe("Progress", func() {
+		BeforeEach(func() {
+			jobScriptProvider.NewDrainScriptStub = func(jobName string, params boshdrain.ScriptParams) boshscript.CancellableScript {
+				script := &progressScript{FakeCancellableScript: &scriptfakes.FakeCancellableScript{}}
+				script.TagReturns(jobName)
+				if jobName == "foo" {
+					script.progress = &boshdrain.Progress{WaitSeconds: 5, Progress: 40, Message: "fake-message"}
+				}
+				if jobName == "bar" {
+					script.output = &outputlog.Output{StdoutPath: "/fake/bar/drain.stdout.log", Stdout: "0"}
+				}
+				return script
+			}
+			jobScriptProvider.NewParallelScriptReturns(&scriptfakes.FakeCancellableScript{})
+
+			currentSpec := boshas.V1ApplySpec{RenderedTemplatesArchiveSpec: &boshas.RenderedTemplatesArchiveSpec{}}
+			currentSpec.JobSpec.JobTemplateSpecs = []boshas.JobTemplateSpec{{Name: "foo"}, {Name: "bar"}}
+			specService.Spec = currentSpec
+		})
+
+		It("reports no progress before the drain scripts ran", func() {
+			Expect(drainAction.Progress()).To(BeNil())
+		})
+
+		It("reports the progress reported by the drain scripts of each job and where they logged their output", func() {
+			_, err := drainAction.Run(action.DrainTypeShutdown)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(drainAction.Progress()).To(Equal(action.DrainProgress{
+				Groups: []action.DrainGroupProgress{{Group: 0, Jobs: []string{"foo", "bar"}, State: "done"}},
+				Jobs: map[string]boshdrain.Progress{
+					"foo": {WaitSeconds: 5, Progress: 40, Message: "fake-message"},
+				},
+				Outputs: map[string]outputlog.Output{
+					"bar": {StdoutPath: "/fake/bar/drain.stdout.log", Stdout: "0"},
+				},
+			}))
+		})
+	})
+
+	Describe("drain groups", func() {
+		var (
+			drainedGroups   [][]string
+			parallelScripts []*scriptfakes.FakeCancellableScript
+		)
+
+		BeforeEach(func() {
+			drainedGroups = nil
+			parallelScripts = nil
+
+			jobScriptProvider.NewDrainScriptStub = func(jobName string, params boshdrain.ScriptParams) boshscript.CancellableScript {
+				script := &scriptfakes.FakeCancellableScript{}
+				script.TagReturns(jobName)
+				return script
+			}
+
+			jobScriptProvider.NewParallelScriptStub = func(name string, scripts []boshscript.Script) boshscript.CancellableScript {
+				jobs := []string{}
+				for _, script := range scripts {
+					jobs = append(jobs, script.Tag())
+				}
+				drainedGroups = append(drainedGroups, jobs)
+
+				script := &scriptfakes.FakeCancellableScript{}
+				script.RunStub = func() error {
+					timeService.Increment(time.Duration(len(jobs)) * 10 * time.Second)
+					return nil
+				}
+				parallelScripts = append(parallelScripts, script)
+				return script
+			}
+
+			currentSpec := boshas.V1ApplySpec{RenderedTemplatesArchiveSpec: &boshas.RenderedTemplatesArchiveSpec{}}
+			currentSpec.JobSpec.JobTemplateSpecs = []boshas.JobTemplateSpec{
+				{Name: "backend", DrainGroup: 2},
+				{Name: "lb-sidecar", DrainGroup: 1},
+				{Name: "metrics"},
+				{Name: "logs", DrainGroup: 1},
+			}
+			specService.Spec = currentSpec
+		})
+
+		It("drains the groups in order and the jobs of each group in parallel", func() {
+			_, err := drainAction.Run(action.DrainTypeShutdown)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(drainedGroups).To(Equal([][]string{{"metrics"}, {"lb-sidecar", "logs"}, {"backend"}}))
+			Expect(drainAction.Progress()).To(Equal(action.DrainProgress{
+				Groups: []action.DrainGroupProgress{
+					{Group: 0, Jobs: []string{"metrics"}, State: "done", Duration: 10},
+					{Group: 1, Jobs: []string{"lb-sidecar", "logs"}, State: "done", Duration: 20},
+					{Group: 2, Jobs: []string{"backend"}, State: "done", Duration: 10},
+				},
+				Jobs:    map[string]boshdrain.Progress{},
+				Outputs: map[string]outputlog.Output{},
+			}))
+		})
+
+		It("does not drain the groups after a group that failed to drain", func() {
+			jobScriptProvider.NewParallelScriptStub = func(name string, scripts []boshscript.Script) boshscript.CancellableScript {
+				drainedGroups = append(drainedGroups, nil)
+
+				script := &scriptfakes.FakeCancellableScript{}
+				script.RunReturns(errors.New("fake-drain-error"))
+				return script
+			}
+
+			_, err := drainAction.Run(action.DrainTypeShutdown)
+			Expect(err).To(MatchError("fake-drain-error"))
+
+			Expect(drainedGroups).To(HaveLen(1))
+
+			progress := drainAction.Progress().(action.DrainProgress)
+			Expect(progress.Groups[0].State).To(Equal("failed"))
+			Expect(progress.Groups[1].State).To(Equal("pending"))
+			Expect(progress.Groups[2].State).To(Equal("pending"))
+		})
+
+		It("cancels the group that is draining and the groups after it", func() {
+			draining := make(chan struct{})
+			canceled := make(chan struct{})
+			defer close(canceled)
+
+			blockingScript := &scriptfakes.FakeCancellableScript{}
+			blockingScript.RunStub = func() error {
+				close(draining)
+				<-canceled
+				return nil
+			}
+
+			jobScriptProvider.NewParallelScriptStub = func(name string, scripts []boshscript.Script) boshscript.CancellableScript {
+				if len(parallelScripts) == 0 {
+					script := &scriptfakes.FakeCancellableScript{}
+					parallelScripts = append(parallelScripts, script)
+					return script
+				}
+
+				parallelScripts = append(parallelScripts, blockingScript)
+				return blockingScript
+			}
+
+			errCh := make(chan error)
+			go func() {
+				_, err := drainAction.Run(action.DrainTypeShutdown)
+				errCh <- err
+			}()
+
+			Eventually(draining).Should(BeClosed())
+			Expect(drainAction.Cancel()).To(Succeed())
+			Eventually(errCh).Should(Receive(BeNil()))
+
+			Expect(parallelScripts).To(HaveLen(2))
+			Expect(parallelScripts[0].CancelCallCount()).To(Equal(0))
+			Expect(blockingScript.CancelCallCount()).To(Equal(1))
+
+			progress := drainAction.Progress().(action.DrainProgress)
+			Expect(progress.Groups[0].State).To(Equal("done"))
+			Expect(progress.Groups[1].State).To(Equal("canceled"))
+			Expect(progress.Groups[2].State).To(Equal("canceled"))
+		})
+	})
+
 	Describe("Cancel", func() {
 		var (
 			parallelScript *scriptfakes.FakeCancellableScript
@@ -370,3 +537,23 @@ var _ = Describe("DrainAction", func() {
 		})
 	})
 })
+
+type progressScript struct {
+	*scriptfakes.FakeCancellableScript
+	progress *boshdrain.Progress
+	output   *outputlog.Output
+}
+
+func (s *progressScript) Progress() (boshdrain.Progress, bool) {
+	if s.progress == nil {
+		return boshdrain.Progress{}, false
+	}
+	return *s.progress, true
+}
+
+func (s *progressScript) Output() (outputlog.Output, bool) {
+	if s.output == nil {
+		return outputlog.Output{}, false
+	}
+	return *s.output, true
+}
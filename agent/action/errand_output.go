@@ -0,0 +1,98 @@
+package action
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
+)
+
+const (
+	// Output taking up more than this once JSON encoded is uploaded to
+	// the blobstore so that replies holding the end of both stdout and
+	// stderr stay well below the 1MB NATS message size limit
+	errandOutputLimit = 256 * 1024
+
+	// Size of the end of uploaded output that is still returned
+	errandUploadedOutputLimit = 16 * 1024
+
+	// Size of the end of the output reported as progress
+	// of the task running an errand
+	errandProgressLimit = 4 * 1024
+)
+
+// ErrandOutputBlob references the full output of an errand that
+// exceeded errandOutputLimit and was uploaded to the blobstore
+type ErrandOutputBlob struct {
+	BlobstoreID string `json:"blobstore_id"`
+	SHA1        string `json:"sha1"`
+	Size        int64  `json:"size"`
+}
+
+// jsonTail returns the end of data that takes up at most limit bytes
+// once JSON encoded and whether it had to leave out more of data
+func jsonTail(data []byte, limit int) (string, bool) {
+	shortened := false
+
+	for {
+		// Marshalling a string only fails for invalid UTF-8,
+		// which is replaced and still has a size
+		encoded, _ := json.Marshal(string(data))
+
+		excess := len(encoded) - limit
+		if excess <= 0 {
+			return string(data), shortened
+		}
+
+		// A byte takes up at most 6 bytes once encoded, e.g. \u001b
+		drop := (excess + 5) / 6
+		if drop > len(data) {
+			drop = len(data)
+		}
+
+		data = data[drop:]
+		shortened = true
+	}
+}
+
+// ErrandProgress is the end of the output of a running errand
+type ErrandProgress struct {
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+}
+
+// runningErrand holds the output of the errand being run
+// so it can be reported while the errand is still running
+type runningErrand struct {
+	lock   sync.Mutex
+	stdout *outputlog.File
+	stderr *outputlog.File
+}
+
+func (r *runningErrand) start(stdout, stderr *outputlog.File) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.stdout = stdout
+	r.stderr = stderr
+}
+
+func (r *runningErrand) finish() {
+	r.start(nil, nil)
+}
+
+func (r *runningErrand) progress() interface{} {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.stdout == nil {
+		return nil
+	}
+
+	// Progress is best effort, the output may not be readable
+	// once the errand exited and its logs are being uploaded
+	stdout, _, _ := r.stdout.Tail(errandProgressLimit)
+	stderr, _, _ := r.stderr.Tail(errandProgressLimit)
+
+	return ErrandProgress{Stdout: string(stdout), Stderr: string(stderr)}
+}
@@ -0,0 +1,149 @@
+package action
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	ErrandStateRunning  = "running"
+	ErrandStateFinished = "finished"
+	ErrandStateFailed   = "failed"
+
+	// Number of finished errands that are still reported
+	finishedErrandsKept = 10
+)
+
+type ErrandStatus struct {
+	Name       string     `json:"name"`
+	State      string     `json:"state"`
+	PID        int        `json:"pid,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Exit code of finished errands
+	ExitCode *int `json:"exit_code,omitempty"`
+
+	// Why errands failed to run
+	Error string `json:"error,omitempty"`
+}
+
+// ErrandRegistry keeps track of running and recently finished errands.
+// Errands of different job templates may run at the same time.
+type ErrandRegistry struct {
+	fs          boshsys.FileSystem
+	timeService clock.Clock
+
+	lock     sync.Mutex
+	running  map[string]*ErrandStatus
+	pidFiles map[string]string
+	finished []ErrandStatus
+}
+
+func NewErrandRegistry(fs boshsys.FileSystem, timeService clock.Clock) *ErrandRegistry {
+	return &ErrandRegistry{
+		fs:          fs,
+		timeService: timeService,
+		running:     map[string]*ErrandStatus{},
+		pidFiles:    map[string]string{},
+	}
+}
+
+// Start registers errand name as running. Its PID is read from
+// pidFile once the errand wrote it.
+func (r *ErrandRegistry) Start(name string, pidFile string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, found := r.running[name]; found {
+		return bosherr.Errorf("Errand %s is already running", name)
+	}
+
+	r.running[name] = &ErrandStatus{
+		Name:      name,
+		State:     ErrandStateRunning,
+		StartedAt: r.timeService.Now(),
+	}
+	r.pidFiles[name] = pidFile
+
+	return nil
+}
+
+// Finish records the exit code of errand name, or err when it failed to run
+func (r *ErrandRegistry) Finish(name string, exitCode int, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	status, found := r.running[name]
+	if !found {
+		return
+	}
+
+	r.readPID(status)
+	_ = r.fs.RemoveAll(r.pidFiles[name])
+
+	delete(r.running, name)
+	delete(r.pidFiles, name)
+
+	finishedAt := r.timeService.Now()
+	status.FinishedAt = &finishedAt
+
+	if err != nil {
+		status.State = ErrandStateFailed
+		status.Error = err.Error()
+	} else {
+		status.State = ErrandStateFinished
+		status.ExitCode = &exitCode
+	}
+
+	r.finished = append(r.finished, *status)
+	if len(r.finished) > finishedErrandsKept {
+		r.finished = r.finished[len(r.finished)-finishedErrandsKept:]
+	}
+}
+
+// List returns running errands by name followed by
+// finished errands, the most recently finished first
+func (r *ErrandRegistry) List() []ErrandStatus {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	statuses := []ErrandStatus{}
+
+	for _, status := range r.running {
+		r.readPID(status)
+		statuses = append(statuses, *status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	for i := len(r.finished) - 1; i >= 0; i-- {
+		statuses = append(statuses, r.finished[i])
+	}
+
+	return statuses
+}
+
+func (r *ErrandRegistry) readPID(status *ErrandStatus) {
+	if status.PID != 0 {
+		return
+	}
+
+	contents, err := r.fs.ReadFileString(r.pidFiles[status.Name])
+	if err != nil {
+		return
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(contents))
+	if err == nil {
+		status.PID = pid
+	}
+}
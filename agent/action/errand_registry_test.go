@@ -0,0 +1,90 @@
+package action_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("ErrandRegistry", func() {
+	var (
+		fs        *fakesys.FakeFileSystem
+		fakeClock *fakeclock.FakeClock
+		startedAt time.Time
+		errands   *action.ErrandRegistry
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		startedAt = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		fakeClock = fakeclock.NewFakeClock(startedAt)
+		errands = action.NewErrandRegistry(fs, fakeClock)
+	})
+
+	It("lists running errands with the PID they recorded", func() {
+		Expect(errands.Start("smoke-tests", "/run/smoke-tests/errand.pid")).To(Succeed())
+		Expect(errands.Start("acceptance-tests", "/run/acceptance-tests/errand.pid")).To(Succeed())
+		Expect(fs.WriteFileString("/run/smoke-tests/errand.pid", "1234\n")).To(Succeed())
+
+		Expect(errands.List()).To(Equal([]action.ErrandStatus{
+			{Name: "acceptance-tests", State: action.ErrandStateRunning, StartedAt: startedAt},
+			{Name: "smoke-tests", State: action.ErrandStateRunning, PID: 1234, StartedAt: startedAt},
+		}))
+	})
+
+	It("does not start an errand that is already running", func() {
+		Expect(errands.Start("smoke-tests", "/run/smoke-tests/errand.pid")).To(Succeed())
+
+		err := errands.Start("smoke-tests", "/run/smoke-tests/errand.pid")
+		Expect(err).To(MatchError("Errand smoke-tests is already running"))
+	})
+
+	It("lists finished errands with their exit code, most recent first", func() {
+		Expect(errands.Start("smoke-tests", "/run/smoke-tests/errand.pid")).To(Succeed())
+		Expect(errands.Start("acceptance-tests", "/run/acceptance-tests/errand.pid")).To(Succeed())
+		Expect(fs.WriteFileString("/run/smoke-tests/errand.pid", "1234")).To(Succeed())
+
+		fakeClock.Increment(time.Minute)
+		errands.Finish("smoke-tests", 1, nil)
+		fakeClock.Increment(time.Minute)
+		errands.Finish("acceptance-tests", -1, errors.New("fake-run-error"))
+
+		exitCode := 1
+		smokeTestsFinishedAt := startedAt.Add(time.Minute)
+		acceptanceTestsFinishedAt := startedAt.Add(2 * time.Minute)
+
+		Expect(errands.List()).To(Equal([]action.ErrandStatus{
+			{Name: "acceptance-tests", State: action.ErrandStateFailed, StartedAt: startedAt, FinishedAt: &acceptanceTestsFinishedAt, Error: "fake-run-error"},
+			{Name: "smoke-tests", State: action.ErrandStateFinished, PID: 1234, StartedAt: startedAt, FinishedAt: &smokeTestsFinishedAt, ExitCode: &exitCode},
+		}))
+		Expect(fs.FileExists("/run/smoke-tests/errand.pid")).To(BeFalse())
+	})
+
+	It("allows finished errands to run again", func() {
+		Expect(errands.Start("smoke-tests", "/run/smoke-tests/errand.pid")).To(Succeed())
+		errands.Finish("smoke-tests", 0, nil)
+
+		Expect(errands.Start("smoke-tests", "/run/smoke-tests/errand.pid")).To(Succeed())
+		Expect(errands.List()).To(HaveLen(2))
+	})
+
+	It("only keeps the most recently finished errands", func() {
+		for i := 0; i < 12; i++ {
+			name := fmt.Sprintf("errand-%d", i)
+			Expect(errands.Start(name, "/run/"+name+"/errand.pid")).To(Succeed())
+			errands.Finish(name, 0, nil)
+		}
+
+		statuses := errands.List()
+		Expect(statuses).To(HaveLen(10))
+		Expect(statuses[0].Name).To(Equal("errand-11"))
+		Expect(statuses[9].Name).To(Equal("errand-2"))
+	})
+})
@@ -47,6 +47,9 @@ type TestAction struct {
 	Asynchronous bool
 	Persistent   bool
 	Loggable     bool
+	Concurrent   bool
+
+	RunStub func() (interface{}, error)
 
 	ResumeValue interface{}
 	ResumeErr   error
@@ -55,6 +58,8 @@ type TestAction struct {
 	Canceled  bool
 	CancelErr error
 
+	ProgressValue interface{}
+
 	ProtocolVersion boshaction.ProtocolVersion
 }
 
@@ -63,6 +68,10 @@ func (a *TestAction) IsAsynchronous(protocolVersion boshaction.ProtocolVersion)
 	return a.Asynchronous
 }
 
+func (a *TestAction) Progress() interface{} {
+	return a.ProgressValue
+}
+
 func (a *TestAction) IsPersistent() bool {
 	return a.Persistent
 }
@@ -71,7 +80,14 @@ func (a *TestAction) IsLoggable() bool {
 	return a.Loggable
 }
 
+func (a *TestAction) IsConcurrent() bool {
+	return a.Concurrent
+}
+
 func (a *TestAction) Run(payload []byte) (interface{}, error) {
+	if a.RunStub != nil {
+		return a.RunStub()
+	}
 	return nil, nil
 }
 
@@ -0,0 +1,51 @@
+package action
+
+import (
+	"errors"
+	"path/filepath"
+
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	boshdirs "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// GetHealthAction returns the health document that the job supervisor
+// records with every heartbeat in the instance directory
+type GetHealthAction struct {
+	fs          boshsys.FileSystem
+	dirProvider boshdirs.Provider
+}
+
+func NewGetHealth(fs boshsys.FileSystem, dirProvider boshdirs.Provider) GetHealthAction {
+	return GetHealthAction{fs: fs, dirProvider: dirProvider}
+}
+
+func (a GetHealthAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetHealthAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetHealthAction) IsLoggable() bool {
+	return true
+}
+
+func (a GetHealthAction) Run() (boshjobsuper.Health, error) {
+	health, err := boshjobsuper.ReadHealth(a.fs, filepath.Join(a.dirProvider.InstanceDir(), "health.json"))
+	if err != nil {
+		return health, bosherr.WrapError(err, "Getting health")
+	}
+
+	return health, nil
+}
+
+func (a GetHealthAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetHealthAction) Cancel() error {
+	return errors.New("not supported")
+}
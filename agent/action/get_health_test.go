@@ -0,0 +1,84 @@
+package action_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	boshdirs "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("GetHealth", func() {
+	var (
+		fs              *fakesys.FakeFileSystem
+		getHealthAction action.GetHealthAction
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		getHealthAction = action.NewGetHealth(fs, boshdirs.NewProvider("/var/vcap"))
+	})
+
+	AssertActionIsNotAsynchronous(getHealthAction)
+	AssertActionIsNotPersistent(getHealthAction)
+	AssertActionIsLoggable(getHealthAction)
+
+	AssertActionIsNotResumable(getHealthAction)
+	AssertActionIsNotCancelable(getHealthAction)
+
+	Describe("Run", func() {
+		It("returns the recorded health", func() {
+			err := fs.WriteFileString("/var/vcap/instance/health.json", `{
+				"state": "failing",
+				"updated_at": "2026-01-01T00:00:00Z",
+				"jobs": {
+					"fake-job": {
+						"state": "failing",
+						"last_transition": "2026-01-01T00:00:00Z",
+						"restarts": 2,
+						"last_failure": {"event": "pid failed", "at": "2026-01-01T00:00:00Z"},
+						"history": [{"from": "running", "to": "failing", "at": "2026-01-01T00:00:00Z"}]
+					}
+				}
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			health, err := getHealthAction.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(health).To(Equal(boshjobsuper.Health{
+				State:     "failing",
+				UpdatedAt: &at,
+				Jobs: map[string]boshjobsuper.JobHealth{
+					"fake-job": {
+						State:          "failing",
+						LastTransition: at,
+						Restarts:       2,
+						LastFailure:    &boshjobsuper.Failure{Event: "pid failed", At: at},
+						History:        []boshjobsuper.Transition{{From: "running", To: "failing", At: at}},
+					},
+				},
+			}))
+		})
+
+		It("returns an unknown state when no health has been recorded", func() {
+			health, err := getHealthAction.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(health).To(Equal(boshjobsuper.Health{State: "unknown"}))
+		})
+
+		It("returns an error when the recorded health cannot be parsed", func() {
+			err := fs.WriteFileString("/var/vcap/instance/health.json", "not-json")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = getHealthAction.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Getting health"))
+		})
+	})
+})
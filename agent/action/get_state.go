@@ -5,6 +5,8 @@ import (
 
 	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
 	boshvitals "github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
 	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
@@ -15,6 +17,8 @@ type GetStateAction struct {
 	specService     boshas.V1Service
 	jobSupervisor   boshjobsuper.JobSupervisor
 	vitalsService   boshvitals.Service
+	probeManager    probe.Manager
+	cgroupManager   cgroup.Manager
 }
 
 func NewGetState(
@@ -22,11 +26,15 @@ func NewGetState(
 	specService boshas.V1Service,
 	jobSupervisor boshjobsuper.JobSupervisor,
 	vitalsService boshvitals.Service,
+	probeManager probe.Manager,
+	cgroupManager cgroup.Manager,
 ) (action GetStateAction) {
 	action.settingsService = settingsService
 	action.specService = specService
 	action.jobSupervisor = jobSupervisor
 	action.vitalsService = vitalsService
+	action.probeManager = probeManager
+	action.cgroupManager = cgroupManager
 	return
 }
 
@@ -45,11 +53,13 @@ func (a GetStateAction) IsLoggable() bool {
 type GetStateV1ApplySpec struct {
 	boshas.V1ApplySpec
 
-	AgentID   string                 `json:"agent_id"`
-	JobState  string                 `json:"job_state"`
-	Vitals    *boshvitals.Vitals     `json:"vitals,omitempty"`
-	Processes []boshjobsuper.Process `json:"processes,omitempty"`
-	VM        boshsettings.VM        `json:"vm"`
+	AgentID   string                   `json:"agent_id"`
+	JobState  string                   `json:"job_state"`
+	Vitals    *boshvitals.Vitals       `json:"vitals,omitempty"`
+	Processes []boshjobsuper.Process   `json:"processes,omitempty"`
+	VM        boshsettings.VM          `json:"vm"`
+	Probes    []probe.Result           `json:"probes,omitempty"`
+	JobLimits map[string]cgroup.Limits `json:"job_limits,omitempty"`
 }
 
 func (a GetStateAction) Run(filters ...string) (GetStateV1ApplySpec, error) {
@@ -83,6 +93,8 @@ func (a GetStateAction) Run(filters ...string) (GetStateV1ApplySpec, error) {
 		vitalsReference,
 		processes,
 		settings.VM,
+		a.probeManager.Results(),
+		a.cgroupManager.Limits(),
 	}
 
 	if value.NetworkSpecs == nil {
@@ -10,7 +10,11 @@ import (
 	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
 	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup/cgroupfakes"
 	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe/probefakes"
 	boshvitals "github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
 	"github.com/cloudfoundry/bosh-agent/v2/platform/vitals/vitalsfakes"
 	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
@@ -24,6 +28,8 @@ var _ = Describe("GetState", func() {
 		specService     *fakeas.FakeV1Service
 		jobSupervisor   *fakejobsuper.FakeJobSupervisor
 		vitalsService   *vitalsfakes.FakeService
+		probeManager    *probefakes.FakeManager
+		cgroupManager   *cgroupfakes.FakeManager
 		getStateAction  action.GetStateAction
 	)
 
@@ -32,7 +38,9 @@ var _ = Describe("GetState", func() {
 		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
 		specService = fakeas.NewFakeV1Service()
 		vitalsService = &vitalsfakes.FakeService{}
-		getStateAction = action.NewGetState(settingsService, specService, jobSupervisor, vitalsService)
+		probeManager = &probefakes.FakeManager{}
+		cgroupManager = &cgroupfakes.FakeManager{}
+		getStateAction = action.NewGetState(settingsService, specService, jobSupervisor, vitalsService, probeManager, cgroupManager)
 	})
 
 	AssertActionIsNotAsynchronous(getStateAction)
@@ -127,6 +135,26 @@ var _ = Describe("GetState", func() {
 					boshassert.MatchesJSONMap(GinkgoT(), state.VM, expectedVM)
 				})
 
+				It("returns health probe results", func() {
+					probeResults := []probe.Result{
+						{Job: "fake-job", Name: "api", Type: probe.TypeHTTP, Healthy: false, ConsecutiveFailures: 3},
+					}
+					probeManager.ResultsReturns(probeResults)
+
+					state, err := getStateAction.Run()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(state.Probes).To(Equal(probeResults))
+				})
+
+				It("returns the resource limits of each job", func() {
+					limits := map[string]cgroup.Limits{"fake-job": {CPUWeight: 200, MemoryMax: "1G"}}
+					cgroupManager.LimitsReturns(limits)
+
+					state, err := getStateAction.Run()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(state.JobLimits).To(Equal(limits))
+				})
+
 				Describe("non-populated field formatting", func() {
 					It("returns network as empty hash if not set", func() {
 						specService.Spec = boshas.V1ApplySpec{NetworkSpecs: nil}
@@ -0,0 +1,115 @@
+package action
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	boshvitals "github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// vitalsHistoryMaxReplySize keeps replies well below the 1MB the NATS
+// handler allows for a response, including the response envelope
+const vitalsHistoryMaxReplySize = 768 * 1024
+
+type GetVitalsHistoryOptions struct {
+	// Unix timestamps; zero values select all recorded samples
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+
+	// Downsampling step in seconds; zero returns every recorded sample
+	Step int `json:"step"`
+
+	// Maximum number of samples, the latest ones are returned; zero returns
+	// as many as fit in a reply
+	Limit int `json:"limit"`
+}
+
+type GetVitalsHistoryAction struct {
+	vitalsHistory boshvitals.History
+}
+
+func NewGetVitalsHistory(vitalsHistory boshvitals.History) GetVitalsHistoryAction {
+	return GetVitalsHistoryAction{vitalsHistory: vitalsHistory}
+}
+
+func (a GetVitalsHistoryAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a GetVitalsHistoryAction) IsPersistent() bool {
+	return false
+}
+
+func (a GetVitalsHistoryAction) IsLoggable() bool {
+	return true
+}
+
+// Run returns the latest samples that fit in a reply when there are more.
+// Callers page through older samples by passing the timestamp of the oldest
+// sample they got as to, or ask for fewer samples with a larger step.
+func (a GetVitalsHistoryAction) Run(options ...GetVitalsHistoryOptions) ([]boshvitals.Sample, error) {
+	if a.vitalsHistory == nil {
+		return nil, bosherr.Error("Vitals history is disabled")
+	}
+
+	var opts GetVitalsHistoryOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	var from, to time.Time
+	if opts.From > 0 {
+		from = time.Unix(opts.From, 0)
+	}
+	if opts.To > 0 {
+		to = time.Unix(opts.To, 0)
+	}
+
+	if !to.IsZero() && to.Before(from) {
+		return nil, bosherr.Errorf("Invalid time range: from %d is after to %d", opts.From, opts.To)
+	}
+
+	if opts.Limit < 0 {
+		return nil, bosherr.Errorf("Invalid limit: %d must not be negative", opts.Limit)
+	}
+
+	samples, err := a.vitalsHistory.Samples(from, to, time.Duration(opts.Step)*time.Second)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting vitals history")
+	}
+
+	if opts.Limit > 0 && len(samples) > opts.Limit {
+		samples = samples[len(samples)-opts.Limit:]
+	}
+
+	return a.latestFittingReply(samples)
+}
+
+// latestFittingReply drops the oldest samples that do not fit in a reply
+func (a GetVitalsHistoryAction) latestFittingReply(samples []boshvitals.Sample) ([]boshvitals.Sample, error) {
+	size := 2 // []
+
+	for i := len(samples) - 1; i >= 0; i-- {
+		sample, err := json.Marshal(samples[i])
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Marshalling vitals sample")
+		}
+
+		size += len(sample) + 1 // ,
+		if size > vitalsHistoryMaxReplySize {
+			return samples[i+1:], nil
+		}
+	}
+
+	return samples, nil
+}
+
+func (a GetVitalsHistoryAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetVitalsHistoryAction) Cancel() error {
+	return errors.New("not supported")
+}
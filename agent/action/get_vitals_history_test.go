@@ -0,0 +1,123 @@
+package action_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	boshvitals "github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
+	"github.com/cloudfoundry/bosh-agent/v2/platform/vitals/vitalsfakes"
+)
+
+var _ = Describe("GetVitalsHistory", func() {
+	var (
+		vitalsHistory *vitalsfakes.FakeHistory
+		getAction     action.GetVitalsHistoryAction
+	)
+
+	BeforeEach(func() {
+		vitalsHistory = &vitalsfakes.FakeHistory{}
+		getAction = action.NewGetVitalsHistory(vitalsHistory)
+	})
+
+	AssertActionIsNotAsynchronous(getAction)
+	AssertActionIsNotPersistent(getAction)
+	AssertActionIsLoggable(getAction)
+
+	AssertActionIsNotResumable(getAction)
+	AssertActionIsNotCancelable(getAction)
+
+	Describe("Run", func() {
+		It("returns samples within the requested range and step", func() {
+			samples := []boshvitals.Sample{
+				{Timestamp: 1700000060, Vitals: boshvitals.Vitals{Load: []string{"1"}}},
+			}
+			vitalsHistory.SamplesReturns(samples, nil)
+
+			result, err := getAction.Run(action.GetVitalsHistoryOptions{From: 1700000000, To: 1700003600, Step: 300})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(samples))
+
+			from, to, step := vitalsHistory.SamplesArgsForCall(0)
+			Expect(from).To(Equal(time.Unix(1700000000, 0)))
+			Expect(to).To(Equal(time.Unix(1700003600, 0)))
+			Expect(step).To(Equal(5 * time.Minute))
+		})
+
+		It("returns all samples when no options are given", func() {
+			_, err := getAction.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			from, to, step := vitalsHistory.SamplesArgsForCall(0)
+			Expect(from.IsZero()).To(BeTrue())
+			Expect(to.IsZero()).To(BeTrue())
+			Expect(step).To(BeZero())
+		})
+
+		It("returns the latest samples up to the limit", func() {
+			vitalsHistory.SamplesReturns([]boshvitals.Sample{
+				{Timestamp: 1700000000},
+				{Timestamp: 1700000060},
+				{Timestamp: 1700000120},
+			}, nil)
+
+			result, err := getAction.Run(action.GetVitalsHistoryOptions{Limit: 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal([]boshvitals.Sample{
+				{Timestamp: 1700000060},
+				{Timestamp: 1700000120},
+			}))
+		})
+
+		It("returns only the latest samples that fit in a reply", func() {
+			samples := []boshvitals.Sample{}
+			for i := 0; i < 2000; i++ {
+				samples = append(samples, boshvitals.Sample{
+					Timestamp: int64(1700000000 + 60*i),
+					Vitals:    boshvitals.Vitals{Load: []string{strings.Repeat("1", 1024)}},
+				})
+			}
+			vitalsHistory.SamplesReturns(samples, nil)
+
+			result, err := getAction.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(result)).To(BeNumerically("<", len(samples)))
+			Expect(result[len(result)-1]).To(Equal(samples[len(samples)-1]))
+
+			reply, err := json.Marshal(result)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(reply)).To(BeNumerically("<=", 768*1024))
+		})
+
+		It("returns an error when the limit is negative", func() {
+			_, err := getAction.Run(action.GetVitalsHistoryOptions{Limit: -1})
+			Expect(err).To(HaveOccurred())
+			Expect(vitalsHistory.SamplesCallCount()).To(Equal(0))
+		})
+
+		It("returns an error when the range is inverted", func() {
+			_, err := getAction.Run(action.GetVitalsHistoryOptions{From: 200, To: 100})
+			Expect(err).To(HaveOccurred())
+			Expect(vitalsHistory.SamplesCallCount()).To(Equal(0))
+		})
+
+		It("returns an error when samples cannot be read", func() {
+			vitalsHistory.SamplesReturns(nil, errors.New("fake-history-error"))
+
+			_, err := getAction.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-history-error"))
+		})
+
+		It("returns an error when vitals history is disabled", func() {
+			_, err := action.NewGetVitalsHistory(nil).Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("disabled"))
+		})
+	})
+})
@@ -0,0 +1,37 @@
+package action
+
+import (
+	"errors"
+)
+
+type ListErrandsAction struct {
+	errands *ErrandRegistry
+}
+
+func NewListErrands(errands *ErrandRegistry) ListErrandsAction {
+	return ListErrandsAction{errands: errands}
+}
+
+func (a ListErrandsAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a ListErrandsAction) IsPersistent() bool {
+	return false
+}
+
+func (a ListErrandsAction) IsLoggable() bool {
+	return true
+}
+
+func (a ListErrandsAction) Run() ([]ErrandStatus, error) {
+	return a.errands.List(), nil
+}
+
+func (a ListErrandsAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a ListErrandsAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,45 @@
+package action_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("ListErrands", func() {
+	var (
+		errands           *action.ErrandRegistry
+		startedAt         time.Time
+		listErrandsAction action.ListErrandsAction
+	)
+
+	BeforeEach(func() {
+		startedAt = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		errands = action.NewErrandRegistry(fakesys.NewFakeFileSystem(), fakeclock.NewFakeClock(startedAt))
+		listErrandsAction = action.NewListErrands(errands)
+	})
+
+	AssertActionIsNotAsynchronous(listErrandsAction)
+	AssertActionIsNotPersistent(listErrandsAction)
+	AssertActionIsLoggable(listErrandsAction)
+
+	AssertActionIsNotResumable(listErrandsAction)
+	AssertActionIsNotCancelable(listErrandsAction)
+
+	It("returns the running and recently finished errands", func() {
+		Expect(errands.Start("smoke-tests", "/run/smoke-tests/errand.pid")).To(Succeed())
+
+		Expect(listErrandsAction.Run()).To(Equal([]action.ErrandStatus{
+			{Name: "smoke-tests", State: action.ErrandStateRunning, StartedAt: startedAt},
+		}))
+	})
+
+	It("returns an empty list when no errand ran", func() {
+		Expect(listErrandsAction.Run()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,93 @@
+package action
+
+import (
+	"errors"
+
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
+	boshdrain "github.com/cloudfoundry/bosh-agent/v2/agent/script/drain"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type PreviewDrainAction struct {
+	specService       boshas.V1Service
+	jobScriptProvider boshscript.JobScriptProvider
+}
+
+// DrainPreview is how the drain script of a job would be
+// invoked when updating the instance to a new spec
+type DrainPreview struct {
+	Job        string `json:"job"`
+	DrainGroup int    `json:"drain_group"`
+
+	boshdrain.Invocation
+}
+
+func NewPreviewDrain(
+	specService boshas.V1Service,
+	jobScriptProvider boshscript.JobScriptProvider,
+) PreviewDrainAction {
+	return PreviewDrainAction{
+		specService:       specService,
+		jobScriptProvider: jobScriptProvider,
+	}
+}
+
+func (a PreviewDrainAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a PreviewDrainAction) IsPersistent() bool {
+	return false
+}
+
+func (a PreviewDrainAction) IsLoggable() bool {
+	return true
+}
+
+// Run returns how the drain scripts of the jobs would be invoked by an
+// update drain to newSpec without running any of them. Jobs without a
+// drain script are left out.
+func (a PreviewDrainAction) Run(newSpec boshas.V1ApplySpec) ([]DrainPreview, error) {
+	currentSpec, err := a.specService.Get()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting current spec")
+	}
+
+	params := boshdrain.NewUpdateParams(currentSpec, newSpec)
+
+	previews := []DrainPreview{}
+
+	for _, job := range currentSpec.Jobs() {
+		script := a.jobScriptProvider.NewDrainScript(job.BundleName(), params)
+		if !script.Exists() {
+			continue
+		}
+
+		previewer, ok := script.(boshdrain.Previewer)
+		if !ok {
+			return nil, bosherr.Errorf("Drain script of job %s cannot be previewed", job.BundleName())
+		}
+
+		invocation, err := previewer.Preview()
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Previewing drain script of job %s", job.BundleName())
+		}
+
+		previews = append(previews, DrainPreview{
+			Job:        job.BundleName(),
+			DrainGroup: job.DrainGroup,
+			Invocation: invocation,
+		})
+	}
+
+	return previews, nil
+}
+
+func (a PreviewDrainAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a PreviewDrainAction) Cancel() error {
+	return errors.New("not supported")
+}
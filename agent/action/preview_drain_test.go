@@ -0,0 +1,158 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
+	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
+	boshdrain "github.com/cloudfoundry/bosh-agent/v2/agent/script/drain"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/scriptfakes"
+	"github.com/cloudfoundry/bosh-utils/crypto"
+)
+
+var _ = Describe("PreviewDrain", func() {
+	var (
+		specService        *fakeas.FakeV1Service
+		jobScriptProvider  *scriptfakes.FakeJobScriptProvider
+		previewDrainAction action.PreviewDrainAction
+		newSpec            boshas.V1ApplySpec
+		drainScripts       map[string]*previewScript
+	)
+
+	BeforeEach(func() {
+		specService = fakeas.NewFakeV1Service()
+		jobScriptProvider = &scriptfakes.FakeJobScriptProvider{}
+		previewDrainAction = action.NewPreviewDrain(specService, jobScriptProvider)
+
+		currentSpec := boshas.V1ApplySpec{
+			ConfigurationHash: "old-config-hash",
+			PackageSpecs: map[string]boshas.PackageSpec{
+				"foo": {Name: "foo", Sha1: crypto.MustParseMultipleDigest("sha1:foosha1")},
+			},
+			RenderedTemplatesArchiveSpec: &boshas.RenderedTemplatesArchiveSpec{},
+		}
+		currentSpec.JobSpec.JobTemplateSpecs = []boshas.JobTemplateSpec{
+			{Name: "backend", DrainGroup: 2},
+			{Name: "metrics"},
+			{Name: "no-drain"},
+		}
+		specService.Spec = currentSpec
+
+		newSpec = boshas.V1ApplySpec{
+			ConfigurationHash: "new-config-hash",
+			PackageSpecs: map[string]boshas.PackageSpec{
+				"foo": {Name: "foo", Sha1: crypto.MustParseMultipleDigest("sha1:foosha1new")},
+			},
+		}
+
+		drainScripts = map[string]*previewScript{}
+		jobScriptProvider.NewDrainScriptStub = func(jobName string, params boshdrain.ScriptParams) boshscript.CancellableScript {
+			script := &previewScript{FakeCancellableScript: &scriptfakes.FakeCancellableScript{}, params: params}
+			script.TagReturns(jobName)
+			script.ExistsReturns(jobName != "no-drain")
+			drainScripts[jobName] = script
+			return script
+		}
+	})
+
+	AssertActionIsNotAsynchronous(previewDrainAction)
+	AssertActionIsNotPersistent(previewDrainAction)
+	AssertActionIsLoggable(previewDrainAction)
+
+	AssertActionIsNotResumable(previewDrainAction)
+	AssertActionIsNotCancelable(previewDrainAction)
+
+	It("returns how the drain script of each job would be invoked by an update", func() {
+		previews, err := previewDrainAction.Run(newSpec)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(previews).To(Equal([]action.DrainPreview{
+			{
+				Job:        "backend",
+				DrainGroup: 2,
+				Invocation: boshdrain.Invocation{
+					Path: "/fake/backend/drain",
+					Args: []string{"job_changed", "hash_changed", "foo"},
+					Env:  map[string]string{"BOSH_JOB_NAME": "backend"},
+				},
+			},
+			{
+				Job: "metrics",
+				Invocation: boshdrain.Invocation{
+					Path: "/fake/metrics/drain",
+					Args: []string{"job_changed", "hash_changed", "foo"},
+					Env:  map[string]string{"BOSH_JOB_NAME": "metrics"},
+				},
+			},
+		}))
+	})
+
+	It("uses the update params of the new spec", func() {
+		_, err := previewDrainAction.Run(newSpec)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(drainScripts["backend"].params).To(Equal(boshdrain.NewUpdateParams(specService.Spec, newSpec)))
+	})
+
+	It("does not run any drain script", func() {
+		_, err := previewDrainAction.Run(newSpec)
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, script := range drainScripts {
+			Expect(script.RunCallCount()).To(Equal(0))
+		}
+	})
+
+	It("returns an empty list when no job has a drain script", func() {
+		specService.Spec.JobSpec.JobTemplateSpecs = []boshas.JobTemplateSpec{{Name: "no-drain"}}
+
+		Expect(previewDrainAction.Run(newSpec)).To(BeEmpty())
+	})
+
+	It("returns an error when a drain script cannot be previewed", func() {
+		drainScriptStub := jobScriptProvider.NewDrainScriptStub
+		jobScriptProvider.NewDrainScriptStub = func(jobName string, params boshdrain.ScriptParams) boshscript.CancellableScript {
+			script := drainScriptStub(jobName, params).(*previewScript)
+			if jobName == "metrics" {
+				script.err = errors.New("fake-preview-error")
+			}
+			return script
+		}
+
+		_, err := previewDrainAction.Run(newSpec)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Previewing drain script of job metrics"))
+		Expect(err.Error()).To(ContainSubstring("fake-preview-error"))
+	})
+
+	It("returns an error when the current spec cannot be read", func() {
+		specService.GetErr = errors.New("fake-get-error")
+
+		_, err := previewDrainAction.Run(newSpec)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-get-error"))
+	})
+})
+
+type previewScript struct {
+	*scriptfakes.FakeCancellableScript
+	params boshdrain.ScriptParams
+	err    error
+}
+
+func (s *previewScript) Preview() (boshdrain.Invocation, error) {
+	if s.err != nil {
+		return boshdrain.Invocation{}, s.err
+	}
+
+	return boshdrain.Invocation{
+		Path: "/fake/" + s.Tag() + "/drain",
+		Args: append([]string{s.params.JobChange(), s.params.HashChange()}, s.params.UpdatedPackages()...),
+		Env:  map[string]string{"BOSH_JOB_NAME": s.Tag()},
+	}, nil
+}
@@ -0,0 +1,55 @@
+package action
+
+import (
+	"errors"
+
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type RestartJobAction struct {
+	stopJobAction StopJobAction
+	jobSupervisor boshjobsuper.JobSupervisor
+}
+
+func NewRestartJob(stopJobAction StopJobAction, jobSupervisor boshjobsuper.JobSupervisor) RestartJobAction {
+	return RestartJobAction{
+		stopJobAction: stopJobAction,
+		jobSupervisor: jobSupervisor,
+	}
+}
+
+func (a RestartJobAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return true
+}
+
+func (a RestartJobAction) IsPersistent() bool {
+	return false
+}
+
+func (a RestartJobAction) IsLoggable() bool {
+	return true
+}
+
+// Run stops a job the same way stop_job does and starts it again
+func (a RestartJobAction) Run(name string) (string, error) {
+	err := a.stopJobAction.stop(name)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.jobSupervisor.StartJob(name)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Starting '%s'", name)
+	}
+
+	return "restarted", nil
+}
+
+func (a RestartJobAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a RestartJobAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,51 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/scriptfakes"
+	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+var _ = Describe("RestartJob", func() {
+	var (
+		jobSupervisor    *fakejobsuper.FakeJobSupervisor
+		restartJobAction action.RestartJobAction
+	)
+
+	BeforeEach(func() {
+		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
+		stopJobAction := action.NewStopJob(jobSupervisor, fakeas.NewFakeV1Service(), &scriptfakes.FakeJobScriptProvider{}, boshlog.NewLogger(boshlog.LevelNone))
+		restartJobAction = action.NewRestartJob(stopJobAction, jobSupervisor)
+	})
+
+	AssertActionIsAsynchronous(restartJobAction)
+	AssertActionIsNotPersistent(restartJobAction)
+	AssertActionIsLoggable(restartJobAction)
+
+	AssertActionIsNotResumable(restartJobAction)
+	AssertActionIsNotCancelable(restartJobAction)
+
+	It("stops and starts the job", func() {
+		restarted, err := restartJobAction.Run("fake-job")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restarted).To(Equal("restarted"))
+		Expect(jobSupervisor.StoppedJobs).To(Equal([]string{"fake-job"}))
+		Expect(jobSupervisor.StartedJobs).To(Equal([]string{"fake-job"}))
+	})
+
+	It("does not start the job when stopping it fails", func() {
+		jobSupervisor.StopJobErr = errors.New("fake-stop-job-err")
+
+		_, err := restartJobAction.Run("fake-job")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-stop-job-err"))
+		Expect(jobSupervisor.StartedJobs).To(BeEmpty())
+	})
+})
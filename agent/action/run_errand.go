@@ -2,11 +2,18 @@ package action
 
 import (
 	"errors"
+	"os"
 	"path"
 	"time"
 
+	"code.cloudfoundry.org/clock"
+
 	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	blobdelegator "github.com/cloudfoundry/bosh-agent/v2/agent/httpblobprovider/blobstore_delegator"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
+	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
+	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -15,32 +22,55 @@ import (
 const runErrandActionLogTag = "runErrandAction"
 
 type RunErrandAction struct {
-	specService boshas.V1Service
-	jobsDir     string
-	cmdRunner   boshsys.CmdRunner
-	logger      boshlog.Logger
+	specService       boshas.V1Service
+	dirProvider       boshdir.Provider
+	fs                boshsys.FileSystem
+	runner            cmd.Runner
+	jobScriptProvider boshscript.JobScriptProvider
+	blobDelegator     blobdelegator.BlobstoreDelegator
+	errands           *ErrandRegistry
+	timeService       clock.Clock
+	logger            boshlog.Logger
 
 	cancelCh chan struct{}
+	running  *runningErrand
 }
 
 func NewRunErrand(
 	specService boshas.V1Service,
-	jobsDir string,
-	cmdRunner boshsys.CmdRunner,
+	dirProvider boshdir.Provider,
+	fs boshsys.FileSystem,
+	runner cmd.Runner,
+	jobScriptProvider boshscript.JobScriptProvider,
+	blobDelegator blobdelegator.BlobstoreDelegator,
+	errands *ErrandRegistry,
+	timeService clock.Clock,
 	logger boshlog.Logger,
 ) RunErrandAction {
 	return RunErrandAction{
-		specService: specService,
-		jobsDir:     jobsDir,
-		cmdRunner:   cmdRunner,
-		logger:      logger,
+		specService:       specService,
+		dirProvider:       dirProvider,
+		fs:                fs,
+		runner:            runner,
+		jobScriptProvider: jobScriptProvider,
+		blobDelegator:     blobDelegator,
+		errands:           errands,
+		timeService:       timeService,
+		logger:            logger,
 
 		// Initialize channel in a constructor to avoid race
 		// between initializing in Run()/Cancel()
 		cancelCh: make(chan struct{}, 1),
+		running:  &runningErrand{},
 	}
 }
 
+// NewInstance gives each errand its own cancellation and progress
+// so errands of different job templates can run at the same time
+func (a RunErrandAction) NewInstance() Action {
+	return NewRunErrand(a.specService, a.dirProvider, a.fs, a.runner, a.jobScriptProvider, a.blobDelegator, a.errands, a.timeService, a.logger)
+}
+
 func (a RunErrandAction) IsAsynchronous(_ ProtocolVersion) bool {
 	return true
 }
@@ -53,10 +83,30 @@ func (a RunErrandAction) IsLoggable() bool {
 	return true
 }
 
+// IsConcurrent lets errands run while other errands are running,
+// the errand registry refuses a second errand of the same job
+func (a RunErrandAction) IsConcurrent() bool {
+	return true
+}
+
 type ErrandResult struct {
 	Stdout     string `json:"stdout"`
 	Stderr     string `json:"stderr"`
 	ExitStatus int    `json:"exit_code"`
+
+	// Full output of errands exceeding errandOutputLimit,
+	// Stdout and Stderr then only hold the end of it
+	StdoutBlob *ErrandOutputBlob `json:"stdout_blob,omitempty"`
+	StderrBlob *ErrandOutputBlob `json:"stderr_blob,omitempty"`
+
+	// Why output exceeding errandOutputLimit could not be uploaded,
+	// Stdout and Stderr then hold as much of its end as fits the limit
+	StdoutUploadError string `json:"stdout_upload_error,omitempty"`
+	StderrUploadError string `json:"stderr_upload_error,omitempty"`
+
+	// Where the full output was logged on the instance
+	StdoutPath string `json:"stdout_path,omitempty"`
+	StderrPath string `json:"stderr_path,omitempty"`
 }
 
 func (a RunErrandAction) Run(errandName ...string) (ErrandResult, error) {
@@ -88,9 +138,57 @@ func (a RunErrandAction) Run(errandName ...string) (ErrandResult, error) {
 		templateName = errandName[0]
 	}
 
-	command := cmd.BuildCommand(path.Join(a.jobsDir, templateName, "bin", "run"))
+	pidFile := path.Join(a.dirProvider.JobRunDir(templateName), "errand.pid")
+
+	err = a.errands.Start(templateName, pidFile)
+	if err != nil {
+		return ErrandResult{}, err
+	}
+
+	result, err := a.run(currentSpec, templateName, pidFile)
+	a.errands.Finish(templateName, result.ExitStatus, err)
+
+	return result, err
+}
+
+func (a RunErrandAction) run(currentSpec boshas.V1ApplySpec, templateName string, pidFile string) (ErrandResult, error) {
+	command := cmd.BuildCommand(path.Join(a.dirProvider.JobsDir(), templateName, "bin", "run"))
+
+	for name, value := range a.jobScriptProvider.ScriptEnv(templateName, boshscript.ReasonErrand) {
+		command.Env[name] = value
+	}
+
+	var limits cmd.Limits
+	for _, job := range currentSpec.Jobs() {
+		if job.BundleName() == templateName {
+			limits = boshscript.CmdLimits(job.Scripts["run"])
+		}
+	}
+
+	err := a.fs.MkdirAll(path.Dir(pidFile), os.FileMode(0750))
+	if err != nil {
+		return ErrandResult{}, bosherr.WrapError(err, "Creating errand run directory")
+	}
+
+	command = cmd.RecordPID(command, pidFile)
+
+	outputLog, err := outputlog.NewPerInvocation(a.fs, path.Join(a.dirProvider.LogsDir(), templateName, "run"), a.timeService.Now())
+	if err != nil {
+		return ErrandResult{}, bosherr.WrapError(err, "Capturing errand output")
+	}
+	defer func() {
+		_ = outputLog.Close()
+	}()
+
+	stdout, stderr := outputLog.Stdout, outputLog.Stderr
+
+	command.Stdout = stdout.Writer()
+	command.Stderr = stderr.Writer()
 
-	process, err := a.cmdRunner.RunComplexCommandAsync(command)
+	a.running.start(stdout, stderr)
+	defer a.running.finish()
+
+	process, err := a.runner.RunComplexCommandAsync(templateName, "run", command, limits)
 	if err != nil {
 		return ErrandResult{}, bosherr.WrapError(err, "Running errand script")
 	}
@@ -115,10 +213,87 @@ func (a RunErrandAction) Run(errandName ...string) (ErrandResult, error) {
 		return ErrandResult{}, bosherr.WrapError(result.Error, "Running errand script")
 	}
 
+	stdoutOutput, err := a.reportOutput(stdout)
+	if err != nil {
+		return ErrandResult{}, bosherr.WrapError(err, "Reading errand stdout")
+	}
+
+	stderrOutput, err := a.reportOutput(stderr)
+	if err != nil {
+		return ErrandResult{}, bosherr.WrapError(err, "Reading errand stderr")
+	}
+
 	return ErrandResult{
-		Stdout:     result.Stdout,
-		Stderr:     result.Stderr,
-		ExitStatus: result.ExitStatus,
+		Stdout:            stdoutOutput.tail,
+		Stderr:            stderrOutput.tail,
+		ExitStatus:        result.ExitStatus,
+		StdoutBlob:        stdoutOutput.blob,
+		StderrBlob:        stderrOutput.blob,
+		StdoutUploadError: stdoutOutput.uploadError,
+		StderrUploadError: stderrOutput.uploadError,
+		StdoutPath:        stdout.Path(),
+		StderrPath:        stderr.Path(),
+	}, nil
+}
+
+// Progress returns the end of the output of the running errand
+func (a RunErrandAction) Progress() interface{} {
+	return a.running.progress()
+}
+
+// reportedOutput is what the result of an errand holds of its stdout or stderr
+type reportedOutput struct {
+	tail        string
+	blob        *ErrandOutputBlob
+	uploadError string
+}
+
+// reportOutput returns the end of the output and uploads the full output to
+// the blobstore when it exceeds errandOutputLimit; failing to upload it is
+// reported instead of failing the errand, which did run
+func (a RunErrandAction) reportOutput(output *outputlog.File) (reportedOutput, error) {
+	data, truncated, err := output.Tail(errandOutputLimit)
+	if err != nil {
+		return reportedOutput{}, err
+	}
+
+	tail, shortened := jsonTail(data, errandOutputLimit)
+	if !truncated && !shortened {
+		return reportedOutput{tail: tail}, nil
+	}
+
+	blob, err := a.uploadOutput(output)
+	if err != nil {
+		a.logger.Error(runErrandActionLogTag, "Failed to upload %s: %s", output.Path(), err.Error())
+		return reportedOutput{tail: tail, uploadError: err.Error()}, nil
+	}
+
+	tail, _ = jsonTail([]byte(tail), errandUploadedOutputLimit)
+
+	return reportedOutput{tail: tail, blob: blob}, nil
+}
+
+// uploadOutput uploads the full output to the blobstore
+func (a RunErrandAction) uploadOutput(output *outputlog.File) (*ErrandOutputBlob, error) {
+	size, err := output.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	err = output.Close()
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Closing %s", output.Path())
+	}
+
+	blobID, digest, err := a.blobDelegator.Write("", output.Path(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ErrandOutputBlob{
+		BlobstoreID: blobID,
+		SHA1:        digest.String(),
+		Size:        size,
 	}, nil
 }
 
@@ -1,17 +1,26 @@
 package action_test
 
 import (
+	"encoding/json"
 	"errors"
 	"runtime"
+	"strings"
 	"time"
 
+	"code.cloudfoundry.org/clock/fakeclock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
 	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
 	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
+	fakeblobdelegator "github.com/cloudfoundry/bosh-agent/v2/agent/httpblobprovider/blobstore_delegator/blobstore_delegatorfakes"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd/cmdfakes"
 	boshenv "github.com/cloudfoundry/bosh-agent/v2/agent/script/pathenv"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/scriptfakes"
+	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
@@ -20,22 +29,43 @@ import (
 var _ = Describe("RunErrand", func() {
 	var (
 		specService     *fakeas.FakeV1Service
-		cmdRunner       *fakesys.FakeCmdRunner
+		fs              *fakesys.FakeFileSystem
+		scriptRunner    *cmdfakes.FakeRunner
+		scriptProvider  *scriptfakes.FakeJobScriptProvider
+		blobDelegator   *fakeblobdelegator.FakeBlobstoreDelegator
 		runErrandAction action.RunErrandAction
 		errandName      string
 		fullCommand     string
+		fakeClock       *fakeclock.FakeClock
+		errands         *action.ErrandRegistry
+		addProcess      func(*fakesys.FakeProcess)
 	)
 
 	BeforeEach(func() {
 		specService = fakeas.NewFakeV1Service()
-		cmdRunner = fakesys.NewFakeCmdRunner()
+		fs = fakesys.NewFakeFileSystem()
+		scriptRunner = &cmdfakes.FakeRunner{}
+		scriptProvider = &scriptfakes.FakeJobScriptProvider{}
+		scriptProvider.ScriptEnvReturns(map[string]string{"BOSH_JOB_NAME": "fake-job-name", "BOSH_SCRIPT_REASON": "errand"})
+		blobDelegator = &fakeblobdelegator.FakeBlobstoreDelegator{}
 		logger := boshlog.NewLogger(boshlog.LevelNone)
-		runErrandAction = action.NewRunErrand(specService, "/fake-jobs-dir", cmdRunner, logger)
+		fakeClock = fakeclock.NewFakeClock(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+		errands = action.NewErrandRegistry(fs, fakeClock)
+		runErrandAction = action.NewRunErrand(specService, boshdir.NewProvider("/fake-base-dir"), fs, scriptRunner, scriptProvider, blobDelegator, errands, fakeClock, logger)
 		errandName = "fake-job-name"
 		if runtime.GOOS == "windows" {
-			fullCommand = "powershell /fake-jobs-dir/fake-job-name/bin/run"
+			fullCommand = "powershell /fake-base-dir/jobs/fake-job-name/bin/run"
 		} else {
-			fullCommand = "/fake-jobs-dir/fake-job-name/bin/run"
+			fullCommand = `/bin/sh -c echo $$ > "$0" && exec "$@" /fake-base-dir/data/sys/run/fake-job-name/errand.pid /fake-base-dir/jobs/fake-job-name/bin/run`
+		}
+
+		// Errand processes write their output to the writers they are given
+		addProcess = func(process *fakesys.FakeProcess) {
+			scriptRunner.RunComplexCommandAsyncStub = func(_ string, _ string, command boshsys.Command, _ cmd.Limits) (boshsys.Process, error) {
+				_, _ = command.Stdout.Write([]byte("fake-stdout"))
+				_, _ = command.Stderr.Write([]byte("fake-stderr"))
+				return process, nil
+			}
 		}
 	})
 
@@ -52,7 +82,7 @@ var _ = Describe("RunErrand", func() {
 					currentSpec := boshas.V1ApplySpec{}
 					currentSpec.JobSpec.Template = "fake-job-name"
 					specService.Spec = currentSpec
-					cmdRunner.AddProcess(fullCommand, &fakesys.FakeProcess{
+					addProcess(&fakesys.FakeProcess{
 						WaitResult: boshsys.Result{
 							Stdout:     "fake-stdout",
 							Stderr:     "fake-stderr",
@@ -69,6 +99,8 @@ var _ = Describe("RunErrand", func() {
 							Stdout:     "fake-stdout",
 							Stderr:     "fake-stderr",
 							ExitStatus: 0,
+							StdoutPath: "/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stdout.log",
+							StderrPath: "/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stderr.log",
 						},
 					))
 				})
@@ -90,7 +122,7 @@ var _ = Describe("RunErrand", func() {
 
 				Context("when errand script exits with non-0 exit code (execution of script is ok)", func() {
 					BeforeEach(func() {
-						cmdRunner.AddProcess(fullCommand, &fakesys.FakeProcess{
+						addProcess(&fakesys.FakeProcess{
 							WaitResult: boshsys.Result{
 								Stdout:     "fake-stdout",
 								Stderr:     "fake-stderr",
@@ -107,6 +139,8 @@ var _ = Describe("RunErrand", func() {
 								Stdout:     "fake-stdout",
 								Stderr:     "fake-stderr",
 								ExitStatus: 0,
+								StdoutPath: "/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stdout.log",
+								StderrPath: "/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stderr.log",
 							},
 						))
 					})
@@ -114,15 +148,141 @@ var _ = Describe("RunErrand", func() {
 					It("runs errand script with properly configured environment", func() {
 						_, err := runErrandAction.Run(errandName)
 						Expect(err).ToNot(HaveOccurred())
-						cmd := cmdRunner.RunComplexCommands[0]
-						env := map[string]string{"PATH": boshenv.Path()}
-						Expect(cmd.Env).To(Equal(env))
+						jobName, scriptName, command, _ := scriptRunner.RunComplexCommandAsyncArgsForCall(0)
+						Expect(jobName).To(Equal("fake-job-name"))
+						Expect(scriptName).To(Equal("run"))
+						Expect(strings.Join(append([]string{command.Name}, command.Args...), " ")).To(Equal(fullCommand))
+						env := map[string]string{
+							"PATH":               boshenv.Path(),
+							"BOSH_JOB_NAME":      "fake-job-name",
+							"BOSH_SCRIPT_REASON": "errand",
+						}
+						Expect(command.Env).To(Equal(env))
+
+						Expect(scriptProvider.ScriptEnvCallCount()).To(Equal(1))
+						envJobName, reason := scriptProvider.ScriptEnvArgsForCall(0)
+						Expect(envJobName).To(Equal("fake-job-name"))
+						Expect(reason).To(Equal("errand"))
+					})
+				})
+
+				Context("when capturing errand output", func() {
+					It("writes the output to the errand's log files", func() {
+						addProcess(&fakesys.FakeProcess{})
+
+						_, err := runErrandAction.Run(errandName)
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(fs.ReadFileString("/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stdout.log")).To(Equal("fake-stdout"))
+						Expect(fs.ReadFileString("/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stderr.log")).To(Equal("fake-stderr"))
+						Expect(blobDelegator.WriteCallCount()).To(Equal(0))
+					})
+
+					It("reports the end of the output while the errand is running", func() {
+						Expect(runErrandAction.Progress()).To(BeNil())
+
+						addProcess(&fakesys.FakeProcess{
+							TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {
+								p.WaitCh <- boshsys.Result{ExitStatus: 143}
+							},
+						})
+
+						resultCh := make(chan error, 1)
+						go func() {
+							_, err := runErrandAction.Run(errandName)
+							resultCh <- err
+						}()
+
+						Eventually(runErrandAction.Progress).Should(Equal(action.ErrandProgress{
+							Stdout: "fake-stdout",
+							Stderr: "fake-stderr",
+						}))
+
+						Expect(runErrandAction.Cancel()).To(Succeed())
+						Eventually(resultCh).Should(Receive(BeNil()))
+						Expect(runErrandAction.Progress()).To(BeNil())
+					})
+
+					Context("when the output exceeds the limit", func() {
+						var largeOutput string
+
+						BeforeEach(func() {
+							largeOutput = strings.Repeat("a", 300*1024) + "the end"
+
+							scriptRunner.RunComplexCommandAsyncStub = func(_ string, _ string, command boshsys.Command, _ cmd.Limits) (boshsys.Process, error) {
+								_, _ = command.Stdout.Write([]byte(largeOutput))
+								_, _ = command.Stderr.Write([]byte("fake-stderr"))
+								return &fakesys.FakeProcess{WaitResult: boshsys.Result{ExitStatus: 0}}, nil
+							}
+
+							blobDelegator.WriteReturns("fake-blob-id", boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, "fake-sha1")), nil)
+						})
+
+						It("uploads the full output and returns its end", func() {
+							result, err := runErrandAction.Run(errandName)
+							Expect(err).ToNot(HaveOccurred())
+
+							Expect(result.Stdout).To(HaveLen(16*1024 - 2))
+							Expect(result.Stdout).To(HaveSuffix("the end"))
+							Expect(result.StdoutBlob).To(Equal(&action.ErrandOutputBlob{
+								BlobstoreID: "fake-blob-id",
+								SHA1:        "fake-sha1",
+								Size:        int64(len(largeOutput)),
+							}))
+							Expect(result.Stderr).To(Equal("fake-stderr"))
+							Expect(result.StderrBlob).To(BeNil())
+
+							Expect(blobDelegator.WriteCallCount()).To(Equal(1))
+							signedURL, path, _ := blobDelegator.WriteArgsForCall(0)
+							Expect(signedURL).To(BeEmpty())
+							Expect(path).To(Equal("/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stdout.log"))
+						})
+
+						It("returns as much of the end of the output as fits and why it was not uploaded when uploading fails", func() {
+							blobDelegator.WriteReturns("", boshcrypto.MultipleDigest{}, errors.New("fake-write-error"))
+
+							result, err := runErrandAction.Run(errandName)
+							Expect(err).ToNot(HaveOccurred())
+
+							Expect(result.Stdout).To(HaveLen(256*1024 - 2))
+							Expect(result.Stdout).To(HaveSuffix("the end"))
+							Expect(result.StdoutBlob).To(BeNil())
+							Expect(result.StdoutUploadError).To(Equal("fake-write-error"))
+							Expect(result.StdoutPath).To(Equal("/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stdout.log"))
+							Expect(result.Stderr).To(Equal("fake-stderr"))
+							Expect(result.StderrUploadError).To(BeEmpty())
+						})
+
+						It("limits the output by its size once JSON encoded", func() {
+							escapedOutput := strings.Repeat("\x1b", 100*1024)
+							scriptRunner.RunComplexCommandAsyncStub = func(_ string, _ string, command boshsys.Command, _ cmd.Limits) (boshsys.Process, error) {
+								_, _ = command.Stdout.Write([]byte(escapedOutput))
+								return &fakesys.FakeProcess{WaitResult: boshsys.Result{ExitStatus: 0}}, nil
+							}
+
+							result, err := runErrandAction.Run(errandName)
+							Expect(err).ToNot(HaveOccurred())
+
+							encoded, err := json.Marshal(result.Stdout)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(len(encoded)).To(BeNumerically("<=", 16*1024))
+							Expect(result.StdoutBlob).ToNot(BeNil())
+						})
+					})
+
+					It("returns an error when the log file cannot be opened", func() {
+						fs.OpenFileErr = errors.New("fake-open-error")
+
+						_, err := runErrandAction.Run(errandName)
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("Capturing errand output"))
+						Expect(scriptRunner.RunComplexCommandAsyncCallCount()).To(Equal(0))
 					})
 				})
 
 				Context("when errand script fails with non-0 exit code (execution of script is ok)", func() {
 					BeforeEach(func() {
-						cmdRunner.AddProcess(fullCommand, &fakesys.FakeProcess{
+						addProcess(&fakesys.FakeProcess{
 							WaitResult: boshsys.Result{
 								Stdout:     "fake-stdout",
 								Stderr:     "fake-stderr",
@@ -140,6 +300,8 @@ var _ = Describe("RunErrand", func() {
 								Stdout:     "fake-stdout",
 								Stderr:     "fake-stderr",
 								ExitStatus: 123,
+								StdoutPath: "/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stdout.log",
+								StderrPath: "/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stderr.log",
 							},
 						))
 					})
@@ -147,7 +309,7 @@ var _ = Describe("RunErrand", func() {
 
 				Context("when errand script fails to execute", func() {
 					BeforeEach(func() {
-						cmdRunner.AddProcess(fullCommand, &fakesys.FakeProcess{
+						addProcess(&fakesys.FakeProcess{
 							WaitResult: boshsys.Result{
 								ExitStatus: -1,
 								Error:      errors.New("fake-bosh-error"),
@@ -178,7 +340,7 @@ var _ = Describe("RunErrand", func() {
 				It("does not run errand script", func() {
 					_, err := runErrandAction.Run(errandName)
 					Expect(err).To(HaveOccurred())
-					Expect(len(cmdRunner.RunComplexCommands)).To(Equal(0))
+					Expect(scriptRunner.RunComplexCommandAsyncCallCount()).To(Equal(0))
 				})
 			})
 		})
@@ -197,7 +359,7 @@ var _ = Describe("RunErrand", func() {
 			It("does not run errand script", func() {
 				_, err := runErrandAction.Run(errandName)
 				Expect(err).To(HaveOccurred())
-				Expect(len(cmdRunner.RunComplexCommands)).To(Equal(0))
+				Expect(scriptRunner.RunComplexCommandAsyncCallCount()).To(Equal(0))
 			})
 		})
 	})
@@ -231,7 +393,7 @@ var _ = Describe("RunErrand", func() {
 					},
 				}
 
-				cmdRunner.AddProcess(fullCommand, process)
+				addProcess(process)
 
 				err := runErrandAction.Cancel()
 				Expect(err).ToNot(HaveOccurred())
@@ -244,7 +406,7 @@ var _ = Describe("RunErrand", func() {
 
 			Context("when errand script exits with non-0 exit code (execution of script is ok)", func() {
 				BeforeEach(func() {
-					cmdRunner.AddProcess(fullCommand, &fakesys.FakeProcess{
+					addProcess(&fakesys.FakeProcess{
 						TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {
 							p.WaitCh <- boshsys.Result{
 								Stdout:     "fake-stdout",
@@ -266,6 +428,8 @@ var _ = Describe("RunErrand", func() {
 							Stdout:     "fake-stdout",
 							Stderr:     "fake-stderr",
 							ExitStatus: 0,
+							StdoutPath: "/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stdout.log",
+							StderrPath: "/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stderr.log",
 						},
 					))
 				})
@@ -273,7 +437,7 @@ var _ = Describe("RunErrand", func() {
 
 			Context("when errand script fails with non-0 exit code (execution of script is ok)", func() {
 				BeforeEach(func() {
-					cmdRunner.AddProcess(fullCommand, &fakesys.FakeProcess{
+					addProcess(&fakesys.FakeProcess{
 						TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {
 							p.WaitCh <- boshsys.Result{
 								Stdout:     "fake-stdout",
@@ -296,6 +460,8 @@ var _ = Describe("RunErrand", func() {
 							Stdout:     "fake-stdout",
 							Stderr:     "fake-stderr",
 							ExitStatus: 123,
+							StdoutPath: "/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stdout.log",
+							StderrPath: "/fake-base-dir/sys/log/fake-job-name/run.20261019T120000.000000000Z.stderr.log",
 						},
 					))
 				})
@@ -303,7 +469,7 @@ var _ = Describe("RunErrand", func() {
 
 			Context("when errand script fails to execute", func() {
 				BeforeEach(func() {
-					cmdRunner.AddProcess(fullCommand, &fakesys.FakeProcess{
+					addProcess(&fakesys.FakeProcess{
 						TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {
 							p.WaitCh <- boshsys.Result{
 								ExitStatus: -1,
@@ -327,7 +493,7 @@ var _ = Describe("RunErrand", func() {
 
 		Context("when runErrandAction was cancelled already", func() {
 			BeforeEach(func() {
-				cmdRunner.AddProcess(fullCommand, &fakesys.FakeProcess{
+				addProcess(&fakesys.FakeProcess{
 					TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {
 						p.WaitCh <- boshsys.Result{
 							ExitStatus: -1,
@@ -346,4 +512,97 @@ var _ = Describe("RunErrand", func() {
 			})
 		})
 	})
+
+	Describe("concurrent errands", func() {
+		var processes map[string]*fakesys.FakeProcess
+
+		BeforeEach(func() {
+			specService.Spec = boshas.V1ApplySpec{
+				JobSpec: boshas.JobSpec{
+					JobTemplateSpecs: []boshas.JobTemplateSpec{
+						{Version: "v1", Name: "first-job"},
+						{Version: "v1", Name: "fake-job-name"},
+					},
+				},
+			}
+
+			processes = map[string]*fakesys.FakeProcess{}
+			for _, name := range []string{"first-job", "fake-job-name"} {
+				processes[name] = &fakesys.FakeProcess{
+					TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {
+						p.WaitCh <- boshsys.Result{ExitStatus: 143}
+					},
+				}
+			}
+
+			scriptRunner.RunComplexCommandAsyncStub = func(jobName string, _ string, _ boshsys.Command, _ cmd.Limits) (boshsys.Process, error) {
+				return processes[jobName], nil
+			}
+		})
+
+		It("runs errands of different job templates with their own cancellation", func() {
+			firstErrand := runErrandAction.NewInstance().(action.RunErrandAction)
+			secondErrand := runErrandAction.NewInstance().(action.RunErrandAction)
+
+			firstResultCh := make(chan action.ErrandResult, 1)
+			go func() {
+				defer GinkgoRecover()
+				result, err := firstErrand.Run("first-job")
+				Expect(err).ToNot(HaveOccurred())
+				firstResultCh <- result
+			}()
+
+			// Fakes are not safe for concurrent use so errands start one after the other
+			Eventually(scriptRunner.RunComplexCommandAsyncCallCount).Should(Equal(1))
+
+			secondResultCh := make(chan action.ErrandResult, 1)
+			go func() {
+				defer GinkgoRecover()
+				result, err := secondErrand.Run("fake-job-name")
+				Expect(err).ToNot(HaveOccurred())
+				secondResultCh <- result
+			}()
+
+			Eventually(scriptRunner.RunComplexCommandAsyncCallCount).Should(Equal(2))
+
+			Expect(firstErrand.Cancel()).To(Succeed())
+
+			var result action.ErrandResult
+			Eventually(firstResultCh).Should(Receive(&result))
+			Expect(result.ExitStatus).To(Equal(143))
+			Consistently(secondResultCh).ShouldNot(Receive())
+
+			statuses := errands.List()
+			Expect(statuses).To(HaveLen(2))
+			Expect(statuses[0].Name).To(Equal("fake-job-name"))
+			Expect(statuses[0].State).To(Equal(action.ErrandStateRunning))
+			Expect(statuses[1].Name).To(Equal("first-job"))
+			Expect(statuses[1].State).To(Equal(action.ErrandStateFinished))
+			Expect(*statuses[1].ExitCode).To(Equal(143))
+
+			Expect(secondErrand.Cancel()).To(Succeed())
+			Eventually(secondResultCh).Should(Receive())
+		})
+
+		It("does not run an errand that is already running", func() {
+			Expect(errands.Start("fake-job-name", "/fake-pid-file")).To(Succeed())
+
+			_, err := runErrandAction.Run("fake-job-name")
+			Expect(err).To(MatchError("Errand fake-job-name is already running"))
+			Expect(scriptRunner.RunComplexCommandAsyncCallCount()).To(Equal(0))
+		})
+
+		It("records errands failing to run", func() {
+			scriptRunner.RunComplexCommandAsyncStub = nil
+			scriptRunner.RunComplexCommandAsyncReturns(nil, errors.New("fake-start-error"))
+
+			_, err := runErrandAction.Run("fake-job-name")
+			Expect(err).To(HaveOccurred())
+
+			statuses := errands.List()
+			Expect(statuses).To(HaveLen(1))
+			Expect(statuses[0].State).To(Equal(action.ErrandStateFailed))
+			Expect(statuses[0].Error).To(ContainSubstring("fake-start-error"))
+		})
+	})
 })
@@ -2,13 +2,20 @@ package action
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 
 	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
 	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 )
 
+// runScriptErrorTailLength is how much of the end of the stderr of each
+// script is kept in the error, which is all the director gets on failure
+const runScriptErrorTailLength = 1024
+
 type RunScriptOptions struct {
 	Env map[string]string `json:"env"`
 }
@@ -47,24 +54,71 @@ func (a RunScriptAction) IsLoggable() bool {
 	return true
 }
 
-func (a RunScriptAction) Run(scriptName string, options RunScriptOptions) (map[string]string, error) {
-	// May be used in future to return more information
-	emptyResults := map[string]string{}
+// Run runs the script of every job that has one and returns, keyed by
+// job, where each of them logged its output and how that output ended
+func (a RunScriptAction) Run(scriptName string, options RunScriptOptions) (map[string]outputlog.Output, error) {
+	results := map[string]outputlog.Output{}
 
 	currentSpec, err := a.specService.Get()
 	if err != nil {
-		return emptyResults, bosherr.WrapError(err, "Getting current spec")
+		return results, bosherr.WrapError(err, "Getting current spec")
+	}
+
+	env := map[string]string{boshscript.ReasonEnvVar: boshscript.ReasonRunScript}
+	for name, value := range options.Env {
+		env[name] = value
 	}
 
 	scripts := make([]boshscript.Script, 0, len(currentSpec.Jobs()))
 	for _, job := range currentSpec.Jobs() {
-		script := a.scriptProvider.NewScript(job.BundleName(), scriptName, options.Env)
+		script := a.scriptProvider.NewScript(job.BundleName(), scriptName, env)
 		scripts = append(scripts, script)
 	}
 
 	parallelScript := a.scriptProvider.NewParallelScript(scriptName, scripts)
+	err = parallelScript.Run()
+
+	for _, script := range scripts {
+		if loggingScript, ok := script.(boshscript.LoggingScript); ok {
+			if output, ran := loggingScript.Output(); ran {
+				results[script.Tag()] = output
+			}
+		}
+	}
+
+	if err != nil {
+		return results, a.describeOutputs(err, results)
+	}
+
+	return results, nil
+}
+
+// describeOutputs adds where the scripts that ran logged their output and
+// how their stderr ended to err, since the results are not returned with it
+func (a RunScriptAction) describeOutputs(err error, results map[string]outputlog.Output) error {
+	if len(results) == 0 {
+		return err
+	}
+
+	jobs := make([]string, 0, len(results))
+	for job := range results {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+
+	errMsg := err.Error()
+	for _, job := range jobs {
+		output := results[job]
+
+		stderr := output.Stderr
+		if len(stderr) > runScriptErrorTailLength {
+			stderr = stderr[len(stderr)-runScriptErrorTailLength:]
+		}
+
+		errMsg += fmt.Sprintf("\nJob %s logged to %s and %s, stderr ended with: %s", job, output.StdoutPath, output.StderrPath, stderr)
+	}
 
-	return emptyResults, parallelScript.Run()
+	return bosherr.Error(errMsg)
 }
 
 func (a RunScriptAction) Resume() (interface{}, error) {
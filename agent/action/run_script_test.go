@@ -2,6 +2,7 @@ package action_test
 
 import (
 	"errors"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -9,6 +10,7 @@ import (
 	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
 	fakeapplyspec "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
 	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/script/scriptfakes"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -43,7 +45,7 @@ var _ = Describe("RunScript", func() {
 	AssertActionIsNotCancelable(runScriptAction)
 
 	Describe("Run", func() {
-		act := func() (map[string]string, error) { return runScriptAction.Run("run-me", options) }
+		act := func() (map[string]outputlog.Output, error) { return runScriptAction.Run("run-me", options) }
 
 		Context("when current spec can be retrieved", func() {
 			var parallelScript *scriptfakes.FakeCancellableScript
@@ -70,6 +72,7 @@ var _ = Describe("RunScript", func() {
 				fakeJobScriptProvider.NewScriptStub = func(jobName, scriptName string, scriptEnv map[string]string) boshscript.Script {
 					Expect(scriptName).To(Equal("run-me"))
 					Expect(scriptEnv["FOO"]).To(Equal("foo"))
+					Expect(scriptEnv["BOSH_SCRIPT_REASON"]).To(Equal("run_script"))
 
 					if jobName == "fake-job-1" {
 						return script1
@@ -84,7 +87,7 @@ var _ = Describe("RunScript", func() {
 
 				results, err := act()
 				Expect(err).ToNot(HaveOccurred())
-				Expect(results).To(Equal(map[string]string{}))
+				Expect(results).To(Equal(map[string]outputlog.Output{}))
 
 				Expect(parallelScript.RunCallCount()).To(Equal(1))
 
@@ -93,13 +96,72 @@ var _ = Describe("RunScript", func() {
 				Expect(scripts).To(Equal([]boshscript.Script{script1, script2}))
 			})
 
+			It("returns where the scripts that ran logged their output", func() {
+				createFakeJob("fake-job-1")
+				script1 := &scriptfakes.FakeLoggingScript{}
+				script1.TagReturns("fake-job-1")
+				output := outputlog.Output{StdoutPath: "/fake/run-me.stdout.log", Stdout: "fake-stdout"}
+				script1.OutputReturns(output, true)
+
+				createFakeJob("fake-job-2")
+				script2 := &scriptfakes.FakeLoggingScript{}
+				script2.TagReturns("fake-job-2")
+
+				fakeJobScriptProvider.NewScriptStub = func(jobName, scriptName string, scriptEnv map[string]string) boshscript.Script {
+					if jobName == "fake-job-1" {
+						return script1
+					}
+					return script2
+				}
+
+				parallelScript.RunReturns(errors.New("fake-error"))
+
+				results, err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(results).To(Equal(map[string]outputlog.Output{"fake-job-1": output}))
+			})
+
+			It("returns where the scripts that ran logged their output and how their stderr ended in the error", func() {
+				createFakeJob("fake-job-1")
+				script1 := &scriptfakes.FakeLoggingScript{}
+				script1.TagReturns("fake-job-1")
+				script1.OutputReturns(outputlog.Output{
+					StdoutPath: "/fake/job-1/run-me.stdout.log",
+					StderrPath: "/fake/job-1/run-me.stderr.log",
+					Stderr:     strings.Repeat("a", 2048) + "fake-stderr",
+				}, true)
+
+				createFakeJob("fake-job-2")
+				script2 := &scriptfakes.FakeLoggingScript{}
+				script2.TagReturns("fake-job-2")
+				script2.OutputReturns(outputlog.Output{
+					StdoutPath: "/fake/job-2/run-me.stdout.log",
+					StderrPath: "/fake/job-2/run-me.stderr.log",
+				}, true)
+
+				fakeJobScriptProvider.NewScriptStub = func(jobName, scriptName string, scriptEnv map[string]string) boshscript.Script {
+					if jobName == "fake-job-1" {
+						return script1
+					}
+					return script2
+				}
+
+				parallelScript.RunReturns(errors.New("fake-error"))
+
+				_, err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("fake-error" +
+					"\nJob fake-job-1 logged to /fake/job-1/run-me.stdout.log and /fake/job-1/run-me.stderr.log, stderr ended with: " + strings.Repeat("a", 1024-len("fake-stderr")) + "fake-stderr" +
+					"\nJob fake-job-2 logged to /fake/job-2/run-me.stdout.log and /fake/job-2/run-me.stderr.log, stderr ended with: "))
+			})
+
 			It("returns an error when parallel script fails", func() {
 				parallelScript.RunReturns(errors.New("fake-error"))
 
 				results, err := act()
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-error"))
-				Expect(results).To(Equal(map[string]string{}))
+				Expect(results).To(Equal(map[string]outputlog.Output{}))
 			})
 		})
 
@@ -110,7 +172,7 @@ var _ = Describe("RunScript", func() {
 				results, err := act()
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-spec-get-error"))
-				Expect(results).To(Equal(map[string]string{}))
+				Expect(results).To(Equal(map[string]outputlog.Output{}))
 			})
 		})
 	})
@@ -24,7 +24,22 @@ func NewStart(jobSupervisor boshjobsuper.JobSupervisor, applier boshappl.Applier
 	return
 }
 
+// IsAsynchronous is true when jobs declare dependencies since jobs
+// then only start once the jobs they depend on are running, which
+// takes longer than callers wait for synchronous replies
 func (a StartAction) IsAsynchronous(_ ProtocolVersion) bool {
+	desiredApplySpec, err := a.specService.Get()
+	if err != nil {
+		// Run reports the error
+		return false
+	}
+
+	for _, job := range desiredApplySpec.JobSpec.JobTemplateSpecs {
+		if len(job.DependsOn) > 0 {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -0,0 +1,48 @@
+package action
+
+import (
+	"errors"
+
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type StartJobAction struct {
+	jobSupervisor boshjobsuper.JobSupervisor
+}
+
+func NewStartJob(jobSupervisor boshjobsuper.JobSupervisor) StartJobAction {
+	return StartJobAction{
+		jobSupervisor: jobSupervisor,
+	}
+}
+
+func (a StartJobAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return true
+}
+
+func (a StartJobAction) IsPersistent() bool {
+	return false
+}
+
+func (a StartJobAction) IsLoggable() bool {
+	return true
+}
+
+// Run starts the processes of a job, or a single process
+func (a StartJobAction) Run(name string) (string, error) {
+	err := a.jobSupervisor.StartJob(name)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Starting '%s'", name)
+	}
+
+	return "started", nil
+}
+
+func (a StartJobAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a StartJobAction) Cancel() error {
+	return errors.New("not supported")
+}
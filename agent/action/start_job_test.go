@@ -0,0 +1,45 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
+)
+
+var _ = Describe("StartJob", func() {
+	var (
+		jobSupervisor  *fakejobsuper.FakeJobSupervisor
+		startJobAction action.StartJobAction
+	)
+
+	BeforeEach(func() {
+		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
+		startJobAction = action.NewStartJob(jobSupervisor)
+	})
+
+	AssertActionIsAsynchronous(startJobAction)
+	AssertActionIsNotPersistent(startJobAction)
+	AssertActionIsLoggable(startJobAction)
+
+	AssertActionIsNotResumable(startJobAction)
+	AssertActionIsNotCancelable(startJobAction)
+
+	It("starts the job and returns started", func() {
+		started, err := startJobAction.Run("fake-job")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(started).To(Equal("started"))
+		Expect(jobSupervisor.StartedJobs).To(Equal([]string{"fake-job"}))
+	})
+
+	It("returns an error when starting the job fails", func() {
+		jobSupervisor.StartJobErr = errors.New("fake-start-job-err")
+
+		_, err := startJobAction.Run("fake-job")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-start-job-err"))
+	})
+})
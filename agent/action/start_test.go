@@ -7,6 +7,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
 	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
 	fakeappl "github.com/cloudfoundry/bosh-agent/v2/agent/applier/fakes"
 	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
@@ -27,13 +28,35 @@ var _ = Describe("Start", func() {
 		startAction = action.NewStart(jobSupervisor, applier, specService)
 	})
 
-	AssertActionIsNotAsynchronous(startAction)
 	AssertActionIsNotPersistent(startAction)
 	AssertActionIsLoggable(startAction)
 
 	AssertActionIsNotResumable(startAction)
 	AssertActionIsNotCancelable(startAction)
 
+	It("is not asynchronous when jobs declare no dependencies", func() {
+		specService.Spec = boshas.V1ApplySpec{
+			JobSpec: boshas.JobSpec{
+				JobTemplateSpecs: []boshas.JobTemplateSpec{{Name: "fake-job"}},
+			},
+		}
+
+		Expect(startAction.IsAsynchronous(action.ProtocolVersion(1))).To(BeFalse())
+	})
+
+	It("is asynchronous when jobs declare dependencies", func() {
+		specService.Spec = boshas.V1ApplySpec{
+			JobSpec: boshas.JobSpec{
+				JobTemplateSpecs: []boshas.JobTemplateSpec{
+					{Name: "fake-db"},
+					{Name: "fake-app", DependsOn: []string{"fake-db"}},
+				},
+			},
+		}
+
+		Expect(startAction.IsAsynchronous(action.ProtocolVersion(1))).To(BeTrue())
+	})
+
 	It("returns started", func() {
 		started, err := startAction.Run()
 		Expect(err).ToNot(HaveOccurred())
@@ -3,17 +3,54 @@ package action
 import (
 	"errors"
 
+	"code.cloudfoundry.org/clock"
+
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/applier/models"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
+	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+const (
+	PreStopScriptName  = "pre-stop"
+	PostStopScriptName = "post-stop"
+
+	// StopReasonEnvVar tells the pre-stop and post-stop scripts why their job is stopped
+	StopReasonEnvVar  = "BOSH_STOP_REASON"
+	DefaultStopReason = "stop"
+
+	StopHookSucceeded = "succeeded"
+	StopHookFailed    = "failed"
 )
 
 type StopAction struct {
-	jobSupervisor boshjobsuper.JobSupervisor
+	jobSupervisor     boshjobsuper.JobSupervisor
+	specService       boshas.V1Service
+	jobScriptProvider boshscript.JobScriptProvider
+	timeService       clock.Clock
+
+	logTag string
+	logger boshlog.Logger
 }
 
-func NewStop(jobSupervisor boshjobsuper.JobSupervisor) (stop StopAction) {
+func NewStop(
+	jobSupervisor boshjobsuper.JobSupervisor,
+	specService boshas.V1Service,
+	jobScriptProvider boshscript.JobScriptProvider,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) (stop StopAction) {
 	stop = StopAction{
-		jobSupervisor: jobSupervisor,
+		jobSupervisor:     jobSupervisor,
+		specService:       specService,
+		jobScriptProvider: jobScriptProvider,
+		timeService:       timeService,
+
+		logTag: "Stop Action",
+		logger: logger,
 	}
 	return
 }
@@ -30,20 +67,102 @@ func (a StopAction) IsLoggable() bool {
 	return true
 }
 
-func (a StopAction) Run(protocolVersion ProtocolVersion) (value string, err error) {
-	if protocolVersion > 2 {
-		err = a.jobSupervisor.StopAndWait()
-	} else {
-		err = a.jobSupervisor.Stop()
+type StopOptions struct {
+	// Run the pre-stop and post-stop scripts of the jobs, for callers
+	// that do not run them themselves with run_script
+	RunHooks bool `json:"run_hooks"`
+
+	// Why the jobs are stopped, e.g. "update" or "delete"
+	Reason string `json:"reason"`
+
+	// Return a StopResponse instead of "stopped" when the agent waits
+	// for the jobs to stop, for callers that understand it
+	Detailed bool `json:"detailed"`
+}
+
+// StopResponse reports how each job was stopped when the agent waited for
+// the jobs and the caller asked for the details
+type StopResponse struct {
+	State string                       `json:"state"`
+	Jobs  []boshjobsuper.JobStopResult `json:"jobs"`
+
+	// Outcome of the pre-stop and post-stop scripts of the jobs that have them
+	PreStop  []StopHookResult `json:"pre_stop,omitempty"`
+	PostStop []StopHookResult `json:"post_stop,omitempty"`
+}
+
+type StopHookResult struct {
+	Job string `json:"job"`
+
+	// "succeeded" or "failed"
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+
+	// Seconds it took for the script to finish
+	Duration float64 `json:"duration"`
+
+	// Where the script logged its output
+	Output *outputlog.Output `json:"output,omitempty"`
+}
+
+// Run stops the jobs. When asked to, it runs the pre-stop scripts of all
+// jobs before and, when it waits for the jobs to stop, their post-stop
+// scripts after. Failed scripts are reported but do not keep the jobs from
+// being stopped. Scripts whose job declares no timeout are terminated after
+// boshscript.DefaultStopHookTimeout.
+func (a StopAction) Run(protocolVersion ProtocolVersion, options ...StopOptions) (interface{}, error) {
+	var opts StopOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	reason := DefaultStopReason
+	if opts.Reason != "" {
+		reason = opts.Reason
+	}
+
+	var jobs []models.Job
+	if opts.RunHooks {
+		jobs = a.jobs()
+	}
+
+	env := map[string]string{
+		boshscript.ReasonEnvVar: boshscript.ReasonStop,
+		StopReasonEnvVar:        reason,
 	}
 
+	preStop := a.runHooks(PreStopScriptName, jobs, env)
+
+	if protocolVersion <= 2 {
+		err := a.jobSupervisor.Stop()
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Stopping Monitored Services")
+		}
+
+		// The jobs may still be running, so their post-stop scripts cannot run
+		if opts.RunHooks {
+			a.logger.Info(a.logTag, "Not running post-stop scripts, jobs are not waited for with protocol version %d", protocolVersion)
+		}
+
+		return "stopped", nil
+	}
+
+	results, err := a.jobSupervisor.StopAndWait()
 	if err != nil {
-		err = bosherr.WrapError(err, "Stopping Monitored Services")
-		return
+		return nil, bosherr.WrapError(err, "Stopping Monitored Services")
 	}
 
-	value = "stopped"
-	return
+	if results == nil {
+		results = []boshjobsuper.JobStopResult{}
+	}
+
+	postStop := a.runHooks(PostStopScriptName, jobs, env)
+
+	if !opts.Detailed {
+		return "stopped", nil
+	}
+
+	return StopResponse{State: "stopped", Jobs: results, PreStop: preStop, PostStop: postStop}, nil
 }
 
 func (a StopAction) Resume() (interface{}, error) {
@@ -53,3 +172,76 @@ func (a StopAction) Resume() (interface{}, error) {
 func (a StopAction) Cancel() error {
 	return errors.New("not supported")
 }
+
+func (a StopAction) jobs() []models.Job {
+	currentSpec, err := a.specService.Get()
+	if err != nil {
+		a.logger.Warn(a.logTag, "Not running stop scripts, getting current spec failed: %s", err.Error())
+		return nil
+	}
+
+	return currentSpec.Jobs()
+}
+
+// runHooks runs the script of all jobs in parallel and returns
+// the results of the jobs that have the script
+func (a StopAction) runHooks(scriptName string, jobs []models.Job, env map[string]string) []StopHookResult {
+	if len(jobs) == 0 {
+		return []StopHookResult{}
+	}
+
+	results := make([]StopHookResult, len(jobs))
+	scripts := make([]boshscript.Script, len(jobs))
+
+	for i, job := range jobs {
+		results[i].Job = job.BundleName()
+		scripts[i] = stopHook{
+			Script:      a.jobScriptProvider.NewScript(job.BundleName(), scriptName, env),
+			timeService: a.timeService,
+			result:      &results[i],
+		}
+	}
+
+	err := a.jobScriptProvider.NewParallelScript(scriptName, scripts).Run()
+	if err != nil {
+		a.logger.Warn(a.logTag, "Continuing after %s scripts failed: %s", scriptName, err.Error())
+	}
+
+	ran := []StopHookResult{}
+	for _, result := range results {
+		if result.Result != "" {
+			ran = append(ran, result)
+		}
+	}
+
+	return ran
+}
+
+// stopHook records the outcome of a pre-stop or post-stop script
+type stopHook struct {
+	boshscript.Script
+
+	timeService clock.Clock
+	result      *StopHookResult
+}
+
+func (h stopHook) Run() error {
+	startedAt := h.timeService.Now()
+
+	err := h.Script.Run()
+
+	h.result.Duration = h.timeService.Since(startedAt).Seconds()
+	h.result.Result = StopHookSucceeded
+	if err != nil {
+		h.result.Result = StopHookFailed
+		h.result.Error = err.Error()
+	}
+
+	if loggingScript, ok := h.Script.(boshscript.LoggingScript); ok {
+		if output, ran := loggingScript.Output(); ran {
+			h.result.Output = &output
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,102 @@
+package action
+
+import (
+	"errors"
+
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
+	boshdrain "github.com/cloudfoundry/bosh-agent/v2/agent/script/drain"
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type StopJobAction struct {
+	jobSupervisor     boshjobsuper.JobSupervisor
+	specService       boshas.V1Service
+	jobScriptProvider boshscript.JobScriptProvider
+
+	logTag string
+	logger boshlog.Logger
+}
+
+func NewStopJob(
+	jobSupervisor boshjobsuper.JobSupervisor,
+	specService boshas.V1Service,
+	jobScriptProvider boshscript.JobScriptProvider,
+	logger boshlog.Logger,
+) StopJobAction {
+	return StopJobAction{
+		jobSupervisor:     jobSupervisor,
+		specService:       specService,
+		jobScriptProvider: jobScriptProvider,
+
+		logTag: "Stop Job Action",
+		logger: logger,
+	}
+}
+
+func (a StopJobAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return true
+}
+
+func (a StopJobAction) IsPersistent() bool {
+	return false
+}
+
+func (a StopJobAction) IsLoggable() bool {
+	return true
+}
+
+// Run drains a job and waits for its processes to stop. Single
+// processes are stopped without draining.
+func (a StopJobAction) Run(name string) (string, error) {
+	err := a.stop(name)
+	if err != nil {
+		return "", err
+	}
+
+	return "stopped", nil
+}
+
+func (a StopJobAction) stop(name string) error {
+	currentSpec, err := a.specService.Get()
+	if err != nil {
+		return bosherr.WrapError(err, "Getting current spec")
+	}
+
+	for _, job := range currentSpec.Jobs() {
+		if job.Name != name {
+			continue
+		}
+
+		script := a.jobScriptProvider.NewDrainScript(job.BundleName(), boshdrain.NewShutdownParams(currentSpec, nil))
+		if !script.Exists() {
+			break
+		}
+
+		a.logger.Debug(a.logTag, "Draining job '%s'", name)
+
+		err = script.Run()
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Draining job '%s'", name)
+		}
+
+		break
+	}
+
+	err = a.jobSupervisor.StopJob(name)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Stopping '%s'", name)
+	}
+
+	return nil
+}
+
+func (a StopJobAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a StopJobAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,112 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
+	boshdrain "github.com/cloudfoundry/bosh-agent/v2/agent/script/drain"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/scriptfakes"
+	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+var _ = Describe("StopJob", func() {
+	var (
+		jobSupervisor     *fakejobsuper.FakeJobSupervisor
+		specService       *fakeas.FakeV1Service
+		jobScriptProvider *scriptfakes.FakeJobScriptProvider
+		drainScript       *scriptfakes.FakeCancellableScript
+		stopJobAction     action.StopJobAction
+	)
+
+	BeforeEach(func() {
+		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
+		specService = fakeas.NewFakeV1Service()
+		jobScriptProvider = &scriptfakes.FakeJobScriptProvider{}
+		stopJobAction = action.NewStopJob(jobSupervisor, specService, jobScriptProvider, boshlog.NewLogger(boshlog.LevelNone))
+
+		drainScript = &scriptfakes.FakeCancellableScript{}
+		drainScript.ExistsReturns(true)
+		jobScriptProvider.NewDrainScriptReturns(drainScript)
+
+		specService.Spec = boshas.V1ApplySpec{
+			RenderedTemplatesArchiveSpec: &boshas.RenderedTemplatesArchiveSpec{},
+			JobSpec: boshas.JobSpec{
+				Template:         "foo",
+				JobTemplateSpecs: []boshas.JobTemplateSpec{{Name: "foo"}, {Name: "bar"}},
+			},
+		}
+	})
+
+	AssertActionIsAsynchronous(stopJobAction)
+	AssertActionIsNotPersistent(stopJobAction)
+	AssertActionIsLoggable(stopJobAction)
+
+	AssertActionIsNotResumable(stopJobAction)
+	AssertActionIsNotCancelable(stopJobAction)
+
+	It("drains the job before waiting for it to stop", func() {
+		drainScript.RunStub = func() error {
+			Expect(jobSupervisor.StoppedJobs).To(BeEmpty())
+			return nil
+		}
+
+		stopped, err := stopJobAction.Run("bar")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stopped).To(Equal("stopped"))
+
+		Expect(jobScriptProvider.NewDrainScriptCallCount()).To(Equal(1))
+		jobName, params := jobScriptProvider.NewDrainScriptArgsForCall(0)
+		Expect(jobName).To(Equal("bar"))
+		Expect(params).To(Equal(boshdrain.NewShutdownParams(specService.Spec, nil)))
+		Expect(drainScript.RunCallCount()).To(Equal(1))
+
+		Expect(jobSupervisor.StoppedJobs).To(Equal([]string{"bar"}))
+	})
+
+	It("stops jobs without a drain script", func() {
+		drainScript.ExistsReturns(false)
+
+		_, err := stopJobAction.Run("bar")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drainScript.RunCallCount()).To(Equal(0))
+		Expect(jobSupervisor.StoppedJobs).To(Equal([]string{"bar"}))
+	})
+
+	It("stops single processes without draining", func() {
+		_, err := stopJobAction.Run("bar-worker")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobScriptProvider.NewDrainScriptCallCount()).To(Equal(0))
+		Expect(jobSupervisor.StoppedJobs).To(Equal([]string{"bar-worker"}))
+	})
+
+	It("does not stop the job when draining fails", func() {
+		drainScript.RunReturns(errors.New("fake-drain-err"))
+
+		_, err := stopJobAction.Run("bar")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-drain-err"))
+		Expect(jobSupervisor.StoppedJobs).To(BeEmpty())
+	})
+
+	It("returns an error when stopping the job fails", func() {
+		jobSupervisor.StopJobErr = errors.New("fake-stop-job-err")
+
+		_, err := stopJobAction.Run("bar")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-stop-job-err"))
+	})
+
+	It("returns an error when getting the current spec fails", func() {
+		specService.GetErr = errors.New("fake-spec-err")
+
+		_, err := stopJobAction.Run("bar")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-spec-err"))
+	})
+})
@@ -1,22 +1,80 @@
 package action_test
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
 	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
+	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/scriptfakes"
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
 	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
 )
 
 var _ = Describe("Stop", func() {
 	var (
-		jobSupervisor *fakejobsuper.FakeJobSupervisor
-		stopAction    action.StopAction
+		jobSupervisor     *fakejobsuper.FakeJobSupervisor
+		specService       *fakeas.FakeV1Service
+		jobScriptProvider *scriptfakes.FakeJobScriptProvider
+		timeService       *fakeclock.FakeClock
+		stopAction        action.StopAction
+
+		calls []string
 	)
 
 	BeforeEach(func() {
 		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
-		stopAction = action.NewStop(jobSupervisor)
+		specService = fakeas.NewFakeV1Service()
+		jobScriptProvider = &scriptfakes.FakeJobScriptProvider{}
+		timeService = fakeclock.NewFakeClock(time.Now())
+		stopAction = action.NewStop(jobSupervisor, specService, jobScriptProvider, timeService, boshlog.NewLogger(boshlog.LevelNone))
+
+		specService.Spec = boshas.V1ApplySpec{
+			JobSpec: boshas.JobSpec{
+				JobTemplateSpecs: []boshas.JobTemplateSpec{{Name: "fake-job-1"}, {Name: "fake-job-2"}},
+			},
+			RenderedTemplatesArchiveSpec: &boshas.RenderedTemplatesArchiveSpec{},
+		}
+
+		calls = nil
+
+		jobScriptProvider.NewScriptStub = func(jobName string, scriptName string, env map[string]string) boshscript.Script {
+			script := &scriptfakes.FakeScript{}
+			script.TagReturns(jobName)
+			script.ExistsReturns(jobName == "fake-job-1")
+			script.RunStub = func() error {
+				calls = append(calls, fmt.Sprintf("%s/%s stopped=%t", jobName, scriptName, jobSupervisor.Stopped))
+				timeService.Increment(2 * time.Second)
+				return nil
+			}
+			return script
+		}
+
+		jobScriptProvider.NewParallelScriptStub = func(scriptName string, scripts []boshscript.Script) boshscript.CancellableScript {
+			parallelScript := &scriptfakes.FakeCancellableScript{}
+			parallelScript.RunStub = func() error {
+				var err error
+				for _, script := range scripts {
+					if script.Exists() {
+						if runErr := script.Run(); runErr != nil {
+							err = runErr
+						}
+					}
+				}
+				return err
+			}
+			return parallelScript
+		}
 	})
 
 	AssertActionIsAsynchronous(stopAction)
@@ -32,6 +90,18 @@ var _ = Describe("Stop", func() {
 		Expect(stopped).To(Equal("stopped"))
 	})
 
+	It("returns stopped when protocol version is greater than 2", func() {
+		stopped, err := stopAction.Run(action.ProtocolVersion(3))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stopped).To(Equal("stopped"))
+	})
+
+	It("returns stopped when protocol version is 2 even when the details are asked for", func() {
+		stopped, err := stopAction.Run(action.ProtocolVersion(2), action.StopOptions{Detailed: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stopped).To(Equal("stopped"))
+	})
+
 	It("stops job supervisor services", func() {
 		_, err := stopAction.Run(action.ProtocolVersion(2))
 		Expect(err).ToNot(HaveOccurred())
@@ -49,4 +119,135 @@ var _ = Describe("Stop", func() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(jobSupervisor.StoppedAndWaited).To(BeTrue())
 	})
+
+	It("returns how each job was stopped when protocol version is greater than 2 and the details are asked for", func() {
+		jobSupervisor.JobStopResults = []boshjobsuper.JobStopResult{
+			{Job: "fake-job-1", Result: "stopped", Duration: 1.5},
+			{Job: "fake-job-2", Result: "killed", Duration: 70},
+		}
+
+		stopped, err := stopAction.Run(action.ProtocolVersion(3), action.StopOptions{RunHooks: true, Detailed: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stopped).To(Equal(action.StopResponse{
+			State: "stopped",
+			Jobs: []boshjobsuper.JobStopResult{
+				{Job: "fake-job-1", Result: "stopped", Duration: 1.5},
+				{Job: "fake-job-2", Result: "killed", Duration: 70},
+			},
+			PreStop:  []action.StopHookResult{{Job: "fake-job-1", Result: "succeeded", Duration: 2}},
+			PostStop: []action.StopHookResult{{Job: "fake-job-1", Result: "succeeded", Duration: 2}},
+		}))
+	})
+
+	It("returns an error when stopping and waiting fails", func() {
+		jobSupervisor.StopErr = errors.New("fake-stop-error")
+
+		_, err := stopAction.Run(action.ProtocolVersion(3), action.StopOptions{RunHooks: true})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-stop-error"))
+		Expect(calls).To(Equal([]string{"fake-job-1/pre-stop stopped=false"}))
+	})
+
+	It("runs the pre-stop scripts of all jobs in parallel before stopping", func() {
+		_, err := stopAction.Run(action.ProtocolVersion(2), action.StopOptions{RunHooks: true})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(calls).To(Equal([]string{"fake-job-1/pre-stop stopped=false"}))
+		Expect(jobSupervisor.Stopped).To(BeTrue())
+
+		Expect(jobScriptProvider.NewParallelScriptCallCount()).To(Equal(1))
+		scriptName, parallelScripts := jobScriptProvider.NewParallelScriptArgsForCall(0)
+		Expect(scriptName).To(Equal("pre-stop"))
+		Expect(parallelScripts).To(HaveLen(2))
+	})
+
+	It("runs the post-stop scripts after the jobs stopped when protocol version is greater than 2", func() {
+		_, err := stopAction.Run(action.ProtocolVersion(3), action.StopOptions{RunHooks: true})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(calls).To(Equal([]string{"fake-job-1/pre-stop stopped=false", "fake-job-1/post-stop stopped=true"}))
+	})
+
+	It("does not run the pre-stop and post-stop scripts unless asked to", func() {
+		stopped, err := stopAction.Run(action.ProtocolVersion(3), action.StopOptions{Detailed: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobSupervisor.StoppedAndWaited).To(BeTrue())
+
+		Expect(calls).To(BeEmpty())
+		Expect(jobScriptProvider.NewScriptCallCount()).To(Equal(0))
+		Expect(stopped.(action.StopResponse).PreStop).To(BeEmpty())
+		Expect(stopped.(action.StopResponse).PostStop).To(BeEmpty())
+	})
+
+	It("tells the scripts why the jobs are stopped", func() {
+		_, err := stopAction.Run(action.ProtocolVersion(3), action.StopOptions{RunHooks: true, Reason: "delete"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(jobScriptProvider.NewScriptCallCount()).To(Equal(4))
+		for i := 0; i < 4; i++ {
+			_, _, env := jobScriptProvider.NewScriptArgsForCall(i)
+			Expect(env).To(Equal(map[string]string{"BOSH_SCRIPT_REASON": "stop", "BOSH_STOP_REASON": "delete"}))
+		}
+	})
+
+	It("tells the scripts that the jobs are stopped when no reason is given", func() {
+		_, err := stopAction.Run(action.ProtocolVersion(2), action.StopOptions{RunHooks: true})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, env := jobScriptProvider.NewScriptArgsForCall(0)
+		Expect(env).To(Equal(map[string]string{"BOSH_SCRIPT_REASON": "stop", "BOSH_STOP_REASON": "stop"}))
+	})
+
+	It("stops the jobs and reports failed scripts", func() {
+		jobScriptProvider.NewScriptStub = func(jobName string, scriptName string, env map[string]string) boshscript.Script {
+			script := &scriptfakes.FakeScript{}
+			script.TagReturns(jobName)
+			script.ExistsReturns(true)
+			if jobName == "fake-job-2" && scriptName == "pre-stop" {
+				script.RunReturns(errors.New("fake-pre-stop-error"))
+			}
+			return script
+		}
+
+		stopped, err := stopAction.Run(action.ProtocolVersion(3), action.StopOptions{RunHooks: true, Detailed: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobSupervisor.StoppedAndWaited).To(BeTrue())
+
+		Expect(stopped.(action.StopResponse).PreStop).To(Equal([]action.StopHookResult{
+			{Job: "fake-job-1", Result: "succeeded"},
+			{Job: "fake-job-2", Result: "failed", Error: "fake-pre-stop-error"},
+		}))
+		Expect(stopped.(action.StopResponse).PostStop).To(Equal([]action.StopHookResult{
+			{Job: "fake-job-1", Result: "succeeded"},
+			{Job: "fake-job-2", Result: "succeeded"},
+		}))
+	})
+
+	It("reports where the scripts logged their output", func() {
+		output := outputlog.Output{StderrPath: "/fake/pre-stop.stderr.log", Stderr: "fake-stderr"}
+		jobScriptProvider.NewScriptStub = func(jobName string, scriptName string, env map[string]string) boshscript.Script {
+			script := &scriptfakes.FakeLoggingScript{}
+			script.TagReturns(jobName)
+			script.ExistsReturns(jobName == "fake-job-1")
+			script.OutputReturns(output, true)
+			return script
+		}
+
+		stopped, err := stopAction.Run(action.ProtocolVersion(3), action.StopOptions{RunHooks: true, Detailed: true})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stopped.(action.StopResponse).PreStop).To(Equal([]action.StopHookResult{
+			{Job: "fake-job-1", Result: "succeeded", Output: &output},
+		}))
+	})
+
+	It("stops the jobs without running scripts when the current spec cannot be read", func() {
+		specService.GetErr = errors.New("fake-get-error")
+
+		stopped, err := stopAction.Run(action.ProtocolVersion(3), action.StopOptions{RunHooks: true, Detailed: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobSupervisor.StoppedAndWaited).To(BeTrue())
+		Expect(jobScriptProvider.NewScriptCallCount()).To(Equal(0))
+		Expect(stopped.(action.StopResponse).PreStop).To(BeEmpty())
+	})
 })
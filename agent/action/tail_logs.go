@@ -0,0 +1,130 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/logtail"
+	boshhandler "github.com/cloudfoundry/bosh-agent/v2/handler"
+	boshnotif "github.com/cloudfoundry/bosh-agent/v2/notification"
+	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+)
+
+const tailLogsActionLogTag = "TailLogs Action"
+
+// tailLogsSubscribeDelay gives the client time to subscribe to the subject
+// returned by Run before the first lines are published to it.
+const tailLogsSubscribeDelay = 2 * time.Second
+
+type TailLogsAction struct {
+	logTailer       logtail.Tailer
+	notifier        boshnotif.Notifier
+	settingsService boshsettings.Service
+	uuidGen         boshuuid.Generator
+	timeService     clock.Clock
+	logger          boshlog.Logger
+}
+
+type TailLogsResponse struct {
+	Subject  string `json:"subject"`
+	Duration int    `json:"duration"`
+}
+
+func NewTailLogs(
+	logTailer logtail.Tailer,
+	notifier boshnotif.Notifier,
+	settingsService boshsettings.Service,
+	uuidGen boshuuid.Generator,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) TailLogsAction {
+	return TailLogsAction{
+		logTailer:       logTailer,
+		notifier:        notifier,
+		settingsService: settingsService,
+		uuidGen:         uuidGen,
+		timeService:     timeService,
+		logger:          logger,
+	}
+}
+
+func (a TailLogsAction) IsAsynchronous(_ ProtocolVersion) bool {
+	return false
+}
+
+func (a TailLogsAction) IsPersistent() bool {
+	return false
+}
+
+func (a TailLogsAction) IsLoggable() bool {
+	return true
+}
+
+// Run returns the subject to subscribe to for the lines appended to the
+// selected log files. Lines are published in the background, starting
+// shortly after Run returns, until the lifetime of the tail has passed,
+// ending with a message marked done.
+// Clients of the HTTPS handler stream logs from its /logs route instead.
+func (a TailLogsAction) Run(opts logtail.Options) (TailLogsResponse, error) {
+	err := opts.Validate()
+	if err != nil {
+		return TailLogsResponse{}, bosherr.WrapError(err, "Validating tail options")
+	}
+
+	streamID, err := a.uuidGen.Generate()
+	if err != nil {
+		return TailLogsResponse{}, bosherr.WrapError(err, "Generating log stream id")
+	}
+
+	// Agents may only publish to director subjects carrying their id in
+	// the third position
+	subject := fmt.Sprintf("%s.%s.%s.%s", boshhandler.Director, boshhandler.TailLogs, a.settingsService.GetSettings().AgentID, streamID)
+
+	go a.stream(subject, opts, a.timeService.NewTimer(tailLogsSubscribeDelay))
+
+	return TailLogsResponse{Subject: subject, Duration: int(opts.Lifetime().Seconds())}, nil
+}
+
+func (a TailLogsAction) stream(subject string, opts logtail.Options, subscribeTimer clock.Timer) {
+	defer a.logger.HandlePanic("Tail Logs Action Stream")
+
+	subscribed := false
+	waitForSubscriber := func() {
+		if !subscribed {
+			<-subscribeTimer.C()
+			subscribed = true
+		}
+	}
+
+	err := a.logTailer.Tail(opts, nil, func(lines []logtail.Line) error {
+		waitForSubscriber()
+		return a.notifier.NotifyLogLines(subject, logtail.Message{Lines: lines})
+	})
+
+	message := logtail.Message{Done: true}
+	if err != nil {
+		a.logger.Error(tailLogsActionLogTag, "Tailing logs for %s: %s", subject, err.Error())
+		message.Error = err.Error()
+	}
+
+	waitForSubscriber()
+
+	err = a.notifier.NotifyLogLines(subject, message)
+	if err != nil {
+		a.logger.Error(tailLogsActionLogTag, "Ending log stream %s: %s", subject, err.Error())
+	}
+}
+
+func (a TailLogsAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a TailLogsAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,109 @@
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/logtail"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/logtail/logtailfakes"
+	fakenotif "github.com/cloudfoundry/bosh-agent/v2/notification/fakes"
+	fakesettings "github.com/cloudfoundry/bosh-agent/v2/settings/fakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+)
+
+var _ = Describe("TailLogs", func() {
+	var (
+		logTailer      *logtailfakes.FakeTailer
+		notifier       *fakenotif.FakeNotifier
+		uuidGen        *fakeuuid.FakeGenerator
+		timeService    *fakeclock.FakeClock
+		tailLogsAction action.TailLogsAction
+	)
+
+	BeforeEach(func() {
+		logTailer = &logtailfakes.FakeTailer{}
+		notifier = fakenotif.NewFakeNotifier()
+		uuidGen = &fakeuuid.FakeGenerator{GeneratedUUID: "fake-stream-id"}
+		settingsService := &fakesettings.FakeSettingsService{}
+		settingsService.Settings.AgentID = "fake-agent-id"
+		timeService = fakeclock.NewFakeClock(time.Now())
+
+		tailLogsAction = action.NewTailLogs(logTailer, notifier, settingsService, uuidGen, timeService, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	AssertActionIsNotAsynchronous(tailLogsAction)
+	AssertActionIsNotPersistent(tailLogsAction)
+	AssertActionIsLoggable(tailLogsAction)
+
+	AssertActionIsNotResumable(tailLogsAction)
+	AssertActionIsNotCancelable(tailLogsAction)
+
+	It("returns the subject the lines are published to", func() {
+		response, err := tailLogsAction.Run(logtail.Options{Duration: 30})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).To(Equal(action.TailLogsResponse{
+			Subject:  "director.tail_logs.fake-agent-id.fake-stream-id",
+			Duration: 30,
+		}))
+	})
+
+	It("publishes the tailed lines and then that the stream is done", func() {
+		lines := []logtail.Line{{File: "job/job.log", Offset: 10, Text: "fake-line"}}
+		logTailer.TailStub = func(_ logtail.Options, _ <-chan struct{}, linesFunc func([]logtail.Line) error) error {
+			return linesFunc(lines)
+		}
+
+		opts := logtail.Options{Filters: []string{"job/*.log"}}
+		_, err := tailLogsAction.Run(opts)
+		Expect(err).ToNot(HaveOccurred())
+		timeService.Increment(2 * time.Second)
+
+		Eventually(notifier.NotifiedLogLines).Should(Equal([]fakenotif.NotifyLogLinesInput{
+			{Subject: "director.tail_logs.fake-agent-id.fake-stream-id", Message: logtail.Message{Lines: lines}},
+			{Subject: "director.tail_logs.fake-agent-id.fake-stream-id", Message: logtail.Message{Done: true}},
+		}))
+
+		tailedOpts, _, _ := logTailer.TailArgsForCall(0)
+		Expect(tailedOpts).To(Equal(opts))
+	})
+
+	It("publishes the error that ended the stream", func() {
+		logTailer.TailReturns(errors.New("fake-tail-err"))
+
+		_, err := tailLogsAction.Run(logtail.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		timeService.Increment(2 * time.Second)
+
+		Eventually(notifier.NotifiedLogLines).Should(Equal([]fakenotif.NotifyLogLinesInput{
+			{Subject: "director.tail_logs.fake-agent-id.fake-stream-id", Message: logtail.Message{Done: true, Error: "fake-tail-err"}},
+		}))
+	})
+
+	It("waits for the client to subscribe before publishing", func() {
+		logTailer.TailStub = func(_ logtail.Options, _ <-chan struct{}, linesFunc func([]logtail.Line) error) error {
+			return linesFunc([]logtail.Line{{File: "job/job.log", Text: "fake-line"}})
+		}
+
+		_, err := tailLogsAction.Run(logtail.Options{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(logTailer.TailCallCount).Should(Equal(1))
+		Consistently(notifier.NotifiedLogLines).Should(BeEmpty())
+
+		timeService.Increment(2 * time.Second)
+		Eventually(notifier.NotifiedLogLines).Should(HaveLen(2))
+	})
+
+	It("returns an error when the options are invalid", func() {
+		_, err := tailLogsAction.Run(logtail.Options{Filters: []string{"/etc/*"}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("must be relative to the logs directory"))
+		Expect(logTailer.TailCallCount()).To(Equal(0))
+	})
+})
@@ -141,6 +141,14 @@ func (dispatcher concreteActionDispatcher) dispatchAsynchronousAction(
 		}
 	}
 
+	if reporter, ok := action.(boshaction.ProgressReporter); ok {
+		task.ProgressFunc = reporter.Progress
+	}
+
+	if concurrentAction, ok := action.(boshaction.ConcurrentAction); ok {
+		task.Concurrent = concurrentAction.IsConcurrent()
+	}
+
 	dispatcher.taskService.StartTask(task)
 
 	return boshhandler.NewValueResponse(boshtask.StateValue{
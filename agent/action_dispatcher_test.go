@@ -15,7 +15,9 @@ import (
 	faketask "github.com/cloudfoundry/bosh-agent/v2/agent/task/fakes"
 	boshhandler "github.com/cloudfoundry/bosh-agent/v2/handler"
 	boshassert "github.com/cloudfoundry/bosh-utils/assert"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	fakes "github.com/cloudfoundry/bosh-utils/logger/loggerfakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
 )
 
 func init() { //nolint:funlen,gochecknoinits
@@ -227,6 +229,13 @@ func init() { //nolint:funlen,gochecknoinits
 					Expect(taskService.StartedTasks["fake-generated-task-id"]).ToNot(BeNil())
 				})
 
+				It("reports the progress of the action as the progress of the task", func() {
+					dispatcher.Dispatch(req)
+
+					action.ProgressValue = "fake-progress"
+					Expect(taskService.StartedTasks["fake-generated-task-id"].Progress()).To(Equal("fake-progress"))
+				})
+
 				It("returns create task error", func() {
 					taskService.CreateTaskErr = errors.New("fake-create-task-error")
 					resp := dispatcher.Dispatch(req)
@@ -358,6 +367,68 @@ func init() { //nolint:funlen,gochecknoinits
 			})
 		})
 
+		Context("when tasks are run by the async task service", func() {
+			var (
+				started chan string
+				release chan struct{}
+			)
+
+			BeforeEach(func() {
+				started = make(chan string, 3)
+				release = make(chan struct{})
+
+				taskService := boshtask.NewAsyncTaskService(fakeuuid.NewFakeGenerator(), boshlog.NewLogger(boshlog.LevelNone))
+				dispatcher = agent.NewActionDispatcher(logger, taskService, taskManager, actionFactory, action.NewRunner())
+			})
+
+			AfterEach(func() {
+				close(release)
+			})
+
+			registerBlockingAction := func(method string, concurrent bool) {
+				started, release := started, release
+				actionFactory.RegisterAction(method, &fakeaction.TestAction{
+					Asynchronous: true,
+					Concurrent:   concurrent,
+					RunStub: func() (interface{}, error) {
+						started <- method
+						<-release
+						return nil, nil
+					},
+				})
+			}
+
+			dispatch := func(method string) {
+				resp := dispatcher.Dispatch(boshhandler.NewRequest("fake-reply", method, []byte(`{"arguments":[""]}`), 0))
+				Expect(resp).To(BeAssignableToTypeOf(boshhandler.NewValueResponse(boshtask.StateValue{})))
+			}
+
+			It("runs concurrent actions while other tasks are running", func() {
+				registerBlockingAction("run_errand", true)
+
+				dispatch("run_errand")
+				dispatch("run_errand")
+
+				Eventually(started).Should(Receive(Equal("run_errand")))
+				Eventually(started).Should(Receive(Equal("run_errand")))
+			})
+
+			It("runs other actions one after the other", func() {
+				registerBlockingAction("fake-action", false)
+				registerBlockingAction("run_errand", true)
+
+				dispatch("fake-action")
+				Eventually(started).Should(Receive(Equal("fake-action")))
+
+				dispatch("fake-action")
+				Consistently(started).ShouldNot(Receive())
+
+				dispatch("run_errand")
+				Eventually(started).Should(Receive(Equal("run_errand")))
+				Consistently(started).ShouldNot(Receive())
+			})
+		})
+
 		Describe("ResumePreviouslyDispatchedTasks", func() {
 			var firstAction, secondAction *fakeaction.TestAction
 
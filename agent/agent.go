@@ -10,6 +10,7 @@ import (
 	boshhandler "github.com/cloudfoundry/bosh-agent/v2/handler"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
 	boshplatform "github.com/cloudfoundry/bosh-agent/v2/platform"
+	boshvitals "github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
 	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -20,6 +21,8 @@ import (
 const (
 	agentLogTag         = "agent"
 	heartbeatMaxRetries = 60
+
+	alertSummaryInterval = 10 * time.Second
 )
 
 var (
@@ -46,6 +49,19 @@ type Agent struct {
 	uuidGenerator     boshuuid.Generator
 	timeService       clock.Clock
 	startManager      StartManager
+
+	jobStateWatchOptions JobStateWatchOptions
+	jobStateWatcher      *JobStateWatcher
+	jobStateCheckCh      chan struct{}
+
+	vitalsHistory         boshvitals.History
+	vitalsHistoryInterval time.Duration
+
+	alertAggregator    boshalert.Aggregator
+	thresholdEvaluator boshalert.ThresholdEvaluator
+	kernelWatcher      KernelWatcher
+
+	periodicScriptScheduler PeriodicScriptScheduler
 }
 
 func New(
@@ -60,6 +76,13 @@ func New(
 	uuidGenerator boshuuid.Generator,
 	timeService clock.Clock,
 	startManager StartManager,
+	jobStateWatchOptions JobStateWatchOptions,
+	vitalsHistory boshvitals.History,
+	vitalsHistoryInterval time.Duration,
+	alertAggregator boshalert.Aggregator,
+	thresholdEvaluator boshalert.ThresholdEvaluator,
+	kernelWatcher KernelWatcher,
+	periodicScriptScheduler PeriodicScriptScheduler,
 ) Agent {
 	return Agent{
 		logger:            logger,
@@ -73,6 +96,19 @@ func New(
 		uuidGenerator:     uuidGenerator,
 		timeService:       timeService,
 		startManager:      startManager,
+
+		jobStateWatchOptions: jobStateWatchOptions,
+		jobStateWatcher:      NewJobStateWatcher(jobStateWatchOptions.Debounce, timeService),
+		jobStateCheckCh:      make(chan struct{}, 1),
+
+		vitalsHistory:         vitalsHistory,
+		vitalsHistoryInterval: vitalsHistoryInterval,
+
+		alertAggregator:    alertAggregator,
+		thresholdEvaluator: thresholdEvaluator,
+		kernelWatcher:      kernelWatcher,
+
+		periodicScriptScheduler: periodicScriptScheduler,
 	}
 }
 
@@ -92,6 +128,24 @@ func (a Agent) Run() error {
 
 	go a.generateHeartbeats(errCh)
 
+	go a.watchJobState()
+
+	if a.vitalsHistory != nil {
+		go a.recordVitalsHistory()
+	}
+
+	if a.alertAggregator != nil {
+		go a.sendAlertSummaries()
+	}
+
+	if a.kernelWatcher != nil {
+		go a.watchKernelMessages()
+	}
+
+	if a.periodicScriptScheduler != nil {
+		go a.runPeriodicScripts()
+	}
+
 	go func() {
 		err := a.jobSupervisor.MonitorJobFailures(a.handleJobFailure(errCh))
 		if err != nil {
@@ -131,6 +185,130 @@ func (a Agent) generateHeartbeats(errCh chan error) {
 	}
 }
 
+func (a Agent) recordVitalsHistory() {
+	defer a.logger.HandlePanic("Agent Record Vitals History")
+
+	ticker := a.timeService.NewTicker(a.vitalsHistoryInterval)
+	defer ticker.Stop()
+
+	for {
+		vitals, err := a.platform.GetVitalsService().Get()
+		if err == nil {
+			err = a.vitalsHistory.Record(vitals)
+		}
+		if err != nil {
+			a.logger.Error(agentLogTag, "Failed to record vitals history: %s", err.Error())
+		}
+
+		<-ticker.C()
+	}
+}
+
+func (a Agent) sendAlertSummaries() {
+	defer a.logger.HandlePanic("Agent Send Alert Summaries")
+
+	ticker := a.timeService.NewTicker(alertSummaryInterval)
+	defer ticker.Stop()
+
+	for range ticker.C() {
+		for _, summary := range a.alertAggregator.Flush() {
+			err := a.mbusHandler.Send(boshhandler.HealthMonitor, boshhandler.Alert, summary)
+			if err != nil {
+				a.logger.Error(agentLogTag, "Failed to send alert summary: %s", err.Error())
+			}
+		}
+	}
+}
+
+func (a Agent) watchKernelMessages() {
+	defer a.logger.HandlePanic("Agent Watch Kernel Messages")
+
+	err := a.kernelWatcher.Watch(func(service string, event string, alert boshalert.Alert) {
+		a.logger.Info(agentLogTag, "Kernel reported %s: %s", event, alert.Summary)
+
+		err := a.sendAlert(service, event, alert)
+		if err != nil {
+			a.logger.Error(agentLogTag, "Failed to send kernel alert: %s", err.Error())
+		}
+
+		a.checkJobState()
+	})
+	if err != nil {
+		a.logger.Error(agentLogTag, "Stopped watching kernel messages: %s", err.Error())
+	}
+}
+
+func (a Agent) runPeriodicScripts() {
+	defer a.logger.HandlePanic("Agent Run Periodic Scripts")
+
+	err := a.periodicScriptScheduler.Run(func(service string, event string, alert boshalert.Alert) {
+		err := a.sendAlert(service, event, alert)
+		if err != nil {
+			a.logger.Error(agentLogTag, "Failed to send periodic script alert: %s", err.Error())
+		}
+	})
+	if err != nil {
+		a.logger.Error(agentLogTag, "Stopped running periodic scripts: %s", err.Error())
+	}
+}
+
+func (a Agent) watchJobState() {
+	defer a.logger.HandlePanic("Agent Watch Job State")
+
+	var tickChan <-chan time.Time
+	if a.jobStateWatchOptions.PollInterval > 0 {
+		ticker := a.timeService.NewTicker(a.jobStateWatchOptions.PollInterval)
+		defer ticker.Stop()
+		tickChan = ticker.C()
+	}
+
+	for {
+		select {
+		case <-tickChan:
+		case <-a.jobStateCheckCh:
+		}
+
+		status := a.jobSupervisor.Status()
+
+		change, changed := a.jobStateWatcher.Observe(status)
+		if !changed {
+			continue
+		}
+
+		a.logger.Info(agentLogTag, "Job state changed from '%s' to '%s', sending heartbeat", change.PreviousState, change.State)
+
+		err := a.sendStateChangeHeartbeat(change)
+		if err != nil {
+			a.logger.Error(agentLogTag, "Failed to send job state change heartbeat: %s", err.Error())
+		}
+	}
+}
+
+func (a Agent) checkJobState() {
+	select {
+	case a.jobStateCheckCh <- struct{}{}:
+	default:
+	}
+}
+
+func (a Agent) sendStateChangeHeartbeat(change JobStateChange) error {
+	heartbeat, err := a.getHeartbeat(change.State)
+	if err != nil {
+		return bosherr.WrapError(err, "Building heartbeat")
+	}
+	a.jobSupervisor.HealthRecorder(change.State)
+
+	heartbeat.PreviousJobState = change.PreviousState
+	heartbeat.ChangedAt = change.ChangedAt.Unix()
+
+	err = a.mbusHandler.Send(boshhandler.HealthMonitor, boshhandler.Heartbeat, heartbeat)
+	if err != nil {
+		return bosherr.WrapError(err, "Sending Heartbeat")
+	}
+
+	return nil
+}
+
 func (a Agent) sendAndRecordHeartbeat(errCh chan error, retry bool) {
 	status := a.jobSupervisor.Status()
 	heartbeat, err := a.getHeartbeat(status)
@@ -140,6 +318,12 @@ func (a Agent) sendAndRecordHeartbeat(errCh chan error, retry bool) {
 		return
 	}
 	a.jobSupervisor.HealthRecorder(status)
+	a.sendThresholdAlerts(heartbeat.Vitals)
+
+	if change, changed := a.jobStateWatcher.Observe(status); changed {
+		heartbeat.PreviousJobState = change.PreviousState
+		heartbeat.ChangedAt = change.ChangedAt.Unix()
+	}
 
 	heartbeatRetryable := boshretry.NewRetryable(func() (bool, error) {
 		a.logger.Info(agentLogTag, "Attempting to send Heartbeat")
@@ -168,6 +352,21 @@ func (a Agent) sendAndRecordHeartbeat(errCh chan error, retry bool) {
 	}
 }
 
+func (a Agent) sendThresholdAlerts(vitals boshvitals.Vitals) {
+	if a.thresholdEvaluator == nil {
+		return
+	}
+
+	for _, alert := range a.thresholdEvaluator.Evaluate(vitals) {
+		a.logger.Info(agentLogTag, "Sending threshold alert: %s", alert.Title)
+
+		err := a.mbusHandler.Send(boshhandler.HealthMonitor, boshhandler.Alert, alert)
+		if err != nil {
+			a.logger.Error(agentLogTag, "Failed to send threshold alert: %s", err.Error())
+		}
+	}
+}
+
 func (a Agent) getHeartbeat(status string) (Heartbeat, error) {
 	a.logger.Debug(agentLogTag, "Building heartbeat")
 	vitalsService := a.platform.GetVitalsService()
@@ -196,7 +395,11 @@ func (a Agent) getHeartbeat(status string) (Heartbeat, error) {
 
 func (a Agent) handleJobFailure(errCh chan error) boshjobsuper.JobFailureHandler {
 	return func(monitAlert boshalert.MonitAlert) error {
-		alertAdapter := boshalert.NewMonitAdapter(monitAlert, a.settingsService, a.timeService)
+		// Job state is likely to have changed so check it
+		// without waiting for the next poll
+		defer a.checkJobState()
+
+		alertAdapter := boshalert.NewMonitAdapter(monitAlert, a.jobMonitAlerts(monitAlert.Service), a.settingsService, a.timeService)
 		if alertAdapter.IsIgnorable() {
 			a.logger.Debug(agentLogTag, "Ignored monit event: ", monitAlert.Event)
 			return nil
@@ -212,7 +415,7 @@ func (a Agent) handleJobFailure(errCh chan error) boshjobsuper.JobFailureHandler
 			errCh <- bosherr.WrapError(err, "Adapting monit alert")
 		}
 
-		err = a.mbusHandler.Send(boshhandler.HealthMonitor, boshhandler.Alert, alert)
+		err = a.sendAlert(monitAlert.Service, monitAlert.Event, alert)
 		if err != nil {
 			errCh <- bosherr.WrapError(err, "Sending monit alert")
 		}
@@ -220,3 +423,45 @@ func (a Agent) handleJobFailure(errCh chan error) boshjobsuper.JobFailureHandler
 		return nil
 	}
 }
+
+// jobMonitAlerts returns the overrides of the monit alerts
+// of the job running the monit service, if it declares any
+func (a Agent) jobMonitAlerts(service string) boshsettings.MonitAlerts {
+	job := service
+
+	processes, err := a.jobSupervisor.Processes()
+	if err != nil {
+		a.logger.Warn(agentLogTag, "Failed to find job of monit service %s: %s", service, err.Error())
+	}
+
+	for _, process := range processes {
+		if process.Name == service && process.Job != "" {
+			job = process.Job
+		}
+	}
+
+	spec, err := a.specService.Get()
+	if err != nil {
+		a.logger.Warn(agentLogTag, "Failed to get alerts of job %s: %s", job, err.Error())
+		return boshsettings.MonitAlerts{}
+	}
+
+	for _, jobSpec := range spec.JobSpec.JobTemplateSpecs {
+		if jobSpec.Name == job && jobSpec.Alerts != nil {
+			return boshsettings.MonitAlerts(*jobSpec.Alerts)
+		}
+	}
+
+	return boshsettings.MonitAlerts{}
+}
+
+// sendAlert sends alert to the health monitor unless it is
+// suppressed as a duplicate of a recent alert for service and event
+func (a Agent) sendAlert(service string, event string, alert boshalert.Alert) error {
+	if a.alertAggregator != nil && !a.alertAggregator.Add(service, event, alert) {
+		a.logger.Debug(agentLogTag, "Suppressed duplicate alert for %s: %s", service, event)
+		return nil
+	}
+
+	return a.mbusHandler.Send(boshhandler.HealthMonitor, boshhandler.Alert, alert)
+}
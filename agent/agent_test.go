@@ -11,10 +11,12 @@ import (
 	"github.com/cloudfoundry/bosh-agent/v2/agent"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/agentfakes"
 	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/alert/alertfakes"
 	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
 	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
 	fakeagent "github.com/cloudfoundry/bosh-agent/v2/agent/fakes"
 	boshhandler "github.com/cloudfoundry/bosh-agent/v2/handler"
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
 	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
 	fakembus "github.com/cloudfoundry/bosh-agent/v2/mbus/fakes"
 	"github.com/cloudfoundry/bosh-agent/v2/platform/platformfakes"
@@ -71,6 +73,13 @@ func init() { //nolint:funlen,gochecknoinits
 				uuidGenerator,
 				timeService,
 				startManager,
+				agent.JobStateWatchOptions{},
+				nil,
+				0,
+				nil,
+				nil,
+				nil,
+				nil,
 			)
 		})
 
@@ -157,6 +166,13 @@ func init() { //nolint:funlen,gochecknoinits
 						uuidGenerator,
 						timeService,
 						startManager,
+						agent.JobStateWatchOptions{},
+						nil,
+						0,
+						nil,
+						nil,
+						nil,
+						nil,
 					)
 
 					// Immediately exit after sending initial heartbeat
@@ -208,6 +224,53 @@ func init() { //nolint:funlen,gochecknoinits
 					Expect(jobSupervisor.GetHealthRecorded()).To(BeNumerically(">=", 3))
 				})
 
+				It("sends an out-of-band heartbeat when job state changes", func() {
+					boshAgent = agent.New(
+						logger,
+						handler,
+						platform,
+						actionDispatcher,
+						jobSupervisor,
+						specService,
+						5*time.Hour,
+						settingsService,
+						uuidGenerator,
+						timeService,
+						startManager,
+						agent.JobStateWatchOptions{PollInterval: 5 * time.Second},
+						nil,
+						0,
+						nil,
+						nil,
+						nil,
+						nil,
+					)
+
+					jobSupervisor.StatusStatus = "running"
+					handler.SendCallback = func(_ fakembus.SendInput) {
+						jobSupervisor.StatusStatus = "failing"
+					}
+					handler.RunCallBack = nil
+
+					err := boshAgent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					Eventually(handler.SendInputs).ShouldNot(BeEmpty())
+					Eventually(timeService.WatcherCount).Should(Equal(1))
+					timeService.Increment(5 * time.Second)
+
+					stateChangeHb := expectedHb
+					stateChangeHb.JobState = "failing"
+					stateChangeHb.PreviousJobState = "running"
+					stateChangeHb.ChangedAt = timeService.Now().Unix()
+
+					Eventually(handler.SendInputs).Should(ContainElement(fakembus.SendInput{
+						Target:  boshhandler.HealthMonitor,
+						Topic:   boshhandler.Heartbeat,
+						Message: stateChangeHb,
+					}))
+				})
+
 				Context("when the boshAgent may not be rebooted", func() {
 					BeforeEach(func() {
 						startManager.CanStartReturns(false)
@@ -246,6 +309,75 @@ func init() { //nolint:funlen,gochecknoinits
 				})
 			})
 
+			It("records vitals into the vitals history", func() {
+				vitalsHistory := &vitalsfakes.FakeHistory{}
+				vitalService.GetReturns(boshvitals.Vitals{Load: []string{"a", "b", "c"}}, nil)
+
+				boshAgent = agent.New(
+					logger,
+					handler,
+					platform,
+					actionDispatcher,
+					jobSupervisor,
+					specService,
+					5*time.Hour,
+					settingsService,
+					uuidGenerator,
+					timeService,
+					startManager,
+					agent.JobStateWatchOptions{},
+					vitalsHistory,
+					time.Minute,
+					nil,
+					nil,
+					nil,
+					nil,
+				)
+
+				err := boshAgent.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(vitalsHistory.RecordCallCount).Should(Equal(1))
+
+				Eventually(func() int {
+					timeService.Increment(time.Minute)
+					return vitalsHistory.RecordCallCount()
+				}).Should(BeNumerically(">=", 2))
+				Expect(vitalsHistory.RecordArgsForCall(0)).To(Equal(boshvitals.Vitals{Load: []string{"a", "b", "c"}}))
+			})
+
+			It("sends job monitoring alerts as the job of the alerting process overrides them", func() {
+				handler.KeepOnRunning()
+
+				monitAlert := boshalert.MonitAlert{
+					ID:      "fake-monit-alert",
+					Service: "fake-service",
+					Event:   "fake-event",
+					Action:  "fake-action",
+					Date:    "Sun, 22 May 2011 20:07:41 +0500",
+				}
+				jobSupervisor.JobFailureAlert = &monitAlert
+				jobSupervisor.ProcessesStatus = []boshjobsuper.Process{{Name: "fake-service", Job: "fake-job"}}
+				specService.Spec = boshas.V1ApplySpec{
+					JobSpec: boshas.JobSpec{
+						JobTemplateSpecs: []boshas.JobTemplateSpec{
+							{Name: "fake-job", Alerts: &boshas.MonitAlertsSpec{Severities: map[string]string{"fake-event": "warning"}}},
+						},
+					},
+				}
+
+				handler.SendCallback = func(input fakembus.SendInput) {
+					if input.Topic == boshhandler.Alert {
+						handler.SendErr = errors.New("stop")
+					}
+				}
+
+				err := boshAgent.Run()
+				Expect(err).To(HaveOccurred())
+
+				Expect(handler.SendInputs()).To(ContainElement(HaveField("Message", HaveField("Severity", boshalert.SeverityWarning))))
+			})
+
 			It("sends job monitoring alerts to health manager", func() {
 				handler.KeepOnRunning()
 
@@ -284,6 +416,216 @@ func init() { //nolint:funlen,gochecknoinits
 					Message: expectedAlert,
 				}))
 			})
+
+			It("sends threshold alerts for heartbeat vitals", func() {
+				vitalService.GetReturns(boshvitals.Vitals{Load: []string{"a", "b", "c"}}, nil)
+
+				thresholdAlert := boshalert.Alert{ID: "fake-threshold-alert", Title: "ephemeral_disk - warning - usage above 80%"}
+				thresholdEvaluator := &alertfakes.FakeThresholdEvaluator{}
+				thresholdEvaluator.EvaluateReturns([]boshalert.Alert{thresholdAlert})
+
+				boshAgent = agent.New(
+					logger,
+					handler,
+					platform,
+					actionDispatcher,
+					jobSupervisor,
+					specService,
+					5*time.Hour,
+					settingsService,
+					uuidGenerator,
+					timeService,
+					startManager,
+					agent.JobStateWatchOptions{},
+					nil,
+					0,
+					nil,
+					thresholdEvaluator,
+					nil,
+					nil,
+				)
+
+				err := boshAgent.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(thresholdEvaluator.EvaluateCallCount).Should(Equal(1))
+				Expect(thresholdEvaluator.EvaluateArgsForCall(0)).To(Equal(boshvitals.Vitals{Load: []string{"a", "b", "c"}}))
+
+				Eventually(handler.SendInputs).Should(ContainElement(fakembus.SendInput{
+					Target:  boshhandler.HealthMonitor,
+					Topic:   boshhandler.Alert,
+					Message: thresholdAlert,
+				}))
+			})
+
+			It("sends alerts for kernel events", func() {
+				kernelAlert := boshalert.Alert{ID: "fake-kernel-alert", Title: "app - oom killed - java[1234]"}
+				kernelWatcher := &agentfakes.FakeKernelWatcher{}
+				kernelWatcher.WatchStub = func(handler agent.KernelAlertHandler) error {
+					handler("app", boshalert.KernelEventOOMKill, kernelAlert)
+					return nil
+				}
+
+				boshAgent = agent.New(
+					logger,
+					handler,
+					platform,
+					actionDispatcher,
+					jobSupervisor,
+					specService,
+					5*time.Hour,
+					settingsService,
+					uuidGenerator,
+					timeService,
+					startManager,
+					agent.JobStateWatchOptions{},
+					nil,
+					0,
+					nil,
+					nil,
+					kernelWatcher,
+					nil,
+				)
+
+				err := boshAgent.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(handler.SendInputs).Should(ContainElement(fakembus.SendInput{
+					Target:  boshhandler.HealthMonitor,
+					Topic:   boshhandler.Alert,
+					Message: kernelAlert,
+				}))
+			})
+
+			It("sends alerts for failed periodic scripts", func() {
+				periodicAlert := boshalert.Alert{ID: "fake-periodic-alert", Title: "app - periodic script failed - cleanup"}
+				periodicScriptScheduler := &agentfakes.FakePeriodicScriptScheduler{}
+				periodicScriptScheduler.RunStub = func(handler agent.PeriodicAlertHandler) error {
+					handler("app", agent.PeriodicScriptFailedEvent, periodicAlert)
+					return nil
+				}
+
+				boshAgent = agent.New(
+					logger,
+					handler,
+					platform,
+					actionDispatcher,
+					jobSupervisor,
+					specService,
+					5*time.Hour,
+					settingsService,
+					uuidGenerator,
+					timeService,
+					startManager,
+					agent.JobStateWatchOptions{},
+					nil,
+					0,
+					nil,
+					nil,
+					nil,
+					periodicScriptScheduler,
+				)
+
+				err := boshAgent.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(handler.SendInputs).Should(ContainElement(fakembus.SendInput{
+					Target:  boshhandler.HealthMonitor,
+					Topic:   boshhandler.Alert,
+					Message: periodicAlert,
+				}))
+			})
+
+			Context("when alerts are aggregated", func() {
+				var (
+					alertAggregator *alertfakes.FakeAggregator
+					monitAlert      boshalert.MonitAlert
+				)
+
+				BeforeEach(func() {
+					alertAggregator = &alertfakes.FakeAggregator{}
+
+					monitAlert = boshalert.MonitAlert{
+						ID:          "fake-monit-alert",
+						Service:     "fake-service",
+						Event:       "fake-event",
+						Action:      "fake-action",
+						Date:        "Sun, 22 May 2011 20:07:41 +0500",
+						Description: "fake-description",
+					}
+					jobSupervisor.JobFailureAlert = &monitAlert
+
+					boshAgent = agent.New(
+						logger,
+						handler,
+						platform,
+						actionDispatcher,
+						jobSupervisor,
+						specService,
+						5*time.Hour,
+						settingsService,
+						uuidGenerator,
+						timeService,
+						startManager,
+						agent.JobStateWatchOptions{},
+						nil,
+						0,
+						alertAggregator,
+						nil,
+						nil,
+						nil,
+					)
+				})
+
+				It("does not send alerts suppressed by the aggregator", func() {
+					alertAggregator.AddReturns(false)
+
+					err := boshAgent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					Eventually(alertAggregator.AddCallCount).Should(Equal(1))
+					service, event, _ := alertAggregator.AddArgsForCall(0)
+					Expect(service).To(Equal("fake-service"))
+					Expect(event).To(Equal("fake-event"))
+
+					for _, input := range handler.SendInputs() {
+						Expect(input.Topic).ToNot(Equal(boshhandler.Alert))
+					}
+				})
+
+				It("periodically sends summary alerts", func() {
+					summary := boshalert.Alert{ID: "fake-summary", Title: "fake-service - fake-event - repeated 3 times", Count: 3}
+					alertAggregator.FlushReturnsOnCall(0, []boshalert.Alert{summary})
+
+					err := boshAgent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					Eventually(timeService.WatcherCount).Should(Equal(1))
+					timeService.Increment(10 * time.Second)
+
+					Eventually(handler.SendInputs).Should(ContainElement(fakembus.SendInput{
+						Target:  boshhandler.HealthMonitor,
+						Topic:   boshhandler.Alert,
+						Message: summary,
+					}))
+				})
+
+				It("keeps sending summary alerts when sending one fails", func() {
+					summary := boshalert.Alert{ID: "fake-summary", Title: "fake-service - fake-event - repeated 3 times", Count: 3}
+					alertAggregator.FlushReturns([]boshalert.Alert{summary})
+					handler.SendErr = errors.New("fake-send-err")
+
+					err := boshAgent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					Eventually(timeService.WatcherCount).Should(Equal(1))
+					timeService.Increment(10 * time.Second)
+					Eventually(alertAggregator.FlushCallCount).Should(Equal(1))
+
+					timeService.Increment(10 * time.Second)
+					Eventually(alertAggregator.FlushCallCount).Should(Equal(2))
+				})
+			})
 		})
 	})
 }
@@ -0,0 +1,166 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Aggregator
+
+// Aggregator groups alerts by service and event so that a flapping
+// process does not flood the health monitor with identical alerts.
+type Aggregator interface {
+	// Add records alert and returns true if it should be sent right away.
+	// Duplicates within the window and alerts over the per-service limit
+	// are suppressed and later reported by Flush as a single summary alert.
+	Add(service, event string, alert Alert) bool
+
+	// Flush returns summary alerts for groups that have been quiet for
+	// a whole window and had suppressed alerts.
+	Flush() []Alert
+}
+
+type aggregator struct {
+	window        time.Duration
+	maxPerService int
+	timeService   clock.Clock
+
+	mutex  sync.Mutex
+	groups map[string]*alertGroup
+	sent   map[string][]time.Time
+}
+
+type alertGroup struct {
+	service string
+	event   string
+	last    Alert
+
+	count      int
+	suppressed int
+	firstSeen  time.Time
+	lastSeen   time.Time
+}
+
+// NewAggregator returns an Aggregator that suppresses duplicates within window
+// and sends at most maxPerService alerts per service within window.
+// A zero window disables aggregation; a non-positive maxPerService disables rate limiting.
+func NewAggregator(window time.Duration, maxPerService int, timeService clock.Clock) Aggregator {
+	return &aggregator{
+		window:        window,
+		maxPerService: maxPerService,
+		timeService:   timeService,
+		groups:        map[string]*alertGroup{},
+		sent:          map[string][]time.Time{},
+	}
+}
+
+func (a *aggregator) Add(service, event string, alert Alert) bool {
+	if a.window <= 0 {
+		return true
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := a.timeService.Now()
+	key := service + "/" + event
+
+	group, found := a.groups[key]
+	if found {
+		group.count++
+		group.suppressed++
+		group.lastSeen = now
+		group.last = alert
+		return false
+	}
+
+	group = &alertGroup{
+		service:   service,
+		event:     event,
+		last:      alert,
+		count:     1,
+		firstSeen: now,
+		lastSeen:  now,
+	}
+	a.groups[key] = group
+
+	if !a.allowed(service, now) {
+		group.suppressed++
+		return false
+	}
+
+	a.sent[service] = append(a.sent[service], now)
+
+	return true
+}
+
+func (a *aggregator) Flush() []Alert {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := a.timeService.Now()
+	summaries := []Alert{}
+
+	for key, group := range a.groups {
+		if now.Sub(group.lastSeen) < a.window {
+			continue
+		}
+
+		delete(a.groups, key)
+
+		if group.suppressed > 0 {
+			summaries = append(summaries, group.summary(now))
+		}
+	}
+
+	for service := range a.sent {
+		a.allowed(service, now)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].ID < summaries[j].ID
+	})
+
+	return summaries
+}
+
+// allowed prunes sends older than the window and reports
+// whether service may send another alert
+func (a *aggregator) allowed(service string, now time.Time) bool {
+	recent := []time.Time{}
+	for _, sentAt := range a.sent[service] {
+		if now.Sub(sentAt) < a.window {
+			recent = append(recent, sentAt)
+		}
+	}
+
+	if len(recent) == 0 {
+		delete(a.sent, service)
+	} else {
+		a.sent[service] = recent
+	}
+
+	return a.maxPerService <= 0 || len(recent) < a.maxPerService
+}
+
+func (g *alertGroup) summary(now time.Time) Alert {
+	return Alert{
+		ID:       fmt.Sprintf("%s-summary-%d", g.last.ID, g.lastSeen.Unix()),
+		Severity: g.last.Severity,
+		Title:    fmt.Sprintf("%s - %s - repeated %d times", g.service, g.event, g.count),
+		Summary: fmt.Sprintf(
+			"Suppressed %d of %d alerts between %s and %s. Last alert: %s",
+			g.suppressed, g.count,
+			g.firstSeen.UTC().Format(time.RFC3339), g.lastSeen.UTC().Format(time.RFC3339),
+			g.last.Summary,
+		),
+		CreatedAt:   now.Unix(),
+		Count:       g.count,
+		FirstSeenAt: g.firstSeen.Unix(),
+		LastSeenAt:  g.lastSeen.Unix(),
+	}
+}
@@ -0,0 +1,103 @@
+package alert_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+
+	. "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+)
+
+var _ = Describe("Aggregator", func() {
+	var (
+		timeService *fakeclock.FakeClock
+		aggregator  Aggregator
+	)
+
+	buildAlert := func(id string) Alert {
+		return Alert{
+			ID:       id,
+			Severity: SeverityAlert,
+			Title:    "nats - does not exist - restart",
+			Summary:  "process is not running",
+		}
+	}
+
+	BeforeEach(func() {
+		timeService = fakeclock.NewFakeClock(time.Unix(1700000000, 0))
+		aggregator = NewAggregator(time.Minute, 2, timeService)
+	})
+
+	It("sends the first alert for a service and event", func() {
+		Expect(aggregator.Add("nats", "does not exist", buildAlert("1"))).To(BeTrue())
+		Expect(aggregator.Flush()).To(BeEmpty())
+	})
+
+	It("suppresses duplicates within the window and summarizes them once the window passes", func() {
+		Expect(aggregator.Add("nats", "does not exist", buildAlert("1"))).To(BeTrue())
+
+		timeService.Increment(10 * time.Second)
+		Expect(aggregator.Add("nats", "does not exist", buildAlert("2"))).To(BeFalse())
+
+		timeService.Increment(10 * time.Second)
+		Expect(aggregator.Add("nats", "does not exist", buildAlert("3"))).To(BeFalse())
+
+		timeService.Increment(59 * time.Second)
+		Expect(aggregator.Flush()).To(BeEmpty())
+
+		timeService.Increment(time.Second)
+		Expect(aggregator.Flush()).To(Equal([]Alert{{
+			ID:          "3-summary-1700000020",
+			Severity:    SeverityAlert,
+			Title:       "nats - does not exist - repeated 3 times",
+			Summary:     "Suppressed 2 of 3 alerts between 2023-11-14T22:13:20Z and 2023-11-14T22:13:40Z. Last alert: process is not running",
+			CreatedAt:   1700000080,
+			Count:       3,
+			FirstSeenAt: 1700000000,
+			LastSeenAt:  1700000020,
+		}}))
+
+		Expect(aggregator.Add("nats", "does not exist", buildAlert("4"))).To(BeTrue())
+	})
+
+	It("does not summarize groups without suppressed alerts", func() {
+		Expect(aggregator.Add("nats", "does not exist", buildAlert("1"))).To(BeTrue())
+
+		timeService.Increment(time.Minute)
+		Expect(aggregator.Flush()).To(BeEmpty())
+	})
+
+	It("groups by event separately", func() {
+		Expect(aggregator.Add("nats", "does not exist", buildAlert("1"))).To(BeTrue())
+		Expect(aggregator.Add("nats", "pid failed", buildAlert("2"))).To(BeTrue())
+		Expect(aggregator.Add("redis", "does not exist", buildAlert("3"))).To(BeTrue())
+	})
+
+	It("rate limits alerts per service", func() {
+		Expect(aggregator.Add("nats", "does not exist", buildAlert("1"))).To(BeTrue())
+		Expect(aggregator.Add("nats", "pid failed", buildAlert("2"))).To(BeTrue())
+		Expect(aggregator.Add("nats", "connection failed", buildAlert("3"))).To(BeFalse())
+		Expect(aggregator.Add("redis", "does not exist", buildAlert("4"))).To(BeTrue())
+
+		timeService.Increment(time.Minute)
+
+		summaries := aggregator.Flush()
+		Expect(summaries).To(HaveLen(1))
+		Expect(summaries[0].Title).To(Equal("nats - connection failed - repeated 1 times"))
+		Expect(summaries[0].Count).To(Equal(1))
+
+		Expect(aggregator.Add("nats", "connection failed", buildAlert("5"))).To(BeTrue())
+	})
+
+	It("sends every alert when aggregation is disabled", func() {
+		aggregator = NewAggregator(0, 2, timeService)
+
+		for i := 0; i < 5; i++ {
+			Expect(aggregator.Add("nats", "does not exist", buildAlert("1"))).To(BeTrue())
+		}
+		Expect(aggregator.Flush()).To(BeEmpty())
+	})
+})
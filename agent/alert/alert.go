@@ -1,5 +1,9 @@
 package alert
 
+import (
+	"strings"
+)
+
 type SeverityLevel int
 
 const (
@@ -11,12 +15,33 @@ const (
 	SeverityDefault  SeverityLevel = SeverityCritical
 )
 
+// ParseSeverityLevel converts a severity name such as
+// "critical" or "ignored" into its SeverityLevel
+func ParseSeverityLevel(name string) (SeverityLevel, bool) {
+	severities := map[string]SeverityLevel{
+		"alert":    SeverityAlert,
+		"critical": SeverityCritical,
+		"error":    SeverityError,
+		"warning":  SeverityWarning,
+		"ignored":  SeverityIgnored,
+	}
+
+	severity, found := severities[strings.ToLower(strings.TrimSpace(name))]
+
+	return severity, found
+}
+
 type Alert struct {
 	ID        string        `json:"id"`
 	Severity  SeverityLevel `json:"severity"`
 	Title     string        `json:"title"`
 	Summary   string        `json:"summary"`
 	CreatedAt int64         `json:"created_at"`
+
+	// Only set on summary alerts of aggregated duplicates
+	Count       int   `json:"count,omitempty"`
+	FirstSeenAt int64 `json:"first_seen_at,omitempty"`
+	LastSeenAt  int64 `json:"last_seen_at,omitempty"`
 }
 
 type Adapter interface {
@@ -0,0 +1,112 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package alertfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	"github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
+)
+
+type FakeThresholdEvaluator struct {
+	EvaluateStub        func(vitals.Vitals) []alert.Alert
+	evaluateMutex       sync.RWMutex
+	evaluateArgsForCall []struct {
+		arg1 vitals.Vitals
+	}
+	evaluateReturns struct {
+		result1 []alert.Alert
+	}
+	evaluateReturnsOnCall map[int]struct {
+		result1 []alert.Alert
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeThresholdEvaluator) Evaluate(arg1 vitals.Vitals) []alert.Alert {
+	fake.evaluateMutex.Lock()
+	ret, specificReturn := fake.evaluateReturnsOnCall[len(fake.evaluateArgsForCall)]
+	fake.evaluateArgsForCall = append(fake.evaluateArgsForCall, struct {
+		arg1 vitals.Vitals
+	}{arg1})
+	stub := fake.EvaluateStub
+	fakeReturns := fake.evaluateReturns
+	fake.recordInvocation("Evaluate", []interface{}{arg1})
+	fake.evaluateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeThresholdEvaluator) EvaluateCallCount() int {
+	fake.evaluateMutex.RLock()
+	defer fake.evaluateMutex.RUnlock()
+	return len(fake.evaluateArgsForCall)
+}
+
+func (fake *FakeThresholdEvaluator) EvaluateCalls(stub func(vitals.Vitals) []alert.Alert) {
+	fake.evaluateMutex.Lock()
+	defer fake.evaluateMutex.Unlock()
+	fake.EvaluateStub = stub
+}
+
+func (fake *FakeThresholdEvaluator) EvaluateArgsForCall(i int) vitals.Vitals {
+	fake.evaluateMutex.RLock()
+	defer fake.evaluateMutex.RUnlock()
+	argsForCall := fake.evaluateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeThresholdEvaluator) EvaluateReturns(result1 []alert.Alert) {
+	fake.evaluateMutex.Lock()
+	defer fake.evaluateMutex.Unlock()
+	fake.EvaluateStub = nil
+	fake.evaluateReturns = struct {
+		result1 []alert.Alert
+	}{result1}
+}
+
+func (fake *FakeThresholdEvaluator) EvaluateReturnsOnCall(i int, result1 []alert.Alert) {
+	fake.evaluateMutex.Lock()
+	defer fake.evaluateMutex.Unlock()
+	fake.EvaluateStub = nil
+	if fake.evaluateReturnsOnCall == nil {
+		fake.evaluateReturnsOnCall = make(map[int]struct {
+			result1 []alert.Alert
+		})
+	}
+	fake.evaluateReturnsOnCall[i] = struct {
+		result1 []alert.Alert
+	}{result1}
+}
+
+func (fake *FakeThresholdEvaluator) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.evaluateMutex.RLock()
+	defer fake.evaluateMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeThresholdEvaluator) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ alert.ThresholdEvaluator = new(FakeThresholdEvaluator)
@@ -0,0 +1,138 @@
+package alert
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	KernelEventOOMKill  = "oom_kill"
+	KernelEventSegfault = "segfault"
+	KernelEventHungTask = "hung_task"
+)
+
+var (
+	oomKillRegexp  = regexp.MustCompile(`oom-kill:.*task_memcg=([^,]*),task=[^,]*,pid=(\d+)`)
+	killedRegexp   = regexp.MustCompile(`[Oo]ut of memory: Kill(?:ed)? process (\d+) \(([^)]*)\)(.*)`)
+	memoryRegexp   = regexp.MustCompile(`(total-vm|anon-rss|file-rss|shmem-rss):\s*(\d+kB)`)
+	segfaultRegexp = regexp.MustCompile(`^(.+)\[(\d+)\]: (segfault at .*)`)
+	hungTaskRegexp = regexp.MustCompile(`task (.+):(\d+) (blocked for more than \d+ seconds)`)
+)
+
+type KernelEvent struct {
+	Type    string
+	PID     int
+	Command string
+
+	// Cgroup and Memory are only known for some events
+	Cgroup string
+	Memory string
+	Detail string
+}
+
+// KernelMessageParser turns /dev/kmsg records into kernel events.
+// It is stateful because the OOM killer reports the victim's
+// cgroup and memory usage on separate lines.
+type KernelMessageParser struct {
+	oomCgroups map[int]string
+}
+
+func NewKernelMessageParser() *KernelMessageParser {
+	return &KernelMessageParser{oomCgroups: map[int]string{}}
+}
+
+// Parse accepts either a raw /dev/kmsg record ("6,1234,5678,-;message")
+// or a bare message and returns the event it reports, if any.
+func (p *KernelMessageParser) Parse(record string) (KernelEvent, bool) {
+	// Continuation lines carry key/value metadata for the previous record
+	if strings.HasPrefix(record, " ") {
+		return KernelEvent{}, false
+	}
+
+	message := strings.TrimSpace(record)
+	if header, rest, found := strings.Cut(message, ";"); found && strings.Count(header, ",") >= 3 {
+		message = rest
+	}
+
+	if matches := oomKillRegexp.FindStringSubmatch(message); matches != nil {
+		pid, _ := strconv.Atoi(matches[2])
+		p.oomCgroups[pid] = matches[1]
+		return KernelEvent{}, false
+	}
+
+	if matches := killedRegexp.FindStringSubmatch(message); matches != nil {
+		pid, _ := strconv.Atoi(matches[1])
+
+		cgroup := p.oomCgroups[pid]
+		delete(p.oomCgroups, pid)
+
+		memory := []string{}
+		for _, memoryMatches := range memoryRegexp.FindAllStringSubmatch(matches[3], -1) {
+			memory = append(memory, memoryMatches[1]+":"+memoryMatches[2])
+		}
+
+		return KernelEvent{
+			Type:    KernelEventOOMKill,
+			PID:     pid,
+			Command: matches[2],
+			Cgroup:  cgroup,
+			Memory:  strings.Join(memory, ", "),
+		}, true
+	}
+
+	if matches := segfaultRegexp.FindStringSubmatch(message); matches != nil {
+		pid, _ := strconv.Atoi(matches[2])
+		return KernelEvent{Type: KernelEventSegfault, PID: pid, Command: matches[1], Detail: matches[3]}, true
+	}
+
+	if matches := hungTaskRegexp.FindStringSubmatch(message); matches != nil {
+		pid, _ := strconv.Atoi(matches[2])
+		return KernelEvent{Type: KernelEventHungTask, PID: pid, Command: matches[1], Detail: matches[3]}, true
+	}
+
+	return KernelEvent{}, false
+}
+
+// Alert builds an alert for the event. service is the monit service
+// the process belongs to and may be empty if it could not be determined.
+func (e KernelEvent) Alert(service string, createdAt int64) Alert {
+	process := fmt.Sprintf("%s[%d]", e.Command, e.PID)
+
+	var severity SeverityLevel
+	var event, summary string
+
+	switch e.Type {
+	case KernelEventOOMKill:
+		severity, event = SeverityCritical, "oom killed"
+		summary = fmt.Sprintf("Process %s was killed by the kernel OOM killer", process)
+	case KernelEventSegfault:
+		severity, event = SeverityCritical, "segfault"
+		summary = fmt.Sprintf("Process %s crashed: %s", process, e.Detail)
+	default:
+		severity, event = SeverityError, "hung task"
+		summary = fmt.Sprintf("Process %s is %s", process, e.Detail)
+	}
+
+	title := fmt.Sprintf("%s - %s", process, event)
+	if service != "" {
+		title = fmt.Sprintf("%s - %s - %s", service, event, process)
+		summary += fmt.Sprintf(" (job process %s)", service)
+	}
+
+	if e.Cgroup != "" {
+		summary += fmt.Sprintf("; cgroup: %s", e.Cgroup)
+	}
+	if e.Memory != "" {
+		summary += fmt.Sprintf("; memory: %s", e.Memory)
+	}
+
+	return Alert{
+		ID:        fmt.Sprintf("kernel-%s-%d-%d", e.Type, e.PID, createdAt),
+		Severity:  severity,
+		Title:     title,
+		Summary:   summary,
+		CreatedAt: createdAt,
+	}
+}
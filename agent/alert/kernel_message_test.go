@@ -0,0 +1,98 @@
+package alert_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+)
+
+var _ = Describe("KernelMessageParser", func() {
+	var parser *KernelMessageParser
+
+	BeforeEach(func() {
+		parser = NewKernelMessageParser()
+	})
+
+	It("parses OOM kills, including the cgroup reported on the preceding line", func() {
+		_, found := parser.Parse("6,1001,5000000,-;oom-kill:constraint=CONSTRAINT_MEMCG,nodemask=(null),cpuset=/,mems_allowed=0,oom_memcg=/system.slice/java.service,task_memcg=/system.slice/java.service,task=java,pid=1234,uid=1000")
+		Expect(found).To(BeFalse())
+
+		event, found := parser.Parse("3,1002,5000001,-;Memory cgroup out of memory: Killed process 1234 (java) total-vm:2048000kB, anon-rss:1024000kB, file-rss:512kB, shmem-rss:0kB, UID:1000 pgtables:4096kB oom_score_adj:0")
+		Expect(found).To(BeTrue())
+		Expect(event).To(Equal(KernelEvent{
+			Type:    KernelEventOOMKill,
+			PID:     1234,
+			Command: "java",
+			Cgroup:  "/system.slice/java.service",
+			Memory:  "total-vm:2048000kB, anon-rss:1024000kB, file-rss:512kB, shmem-rss:0kB",
+		}))
+	})
+
+	It("parses OOM kills without a cgroup", func() {
+		event, found := parser.Parse("Out of memory: Killed process 42 (redis-server) total-vm:100kB, anon-rss:50kB")
+		Expect(found).To(BeTrue())
+		Expect(event.PID).To(Equal(42))
+		Expect(event.Command).To(Equal("redis-server"))
+		Expect(event.Cgroup).To(BeEmpty())
+	})
+
+	It("parses segfaults", func() {
+		event, found := parser.Parse("6,1003,5000002,-;nats-server[4321]: segfault at 0 ip 00007f0000000000 sp 00007ffd00000000 error 4 in libc.so.6[7f0000000000+1000]")
+		Expect(found).To(BeTrue())
+		Expect(event).To(Equal(KernelEvent{
+			Type:    KernelEventSegfault,
+			PID:     4321,
+			Command: "nats-server",
+			Detail:  "segfault at 0 ip 00007f0000000000 sp 00007ffd00000000 error 4 in libc.so.6[7f0000000000+1000]",
+		}))
+	})
+
+	It("parses hung tasks", func() {
+		event, found := parser.Parse("3,1004,5000003,-;INFO: task postgres:777 blocked for more than 120 seconds.")
+		Expect(found).To(BeTrue())
+		Expect(event).To(Equal(KernelEvent{
+			Type:    KernelEventHungTask,
+			PID:     777,
+			Command: "postgres",
+			Detail:  "blocked for more than 120 seconds",
+		}))
+	})
+
+	It("ignores unrelated messages and continuation lines", func() {
+		_, found := parser.Parse("6,1005,5000004,-;eth0: link up")
+		Expect(found).To(BeFalse())
+
+		_, found = parser.Parse(" SUBSYSTEM=net")
+		Expect(found).To(BeFalse())
+	})
+
+	Describe("KernelEvent.Alert", func() {
+		It("names the service, process, cgroup and memory", func() {
+			event := KernelEvent{
+				Type:    KernelEventOOMKill,
+				PID:     1234,
+				Command: "java",
+				Cgroup:  "/system.slice/java.service",
+				Memory:  "total-vm:2048000kB, anon-rss:1024000kB",
+			}
+
+			Expect(event.Alert("app", 1700000000)).To(Equal(Alert{
+				ID:        "kernel-oom_kill-1234-1700000000",
+				Severity:  SeverityCritical,
+				Title:     "app - oom killed - java[1234]",
+				Summary:   "Process java[1234] was killed by the kernel OOM killer (job process app); cgroup: /system.slice/java.service; memory: total-vm:2048000kB, anon-rss:1024000kB",
+				CreatedAt: 1700000000,
+			}))
+		})
+
+		It("names only the process when the service is unknown", func() {
+			event := KernelEvent{Type: KernelEventHungTask, PID: 777, Command: "postgres", Detail: "blocked for more than 120 seconds"}
+
+			alert := event.Alert("", 1700000000)
+			Expect(alert.Severity).To(Equal(SeverityError))
+			Expect(alert.Title).To(Equal("postgres[777] - hung task"))
+			Expect(alert.Summary).To(Equal("Process postgres[777] is blocked for more than 120 seconds"))
+		})
+	})
+})
@@ -1,9 +1,11 @@
 package alert
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"code.cloudfoundry.org/clock"
@@ -18,13 +20,18 @@ type MonitAdapter interface {
 
 type monitAdapter struct {
 	monitAlert      MonitAlert
+	jobAlerts       boshsettings.MonitAlerts
 	settingsService boshsettings.Service
 	timeService     clock.Clock
 }
 
-func NewMonitAdapter(monitAlert MonitAlert, settingsService boshsettings.Service, timeService clock.Clock) MonitAdapter {
+// NewMonitAdapter adapts monitAlert using the overrides of the job the
+// alerting process belongs to, which take precedence over those of the
+// agent settings
+func NewMonitAdapter(monitAlert MonitAlert, jobAlerts boshsettings.MonitAlerts, settingsService boshsettings.Service, timeService clock.Clock) MonitAdapter {
 	return &monitAdapter{
 		monitAlert:      monitAlert,
+		jobAlerts:       jobAlerts,
 		settingsService: settingsService,
 		timeService:     timeService,
 	}
@@ -46,6 +53,16 @@ func (m *monitAdapter) Alert() (Alert, error) {
 	}, nil
 }
 
+const defaultMonitTitleFormat = "{{.Service}} - {{.Event}} - {{.Action}}"
+
+type monitTitleData struct {
+	Service     string
+	Name        string
+	Event       string
+	Action      string
+	Description string
+}
+
 func (m *monitAdapter) title() string {
 	settings := m.settingsService.GetSettings()
 
@@ -58,7 +75,45 @@ func (m *monitAdapter) title() string {
 		service = fmt.Sprintf("%s (%s)", service, strings.Join(ips, ", "))
 	}
 
-	return fmt.Sprintf("%s - %s - %s", service, m.monitAlert.Event, m.monitAlert.Action)
+	data := monitTitleData{
+		Service:     service,
+		Name:        m.monitAlert.Service,
+		Event:       m.monitAlert.Event,
+		Action:      m.monitAlert.Action,
+		Description: m.monitAlert.Description,
+	}
+
+	format := m.jobAlerts.TitleFormat
+	if format == "" {
+		format = settings.Env.Bosh.Agent.Alerts.Monit.TitleFormat
+	}
+
+	// A broken title format must not stop the alert from being sent
+	if format != "" {
+		if title, err := renderMonitTitle(format, data); err == nil {
+			return title
+		}
+	}
+
+	title, _ := renderMonitTitle(defaultMonitTitleFormat, data)
+
+	return title
+}
+
+func renderMonitTitle(format string, data monitTitleData) (string, error) {
+	tmpl, err := template.New("title").Parse(format)
+	if err != nil {
+		return "", err
+	}
+
+	var title bytes.Buffer
+
+	err = tmpl.Execute(&title, data)
+	if err != nil {
+		return "", err
+	}
+
+	return title.String(), nil
 }
 
 func (m *monitAdapter) createdAt() int64 {
@@ -70,7 +125,38 @@ func (m *monitAdapter) createdAt() int64 {
 	return createdAt.Unix()
 }
 
+func configuredSeverity(config boshsettings.MonitAlerts, event string) (SeverityLevel, bool) {
+	for _, ignored := range config.Ignore {
+		if strings.ToLower(ignored) == event {
+			return SeverityIgnored, true
+		}
+	}
+
+	for overriddenEvent, name := range config.Severities {
+		if strings.ToLower(overriddenEvent) != event {
+			continue
+		}
+		if severity, found := ParseSeverityLevel(name); found {
+			return severity, true
+		}
+	}
+
+	return SeverityDefault, false
+}
+
 func (m *monitAdapter) Severity() (severity SeverityLevel, found bool) {
+	event := strings.ToLower(m.monitAlert.Event)
+
+	for _, config := range []boshsettings.MonitAlerts{m.jobAlerts, m.settingsService.GetSettings().Env.Bosh.Agent.Alerts.Monit} {
+		if severity, found := configuredSeverity(config, event); found {
+			return severity, true
+		}
+	}
+
+	if severity, found := ParseSeverityLevel(m.monitAlert.Severity); found {
+		return severity, true
+	}
+
 	var eventToSeverity = map[string]SeverityLevel{ // located inside Severity() so as not to create a global
 		"action done":                  SeverityIgnored,
 		"checksum failed":              SeverityCritical,
@@ -155,7 +241,7 @@ func (m *monitAdapter) Severity() (severity SeverityLevel, found bool) {
 		"uid not changed":              SeverityIgnored,
 	}
 
-	severity, found = eventToSeverity[strings.ToLower(m.monitAlert.Event)]
+	severity, found = eventToSeverity[event]
 	if !found {
 		severity = SeverityDefault
 	}
@@ -40,7 +40,7 @@ var _ = Describe("monitAdapter", func() {
 			monitAlert := buildMonitAlert()
 			monitAlert.Event = event
 
-			monitAdapter := NewMonitAdapter(monitAlert, settingsService, timeService)
+			monitAdapter := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService)
 			Expect(monitAdapter.IsIgnorable()).To(BeTrue())
 		}
 
@@ -48,7 +48,7 @@ var _ = Describe("monitAdapter", func() {
 			monitAlert := buildMonitAlert()
 			monitAlert.Event = event
 
-			monitAdapter := NewMonitAdapter(monitAlert, settingsService, timeService)
+			monitAdapter := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService)
 			Expect(monitAdapter.IsIgnorable()).To(BeFalse())
 		}
 
@@ -71,12 +71,23 @@ var _ = Describe("monitAdapter", func() {
 		It("does not ignore unknown monit events", func() {
 			itDoesNotIgnore("fake event")
 		})
+
+		It("ignores events configured to be ignored", func() {
+			settingsService.Settings.Env.Bosh.Agent.Alerts.Monit = boshsettings.MonitAlerts{
+				Ignore:     []string{"PID changed"},
+				Severities: map[string]string{"checksum failed": "ignored"},
+			}
+
+			itIgnores("pid changed")
+			itIgnores("checksum failed")
+			itDoesNotIgnore("connection failed")
+		})
 	})
 
 	Describe("Alert", func() {
 		It("defaults to severty critical, when the event is unknown", func() {
 			monitAlert := buildMonitAlert()
-			monitAdapter := NewMonitAdapter(monitAlert, settingsService, timeService)
+			monitAdapter := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService)
 
 			builtAlert, err := monitAdapter.Alert()
 			Expect(err).ToNot(HaveOccurred())
@@ -90,7 +101,7 @@ var _ = Describe("monitAdapter", func() {
 		It("defaults to severty critical, when the event is unknown", func() {
 			monitAlert := buildMonitAlert()
 			monitAlert.Event = "fake-event"
-			monitAdapter := NewMonitAdapter(monitAlert, settingsService, timeService)
+			monitAdapter := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService)
 
 			builtAlert, err := monitAdapter.Alert()
 			Expect(err).ToNot(HaveOccurred())
@@ -107,7 +118,7 @@ var _ = Describe("monitAdapter", func() {
 			for event, expectedSeverity := range alerts {
 				monitAlert := buildMonitAlert()
 				monitAlert.Event = event
-				monitAdapter := NewMonitAdapter(monitAlert, settingsService, timeService)
+				monitAdapter := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService)
 				builtAlert, err := monitAdapter.Alert()
 				Expect(err).ToNot(HaveOccurred())
 				Expect(builtAlert.Severity).To(Equal(expectedSeverity))
@@ -118,7 +129,7 @@ var _ = Describe("monitAdapter", func() {
 			monitAlert := buildMonitAlert()
 			monitAlert.Date = "Thu, 02 May 2013 20:07:0"
 
-			monitAdapter := NewMonitAdapter(monitAlert, settingsService, timeService)
+			monitAdapter := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService)
 			builtAlert, err := monitAdapter.Alert()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(builtAlert.CreatedAt).To(Equal(timeService.Now().Unix()))
@@ -131,10 +142,99 @@ var _ = Describe("monitAdapter", func() {
 				"fake-net2": boshsettings.Network{IP: "10.0.0.1"},
 			}
 
-			monitAdapter := NewMonitAdapter(monitAlert, settingsService, timeService)
+			monitAdapter := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService)
 			builtAlert, err := monitAdapter.Alert()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(builtAlert.Title).To(Equal("nats (10.0.0.1, 192.168.0.1) - does not exist - restart"))
 		})
+
+		It("uses configured severities", func() {
+			settingsService.Settings.Env.Bosh.Agent.Alerts.Monit = boshsettings.MonitAlerts{
+				Severities: map[string]string{"Resource limit matched": "alert", "does not exist": "bogus"},
+			}
+
+			monitAlert := buildMonitAlert()
+			monitAlert.Event = "resource limit matched"
+
+			builtAlert, err := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService).Alert()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(builtAlert.Severity).To(Equal(SeverityAlert))
+
+			monitAlert.Event = "does not exist"
+
+			severity, found := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService).Severity()
+			Expect(found).To(BeTrue())
+			Expect(severity).To(Equal(SeverityAlert))
+		})
+
+		It("uses the severity raised with the alert unless a severity is configured for its event", func() {
+			monitAlert := buildMonitAlert()
+			monitAlert.Event = "job alert"
+			monitAlert.Severity = "warning"
+
+			severity, found := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService).Severity()
+			Expect(found).To(BeTrue())
+			Expect(severity).To(Equal(SeverityWarning))
+
+			settingsService.Settings.Env.Bosh.Agent.Alerts.Monit = boshsettings.MonitAlerts{
+				Severities: map[string]string{"job alert": "error"},
+			}
+
+			severity, found = NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService).Severity()
+			Expect(found).To(BeTrue())
+			Expect(severity).To(Equal(SeverityError))
+		})
+
+		It("sets the title using the configured format", func() {
+			monitAlert := buildMonitAlert()
+			settingsService.Settings.Networks = boshsettings.Networks{
+				"fake-net1": boshsettings.Network{IP: "10.0.0.1"},
+			}
+			settingsService.Settings.Env.Bosh.Agent.Alerts.Monit.TitleFormat = "[{{.Name}}] {{.Event}}: {{.Description}}"
+
+			builtAlert, err := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService).Alert()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(builtAlert.Title).To(Equal("[nats] does not exist: process is not running"))
+		})
+
+		It("uses the severities and title format of the job before those of the settings", func() {
+			settingsService.Settings.Env.Bosh.Agent.Alerts.Monit = boshsettings.MonitAlerts{
+				Severities:  map[string]string{"does not exist": "warning", "pid changed": "error"},
+				TitleFormat: "{{.Name}} from settings",
+			}
+			jobAlerts := boshsettings.MonitAlerts{
+				Severities:  map[string]string{"does not exist": "alert"},
+				TitleFormat: "{{.Name}} from job",
+			}
+
+			monitAlert := buildMonitAlert()
+
+			builtAlert, err := NewMonitAdapter(monitAlert, jobAlerts, settingsService, timeService).Alert()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(builtAlert.Severity).To(Equal(SeverityAlert))
+			Expect(builtAlert.Title).To(Equal("nats from job"))
+
+			monitAlert.Event = "pid changed"
+
+			severity, found := NewMonitAdapter(monitAlert, jobAlerts, settingsService, timeService).Severity()
+			Expect(found).To(BeTrue())
+			Expect(severity).To(Equal(SeverityError))
+		})
+
+		It("ignores events the job ignores", func() {
+			monitAlert := buildMonitAlert()
+
+			monitAdapter := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{Ignore: []string{"does not exist"}}, settingsService, timeService)
+			Expect(monitAdapter.IsIgnorable()).To(BeTrue())
+		})
+
+		It("falls back to the default title when the configured format is invalid", func() {
+			monitAlert := buildMonitAlert()
+			settingsService.Settings.Env.Bosh.Agent.Alerts.Monit.TitleFormat = "{{.Unknown"
+
+			builtAlert, err := NewMonitAdapter(monitAlert, boshsettings.MonitAlerts{}, settingsService, timeService).Alert()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(builtAlert.Title).To(Equal("nats - does not exist - restart"))
+		})
 	})
 })
@@ -7,4 +7,8 @@ type MonitAlert struct {
 	Action      string
 	Date        string // RFC1123Z formatted date string
 	Description string
+
+	// Severity optionally overrides the severity of the event;
+	// it is only set by alerts raised through the alert server
+	Severity string
 }
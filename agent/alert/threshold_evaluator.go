@@ -0,0 +1,185 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"code.cloudfoundry.org/clock"
+
+	boshvitals "github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
+	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
+)
+
+type thresholdLevel int
+
+const (
+	thresholdLevelNone thresholdLevel = iota
+	thresholdLevelWarning
+	thresholdLevelCritical
+)
+
+func (l thresholdLevel) String() string {
+	switch l {
+	case thresholdLevelWarning:
+		return "warning"
+	case thresholdLevelCritical:
+		return "critical"
+	}
+	return "normal"
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . ThresholdEvaluator
+
+// ThresholdEvaluator raises alerts when vitals cross their configured thresholds.
+type ThresholdEvaluator interface {
+	// Evaluate returns alerts for metrics that crossed into a more severe
+	// level or recovered back to normal since the previous evaluation.
+	Evaluate(vitals boshvitals.Vitals) []Alert
+}
+
+type thresholdEvaluator struct {
+	thresholds  map[string]boshsettings.AlertThreshold
+	timeService clock.Clock
+
+	mutex  sync.Mutex
+	levels map[string]thresholdLevel
+}
+
+func NewThresholdEvaluator(thresholds map[string]boshsettings.AlertThreshold, timeService clock.Clock) ThresholdEvaluator {
+	return &thresholdEvaluator{
+		thresholds:  thresholds,
+		timeService: timeService,
+		levels:      map[string]thresholdLevel{},
+	}
+}
+
+func (e *thresholdEvaluator) Evaluate(vitals boshvitals.Vitals) []Alert {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	values := vitalsPercentages(vitals)
+
+	metrics := []string{}
+	for metric := range e.thresholds {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	alerts := []Alert{}
+
+	for _, metric := range metrics {
+		value, found := values[metric]
+		if !found {
+			continue
+		}
+
+		threshold := e.thresholds[metric]
+		current := e.levels[metric]
+		level := evaluateThreshold(threshold, current, value)
+
+		if level == current {
+			continue
+		}
+
+		e.levels[metric] = level
+
+		if level > current {
+			alerts = append(alerts, e.raisedAlert(metric, threshold, level, value))
+		} else if level == thresholdLevelNone {
+			alerts = append(alerts, e.recoveredAlert(metric, threshold, value))
+		}
+	}
+
+	return alerts
+}
+
+// evaluateThreshold returns the level for value. Levels at or below
+// the current one are lowered by the hysteresis so that a value
+// hovering around a threshold does not repeatedly raise and clear it.
+func evaluateThreshold(threshold boshsettings.AlertThreshold, current thresholdLevel, value float64) thresholdLevel {
+	critical := threshold.Critical
+	if current >= thresholdLevelCritical {
+		critical -= threshold.Hysteresis
+	}
+
+	warning := threshold.Warning
+	if current >= thresholdLevelWarning {
+		warning -= threshold.Hysteresis
+	}
+
+	switch {
+	case threshold.Critical > 0 && value >= critical:
+		return thresholdLevelCritical
+	case threshold.Warning > 0 && value >= warning:
+		return thresholdLevelWarning
+	}
+
+	return thresholdLevelNone
+}
+
+func (e *thresholdEvaluator) raisedAlert(metric string, threshold boshsettings.AlertThreshold, level thresholdLevel, value float64) Alert {
+	severity, limit := SeverityWarning, threshold.Warning
+	if level == thresholdLevelCritical {
+		severity, limit = SeverityCritical, threshold.Critical
+	}
+
+	return e.buildAlert(
+		metric, level, severity,
+		fmt.Sprintf("%s - %s - usage above %s%%", metric, level, formatPercent(limit)),
+		fmt.Sprintf("%s usage is %s%%, above the %s threshold of %s%%", metric, formatPercent(value), level, formatPercent(limit)),
+	)
+}
+
+func (e *thresholdEvaluator) recoveredAlert(metric string, threshold boshsettings.AlertThreshold, value float64) Alert {
+	limit := threshold.Warning
+	if limit <= 0 {
+		limit = threshold.Critical
+	}
+
+	return e.buildAlert(
+		metric, thresholdLevelNone, SeverityWarning,
+		fmt.Sprintf("%s - recovered", metric),
+		fmt.Sprintf("%s usage is %s%%, back below the threshold of %s%%", metric, formatPercent(value), formatPercent(limit)),
+	)
+}
+
+func (e *thresholdEvaluator) buildAlert(metric string, level thresholdLevel, severity SeverityLevel, title, summary string) Alert {
+	createdAt := e.timeService.Now().Unix()
+
+	return Alert{
+		ID:        fmt.Sprintf("%s-%s-%d", metric, level, createdAt),
+		Severity:  severity,
+		Title:     title,
+		Summary:   summary,
+		CreatedAt: createdAt,
+	}
+}
+
+// vitalsPercentages maps threshold metric names to their current
+// value; vitals that are not reported (e.g. no persistent disk) are omitted
+func vitalsPercentages(vitals boshvitals.Vitals) map[string]float64 {
+	values := map[string]float64{}
+
+	add := func(metric, percent string) {
+		value, err := strconv.ParseFloat(percent, 64)
+		if err == nil {
+			values[metric] = value
+		}
+	}
+
+	for name, disk := range vitals.Disk {
+		add(name+"_disk", disk.Percent)
+		add(name+"_inode", disk.InodePercent)
+	}
+
+	add("mem", vitals.Mem.Percent)
+	add("swap", vitals.Swap.Percent)
+
+	return values
+}
+
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,98 @@
+package alert_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+
+	. "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	boshvitals "github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
+	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
+)
+
+var _ = Describe("ThresholdEvaluator", func() {
+	var (
+		timeService *fakeclock.FakeClock
+		evaluator   ThresholdEvaluator
+	)
+
+	diskVitals := func(percent string) boshvitals.Vitals {
+		return boshvitals.Vitals{
+			Disk: boshvitals.DiskVitals{
+				"ephemeral": boshvitals.SpecificDiskVitals{Percent: percent, InodePercent: "1"},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		timeService = fakeclock.NewFakeClock(time.Unix(1700000000, 0))
+		evaluator = NewThresholdEvaluator(map[string]boshsettings.AlertThreshold{
+			"ephemeral_disk":  {Warning: 80, Critical: 90, Hysteresis: 5},
+			"ephemeral_inode": {Warning: 80, Critical: 90, Hysteresis: 5},
+			"persistent_disk": {Warning: 80, Critical: 90, Hysteresis: 5},
+			"mem":             {Critical: 95},
+		}, timeService)
+	})
+
+	It("does not alert while vitals are below thresholds", func() {
+		Expect(evaluator.Evaluate(diskVitals("79"))).To(BeEmpty())
+	})
+
+	It("raises a warning alert when crossing the warning threshold", func() {
+		Expect(evaluator.Evaluate(diskVitals("85"))).To(Equal([]Alert{{
+			ID:        "ephemeral_disk-warning-1700000000",
+			Severity:  SeverityWarning,
+			Title:     "ephemeral_disk - warning - usage above 80%",
+			Summary:   "ephemeral_disk usage is 85%, above the warning threshold of 80%",
+			CreatedAt: 1700000000,
+		}}))
+
+		Expect(evaluator.Evaluate(diskVitals("86"))).To(BeEmpty())
+	})
+
+	It("raises a critical alert when crossing the critical threshold", func() {
+		Expect(evaluator.Evaluate(diskVitals("85"))).To(HaveLen(1))
+
+		alerts := evaluator.Evaluate(diskVitals("92.5"))
+		Expect(alerts).To(HaveLen(1))
+		Expect(alerts[0].Severity).To(Equal(SeverityCritical))
+		Expect(alerts[0].Summary).To(Equal("ephemeral_disk usage is 92.5%, above the critical threshold of 90%"))
+	})
+
+	It("only clears a level after dropping below it by the hysteresis", func() {
+		Expect(evaluator.Evaluate(diskVitals("91"))).To(HaveLen(1))
+
+		Expect(evaluator.Evaluate(diskVitals("89"))).To(BeEmpty())
+		Expect(evaluator.Evaluate(diskVitals("90"))).To(BeEmpty())
+
+		Expect(evaluator.Evaluate(diskVitals("84"))).To(BeEmpty())
+		Expect(evaluator.Evaluate(diskVitals("90"))).To(HaveLen(1))
+
+		Expect(evaluator.Evaluate(diskVitals("76"))).To(BeEmpty())
+
+		alerts := evaluator.Evaluate(diskVitals("74"))
+		Expect(alerts).To(Equal([]Alert{{
+			ID:        "ephemeral_disk-normal-1700000000",
+			Severity:  SeverityWarning,
+			Title:     "ephemeral_disk - recovered",
+			Summary:   "ephemeral_disk usage is 74%, back below the threshold of 80%",
+			CreatedAt: 1700000000,
+		}}))
+	})
+
+	It("skips disabled levels", func() {
+		alerts := evaluator.Evaluate(boshvitals.Vitals{Mem: boshvitals.MemoryVitals{Percent: "94"}})
+		Expect(alerts).To(BeEmpty())
+
+		alerts = evaluator.Evaluate(boshvitals.Vitals{Mem: boshvitals.MemoryVitals{Percent: "96"}})
+		Expect(alerts).To(HaveLen(1))
+		Expect(alerts[0].Title).To(Equal("mem - critical - usage above 95%"))
+	})
+
+	It("ignores vitals that are not reported", func() {
+		Expect(evaluator.Evaluate(boshvitals.Vitals{})).To(BeEmpty())
+	})
+})
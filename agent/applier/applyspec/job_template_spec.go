@@ -7,11 +7,45 @@ import (
 type JobTemplateSpec struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
+
+	// Co-located jobs that have to run before this job is started
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	// How long stopping the job may take and whether it is killed afterwards
+	Stop *models.JobStopPolicy `json:"stop,omitempty"`
+
+	// Jobs drain in ascending order of their group and in parallel
+	// with the other jobs of their group
+	DrainGroup int `json:"drain_group,omitempty"`
+
+	// Limits of the job's scripts keyed by script name, e.g. pre-start
+	Scripts map[string]models.ScriptLimits `json:"scripts,omitempty"`
+
+	// Cron schedules of the job's bin/periodic scripts keyed by script name;
+	// their limits are declared in Scripts keyed by periodic/<name>
+	Periodic map[string]string `json:"periodic,omitempty"`
+
+	// Overrides of the monit alerts of the job's processes,
+	// taking precedence over those of the agent settings
+	Alerts *MonitAlertsSpec `json:"alerts,omitempty"`
+}
+
+// MonitAlertsSpec has the fields of the agent's monit alert settings,
+// see settings.MonitAlerts
+type MonitAlertsSpec struct {
+	Severities  map[string]string `json:"severities"`
+	Ignore      []string          `json:"ignore"`
+	TitleFormat string            `json:"title_format"`
 }
 
 func (s *JobTemplateSpec) AsJob() models.Job {
 	return models.Job{
-		Name:    s.Name,
-		Version: s.Version,
+		Name:       s.Name,
+		Version:    s.Version,
+		DependsOn:  s.DependsOn,
+		Stop:       s.Stop,
+		DrainGroup: s.DrainGroup,
+		Scripts:    s.Scripts,
+		Periodic:   s.Periodic,
 	}
 }
@@ -27,8 +27,8 @@ var _ = Describe("V1ApplySpec", func() {
 					"sha1": "sha1:routersha1;sha256:routersha256",
 					"blobstore_id": "router-blob-id-1",
 					"templates": [
-						{"name": "template 1", "version": "0.1"},
-						{"name": "template 2", "version": "0.2"}
+						{"name": "template 1", "version": "0.1", "drain_group": 1, "scripts": {"pre-start": {"timeout": 600, "user": "vcap", "resources": {"memory_max": "512M"}}}, "periodic": {"cleanup": "@hourly"}},
+						{"name": "template 2", "version": "0.2", "depends_on": ["template 1"], "stop": {"timeout": 120, "escalation": "kill", "grace_period": 15}}
 					]
 				},
 				"packages": {
@@ -87,8 +87,8 @@ var _ = Describe("V1ApplySpec", func() {
 					Template: "router template",
 					Version:  "1.0",
 					JobTemplateSpecs: []JobTemplateSpec{
-						{Name: "template 1", Version: "0.1"},
-						{Name: "template 2", Version: "0.2"},
+						{Name: "template 1", Version: "0.1", DrainGroup: 1, Scripts: map[string]models.ScriptLimits{"pre-start": {Timeout: 600, User: "vcap", Resources: models.ResourceLimits{MemoryMax: "512M"}}}, Periodic: map[string]string{"cleanup": "@hourly"}},
+						{Name: "template 2", Version: "0.2", DependsOn: []string{"template 1"}, Stop: &models.JobStopPolicy{Timeout: 120, Escalation: "kill", GracePeriod: 15}},
 					},
 				},
 				PackageSpecs: map[string]PackageSpec{
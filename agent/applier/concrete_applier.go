@@ -3,7 +3,10 @@ package applier
 import (
 	as "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/applier/jobs"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/applier/models"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/applier/packages"
+	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
+	boshcron "github.com/cloudfoundry/bosh-agent/v2/agent/script/cron"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
 	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
 	boshdirs "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
@@ -81,7 +84,27 @@ func (a *concreteApplier) Prepare(desiredApplySpec as.ApplySpec) error {
 }
 
 func (a *concreteApplier) Apply(desiredApplySpec as.ApplySpec) error {
-	err := a.jobSupervisor.RemoveAllJobs()
+	_, err := jobDependencies(desiredApplySpec.Jobs()).StartOrder()
+	if err != nil {
+		return bosherr.WrapError(err, "Ordering jobs")
+	}
+
+	err = jobStopPolicies(desiredApplySpec.Jobs()).Validate()
+	if err != nil {
+		return err
+	}
+
+	err = validateScriptLimits(desiredApplySpec.Jobs())
+	if err != nil {
+		return err
+	}
+
+	err = validatePeriodicSchedules(desiredApplySpec.Jobs())
+	if err != nil {
+		return err
+	}
+
+	err = a.jobSupervisor.RemoveAllJobs()
 	if err != nil {
 		return bosherr.WrapError(err, "Removing all jobs")
 	}
@@ -135,7 +158,17 @@ func (a *concreteApplier) ConfigureJobs(desiredApplySpec as.ApplySpec) error {
 		}
 	}
 
-	err := a.jobSupervisor.Reload()
+	err := a.jobSupervisor.SetJobDependencies(jobDependencies(jobs))
+	if err != nil {
+		return bosherr.WrapError(err, "Setting job dependencies")
+	}
+
+	err = a.jobSupervisor.SetJobStopPolicies(jobStopPolicies(jobs))
+	if err != nil {
+		return bosherr.WrapError(err, "Setting job stop policies")
+	}
+
+	err = a.jobSupervisor.Reload()
 	if err != nil {
 		return bosherr.WrapError(err, "Reloading jobSupervisor")
 	}
@@ -143,6 +176,48 @@ func (a *concreteApplier) ConfigureJobs(desiredApplySpec as.ApplySpec) error {
 	return nil
 }
 
+func jobDependencies(jobs []models.Job) boshjobsuper.JobDependencies {
+	dependencies := boshjobsuper.JobDependencies{}
+	for _, job := range jobs {
+		dependencies[job.Name] = job.DependsOn
+	}
+	return dependencies
+}
+
+func jobStopPolicies(jobs []models.Job) boshjobsuper.JobStopPolicies {
+	policies := boshjobsuper.JobStopPolicies{}
+	for _, job := range jobs {
+		if job.Stop != nil {
+			policies[job.Name] = boshjobsuper.JobStopPolicy(*job.Stop)
+		}
+	}
+	return policies
+}
+
+func validateScriptLimits(jobs []models.Job) error {
+	for _, job := range jobs {
+		for scriptName, limits := range job.Scripts {
+			err := boshscript.CmdLimits(limits).Validate()
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Validating limits of script '%s' of job '%s'", scriptName, job.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func validatePeriodicSchedules(jobs []models.Job) error {
+	for _, job := range jobs {
+		for scriptName, schedule := range job.Periodic {
+			_, err := boshcron.Parse(schedule)
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Validating schedule of periodic script '%s' of job '%s'", scriptName, job.Name)
+			}
+		}
+	}
+	return nil
+}
+
 func (a *concreteApplier) setUpLogrotate(applySpec as.ApplySpec) error {
 	err := a.logrotateDelegate.SetupLogrotate(
 		boshsettings.VCAPUsername,
@@ -12,6 +12,7 @@ import (
 	fakejobs "github.com/cloudfoundry/bosh-agent/v2/agent/applier/jobs/jobsfakes"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/applier/models"
 	fakepackages "github.com/cloudfoundry/bosh-agent/v2/agent/applier/packages/fakes"
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
 	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
 	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
 	boshdirs "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
@@ -171,6 +172,53 @@ var _ = Describe("concreteApplier", func() {
 			job, _ = jobApplier.ConfigureArgsForCall(1)
 			Expect(job).To(Equal(job1))
 		})
+
+		It("sets the dependencies of the jobs on the job supervisor", func() {
+			jobs := []models.Job{
+				{Name: "fake-job-name-1", Version: "fake-version-name-1", DependsOn: []string{"fake-job-name-2"}},
+				{Name: "fake-job-name-2", Version: "fake-version-name-2"},
+			}
+
+			err := agentApplier.ConfigureJobs(&fakeas.FakeApplySpec{JobResults: jobs})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(jobSupervisor.JobDependencies).To(Equal(boshjobsuper.JobDependencies{
+				"fake-job-name-1": {"fake-job-name-2"},
+				"fake-job-name-2": nil,
+			}))
+		})
+
+		It("returns an error when setting the dependencies fails", func() {
+			jobSupervisor.SetJobDependenciesErr = errors.New("fake-set-job-dependencies-error")
+
+			err := agentApplier.ConfigureJobs(&fakeas.FakeApplySpec{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-set-job-dependencies-error"))
+			Expect(jobSupervisor.Reloaded).To(BeFalse())
+		})
+
+		It("sets the stop policies of the jobs on the job supervisor", func() {
+			jobs := []models.Job{
+				{Name: "fake-job-name-1", Stop: &models.JobStopPolicy{Timeout: 60, Escalation: "kill"}},
+				{Name: "fake-job-name-2"},
+			}
+
+			err := agentApplier.ConfigureJobs(&fakeas.FakeApplySpec{JobResults: jobs})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(jobSupervisor.JobStopPolicies).To(Equal(boshjobsuper.JobStopPolicies{
+				"fake-job-name-1": {Timeout: 60, Escalation: "kill"},
+			}))
+		})
+
+		It("returns an error when setting the stop policies fails", func() {
+			jobSupervisor.SetJobStopPoliciesErr = errors.New("fake-set-job-stop-policies-error")
+
+			err := agentApplier.ConfigureJobs(&fakeas.FakeApplySpec{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-set-job-stop-policies-error"))
+			Expect(jobSupervisor.Reloaded).To(BeFalse())
+		})
 	})
 
 	Describe("Apply", func() {
@@ -181,6 +229,51 @@ var _ = Describe("concreteApplier", func() {
 			Expect(jobSupervisor.RemovedAllJobs).To(BeTrue())
 		})
 
+		It("returns an error without removing jobs when the job dependencies form a cycle", func() {
+			jobs := []models.Job{
+				{Name: "fake-job-name-1", DependsOn: []string{"fake-job-name-2"}},
+				{Name: "fake-job-name-2", DependsOn: []string{"fake-job-name-1"}},
+			}
+
+			err := agentApplier.Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Job dependencies form a cycle: fake-job-name-1 -> fake-job-name-2 -> fake-job-name-1"))
+			Expect(jobSupervisor.RemovedAllJobs).To(BeFalse())
+		})
+
+		It("returns an error without removing jobs when a stop policy is invalid", func() {
+			jobs := []models.Job{
+				{Name: "fake-job-name-1", Stop: &models.JobStopPolicy{Escalation: "fake-escalation"}},
+			}
+
+			err := agentApplier.Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating stop policy of job 'fake-job-name-1': Unknown stop escalation 'fake-escalation'"))
+			Expect(jobSupervisor.RemovedAllJobs).To(BeFalse())
+		})
+
+		It("returns an error without removing jobs when script limits are invalid", func() {
+			jobs := []models.Job{
+				{Name: "fake-job-name-1", Scripts: map[string]models.ScriptLimits{"pre-start": {Timeout: -1}}},
+			}
+
+			err := agentApplier.Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating limits of script 'pre-start' of job 'fake-job-name-1': timeout must not be negative"))
+			Expect(jobSupervisor.RemovedAllJobs).To(BeFalse())
+		})
+
+		It("returns an error without removing jobs when a periodic schedule is invalid", func() {
+			jobs := []models.Job{
+				{Name: "fake-job-name-1", Periodic: map[string]string{"cleanup": "* * *"}},
+			}
+
+			err := agentApplier.Apply(&fakeas.FakeApplySpec{JobResults: jobs})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Validating schedule of periodic script 'cleanup' of job 'fake-job-name-1': Schedule '* * *' must have 5 fields"))
+			Expect(jobSupervisor.RemovedAllJobs).To(BeFalse())
+		})
+
 		It("removes all previous jobs from job supervisor before starting to apply jobs", func() {
 			// force remove all error
 			jobSupervisor.RemovedAllJobsErr = errors.New("fake-remove-all-jobs-error")
@@ -14,6 +14,47 @@ type Job struct {
 	// Packages that this job depends on; however,
 	// currently it will contain packages from all jobs
 	Packages []Package
+
+	// Co-located jobs that have to run before this job is started
+	DependsOn []string
+
+	// Stop policy of the job, nil for the default policy
+	Stop *JobStopPolicy
+
+	// Jobs of lower drain groups drain first
+	DrainGroup int
+
+	// Limits of the job's scripts keyed by script name
+	Scripts map[string]ScriptLimits
+
+	// Cron schedules of the job's periodic scripts keyed by script name
+	Periodic map[string]string
+}
+
+// JobStopPolicy is how a job declares it is stopped in the apply spec;
+// the job supervisor gets it as a jobsupervisor.JobStopPolicy
+type JobStopPolicy struct {
+	Timeout     int    `json:"timeout,omitempty"`
+	Escalation  string `json:"escalation,omitempty"`
+	GracePeriod int    `json:"grace_period,omitempty"`
+}
+
+// ScriptLimits are how a job declares the limits of one of its scripts in
+// the apply spec; scripts are run with them as a cmd.Limits
+type ScriptLimits struct {
+	Timeout     int            `json:"timeout,omitempty"`
+	GracePeriod int            `json:"grace_period,omitempty"`
+	User        string         `json:"user,omitempty"`
+	Resources   ResourceLimits `json:"resources,omitempty"`
+}
+
+// ResourceLimits are the resources a script may use, see cgroup.Limits
+type ResourceLimits struct {
+	CPUWeight  int    `json:"cpu_weight,omitempty"`
+	MemoryMax  string `json:"memory_max,omitempty"`
+	MemoryHigh string `json:"memory_high,omitempty"`
+	IOWeight   int    `json:"io_weight,omitempty"`
+	PidsMax    int    `json:"pids_max,omitempty"`
 }
 
 func (s Job) BundleName() string {
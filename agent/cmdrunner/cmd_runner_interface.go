@@ -12,6 +12,10 @@ type CmdResult struct {
 	Stdout []byte
 	Stderr []byte
 
+	// Where the complete output was logged
+	StdoutPath string
+	StderrPath string
+
 	ExitStatus int
 }
 
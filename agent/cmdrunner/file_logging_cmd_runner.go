@@ -7,6 +7,9 @@ import (
 	"path"
 	"unicode/utf8"
 
+	"code.cloudfoundry.org/clock"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
@@ -21,6 +24,7 @@ type FileLoggingCmdRunner struct {
 	cmdRunner      boshsys.CmdRunner
 	baseDir        string
 	truncateLength int64
+	timeService    clock.Clock
 }
 
 type FileLoggingExecErr struct {
@@ -38,12 +42,14 @@ func (f FileLoggingExecErr) Error() string {
 		stderrTitle = "Truncated stderr"
 	}
 
-	return fmt.Sprintf("Command exited with %d; %s: %s, %s: %s",
+	return fmt.Sprintf("Command exited with %d; %s: %s, %s: %s; Full output logged to %s and %s",
 		f.result.ExitStatus,
 		stdoutTitle,
 		f.result.Stdout,
 		stderrTitle,
 		f.result.Stderr,
+		f.result.StdoutPath,
+		f.result.StderrPath,
 	)
 }
 
@@ -52,31 +58,23 @@ func NewFileLoggingCmdRunner(
 	cmdRunner boshsys.CmdRunner,
 	baseDir string,
 	truncateLength int64,
+	timeService clock.Clock,
 ) CmdRunner {
 	return FileLoggingCmdRunner{
 		fs:             fs,
 		cmdRunner:      cmdRunner,
 		baseDir:        baseDir,
 		truncateLength: truncateLength,
+		timeService:    timeService,
 	}
 }
 
 func (f FileLoggingCmdRunner) RunCommand(jobName string, taskName string, cmd boshsys.Command) (*CmdResult, error) {
-	logsDir := path.Join(f.baseDir, jobName)
-
-	err := f.fs.RemoveAll(logsDir)
+	stdoutPath, stderrPath, err := outputlog.Paths(f.fs, path.Join(f.baseDir, jobName, taskName), f.timeService.Now())
 	if err != nil {
-		return nil, bosherr.WrapErrorf(err, "Removing log dir for job %s", jobName)
+		return nil, bosherr.WrapErrorf(err, "Preparing logs for task %s", taskName)
 	}
 
-	err = f.fs.MkdirAll(logsDir, os.FileMode(0750))
-	if err != nil {
-		return nil, bosherr.WrapErrorf(err, "Creating log dir for job %s", jobName)
-	}
-
-	stdoutPath := path.Join(logsDir, fmt.Sprintf("%s.stdout.log", taskName))
-	stderrPath := path.Join(logsDir, fmt.Sprintf("%s.stderr.log", taskName))
-
 	stdoutFile, err := f.fs.OpenFile(stdoutPath, fileOpenFlag, fileOpenPerm)
 	if err != nil {
 		return nil, bosherr.WrapErrorf(err, "Opening stdout for task %s", taskName)
@@ -117,6 +115,9 @@ func (f FileLoggingCmdRunner) RunCommand(jobName string, taskName string, cmd bo
 		Stdout: stdout,
 		Stderr: stderr,
 
+		StdoutPath: stdoutPath,
+		StderrPath: stderrPath,
+
 		ExitStatus: exitStatus,
 	}
 
@@ -2,7 +2,11 @@ package cmdrunner_test
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -20,10 +24,15 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 		runner    CmdRunner
 	)
 
+	const (
+		stdoutLogPath = "/fake-base-dir/fake-log-dir-name/fake-log-file-name.20261019T120000.000000000Z.stdout.log"
+		stderrLogPath = "/fake-base-dir/fake-log-dir-name/fake-log-file-name.20261019T120000.000000000Z.stderr.log"
+	)
+
 	BeforeEach(func() {
 		fs = fakesys.NewFakeFileSystem()
 		cmdRunner = fakesys.NewFakeCmdRunner()
-		runner = NewFileLoggingCmdRunner(fs, cmdRunner, "/fake-base-dir", 15)
+		runner = NewFileLoggingCmdRunner(fs, cmdRunner, "/fake-base-dir", 15, fakeclock.NewFakeClock(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)))
 
 		cmd = boshsys.Command{
 			Name:       "fake-cmd",
@@ -34,20 +43,25 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 	})
 
 	Describe("RunCommand", func() {
-		It("cleans logs directory", func() {
+		It("keeps the logs of earlier runs", func() {
 			err := fs.MkdirAll("/fake-base-dir/fake-log-dir-name/", os.FileMode(0750))
 			Expect(err).ToNot(HaveOccurred())
 
-			err = fs.WriteFile("/fake-base-dir/fake-log-dir-name/old-file", []byte("test-data"))
+			err = fs.WriteFile("/fake-base-dir/fake-log-dir-name/fake-log-file-name.20261018T120000.000000000Z.stdout.log", []byte("test-data"))
 			Expect(err).ToNot(HaveOccurred())
 
 			_, err = runner.RunCommand("fake-log-dir-name", "fake-log-file-name", cmd)
 			Expect(err).ToNot(HaveOccurred())
 
-			Expect(fs.FileExists("/fake-base-dir/fake-log-dir-name/old-file")).To(BeFalse())
+			Expect(fs.FileExists("/fake-base-dir/fake-log-dir-name/fake-log-file-name.20261018T120000.000000000Z.stdout.log")).To(BeTrue())
 		})
 
-		It("returns an error if it fails to remove previous logs directory", func() {
+		It("returns an error if it fails to remove the logs of the oldest runs", func() {
+			oldLogs := []string{}
+			for day := 1; day <= 10; day++ {
+				oldLogs = append(oldLogs, fmt.Sprintf("/fake-base-dir/fake-log-dir-name/fake-log-file-name.202610%02dT120000.000000000Z.stdout.log", day))
+			}
+			fs.SetGlob("/fake-base-dir/fake-log-dir-name/fake-log-file-name.*.stdout.log", oldLogs)
 			fs.RemoveAllStub = func(_ string) error {
 				return errors.New("fake-remove-all-error")
 			}
@@ -100,6 +114,8 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 					IsStdoutTruncated: false,
 					Stdout:            []byte("fake-stdout"),
 					Stderr:            []byte("fake-stderr"),
+					StdoutPath:        stdoutLogPath,
+					StderrPath:        stderrLogPath,
 					ExitStatus:        0,
 				}
 
@@ -112,9 +128,9 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 				_, err := runner.RunCommand("fake-log-dir-name", "fake-log-file-name", cmd)
 				Expect(err).ToNot(HaveOccurred())
 
-				Expect(fs.FileExists("/fake-base-dir/fake-log-dir-name/fake-log-file-name.stdout.log")).To(BeTrue())
+				Expect(fs.FileExists(stdoutLogPath)).To(BeTrue())
 
-				stdout, err := fs.ReadFileString("/fake-base-dir/fake-log-dir-name/fake-log-file-name.stdout.log")
+				stdout, err := fs.ReadFileString(stdoutLogPath)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(stdout).To(Equal("fake-stdout"))
 			})
@@ -123,9 +139,9 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 				_, err := runner.RunCommand("fake-log-dir-name", "fake-log-file-name", cmd)
 				Expect(err).ToNot(HaveOccurred())
 
-				Expect(fs.FileExists("/fake-base-dir/fake-log-dir-name/fake-log-file-name.stderr.log")).To(BeTrue())
+				Expect(fs.FileExists(stderrLogPath)).To(BeTrue())
 
-				stdout, err := fs.ReadFileString("/fake-base-dir/fake-log-dir-name/fake-log-file-name.stderr.log")
+				stdout, err := fs.ReadFileString(stderrLogPath)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(stdout).To(Equal("fake-stderr"))
 			})
@@ -144,7 +160,8 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 			It("returns script error", func() {
 				result, err := runner.RunCommand("fake-log-dir-name", "fake-log-file-name", cmd)
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("Command exited with 1; Stdout: fake-stdout, Stderr: fake-stderr"))
+				Expect(err.Error()).To(Equal("Command exited with 1; Stdout: fake-stdout, Stderr: fake-stderr; " +
+					"Full output logged to " + stdoutLogPath + " and " + stderrLogPath))
 				Expect(result).To(BeNil())
 			})
 
@@ -152,9 +169,9 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 				_, err := runner.RunCommand("fake-log-dir-name", "fake-log-file-name", cmd)
 				Expect(err).To(HaveOccurred())
 
-				Expect(fs.FileExists("/fake-base-dir/fake-log-dir-name/fake-log-file-name.stdout.log")).To(BeTrue())
+				Expect(fs.FileExists(stdoutLogPath)).To(BeTrue())
 
-				stdout, err := fs.ReadFileString("/fake-base-dir/fake-log-dir-name/fake-log-file-name.stdout.log")
+				stdout, err := fs.ReadFileString(stdoutLogPath)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(stdout).To(Equal("fake-stdout"))
 			})
@@ -163,9 +180,9 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 				_, err := runner.RunCommand("fake-log-dir-name", "fake-log-file-name", cmd)
 				Expect(err).To(HaveOccurred())
 
-				Expect(fs.FileExists("/fake-base-dir/fake-log-dir-name/fake-log-file-name.stderr.log")).To(BeTrue())
+				Expect(fs.FileExists(stderrLogPath)).To(BeTrue())
 
-				stdout, err := fs.ReadFileString("/fake-base-dir/fake-log-dir-name/fake-log-file-name.stderr.log")
+				stdout, err := fs.ReadFileString(stderrLogPath)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(stdout).To(Equal("fake-stderr"))
 			})
@@ -184,6 +201,8 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 					IsStderrTruncated: true,
 					Stdout:            []byte("g-output-stdout"),
 					Stderr:            []byte("g-output-stderr"),
+					StdoutPath:        stdoutLogPath,
+					StderrPath:        stderrLogPath,
 					ExitStatus:        0,
 				}
 
@@ -204,6 +223,8 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 					IsStderrTruncated: true,
 					Stdout:            []byte("output-stdout"),
 					Stderr:            []byte("output-stderr"),
+					StdoutPath:        stdoutLogPath,
+					StderrPath:        stderrLogPath,
 					ExitStatus:        0,
 				}
 
@@ -224,6 +245,8 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 					IsStderrTruncated: true,
 					Stdout:            []byte("-output-std\nout"),
 					Stderr:            []byte("-output-std\nerr"),
+					StdoutPath:        stdoutLogPath,
+					StderrPath:        stderrLogPath,
 					ExitStatus:        0,
 				}
 
@@ -244,6 +267,8 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 					IsStderrTruncated: true,
 					Stdout:            []byte("иветstdout"),
 					Stderr:            []byte("иветstderr"),
+					StdoutPath:        stdoutLogPath,
+					StderrPath:        stderrLogPath,
 					ExitStatus:        0,
 				}
 
@@ -262,12 +287,13 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 
 				result, err := runner.RunCommand("fake-log-dir-name", "fake-log-file-name", cmd)
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("Command exited with 1; Truncated stdout: g-output-stdout, Truncated stderr: g-output-stderr"))
+				Expect(err.Error()).To(Equal("Command exited with 1; Truncated stdout: g-output-stdout, Truncated stderr: g-output-stderr; " +
+					"Full output logged to " + stdoutLogPath + " and " + stderrLogPath))
 				Expect(result).To(BeNil())
 			})
 
 			It("return an error if it fails to read from saved stdout file", func() {
-				filePath := "/fake-base-dir/fake-log-dir-name/fake-log-file-name.stdout.log"
+				filePath := stdoutLogPath
 				file := fakesys.NewFakeFile(filePath, fs)
 				file.ReadAtErr = errors.New("fake-read-at-err")
 
@@ -280,7 +306,7 @@ var _ = Describe("FileLoggingCmdRunner", func() {
 			})
 
 			It("return an error if it fails to read from saved stderr file", func() {
-				filePath := "/fake-base-dir/fake-log-dir-name/fake-log-file-name.stderr.log"
+				filePath := stderrLogPath
 				file := fakesys.NewFakeFile(filePath, fs)
 				file.ReadAtErr = errors.New("fake-read-at-err")
 
@@ -14,6 +14,10 @@ type Heartbeat struct {
 	JobState   string            `json:"job_state"`
 	Vitals     boshvitals.Vitals `json:"vitals"`
 	NodeID     string            `json:"node_id"`
+
+	// Only set on heartbeats sent out-of-band because job state changed
+	PreviousJobState string `json:"previous_job_state,omitempty"`
+	ChangedAt        int64  `json:"changed_at,omitempty"`
 }
 
 // Heartbeat payload example:
@@ -21,6 +25,8 @@ type Heartbeat struct {
 //   "job": "cloud_controller",
 //   "index": 3,
 //   "job_state":"running",
+//   "previous_job_state":"failing", (out-of-band heartbeats only)
+//   "changed_at":1306076861, (out-of-band heartbeats only)
 //   "vitals": {
 //     "load": ["0.09","0.04","0.01"],
 //     "cpu": {"user":"0.0","sys":"0.0","wait":"0.4"},
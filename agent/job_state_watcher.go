@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+type JobStateWatchOptions struct {
+	// How often job supervisor status is polled for transitions;
+	// zero disables polling so that only job failure alerts trigger a check
+	PollInterval time.Duration
+
+	// Minimum amount of time between two out-of-band heartbeats
+	Debounce time.Duration
+}
+
+type JobStateChange struct {
+	PreviousState string
+	State         string
+	ChangedAt     time.Time
+}
+
+// JobStateWatcher keeps track of the last observed job state and decides
+// when a transition should result in an out-of-band heartbeat.
+type JobStateWatcher struct {
+	debounce    time.Duration
+	timeService clock.Clock
+
+	mutex         sync.Mutex
+	state         string
+	pending       *JobStateChange
+	lastEmittedAt time.Time
+}
+
+func NewJobStateWatcher(debounce time.Duration, timeService clock.Clock) *JobStateWatcher {
+	return &JobStateWatcher{
+		debounce:    debounce,
+		timeService: timeService,
+	}
+}
+
+// Observe records state and returns a change when one is ready to be reported.
+// Changes that happen within the debounce window are coalesced: the returned
+// change keeps the state that was last reported and the time of the first
+// transition, and it is dropped entirely if the job returned to that state.
+func (w *JobStateWatcher) Observe(state string) (JobStateChange, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := w.timeService.Now()
+
+	if w.state == "" {
+		w.state = state
+		return JobStateChange{}, false
+	}
+
+	if state != w.state {
+		if w.pending == nil {
+			w.pending = &JobStateChange{PreviousState: w.state, ChangedAt: now}
+		}
+		w.pending.State = state
+		w.state = state
+	}
+
+	if w.pending == nil {
+		return JobStateChange{}, false
+	}
+
+	if w.pending.PreviousState == w.pending.State {
+		w.pending = nil
+		return JobStateChange{}, false
+	}
+
+	if !w.lastEmittedAt.IsZero() && now.Sub(w.lastEmittedAt) < w.debounce {
+		return JobStateChange{}, false
+	}
+
+	change := *w.pending
+	w.pending = nil
+	w.lastEmittedAt = now
+
+	return change, true
+}
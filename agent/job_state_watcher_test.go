@@ -0,0 +1,91 @@
+package agent_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+
+	. "github.com/cloudfoundry/bosh-agent/v2/agent"
+)
+
+func init() { //nolint:gochecknoinits
+	Describe("JobStateWatcher", func() {
+		var (
+			timeService *fakeclock.FakeClock
+			watcher     *JobStateWatcher
+		)
+
+		BeforeEach(func() {
+			timeService = fakeclock.NewFakeClock(time.Now())
+			watcher = NewJobStateWatcher(10*time.Second, timeService)
+		})
+
+		It("does not report the first observed state as a change", func() {
+			_, changed := watcher.Observe("running")
+			Expect(changed).To(BeFalse())
+		})
+
+		It("does not report a change when the state stays the same", func() {
+			watcher.Observe("running")
+
+			_, changed := watcher.Observe("running")
+			Expect(changed).To(BeFalse())
+		})
+
+		It("reports a transition with the previous state and time of change", func() {
+			watcher.Observe("running")
+
+			change, changed := watcher.Observe("failing")
+			Expect(changed).To(BeTrue())
+			Expect(change).To(Equal(JobStateChange{
+				PreviousState: "running",
+				State:         "failing",
+				ChangedAt:     timeService.Now(),
+			}))
+		})
+
+		Context("when a change was reported within the debounce interval", func() {
+			BeforeEach(func() {
+				watcher.Observe("running")
+				_, changed := watcher.Observe("failing")
+				Expect(changed).To(BeTrue())
+			})
+
+			It("holds back further changes until the interval passes", func() {
+				timeService.Increment(2 * time.Second)
+				changedAt := timeService.Now()
+
+				_, changed := watcher.Observe("stopped")
+				Expect(changed).To(BeFalse())
+
+				timeService.Increment(10 * time.Second)
+
+				change, changed := watcher.Observe("stopped")
+				Expect(changed).To(BeTrue())
+				Expect(change).To(Equal(JobStateChange{
+					PreviousState: "failing",
+					State:         "stopped",
+					ChangedAt:     changedAt,
+				}))
+			})
+
+			It("drops held back changes when the state flips back", func() {
+				timeService.Increment(2 * time.Second)
+
+				_, changed := watcher.Observe("running")
+				Expect(changed).To(BeFalse())
+
+				_, changed = watcher.Observe("failing")
+				Expect(changed).To(BeFalse())
+
+				timeService.Increment(10 * time.Second)
+
+				_, changed = watcher.Observe("failing")
+				Expect(changed).To(BeFalse())
+			})
+		})
+	})
+}
@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"code.cloudfoundry.org/clock"
+
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	boshcgroup "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	kernelWatcherLogTag = "kernelWatcher"
+
+	// Processes are usually started by a wrapper script so
+	// look a few levels up for the process monit knows about
+	maxParentLookups = 5
+)
+
+// KernelAlertHandler receives alerts for kernel events; service is the
+// monit service the process belonged to, or empty if it is unknown.
+type KernelAlertHandler func(service string, event string, alert boshalert.Alert)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . KernelWatcher
+
+type KernelWatcher interface {
+	// Watch blocks reading kernel messages and calls handler for
+	// every OOM kill, segfault or hung task it finds
+	Watch(handler KernelAlertHandler) error
+}
+
+type kmsgWatcher struct {
+	path          string
+	fs            boshsys.FileSystem
+	jobSupervisor boshjobsuper.JobSupervisor
+	timeService   clock.Clock
+	logger        boshlog.Logger
+}
+
+func NewKmsgWatcher(
+	path string,
+	fs boshsys.FileSystem,
+	jobSupervisor boshjobsuper.JobSupervisor,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) KernelWatcher {
+	return kmsgWatcher{
+		path:          path,
+		fs:            fs,
+		jobSupervisor: jobSupervisor,
+		timeService:   timeService,
+		logger:        logger,
+	}
+}
+
+func (w kmsgWatcher) Watch(handler KernelAlertHandler) error {
+	if !w.fs.FileExists(w.path) {
+		w.logger.Info(kernelWatcherLogTag, "Not watching kernel messages, %s does not exist", w.path)
+		return nil
+	}
+
+	file, err := w.fs.OpenFile(w.path, os.O_RDONLY, 0)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Opening %s", w.path)
+	}
+	defer file.Close()
+
+	// Only report events that happen from now on
+	_, err = file.Seek(0, io.SeekEnd)
+	if err != nil {
+		w.logger.Warn(kernelWatcherLogTag, "Failed to skip existing kernel messages: %s", err.Error())
+	}
+
+	parser := boshalert.NewKernelMessageParser()
+
+	// Each read of /dev/kmsg returns a single record which must fit in the buffer
+	reader := bufio.NewReaderSize(file, 8192)
+
+	for {
+		record, err := reader.ReadString('\n')
+
+		if record != "" {
+			if event, found := parser.Parse(record); found {
+				service := w.annotate(&event)
+				handler(service, event.Type, event.Alert(service, w.timeService.Now().Unix()))
+			}
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			// Records were overwritten before they were read
+			if errors.Is(err, syscall.EPIPE) {
+				continue
+			}
+
+			return bosherr.WrapErrorf(err, "Reading %s", w.path)
+		}
+	}
+}
+
+// annotate fills in what the kernel did not report about the process and
+// returns the monit service it belongs to, or its job if that has several
+func (w kmsgWatcher) annotate(event *boshalert.KernelEvent) string {
+	processes, err := w.jobSupervisor.Processes()
+	if err != nil {
+		w.logger.Warn(kernelWatcherLogTag, "Failed to get job processes: %s", err.Error())
+	}
+
+	if event.Cgroup == "" {
+		event.Cgroup = w.cgroup(event.PID)
+	}
+
+	pid, group := event.PID, 0
+
+	for i := 0; i < maxParentLookups && pid > 1; i++ {
+		if process, found := processWithPID(processes, pid); found {
+			return annotateProcess(event, process)
+		}
+
+		// Only possible while the process is still around, e.g. hung tasks
+		var parentGroup int
+		pid, parentGroup = w.procStat(pid)
+		if group == 0 {
+			group = parentGroup
+		}
+	}
+
+	// Daemonized children are no longer below the process monit knows
+	// about but usually stay in the process group it leads
+	if process, found := processWithPID(processes, group); found && group > 1 {
+		return annotateProcess(event, process)
+	}
+
+	// Killed processes are gone by the time their messages are read
+	// so their job can only be told from the cgroup they were in
+	jobName := boshcgroup.JobName(event.Cgroup)
+	if jobName == "" {
+		return ""
+	}
+
+	jobProcesses := []boshjobsuper.Process{}
+	for _, process := range processes {
+		if process.Job == jobName {
+			jobProcesses = append(jobProcesses, process)
+		}
+	}
+
+	if len(jobProcesses) == 1 {
+		return annotateProcess(event, jobProcesses[0])
+	}
+
+	return jobName
+}
+
+func annotateProcess(event *boshalert.KernelEvent, process boshjobsuper.Process) string {
+	if event.Memory == "" {
+		event.Memory = fmt.Sprintf("%dkB (%.1f%%) used by %s", process.Memory.Kb, process.Memory.Percent, process.Name)
+	}
+
+	return process.Name
+}
+
+func processWithPID(processes []boshjobsuper.Process, pid int) (boshjobsuper.Process, bool) {
+	for _, process := range processes {
+		if process.PID == pid {
+			return process, true
+		}
+	}
+
+	return boshjobsuper.Process{}, false
+}
+
+// procStat returns the parent and the process group of pid
+func (w kmsgWatcher) procStat(pid int) (int, int) {
+	stat, err := w.fs.ReadFileString(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, 0
+	}
+
+	// Format is "pid (comm) state ppid pgrp ..." where comm may contain spaces
+	fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+	if len(fields) < 3 {
+		return 0, 0
+	}
+
+	ppid, _ := strconv.Atoi(fields[1])
+	pgrp, _ := strconv.Atoi(fields[2])
+
+	return ppid, pgrp
+}
+
+func (w kmsgWatcher) cgroup(pid int) string {
+	contents, err := w.fs.ReadFileString(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return ""
+	}
+
+	// Prefer the unified (cgroup v2) hierarchy
+	for _, line := range strings.Split(strings.TrimSpace(contents), "\n") {
+		if path, found := strings.CutPrefix(line, "0::"); found {
+			return path
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,162 @@
+package agent_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent"
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
+)
+
+var _ = Describe("KmsgWatcher", func() {
+	type handledAlert struct {
+		service string
+		event   string
+		alert   boshalert.Alert
+	}
+
+	var (
+		fs            *fakesys.FakeFileSystem
+		jobSupervisor *fakejobsuper.FakeJobSupervisor
+		timeService   *fakeclock.FakeClock
+		watcher       agent.KernelWatcher
+
+		handled []handledAlert
+		handler agent.KernelAlertHandler
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
+		timeService = fakeclock.NewFakeClock(time.Unix(1700000000, 0))
+		watcher = agent.NewKmsgWatcher("/dev/kmsg", fs, jobSupervisor, timeService, boshlog.NewLogger(boshlog.LevelNone))
+
+		handled = nil
+		handler = func(service string, event string, alert boshalert.Alert) {
+			handled = append(handled, handledAlert{service: service, event: event, alert: alert})
+		}
+	})
+
+	It("does nothing when kernel messages are not available", func() {
+		Expect(watcher.Watch(handler)).To(Succeed())
+		Expect(handled).To(BeEmpty())
+	})
+
+	It("reports OOM kills of job processes", func() {
+		jobSupervisor.ProcessesStatus = []boshjobsuper.Process{
+			{Name: "redis", PID: 100},
+			{Name: "app", PID: 1234, Memory: boshjobsuper.MemoryVitals{Kb: 2048, Percent: 12.5}},
+		}
+
+		err := fs.WriteFileString("/dev/kmsg", ""+
+			"6,1001,5000000,-;oom-kill:constraint=CONSTRAINT_MEMCG,task_memcg=/system.slice/app.service,task=java,pid=1234,uid=1000\n"+
+			"3,1002,5000001,-;Memory cgroup out of memory: Killed process 1234 (java) total-vm:2048kB, anon-rss:1024kB\n"+
+			"6,1003,5000002,-;eth0: link up\n")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(watcher.Watch(handler)).To(Succeed())
+
+		Expect(handled).To(Equal([]handledAlert{{
+			service: "app",
+			event:   boshalert.KernelEventOOMKill,
+			alert: boshalert.Alert{
+				ID:        "kernel-oom_kill-1234-1700000000",
+				Severity:  boshalert.SeverityCritical,
+				Title:     "app - oom killed - java[1234]",
+				Summary:   "Process java[1234] was killed by the kernel OOM killer (job process app); cgroup: /system.slice/app.service; memory: total-vm:2048kB, anon-rss:1024kB",
+				CreatedAt: 1700000000,
+			},
+		}}))
+	})
+
+	It("maps child processes to their job process while they are still running", func() {
+		jobSupervisor.ProcessesStatus = []boshjobsuper.Process{
+			{Name: "postgres", PID: 50, Memory: boshjobsuper.MemoryVitals{Kb: 4096, Percent: 25}},
+		}
+
+		Expect(fs.WriteFileString("/proc/777/stat", "777 (postgres: writer) D 60 777 777 0 -1")).To(Succeed())
+		Expect(fs.WriteFileString("/proc/60/stat", "60 (pg_ctl) S 50 60 60 0 -1")).To(Succeed())
+		Expect(fs.WriteFileString("/proc/777/cgroup", "0::/system.slice/postgres.service\n")).To(Succeed())
+
+		err := fs.WriteFileString("/dev/kmsg", "3,1004,5000003,-;INFO: task postgres:777 blocked for more than 120 seconds.\n")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(watcher.Watch(handler)).To(Succeed())
+
+		Expect(handled).To(HaveLen(1))
+		Expect(handled[0].service).To(Equal("postgres"))
+		Expect(handled[0].event).To(Equal(boshalert.KernelEventHungTask))
+		Expect(handled[0].alert.Summary).To(Equal("Process postgres[777] is blocked for more than 120 seconds (job process postgres); cgroup: /system.slice/postgres.service; memory: 4096kB (25.0%) used by postgres"))
+	})
+
+	It("maps processes that are no longer running to their job through their cgroup", func() {
+		jobSupervisor.ProcessesStatus = []boshjobsuper.Process{
+			{Name: "redis", Job: "redis", PID: 100, Memory: boshjobsuper.MemoryVitals{Kb: 1024, Percent: 6.25}},
+			{Name: "web", Job: "app", PID: 200},
+			{Name: "worker", Job: "app", PID: 300},
+		}
+
+		err := fs.WriteFileString("/dev/kmsg", ""+
+			"6,1001,5000000,-;oom-kill:constraint=CONSTRAINT_MEMCG,task_memcg=/bosh/redis,task=redis-check,pid=4321,uid=1000\n"+
+			"3,1002,5000001,-;Memory cgroup out of memory: Killed process 4321 (redis-check) total-vm:512kB\n"+
+			"6,1003,5000002,-;oom-kill:constraint=CONSTRAINT_MEMCG,task_memcg=/bosh.slice/bosh-app.slice/web.service,task=ruby,pid=4322,uid=1000\n"+
+			"3,1004,5000003,-;Memory cgroup out of memory: Killed process 4322 (ruby) total-vm:512kB\n")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(watcher.Watch(handler)).To(Succeed())
+
+		Expect(handled).To(HaveLen(2))
+		Expect(handled[0].service).To(Equal("redis"))
+		Expect(handled[0].alert.Summary).To(Equal("Process redis-check[4321] was killed by the kernel OOM killer (job process redis); cgroup: /bosh/redis; memory: total-vm:512kB"))
+		Expect(handled[1].service).To(Equal("app"))
+		Expect(handled[1].alert.Title).To(Equal("app - oom killed - ruby[4322]"))
+	})
+
+	It("maps daemonized processes to the job process leading their process group", func() {
+		jobSupervisor.ProcessesStatus = []boshjobsuper.Process{
+			{Name: "nginx", PID: 70, Memory: boshjobsuper.MemoryVitals{Kb: 512, Percent: 3}},
+		}
+
+		Expect(fs.WriteFileString("/proc/888/stat", "888 (nginx) D 1 70 70 0 -1")).To(Succeed())
+
+		err := fs.WriteFileString("/dev/kmsg", "3,1005,5000004,-;INFO: task nginx:888 blocked for more than 120 seconds.\n")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(watcher.Watch(handler)).To(Succeed())
+
+		Expect(handled).To(HaveLen(1))
+		Expect(handled[0].service).To(Equal("nginx"))
+		Expect(handled[0].alert.Summary).To(Equal("Process nginx[888] is blocked for more than 120 seconds (job process nginx); memory: 512kB (3.0%) used by nginx"))
+	})
+
+	It("reports processes that do not belong to a job", func() {
+		jobSupervisor.ProcessesError = errors.New("fake-processes-err")
+
+		err := fs.WriteFileString("/dev/kmsg", "6,1003,5000002,-;sshd[42]: segfault at 0 ip 0 sp 0 error 4\n")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(watcher.Watch(handler)).To(Succeed())
+
+		Expect(handled).To(HaveLen(1))
+		Expect(handled[0].service).To(BeEmpty())
+		Expect(handled[0].alert.Title).To(Equal("sshd[42] - segfault"))
+	})
+
+	It("returns an error when kernel messages cannot be opened", func() {
+		Expect(fs.WriteFileString("/dev/kmsg", "")).To(Succeed())
+		fs.OpenFileErr = errors.New("fake-open-err")
+
+		err := watcher.Watch(handler)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-open-err"))
+	})
+})
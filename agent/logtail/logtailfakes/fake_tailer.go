@@ -0,0 +1,115 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package logtailfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/logtail"
+)
+
+type FakeTailer struct {
+	TailStub        func(logtail.Options, <-chan struct{}, func([]logtail.Line) error) error
+	tailMutex       sync.RWMutex
+	tailArgsForCall []struct {
+		arg1 logtail.Options
+		arg2 <-chan struct{}
+		arg3 func([]logtail.Line) error
+	}
+	tailReturns struct {
+		result1 error
+	}
+	tailReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeTailer) Tail(arg1 logtail.Options, arg2 <-chan struct{}, arg3 func([]logtail.Line) error) error {
+	fake.tailMutex.Lock()
+	ret, specificReturn := fake.tailReturnsOnCall[len(fake.tailArgsForCall)]
+	fake.tailArgsForCall = append(fake.tailArgsForCall, struct {
+		arg1 logtail.Options
+		arg2 <-chan struct{}
+		arg3 func([]logtail.Line) error
+	}{arg1, arg2, arg3})
+	stub := fake.TailStub
+	fakeReturns := fake.tailReturns
+	fake.recordInvocation("Tail", []interface{}{arg1, arg2, arg3})
+	fake.tailMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTailer) TailCallCount() int {
+	fake.tailMutex.RLock()
+	defer fake.tailMutex.RUnlock()
+	return len(fake.tailArgsForCall)
+}
+
+func (fake *FakeTailer) TailCalls(stub func(logtail.Options, <-chan struct{}, func([]logtail.Line) error) error) {
+	fake.tailMutex.Lock()
+	defer fake.tailMutex.Unlock()
+	fake.TailStub = stub
+}
+
+func (fake *FakeTailer) TailArgsForCall(i int) (logtail.Options, <-chan struct{}, func([]logtail.Line) error) {
+	fake.tailMutex.RLock()
+	defer fake.tailMutex.RUnlock()
+	argsForCall := fake.tailArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeTailer) TailReturns(result1 error) {
+	fake.tailMutex.Lock()
+	defer fake.tailMutex.Unlock()
+	fake.TailStub = nil
+	fake.tailReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTailer) TailReturnsOnCall(i int, result1 error) {
+	fake.tailMutex.Lock()
+	defer fake.tailMutex.Unlock()
+	fake.TailStub = nil
+	if fake.tailReturnsOnCall == nil {
+		fake.tailReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.tailReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTailer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.tailMutex.RLock()
+	defer fake.tailMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeTailer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ logtail.Tailer = new(FakeTailer)
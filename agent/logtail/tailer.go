@@ -0,0 +1,220 @@
+package logtail
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	tailerLogTag = "logTailer"
+
+	pollInterval = 1 * time.Second
+
+	// maxReadPerPoll keeps every batch of lines well
+	// below the maximum payload of a NATS message
+	maxReadPerPoll = 256 * 1024
+)
+
+type tailer struct {
+	fs          boshsys.FileSystem
+	logsDir     string
+	timeService clock.Clock
+	logger      boshlog.Logger
+}
+
+func NewTailer(
+	fs boshsys.FileSystem,
+	logsDir string,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) Tailer {
+	return tailer{
+		fs:          fs,
+		logsDir:     logsDir,
+		timeService: timeService,
+		logger:      logger,
+	}
+}
+
+func (t tailer) Tail(opts Options, done <-chan struct{}, linesFunc func([]Line) error) error {
+	err := opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	filters := opts.Filters
+	if len(filters) == 0 {
+		filters = defaultFilters
+	}
+
+	sizes, err := t.files(filters)
+	if err != nil {
+		return err
+	}
+
+	offsets := map[string]int64{}
+	for file, offset := range opts.Offsets {
+		offsets[file] = offset
+	}
+	for file, size := range sizes {
+		if _, found := offsets[file]; !found {
+			offsets[file] = size
+		}
+	}
+
+	timer := t.timeService.NewTimer(opts.Lifetime())
+	defer timer.Stop()
+
+	ticker := t.timeService.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-timer.C():
+			return nil
+		case <-ticker.C():
+		}
+
+		lines, err := t.poll(filters, offsets)
+		if err != nil {
+			return err
+		}
+
+		if len(lines) > 0 {
+			err = linesFunc(lines)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// poll reads the lines appended since the last poll. Files that are new since
+// the tail started are read from the beginning, as are files that shrunk
+// because they were truncated by log rotation.
+func (t tailer) poll(filters []string, offsets map[string]int64) ([]Line, error) {
+	sizes, err := t.files(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for file := range sizes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	lines := []Line{}
+	budget := int64(maxReadPerPoll)
+
+	for _, file := range files {
+		offset := offsets[file]
+		if sizes[file] < offset {
+			offset = 0
+		}
+
+		if sizes[file] == offset || budget <= 0 {
+			offsets[file] = offset
+			continue
+		}
+
+		fileLines, newOffset, err := t.read(file, offset, sizes[file], budget)
+		if err != nil {
+			t.logger.Warn(tailerLogTag, "Skipping log file '%s': %s", file, err.Error())
+			continue
+		}
+
+		budget -= newOffset - offset
+		offsets[file] = newOffset
+		lines = append(lines, fileLines...)
+	}
+
+	return lines, nil
+}
+
+// read returns the complete lines between offset and size. A trailing
+// partial line is left for the next poll unless it alone exceeds what
+// may be read at once.
+func (t tailer) read(file string, offset int64, size int64, budget int64) ([]Line, int64, error) {
+	f, err := t.fs.OpenFile(filepath.Join(t.logsDir, file), os.O_RDONLY, 0)
+	if err != nil {
+		return nil, offset, bosherr.WrapError(err, "Opening log file")
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	length := size - offset
+	if length > budget {
+		length = budget
+	}
+
+	buf := make([]byte, length)
+	n, err := f.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return nil, offset, bosherr.WrapError(err, "Reading log file")
+	}
+	buf = buf[:n]
+
+	lines := []Line{}
+
+	for {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		offset += int64(i + 1)
+		lines = append(lines, Line{File: file, Offset: offset, Text: string(bytes.TrimSuffix(buf[:i], []byte("\r")))})
+		buf = buf[i+1:]
+	}
+
+	if len(lines) == 0 && n == maxReadPerPoll {
+		offset += int64(n)
+		lines = append(lines, Line{File: file, Offset: offset, Text: string(buf)})
+	}
+
+	return lines, offset, nil
+}
+
+// files returns the size of each regular file matching
+// the filters keyed by its path relative to the logs directory
+func (t tailer) files(filters []string) (map[string]int64, error) {
+	sizes := map[string]int64{}
+
+	for _, filter := range filters {
+		matches, err := t.fs.RecursiveGlob(filepath.Join(t.logsDir, filepath.Clean(filter)))
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Globbing log files '%s'", filter)
+		}
+
+		for _, match := range matches {
+			info, err := t.fs.Stat(match)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+
+			file, err := filepath.Rel(t.logsDir, match)
+			if err != nil {
+				continue
+			}
+
+			sizes[file] = info.Size()
+		}
+	}
+
+	return sizes, nil
+}
@@ -0,0 +1,78 @@
+package logtail
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	DefaultDuration = 5 * time.Minute
+	MaxDuration     = 1 * time.Hour
+)
+
+var defaultFilters = []string{"**/*.log"}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Tailer
+
+type Tailer interface {
+	// Tail calls linesFunc with the lines appended to the log files matching
+	// the filters until done is closed or the lifetime of the tail has passed
+	Tail(opts Options, done <-chan struct{}, linesFunc func([]Line) error) error
+}
+
+// Options select the files to tail. Filters are globs relative to the logs
+// directory. Offsets are keyed by file and resume a previous tail; files
+// without an offset are tailed from their current end.
+type Options struct {
+	Filters  []string         `json:"filters"`
+	Offsets  map[string]int64 `json:"offsets"`
+	Duration int              `json:"duration"`
+}
+
+// Line is a complete line of a log file. Offset is the position in
+// the file after the line and can be passed back to resume the tail.
+type Line struct {
+	File   string `json:"file"`
+	Offset int64  `json:"offset"`
+	Text   string `json:"line"`
+}
+
+// Message is published for every batch of lines tailed over NATS
+// and once more with Done set when the tail has ended
+type Message struct {
+	Lines []Line `json:"lines,omitempty"`
+	Done  bool   `json:"done,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func (o Options) Validate() error {
+	for _, filter := range o.Filters {
+		cleaned := filepath.Clean(filter)
+		if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+			return bosherr.Errorf("Log filter '%s' must be relative to the logs directory", filter)
+		}
+	}
+
+	for file, offset := range o.Offsets {
+		if offset < 0 {
+			return bosherr.Errorf("Offset of log file '%s' must not be negative", file)
+		}
+	}
+
+	return nil
+}
+
+// Lifetime is the requested duration bounded by MaxDuration
+func (o Options) Lifetime() time.Duration {
+	duration := time.Duration(o.Duration) * time.Second
+	if duration <= 0 {
+		return DefaultDuration
+	}
+	if duration > MaxDuration {
+		return MaxDuration
+	}
+	return duration
+}
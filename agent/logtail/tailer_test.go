@@ -0,0 +1,184 @@
+package logtail_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/logtail"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+var _ = Describe("Tailer", func() {
+	var (
+		logsDir   string
+		fakeClock *fakeclock.FakeClock
+		tailer    logtail.Tailer
+
+		lock  sync.Mutex
+		lines []logtail.Line
+	)
+
+	appendToFile := func(file string, contents string) {
+		path := filepath.Join(logsDir, file)
+		Expect(os.MkdirAll(filepath.Dir(path), 0700)).To(Succeed())
+
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.WriteString(contents)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+	}
+
+	tailedLines := func() []logtail.Line {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]logtail.Line{}, lines...)
+	}
+
+	startTail := func(opts logtail.Options) (chan struct{}, chan error) {
+		done := make(chan struct{})
+		errCh := make(chan error, 1)
+
+		go func() {
+			errCh <- tailer.Tail(opts, done, func(newLines []logtail.Line) error {
+				lock.Lock()
+				defer lock.Unlock()
+				lines = append(lines, newLines...)
+				return nil
+			})
+		}()
+
+		// the lifetime timer and the poll ticker
+		fakeClock.WaitForNWatchersAndIncrement(0, 2)
+
+		return done, errCh
+	}
+
+	poll := func() {
+		fakeClock.WaitForNWatchersAndIncrement(time.Second, 2)
+	}
+
+	BeforeEach(func() {
+		var err error
+		logsDir, err = os.MkdirTemp("", "logtail")
+		Expect(err).ToNot(HaveOccurred())
+
+		lines = nil
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		tailer = logtail.NewTailer(boshsys.NewOsFileSystem(logger), logsDir, fakeClock, logger)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(logsDir)).To(Succeed())
+	})
+
+	It("streams lines appended to existing files after the tail started", func() {
+		appendToFile("job/job.log", "old line\n")
+
+		done, errCh := startTail(logtail.Options{})
+		defer close(done)
+
+		appendToFile("job/job.log", "new line 1\nnew line 2\npartial")
+		poll()
+
+		Eventually(tailedLines).Should(Equal([]logtail.Line{
+			{File: "job/job.log", Offset: 20, Text: "new line 1"},
+			{File: "job/job.log", Offset: 31, Text: "new line 2"},
+		}))
+
+		appendToFile("job/job.log", " line\n")
+		poll()
+
+		Eventually(tailedLines).Should(HaveLen(3))
+		Expect(tailedLines()[2]).To(Equal(logtail.Line{File: "job/job.log", Offset: 44, Text: "partial line"}))
+		Consistently(errCh).ShouldNot(Receive())
+	})
+
+	It("resumes files from the given offsets", func() {
+		appendToFile("job/job.log", "line 1\nline 2\n")
+
+		done, _ := startTail(logtail.Options{Offsets: map[string]int64{"job/job.log": 7}})
+		defer close(done)
+
+		poll()
+
+		Eventually(tailedLines).Should(Equal([]logtail.Line{{File: "job/job.log", Offset: 14, Text: "line 2"}}))
+	})
+
+	It("streams files created after the tail started from their beginning", func() {
+		done, _ := startTail(logtail.Options{})
+		defer close(done)
+
+		appendToFile("new.log", "first\n")
+		poll()
+
+		Eventually(tailedLines).Should(Equal([]logtail.Line{{File: "new.log", Offset: 6, Text: "first"}}))
+	})
+
+	It("restarts files that were truncated", func() {
+		appendToFile("job.log", "a long line before rotation\n")
+
+		done, _ := startTail(logtail.Options{})
+		defer close(done)
+
+		Expect(os.Truncate(filepath.Join(logsDir, "job.log"), 0)).To(Succeed())
+		appendToFile("job.log", "rotated\n")
+		poll()
+
+		Eventually(tailedLines).Should(Equal([]logtail.Line{{File: "job.log", Offset: 8, Text: "rotated"}}))
+	})
+
+	It("only streams files matching the filters", func() {
+		done, _ := startTail(logtail.Options{Filters: []string{"job-a/*.log"}})
+		defer close(done)
+
+		appendToFile("job-a/a.log", "a\n")
+		appendToFile("job-b/b.log", "b\n")
+		poll()
+
+		Eventually(tailedLines).Should(Equal([]logtail.Line{{File: "job-a/a.log", Offset: 2, Text: "a"}}))
+	})
+
+	It("ends when its lifetime has passed", func() {
+		_, errCh := startTail(logtail.Options{Duration: 10})
+
+		fakeClock.WaitForNWatchersAndIncrement(10*time.Second, 2)
+
+		Eventually(errCh).Should(Receive(BeNil()))
+	})
+
+	It("ends when done is closed", func() {
+		done, errCh := startTail(logtail.Options{})
+		close(done)
+
+		Eventually(errCh).Should(Receive(BeNil()))
+	})
+
+	It("returns an error when a filter escapes the logs directory", func() {
+		err := tailer.Tail(logtail.Options{Filters: []string{"../*.log"}}, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("must be relative to the logs directory"))
+	})
+
+	Describe("Options", func() {
+		It("bounds the lifetime", func() {
+			Expect(logtail.Options{}.Lifetime()).To(Equal(logtail.DefaultDuration))
+			Expect(logtail.Options{Duration: 30}.Lifetime()).To(Equal(30 * time.Second))
+			Expect(logtail.Options{Duration: 100000}.Lifetime()).To(Equal(logtail.MaxDuration))
+		})
+
+		It("rejects absolute filters and negative offsets", func() {
+			Expect(logtail.Options{Filters: []string{"/var/log/*"}}.Validate()).ToNot(Succeed())
+			Expect(logtail.Options{Offsets: map[string]int64{"a.log": -1}}.Validate()).ToNot(Succeed())
+			Expect(logtail.Options{Filters: []string{"job/**/*.log"}}.Validate()).To(Succeed())
+		})
+	})
+})
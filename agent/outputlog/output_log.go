@@ -0,0 +1,287 @@
+// Package outputlog logs the output of each invocation of a script to its
+// own files and reports where it was logged and how the output ended. The
+// output is also appended to the files logging every invocation of the
+// script, e.g. /var/vcap/sys/log/job/pre-start.stdout.log, which operators
+// and log forwarders rely on.
+package outputlog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	// LogsKept is how many invocations of a script keep their output logs
+	LogsKept = 10
+
+	// TailLength is how much of the end of its output
+	// a script reports along with its log files
+	TailLength = 10 * 1024
+
+	// Sorts in the order the scripts were invoked
+	timeFormat = "20060102T150405.000000000Z"
+)
+
+// Output tells where the complete output of a script invocation
+// was logged and what it ended with
+type Output struct {
+	StdoutPath string `json:"stdout_path"`
+	StderrPath string `json:"stderr_path"`
+
+	// Up to TailLength bytes from the end of the output
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+}
+
+// Paths returns where to log the output of a script invoked at
+// startedAt, e.g. /var/vcap/sys/log/job/drain.20261019T120000.000000000Z.stdout.log
+// for logPath /var/vcap/sys/log/job/drain. It removes the logs of older
+// invocations so those of the last LogsKept invocations remain.
+func Paths(fs boshsys.FileSystem, logPath string, startedAt time.Time) (string, string, error) {
+	err := fs.MkdirAll(filepath.Dir(logPath), os.FileMode(0750))
+	if err != nil {
+		return "", "", bosherr.WrapErrorf(err, "Creating log directory of %s", logPath)
+	}
+
+	for _, stream := range []string{"stdout", "stderr"} {
+		logs, err := fs.Glob(logPath + ".*." + stream + ".log")
+		if err != nil {
+			return "", "", bosherr.WrapErrorf(err, "Finding %s logs of %s", stream, logPath)
+		}
+
+		// Glob sorts the oldest logs first
+		for len(logs) >= LogsKept {
+			err = fs.RemoveAll(logs[0])
+			if err != nil {
+				return "", "", bosherr.WrapErrorf(err, "Removing %s", logs[0])
+			}
+			logs = logs[1:]
+		}
+	}
+
+	timestamp := startedAt.UTC().Format(timeFormat)
+
+	return logPath + "." + timestamp + ".stdout.log", logPath + "." + timestamp + ".stderr.log", nil
+}
+
+// Log holds the log files of a script invocation
+type Log struct {
+	Stdout *File
+	Stderr *File
+}
+
+// New creates the log files of a script invoked at startedAt, their
+// output is appended to logPath.stdout.log and logPath.stderr.log when closed
+func New(fs boshsys.FileSystem, logPath string, startedAt time.Time) (*Log, error) {
+	return newLog(fs, logPath, startedAt, logPath+".stdout.log", logPath+".stderr.log")
+}
+
+// NewPerInvocation creates the log files of a script invoked at startedAt
+// without appending their output to the logs of every invocation
+func NewPerInvocation(fs boshsys.FileSystem, logPath string, startedAt time.Time) (*Log, error) {
+	return newLog(fs, logPath, startedAt, "", "")
+}
+
+func newLog(fs boshsys.FileSystem, logPath string, startedAt time.Time, allStdoutPath, allStderrPath string) (*Log, error) {
+	stdoutPath, stderrPath, err := Paths(fs, logPath, startedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := newFile(fs, stdoutPath, allStdoutPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stderr, err := newFile(fs, stderrPath, allStderrPath)
+	if err != nil {
+		_ = stdout.Close()
+		return nil, err
+	}
+
+	return &Log{Stdout: stdout, Stderr: stderr}, nil
+}
+
+// Output reads the end of the output logged so far, so it
+// has to be called before the log files are closed
+func (l *Log) Output() (Output, error) {
+	stdout, _, err := l.Stdout.Tail(TailLength)
+	if err != nil {
+		return Output{}, err
+	}
+
+	stderr, _, err := l.Stderr.Tail(TailLength)
+	if err != nil {
+		return Output{}, err
+	}
+
+	return Output{
+		StdoutPath: l.Stdout.Path(),
+		StderrPath: l.Stderr.Path(),
+		Stdout:     string(stdout),
+		Stderr:     string(stderr),
+	}, nil
+}
+
+func (l *Log) Close() error {
+	stdoutErr := l.Stdout.Close()
+	stderrErr := l.Stderr.Close()
+
+	if stdoutErr != nil {
+		return stdoutErr
+	}
+
+	return stderrErr
+}
+
+// File is the log file of an output stream of a script
+type File struct {
+	fs   boshsys.FileSystem
+	path string
+	file boshsys.File
+
+	// Logs the output of every invocation, if set
+	allPath string
+
+	lock   sync.Mutex
+	closed bool
+}
+
+func newFile(fs boshsys.FileSystem, path string, allPath string) (*File, error) {
+	file, err := fs.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, os.FileMode(0640))
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Opening %s", path)
+	}
+
+	return &File{fs: fs, path: path, file: file, allPath: allPath}, nil
+}
+
+// Writer returns the log file itself for the script to write to. Given a
+// file os/exec hands it to the script instead of copying the output through
+// a pipe, which would block waiting for the script until every process it
+// left running in the background exited as well.
+func (f *File) Writer() io.Writer { return f.file }
+
+func (f *File) Path() string { return f.path }
+
+// Size returns how much output was logged so far
+func (f *File) Size() (int64, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.closed {
+		return 0, bosherr.Errorf("Log %s is closed", f.path)
+	}
+
+	stat, err := f.file.Stat()
+	if err != nil {
+		return 0, bosherr.WrapErrorf(err, "Getting size of %s", f.path)
+	}
+
+	return stat.Size(), nil
+}
+
+// Tail reads up to limit bytes from the end of the output logged
+// so far and whether there was more output before them
+func (f *File) Tail(limit int64) ([]byte, bool, error) {
+	size, err := f.Size()
+	if err != nil {
+		return nil, false, err
+	}
+
+	offset := size - limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	data := make([]byte, size-offset)
+
+	n, err := f.file.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return nil, false, bosherr.WrapErrorf(err, "Reading %s", f.path)
+	}
+
+	return data[:n], offset > 0, nil
+}
+
+// Close closes the log file and appends the output to the log of every
+// invocation, closing it again does nothing
+func (f *File) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.closed {
+		return nil
+	}
+
+	f.closed = true
+
+	err := f.file.Close()
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Closing %s", f.path)
+	}
+
+	if f.allPath == "" {
+		return nil
+	}
+
+	return f.appendTo(f.allPath)
+}
+
+func (f *File) appendTo(path string) error {
+	src, err := f.fs.OpenFile(f.path, os.O_RDONLY, os.FileMode(0))
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Opening %s", f.path)
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	dst, err := f.fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, os.FileMode(0640))
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Opening %s", path)
+	}
+	defer func() {
+		_ = dst.Close()
+	}()
+
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Appending %s to %s", f.path, path)
+	}
+
+	return nil
+}
+
+// LastOutput holds the output of the last invocation of a
+// script so it can be reported after the script ran
+type LastOutput struct {
+	lock     sync.Mutex
+	output   Output
+	recorded bool
+}
+
+func (o *LastOutput) Record(output Output) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.output = output
+	o.recorded = true
+}
+
+// Get returns the output of the last invocation, false if there was none
+func (o *LastOutput) Get() (Output, bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return o.output, o.recorded
+}
@@ -0,0 +1,174 @@
+package outputlog_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+var _ = Describe("Log", func() {
+	var (
+		fs        boshsys.FileSystem
+		logPath   string
+		startedAt time.Time
+	)
+
+	BeforeEach(func() {
+		fs = boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+		logPath = filepath.Join(GinkgoT().TempDir(), "job", "drain")
+		startedAt = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	})
+
+	It("logs each stream to its own file named after the time the script started", func() {
+		outputLog, err := outputlog.New(fs, logPath, startedAt)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = fmt.Fprint(outputLog.Stdout.Writer(), "fake-stdout")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = fmt.Fprint(outputLog.Stderr.Writer(), "fake-stderr")
+		Expect(err).ToNot(HaveOccurred())
+
+		output, err := outputLog.Output()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal(outputlog.Output{
+			StdoutPath: logPath + ".20261019T120000.000000000Z.stdout.log",
+			StderrPath: logPath + ".20261019T120000.000000000Z.stderr.log",
+			Stdout:     "fake-stdout",
+			Stderr:     "fake-stderr",
+		}))
+
+		Expect(outputLog.Close()).To(Succeed())
+		Expect(fs.ReadFileString(output.StdoutPath)).To(Equal("fake-stdout"))
+		Expect(fs.ReadFileString(output.StderrPath)).To(Equal("fake-stderr"))
+	})
+
+	It("appends the output to the logs of every invocation when closed", func() {
+		for _, out := range []string{"first-", "second"} {
+			outputLog, err := outputlog.New(fs, logPath, startedAt)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = fmt.Fprint(outputLog.Stdout.Writer(), out+"-stdout")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = fmt.Fprint(outputLog.Stderr.Writer(), out+"-stderr")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(outputLog.Close()).To(Succeed())
+			Expect(outputLog.Close()).To(Succeed())
+
+			startedAt = startedAt.Add(time.Second)
+		}
+
+		Expect(fs.ReadFileString(logPath + ".stdout.log")).To(Equal("first--stdoutsecond-stdout"))
+		Expect(fs.ReadFileString(logPath + ".stderr.log")).To(Equal("first--stderrsecond-stderr"))
+	})
+
+	It("does not append the output to the logs of every invocation when logging per invocation only", func() {
+		outputLog, err := outputlog.NewPerInvocation(fs, logPath, startedAt)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = fmt.Fprint(outputLog.Stdout.Writer(), "fake-stdout")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(outputLog.Close()).To(Succeed())
+		Expect(fs.FileExists(logPath + ".stdout.log")).To(BeFalse())
+		Expect(fs.FileExists(logPath + ".stderr.log")).To(BeFalse())
+	})
+
+	It("hands scripts the log files themselves so their output does not go through a pipe", func() {
+		outputLog, err := outputlog.New(fs, logPath, startedAt)
+		Expect(err).ToNot(HaveOccurred())
+		defer outputLog.Close() //nolint:errcheck
+
+		Expect(outputLog.Stdout.Writer()).To(BeAssignableToTypeOf(&os.File{}))
+		Expect(outputLog.Stderr.Writer()).To(BeAssignableToTypeOf(&os.File{}))
+	})
+
+	It("reads the end of the output from the log file", func() {
+		outputLog, err := outputlog.New(fs, logPath, startedAt)
+		Expect(err).ToNot(HaveOccurred())
+		defer outputLog.Close() //nolint:errcheck
+
+		_, err = fmt.Fprint(outputLog.Stdout.Writer(), "abcdef")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = fmt.Fprint(outputLog.Stdout.Writer(), "gh")
+		Expect(err).ToNot(HaveOccurred())
+
+		tail, truncated, err := outputLog.Stdout.Tail(4)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(tail)).To(Equal("efgh"))
+		Expect(truncated).To(BeTrue())
+
+		tail, truncated, err = outputLog.Stdout.Tail(outputlog.TailLength)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(tail)).To(Equal("abcdefgh"))
+		Expect(truncated).To(BeFalse())
+
+		Expect(outputLog.Stdout.Size()).To(Equal(int64(8)))
+	})
+
+	It("cannot read the output once the log files are closed", func() {
+		outputLog, err := outputlog.New(fs, logPath, startedAt)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(outputLog.Close()).To(Succeed())
+		Expect(outputLog.Close()).To(Succeed())
+
+		_, err = outputLog.Output()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("removes the logs of older invocations", func() {
+		for i := 0; i < outputlog.LogsKept+2; i++ {
+			outputLog, err := outputlog.New(fs, logPath, startedAt.Add(time.Duration(i)*time.Minute))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(outputLog.Close()).To(Succeed())
+		}
+
+		stdoutLogs, err := filepath.Glob(logPath + ".*.stdout.log")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stdoutLogs).To(HaveLen(outputlog.LogsKept))
+		Expect(stdoutLogs[0]).To(Equal(logPath + ".20261019T120200.000000000Z.stdout.log"))
+
+		stderrLogs, err := filepath.Glob(logPath + ".*.stderr.log")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stderrLogs).To(HaveLen(outputlog.LogsKept))
+	})
+
+	It("does not remove the logs of other scripts", func() {
+		otherLog := filepath.Join(filepath.Dir(logPath), "post-start.20261019T110000.000000000Z.stdout.log")
+		Expect(os.MkdirAll(filepath.Dir(logPath), 0750)).To(Succeed())
+		Expect(os.WriteFile(otherLog, []byte("other"), 0640)).To(Succeed())
+
+		for i := 0; i < outputlog.LogsKept+1; i++ {
+			outputLog, err := outputlog.New(fs, logPath, startedAt.Add(time.Duration(i)*time.Minute))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(outputLog.Close()).To(Succeed())
+		}
+
+		Expect(otherLog).To(BeAnExistingFile())
+	})
+})
+
+var _ = Describe("LastOutput", func() {
+	It("returns the last recorded output", func() {
+		lastOutput := &outputlog.LastOutput{}
+
+		_, found := lastOutput.Get()
+		Expect(found).To(BeFalse())
+
+		lastOutput.Record(outputlog.Output{Stdout: "first"})
+		lastOutput.Record(outputlog.Output{Stdout: "second"})
+
+		output, found := lastOutput.Get()
+		Expect(found).To(BeTrue())
+		Expect(output.Stdout).To(Equal("second"))
+	})
+})
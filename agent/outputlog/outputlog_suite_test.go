@@ -0,0 +1,13 @@
+package outputlog_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestOutputlog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Outputlog Suite")
+}
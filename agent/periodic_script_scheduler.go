@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
+	boshcron "github.com/cloudfoundry/bosh-agent/v2/agent/script/cron"
+	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+const (
+	periodicScriptSchedulerLogTag = "periodicScriptScheduler"
+
+	PeriodicScriptFailedEvent = "periodic script failed"
+)
+
+// PeriodicAlertHandler receives alerts for failed periodic scripts;
+// service is the name of the job the script belongs to.
+type PeriodicAlertHandler func(service string, event string, alert boshalert.Alert)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . PeriodicScriptScheduler
+
+type PeriodicScriptScheduler interface {
+	// Run blocks running the bin/periodic/<name> scripts of the current
+	// jobs on their schedules and calls handler for every failed run.
+	// Scripts of jobs that are not running, e.g. while they are being
+	// stopped or updated, are skipped.
+	Run(handler PeriodicAlertHandler) error
+}
+
+type periodicScriptScheduler struct {
+	specService       boshas.V1Service
+	jobSupervisor     boshjobsuper.JobSupervisor
+	jobScriptProvider boshscript.JobScriptProvider
+	timeService       clock.Clock
+	logger            boshlog.Logger
+
+	// Scripts that are still running keyed by job and script name
+	running     map[string]struct{}
+	runningLock sync.Mutex
+}
+
+func NewPeriodicScriptScheduler(
+	specService boshas.V1Service,
+	jobSupervisor boshjobsuper.JobSupervisor,
+	jobScriptProvider boshscript.JobScriptProvider,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) PeriodicScriptScheduler {
+	return &periodicScriptScheduler{
+		specService:       specService,
+		jobSupervisor:     jobSupervisor,
+		jobScriptProvider: jobScriptProvider,
+		timeService:       timeService,
+		logger:            logger,
+		running:           map[string]struct{}{},
+	}
+}
+
+func (s *periodicScriptScheduler) Run(handler PeriodicAlertHandler) error {
+	for {
+		// Schedules have a resolution of a minute so check
+		// them at the beginning of every minute
+		now := s.timeService.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		s.timeService.Sleep(next.Sub(now))
+
+		s.runDue(next, handler)
+	}
+}
+
+func (s *periodicScriptScheduler) runDue(now time.Time, handler PeriodicAlertHandler) {
+	spec, err := s.specService.Get()
+	if err != nil {
+		s.logger.Warn(periodicScriptSchedulerLogTag, "Not running periodic scripts: %s", err.Error())
+		return
+	}
+
+	var statuses map[string]string
+
+	for _, job := range spec.Jobs() {
+		jobName := job.BundleName()
+
+		if len(job.Periodic) == 0 {
+			continue
+		}
+
+		if statuses == nil {
+			statuses = s.jobSupervisor.JobStatuses()
+		}
+
+		if statuses[jobName] != "running" {
+			s.logger.Debug(periodicScriptSchedulerLogTag, "Not running periodic scripts of job %s, job is not running", jobName)
+			continue
+		}
+
+		for name, expression := range job.Periodic {
+			schedule, err := boshcron.Parse(expression)
+			if err != nil {
+				s.logger.Error(periodicScriptSchedulerLogTag, "Not running periodic script %s of job %s: %s", name, jobName, err.Error())
+				continue
+			}
+
+			if !schedule.Matches(now) {
+				continue
+			}
+
+			if !s.start(jobName, name) {
+				s.logger.Warn(periodicScriptSchedulerLogTag, "Skipping periodic script %s of job %s, previous run is still running", name, jobName)
+				continue
+			}
+
+			go s.run(jobName, name, now, handler)
+		}
+	}
+}
+
+func (s *periodicScriptScheduler) run(jobName string, name string, now time.Time, handler PeriodicAlertHandler) {
+	defer s.logger.HandlePanic("Periodic Script Scheduler Run")
+	defer s.finish(jobName, name)
+
+	script := s.jobScriptProvider.NewScript(jobName, path.Join("periodic", name), map[string]string{
+		boshscript.ReasonEnvVar: boshscript.ReasonPeriodic,
+	})
+	if !script.Exists() {
+		s.logger.Debug(periodicScriptSchedulerLogTag, "Skipping periodic script %s of job %s, %s does not exist", name, jobName, script.Path())
+		return
+	}
+
+	s.logger.Info(periodicScriptSchedulerLogTag, "Running periodic script %s of job %s", name, jobName)
+
+	err := script.Run()
+	if err != nil {
+		s.logger.Error(periodicScriptSchedulerLogTag, "Periodic script %s of job %s failed: %s", name, jobName, err.Error())
+
+		handler(jobName, PeriodicScriptFailedEvent, boshalert.Alert{
+			ID:        fmt.Sprintf("periodic-%s-%s-%d", jobName, name, now.Unix()),
+			Severity:  boshalert.SeverityError,
+			Title:     fmt.Sprintf("%s - %s - %s", jobName, PeriodicScriptFailedEvent, name),
+			Summary:   fmt.Sprintf("Periodic script %s of job %s failed: %s", name, jobName, err.Error()),
+			CreatedAt: s.timeService.Now().Unix(),
+		})
+	}
+}
+
+func (s *periodicScriptScheduler) start(jobName string, name string) bool {
+	s.runningLock.Lock()
+	defer s.runningLock.Unlock()
+
+	key := jobName + "/" + name
+	if _, found := s.running[key]; found {
+		return false
+	}
+
+	s.running[key] = struct{}{}
+	return true
+}
+
+func (s *periodicScriptScheduler) finish(jobName string, name string) {
+	s.runningLock.Lock()
+	defer s.runningLock.Unlock()
+
+	delete(s.running, jobName+"/"+name)
+}
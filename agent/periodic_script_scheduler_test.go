@@ -0,0 +1,151 @@
+package agent_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent"
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/scriptfakes"
+	fakejobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
+)
+
+var _ = Describe("PeriodicScriptScheduler", func() {
+	var (
+		specService       *fakeas.FakeV1Service
+		jobSupervisor     *fakejobsuper.FakeJobSupervisor
+		jobScriptProvider *scriptfakes.FakeJobScriptProvider
+		script            *scriptfakes.FakeScript
+		timeService       *fakeclock.FakeClock
+		scheduler         agent.PeriodicScriptScheduler
+
+		handledLock sync.Mutex
+		handled     []boshalert.Alert
+		handler     agent.PeriodicAlertHandler
+	)
+
+	BeforeEach(func() {
+		specService = fakeas.NewFakeV1Service()
+		specService.Spec = boshas.V1ApplySpec{
+			JobSpec: boshas.JobSpec{
+				JobTemplateSpecs: []boshas.JobTemplateSpec{
+					{Name: "fake-job", Periodic: map[string]string{"cleanup": "* * * * *", "backup": "@yearly"}},
+				},
+			},
+			RenderedTemplatesArchiveSpec: &boshas.RenderedTemplatesArchiveSpec{},
+		}
+
+		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
+		jobSupervisor.JobStatusesMap = map[string]string{"fake-job": "running"}
+
+		script = &scriptfakes.FakeScript{}
+		script.ExistsReturns(true)
+		script.PathReturns("/var/vcap/jobs/fake-job/bin/periodic/cleanup")
+
+		jobScriptProvider = &scriptfakes.FakeJobScriptProvider{}
+		jobScriptProvider.NewScriptReturns(script)
+
+		// Tuesday, 14 November 2023 22:13:20 UTC
+		timeService = fakeclock.NewFakeClock(time.Unix(1700000000, 0))
+		scheduler = agent.NewPeriodicScriptScheduler(specService, jobSupervisor, jobScriptProvider, timeService, boshlog.NewLogger(boshlog.LevelNone))
+
+		handled = nil
+		handler = func(service string, event string, alert boshalert.Alert) {
+			defer GinkgoRecover()
+
+			Expect(service).To(Equal("fake-job"))
+			Expect(event).To(Equal(agent.PeriodicScriptFailedEvent))
+
+			handledLock.Lock()
+			defer handledLock.Unlock()
+			handled = append(handled, alert)
+		}
+
+		go scheduler.Run(handler) //nolint:errcheck
+	})
+
+	handledAlerts := func() []boshalert.Alert {
+		handledLock.Lock()
+		defer handledLock.Unlock()
+		return append([]boshalert.Alert{}, handled...)
+	}
+
+	It("runs the scripts that are due at the beginning of the next minute", func() {
+		timeService.WaitForWatcherAndIncrement(39 * time.Second)
+		Consistently(jobScriptProvider.NewScriptCallCount).Should(Equal(0))
+
+		timeService.WaitForWatcherAndIncrement(time.Second)
+		Eventually(script.RunCallCount).Should(Equal(1))
+
+		Expect(jobScriptProvider.NewScriptCallCount()).To(Equal(1))
+		jobName, scriptName, env := jobScriptProvider.NewScriptArgsForCall(0)
+		Expect(jobName).To(Equal("fake-job"))
+		Expect(scriptName).To(Equal("periodic/cleanup"))
+		Expect(env).To(Equal(map[string]string{"BOSH_SCRIPT_REASON": "periodic"}))
+
+		Expect(handledAlerts()).To(BeEmpty())
+	})
+
+	It("does not run scripts that do not exist", func() {
+		script.ExistsReturns(false)
+
+		timeService.WaitForWatcherAndIncrement(time.Minute)
+		Eventually(script.ExistsCallCount).Should(Equal(1))
+
+		timeService.WaitForWatcherAndIncrement(time.Minute)
+		Expect(script.RunCallCount()).To(Equal(0))
+	})
+
+	It("does not run the scripts of jobs that are not running", func() {
+		jobSupervisor.JobStatusesMap = map[string]string{"fake-job": "stopped"}
+
+		timeService.WaitForWatcherAndIncrement(time.Minute)
+		timeService.WaitForWatcherAndIncrement(time.Minute)
+		Expect(jobScriptProvider.NewScriptCallCount()).To(Equal(0))
+	})
+
+	It("raises an alert when a script fails", func() {
+		script.RunReturns(errors.New("fake-run-error"))
+
+		timeService.WaitForWatcherAndIncrement(40 * time.Second)
+
+		Eventually(handledAlerts).Should(Equal([]boshalert.Alert{{
+			ID:        "periodic-fake-job-cleanup-1700000040",
+			Severity:  boshalert.SeverityError,
+			Title:     "fake-job - periodic script failed - cleanup",
+			Summary:   "Periodic script cleanup of job fake-job failed: fake-run-error",
+			CreatedAt: 1700000040,
+		}}))
+	})
+
+	It("skips a run while the previous run of the script is still running", func() {
+		release := make(chan struct{})
+		script.RunStub = func() error {
+			<-release
+			return nil
+		}
+
+		timeService.WaitForWatcherAndIncrement(time.Minute)
+		Eventually(script.RunCallCount).Should(Equal(1))
+
+		timeService.WaitForWatcherAndIncrement(time.Minute)
+		timeService.WaitForWatcherAndIncrement(time.Minute)
+		Expect(jobScriptProvider.NewScriptCallCount()).To(Equal(1))
+
+		close(release)
+
+		Eventually(func() int {
+			timeService.WaitForWatcherAndIncrement(time.Minute)
+			return jobScriptProvider.NewScriptCallCount()
+		}).Should(Equal(2))
+	})
+})
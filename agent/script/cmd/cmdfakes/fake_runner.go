@@ -0,0 +1,218 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package cmdfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
+	"github.com/cloudfoundry/bosh-utils/system"
+)
+
+type FakeRunner struct {
+	RunComplexCommandStub        func(string, string, system.Command, cmd.Limits) (string, string, int, error)
+	runComplexCommandMutex       sync.RWMutex
+	runComplexCommandArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 system.Command
+		arg4 cmd.Limits
+	}
+	runComplexCommandReturns struct {
+		result1 string
+		result2 string
+		result3 int
+		result4 error
+	}
+	runComplexCommandReturnsOnCall map[int]struct {
+		result1 string
+		result2 string
+		result3 int
+		result4 error
+	}
+	RunComplexCommandAsyncStub        func(string, string, system.Command, cmd.Limits) (system.Process, error)
+	runComplexCommandAsyncMutex       sync.RWMutex
+	runComplexCommandAsyncArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 system.Command
+		arg4 cmd.Limits
+	}
+	runComplexCommandAsyncReturns struct {
+		result1 system.Process
+		result2 error
+	}
+	runComplexCommandAsyncReturnsOnCall map[int]struct {
+		result1 system.Process
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRunner) RunComplexCommand(arg1 string, arg2 string, arg3 system.Command, arg4 cmd.Limits) (string, string, int, error) {
+	fake.runComplexCommandMutex.Lock()
+	ret, specificReturn := fake.runComplexCommandReturnsOnCall[len(fake.runComplexCommandArgsForCall)]
+	fake.runComplexCommandArgsForCall = append(fake.runComplexCommandArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 system.Command
+		arg4 cmd.Limits
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RunComplexCommandStub
+	fakeReturns := fake.runComplexCommandReturns
+	fake.recordInvocation("RunComplexCommand", []interface{}{arg1, arg2, arg3, arg4})
+	fake.runComplexCommandMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3, ret.result4
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3, fakeReturns.result4
+}
+
+func (fake *FakeRunner) RunComplexCommandCallCount() int {
+	fake.runComplexCommandMutex.RLock()
+	defer fake.runComplexCommandMutex.RUnlock()
+	return len(fake.runComplexCommandArgsForCall)
+}
+
+func (fake *FakeRunner) RunComplexCommandCalls(stub func(string, string, system.Command, cmd.Limits) (string, string, int, error)) {
+	fake.runComplexCommandMutex.Lock()
+	defer fake.runComplexCommandMutex.Unlock()
+	fake.RunComplexCommandStub = stub
+}
+
+func (fake *FakeRunner) RunComplexCommandArgsForCall(i int) (string, string, system.Command, cmd.Limits) {
+	fake.runComplexCommandMutex.RLock()
+	defer fake.runComplexCommandMutex.RUnlock()
+	argsForCall := fake.runComplexCommandArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeRunner) RunComplexCommandReturns(result1 string, result2 string, result3 int, result4 error) {
+	fake.runComplexCommandMutex.Lock()
+	defer fake.runComplexCommandMutex.Unlock()
+	fake.RunComplexCommandStub = nil
+	fake.runComplexCommandReturns = struct {
+		result1 string
+		result2 string
+		result3 int
+		result4 error
+	}{result1, result2, result3, result4}
+}
+
+func (fake *FakeRunner) RunComplexCommandReturnsOnCall(i int, result1 string, result2 string, result3 int, result4 error) {
+	fake.runComplexCommandMutex.Lock()
+	defer fake.runComplexCommandMutex.Unlock()
+	fake.RunComplexCommandStub = nil
+	if fake.runComplexCommandReturnsOnCall == nil {
+		fake.runComplexCommandReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 string
+			result3 int
+			result4 error
+		})
+	}
+	fake.runComplexCommandReturnsOnCall[i] = struct {
+		result1 string
+		result2 string
+		result3 int
+		result4 error
+	}{result1, result2, result3, result4}
+}
+
+func (fake *FakeRunner) RunComplexCommandAsync(arg1 string, arg2 string, arg3 system.Command, arg4 cmd.Limits) (system.Process, error) {
+	fake.runComplexCommandAsyncMutex.Lock()
+	ret, specificReturn := fake.runComplexCommandAsyncReturnsOnCall[len(fake.runComplexCommandAsyncArgsForCall)]
+	fake.runComplexCommandAsyncArgsForCall = append(fake.runComplexCommandAsyncArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 system.Command
+		arg4 cmd.Limits
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RunComplexCommandAsyncStub
+	fakeReturns := fake.runComplexCommandAsyncReturns
+	fake.recordInvocation("RunComplexCommandAsync", []interface{}{arg1, arg2, arg3, arg4})
+	fake.runComplexCommandAsyncMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRunner) RunComplexCommandAsyncCallCount() int {
+	fake.runComplexCommandAsyncMutex.RLock()
+	defer fake.runComplexCommandAsyncMutex.RUnlock()
+	return len(fake.runComplexCommandAsyncArgsForCall)
+}
+
+func (fake *FakeRunner) RunComplexCommandAsyncCalls(stub func(string, string, system.Command, cmd.Limits) (system.Process, error)) {
+	fake.runComplexCommandAsyncMutex.Lock()
+	defer fake.runComplexCommandAsyncMutex.Unlock()
+	fake.RunComplexCommandAsyncStub = stub
+}
+
+func (fake *FakeRunner) RunComplexCommandAsyncArgsForCall(i int) (string, string, system.Command, cmd.Limits) {
+	fake.runComplexCommandAsyncMutex.RLock()
+	defer fake.runComplexCommandAsyncMutex.RUnlock()
+	argsForCall := fake.runComplexCommandAsyncArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeRunner) RunComplexCommandAsyncReturns(result1 system.Process, result2 error) {
+	fake.runComplexCommandAsyncMutex.Lock()
+	defer fake.runComplexCommandAsyncMutex.Unlock()
+	fake.RunComplexCommandAsyncStub = nil
+	fake.runComplexCommandAsyncReturns = struct {
+		result1 system.Process
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRunner) RunComplexCommandAsyncReturnsOnCall(i int, result1 system.Process, result2 error) {
+	fake.runComplexCommandAsyncMutex.Lock()
+	defer fake.runComplexCommandAsyncMutex.Unlock()
+	fake.RunComplexCommandAsyncStub = nil
+	if fake.runComplexCommandAsyncReturnsOnCall == nil {
+		fake.runComplexCommandAsyncReturnsOnCall = make(map[int]struct {
+			result1 system.Process
+			result2 error
+		})
+	}
+	fake.runComplexCommandAsyncReturnsOnCall[i] = struct {
+		result1 system.Process
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRunner) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.runComplexCommandMutex.RLock()
+	defer fake.runComplexCommandMutex.RUnlock()
+	fake.runComplexCommandAsyncMutex.RLock()
+	defer fake.runComplexCommandAsyncMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRunner) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ cmd.Runner = new(FakeRunner)
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"time"
+
+	boshcgroup "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// DefaultGracePeriod is how long script processes get to exit after they
+// were asked to terminate before they are killed
+const DefaultGracePeriod = 10 * time.Second
+
+// Limits confine a script the agent runs for a job. They are declared per
+// script in the job spec, e.g.
+//
+//	"scripts": {"pre-start": {"timeout": 600, "user": "vcap", "resources": {"memory_max": "512M"}}}
+//
+// Limits that are not set are not restricted.
+type Limits struct {
+	// Seconds after which the processes of the script are terminated
+	Timeout int `json:"timeout,omitempty"`
+
+	// Seconds between asking the processes to terminate and killing them
+	GracePeriod int `json:"grace_period,omitempty"`
+
+	// User the script runs as instead of the agent's user
+	User string `json:"user,omitempty"`
+
+	Resources boshcgroup.Limits `json:"resources,omitempty"`
+}
+
+func (l Limits) Validate() error {
+	if l.Timeout < 0 {
+		return bosherr.Error("timeout must not be negative")
+	}
+
+	if l.GracePeriod < 0 {
+		return bosherr.Error("grace_period must not be negative")
+	}
+
+	err := l.Resources.Validate()
+	if err != nil {
+		return bosherr.WrapError(err, "Validating resources")
+	}
+
+	return nil
+}
+
+// TimeoutDuration is zero when the script may run for as long as it takes
+func (l Limits) TimeoutDuration() time.Duration {
+	return time.Duration(l.Timeout) * time.Second
+}
+
+func (l Limits) GracePeriodDuration() time.Duration {
+	if l.GracePeriod == 0 {
+		return DefaultGracePeriod
+	}
+
+	return time.Duration(l.GracePeriod) * time.Second
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// RecordPID writes the PID of command to pidFile when it starts.
+// The shell execs command so it keeps the PID that was written.
+func RecordPID(command boshsys.Command, pidFile string) boshsys.Command {
+	wrapped := command
+	wrapped.Name = "/bin/sh"
+	wrapped.Args = append([]string{"-c", `echo $$ > "$0" && exec "$@"`, pidFile, command.Name}, command.Args...)
+
+	return wrapped
+}
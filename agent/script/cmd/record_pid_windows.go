@@ -0,0 +1,10 @@
+package cmd
+
+import (
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// RecordPID leaves command as it is since PIDs of scripts are not recorded on Windows
+func RecordPID(command boshsys.Command, pidFile string) boshsys.Command {
+	return command
+}
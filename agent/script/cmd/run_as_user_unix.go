@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// runAsUser drops privileges with chpst from runit which
+// ships on stemcells to run job processes as vcap
+func runAsUser(command boshsys.Command, user string) (boshsys.Command, error) {
+	wrapped := command
+	wrapped.Name = "chpst"
+	wrapped.Args = append([]string{"-u", user, command.Name}, command.Args...)
+
+	return wrapped, nil
+}
@@ -0,0 +1,10 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+func runAsUser(command boshsys.Command, user string) (boshsys.Command, error) {
+	return command, bosherr.Error("Running scripts as another user is not supported on Windows")
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	boshcgroup "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const runnerLogTag = "scriptRunner"
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Runner
+
+// Runner runs the scripts of jobs confined by their limits
+type Runner interface {
+	RunComplexCommand(jobName string, scriptName string, command boshsys.Command, limits Limits) (string, string, int, error)
+	RunComplexCommandAsync(jobName string, scriptName string, command boshsys.Command, limits Limits) (boshsys.Process, error)
+}
+
+type concreteRunner struct {
+	cmdRunner     boshsys.CmdRunner
+	cgroupManager boshcgroup.Manager
+	timeService   clock.Clock
+	logger        boshlog.Logger
+}
+
+func NewRunner(
+	cmdRunner boshsys.CmdRunner,
+	cgroupManager boshcgroup.Manager,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) Runner {
+	return concreteRunner{
+		cmdRunner:     cmdRunner,
+		cgroupManager: cgroupManager,
+		timeService:   timeService,
+		logger:        logger,
+	}
+}
+
+func (r concreteRunner) RunComplexCommand(jobName string, scriptName string, command boshsys.Command, limits Limits) (string, string, int, error) {
+	// Scripts without a timeout do not need to be watched
+	if limits.Timeout == 0 {
+		command, err := r.confine(jobName, scriptName, command, limits)
+		if err != nil {
+			return "", "", -1, err
+		}
+
+		return r.cmdRunner.RunComplexCommand(command)
+	}
+
+	process, err := r.RunComplexCommandAsync(jobName, scriptName, command, limits)
+	if err != nil {
+		return "", "", -1, err
+	}
+
+	result := <-process.Wait()
+
+	return result.Stdout, result.Stderr, result.ExitStatus, result.Error
+}
+
+func (r concreteRunner) RunComplexCommandAsync(jobName string, scriptName string, command boshsys.Command, limits Limits) (boshsys.Process, error) {
+	command, err := r.confine(jobName, scriptName, command, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	process, err := r.cmdRunner.RunComplexCommandAsync(command)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTimedProcess(process, scriptName+" of "+jobName, limits, r.timeService, r.logger), nil
+}
+
+func (r concreteRunner) confine(jobName string, scriptName string, command boshsys.Command, limits Limits) (boshsys.Command, error) {
+	var err error
+
+	// The user is switched last so the script can still join its cgroup
+	if limits.User != "" {
+		command, err = runAsUser(command, limits.User)
+		if err != nil {
+			return command, bosherr.WrapErrorf(err, "Running script %s of job %s as %s", scriptName, jobName, limits.User)
+		}
+	}
+
+	if limits.Resources != (boshcgroup.Limits{}) {
+		command, err = r.cgroupManager.ConfineScript(jobName, scriptName, limits.Resources, command)
+		if err != nil {
+			return command, bosherr.WrapErrorf(err, "Limiting resources of script %s of job %s", scriptName, jobName)
+		}
+	}
+
+	return command, nil
+}
+
+// timedProcess terminates the process tree of a script that
+// is still running when its timeout passes
+type timedProcess struct {
+	process     boshsys.Process
+	description string
+	limits      Limits
+	timeService clock.Clock
+	logger      boshlog.Logger
+
+	// Closed once result is set
+	done   chan struct{}
+	result boshsys.Result
+}
+
+// newTimedProcess starts timing the script right away,
+// whether or not anyone waits for it
+func newTimedProcess(
+	process boshsys.Process,
+	description string,
+	limits Limits,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) *timedProcess {
+	p := &timedProcess{
+		process:     process,
+		description: description,
+		limits:      limits,
+		timeService: timeService,
+		logger:      logger,
+		done:        make(chan struct{}),
+	}
+
+	var timeoutCh <-chan time.Time
+	if timeout := limits.TimeoutDuration(); timeout > 0 {
+		timer := timeService.NewTimer(timeout)
+		timeoutCh = timer.C()
+
+		go func() {
+			<-p.done
+			timer.Stop()
+		}()
+	}
+
+	// The process may only be waited for once
+	go p.wait(process.Wait(), timeoutCh)
+
+	return p
+}
+
+func (p *timedProcess) wait(exitedCh <-chan boshsys.Result, timeoutCh <-chan time.Time) {
+	defer close(p.done)
+
+	select {
+	case p.result = <-exitedCh:
+
+	case <-timeoutCh:
+		timeout := p.limits.TimeoutDuration()
+		p.logger.Error(runnerLogTag, "Terminating script %s after its timeout of %s", p.description, timeout)
+
+		err := p.process.TerminateNicely(p.limits.GracePeriodDuration())
+		if err != nil {
+			p.logger.Error(runnerLogTag, "Failed to terminate script %s: %s", p.description, err.Error())
+		}
+
+		p.result = <-exitedCh
+		p.result.ExitStatus = -1
+		p.result.Error = bosherr.Errorf("Script timed out after %s", timeout)
+	}
+}
+
+// Wait can be called any number of times, every call gets the result
+func (p *timedProcess) Wait() <-chan boshsys.Result {
+	resultCh := make(chan boshsys.Result, 1)
+
+	go func() {
+		<-p.done
+		resultCh <- p.result
+	}()
+
+	return resultCh
+}
+
+func (p *timedProcess) TerminateNicely(killGracePeriod time.Duration) error {
+	return p.process.TerminateNicely(killGracePeriod)
+}
@@ -0,0 +1,198 @@
+package cmd_test
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup/cgroupfakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("Runner", func() {
+	var (
+		cmdRunner     *fakesys.FakeCmdRunner
+		cgroupManager *cgroupfakes.FakeManager
+		fakeClock     *fakeclock.FakeClock
+		runner        cmd.Runner
+		command       boshsys.Command
+	)
+
+	BeforeEach(func() {
+		cmdRunner = fakesys.NewFakeCmdRunner()
+		cgroupManager = &cgroupfakes.FakeManager{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		runner = cmd.NewRunner(cmdRunner, cgroupManager, fakeClock, boshlog.NewLogger(boshlog.LevelNone))
+		command = boshsys.Command{Name: "/jobs/foo/bin/pre-start", Env: map[string]string{"FOO": "bar"}}
+	})
+
+	It("runs scripts without limits as they are", func() {
+		cmdRunner.AddCmdResult("/jobs/foo/bin/pre-start", fakesys.FakeCmdResult{Stdout: "fake-stdout"})
+
+		stdout, _, _, err := runner.RunComplexCommand("foo", "pre-start", command, cmd.Limits{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stdout).To(Equal("fake-stdout"))
+
+		Expect(cmdRunner.RunComplexCommands).To(Equal([]boshsys.Command{command}))
+		Expect(cgroupManager.ConfineScriptCallCount()).To(Equal(0))
+	})
+
+	It("runs scripts confined to their resources", func() {
+		confined := boshsys.Command{Name: "confined"}
+		cgroupManager.ConfineScriptReturns(confined, nil)
+
+		_, _, _, err := runner.RunComplexCommand("foo", "pre-start", command, cmd.Limits{Resources: cgroup.Limits{MemoryMax: "1G"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		jobName, scriptName, limits, _ := cgroupManager.ConfineScriptArgsForCall(0)
+		Expect(jobName).To(Equal("foo"))
+		Expect(scriptName).To(Equal("pre-start"))
+		Expect(limits).To(Equal(cgroup.Limits{MemoryMax: "1G"}))
+		Expect(cmdRunner.RunComplexCommands).To(Equal([]boshsys.Command{confined}))
+	})
+
+	It("returns an error when confining a script fails", func() {
+		cgroupManager.ConfineScriptReturns(boshsys.Command{}, errors.New("fake-confine-error"))
+
+		_, _, _, err := runner.RunComplexCommand("foo", "pre-start", command, cmd.Limits{Resources: cgroup.Limits{PidsMax: 10}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Limiting resources of script pre-start of job foo: fake-confine-error"))
+		Expect(cmdRunner.RunComplexCommands).To(BeEmpty())
+	})
+
+	It("runs scripts as their user", func() {
+		if runtime.GOOS == "windows" {
+			Skip("Running scripts as another user is not supported on Windows")
+		}
+
+		_, _, _, err := runner.RunComplexCommand("foo", "pre-start", command, cmd.Limits{User: "vcap"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cmdRunner.RunComplexCommands).To(Equal([]boshsys.Command{
+			{Name: "chpst", Args: []string{"-u", "vcap", "/jobs/foo/bin/pre-start"}, Env: map[string]string{"FOO": "bar"}},
+		}))
+	})
+
+	Context("when the script has a timeout", func() {
+		var (
+			process *fakesys.FakeProcess
+			limits  cmd.Limits
+		)
+
+		BeforeEach(func() {
+			limits = cmd.Limits{Timeout: 60, GracePeriod: 5}
+
+			process = &fakesys.FakeProcess{
+				TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {
+					p.WaitCh <- boshsys.Result{Stdout: "fake-stdout", ExitStatus: 143}
+				},
+			}
+			cmdRunner.AddProcess("/jobs/foo/bin/pre-start", process)
+		})
+
+		It("terminates the script when it does not finish in time", func() {
+			errCh := make(chan error, 1)
+			go func() {
+				_, _, _, err := runner.RunComplexCommand("foo", "pre-start", command, limits)
+				errCh <- err
+			}()
+
+			fakeClock.WaitForWatcherAndIncrement(60 * time.Second)
+
+			var err error
+			Eventually(errCh).Should(Receive(&err))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Script timed out after 1m0s"))
+
+			Expect(process.TerminatedNicely).To(BeTrue())
+			Expect(process.TerminateNicelyKillGracePeriod).To(Equal(5 * time.Second))
+		})
+
+		It("returns the result of scripts finishing in time", func() {
+			process.TerminatedNicelyCallBack = nil
+			process.WaitResult = boshsys.Result{Stdout: "fake-stdout", ExitStatus: 0}
+
+			stdout, _, exitStatus, err := runner.RunComplexCommand("foo", "pre-start", command, limits)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout).To(Equal("fake-stdout"))
+			Expect(exitStatus).To(Equal(0))
+			Expect(process.TerminatedNicely).To(BeFalse())
+		})
+
+		It("reports a timed out asynchronous script as failed to run", func() {
+			started, err := runner.RunComplexCommandAsync("foo", "pre-start", command, limits)
+			Expect(err).ToNot(HaveOccurred())
+
+			resultCh := started.Wait()
+			fakeClock.WaitForWatcherAndIncrement(60 * time.Second)
+
+			var result boshsys.Result
+			Eventually(resultCh).Should(Receive(&result))
+			Expect(result.ExitStatus).To(Equal(-1))
+			Expect(result.Stdout).To(Equal("fake-stdout"))
+		})
+
+		It("times asynchronous scripts from when they started, whether or not anyone waits", func() {
+			started, err := runner.RunComplexCommandAsync("foo", "pre-start", command, limits)
+			Expect(err).ToNot(HaveOccurred())
+
+			fakeClock.WaitForWatcherAndIncrement(60 * time.Second)
+
+			var result boshsys.Result
+			Eventually(started.Wait()).Should(Receive(&result))
+			Expect(result.ExitStatus).To(Equal(-1))
+			Expect(process.TerminatedNicely).To(BeTrue())
+		})
+
+		It("returns the result to every caller waiting for an asynchronous script", func() {
+			process.TerminatedNicelyCallBack = nil
+			process.WaitResult = boshsys.Result{Stdout: "fake-stdout", ExitStatus: 0}
+
+			started, err := runner.RunComplexCommandAsync("foo", "pre-start", command, limits)
+			Expect(err).ToNot(HaveOccurred())
+
+			for i := 0; i < 2; i++ {
+				var result boshsys.Result
+				Eventually(started.Wait()).Should(Receive(&result))
+				Expect(result.Stdout).To(Equal("fake-stdout"))
+			}
+		})
+	})
+})
+
+var _ = Describe("Limits", func() {
+	It("defaults the grace period", func() {
+		Expect(cmd.Limits{}.GracePeriodDuration()).To(Equal(cmd.DefaultGracePeriod))
+		Expect(cmd.Limits{GracePeriod: 30}.GracePeriodDuration()).To(Equal(30 * time.Second))
+	})
+
+	It("validates", func() {
+		Expect(cmd.Limits{Timeout: 60, Resources: cgroup.Limits{MemoryMax: "1G"}}.Validate()).To(Succeed())
+		Expect(cmd.Limits{Timeout: -1}.Validate()).To(MatchError("timeout must not be negative"))
+		Expect(cmd.Limits{GracePeriod: -1}.Validate()).To(MatchError("grace_period must not be negative"))
+		Expect(cmd.Limits{Resources: cgroup.Limits{MemoryMax: "lots"}}.Validate()).To(MatchError(ContainSubstring("Validating resources")))
+	})
+})
+
+var _ = Describe("RecordPID", func() {
+	It("writes the PID of the command to the file before running it", func() {
+		if runtime.GOOS == "windows" {
+			Skip("PIDs of scripts are not recorded on Windows")
+		}
+
+		command := boshsys.Command{Name: "/jobs/foo/bin/run", Args: []string{"arg"}}
+
+		Expect(cmd.RecordPID(command, "/run/foo/errand.pid")).To(Equal(boshsys.Command{
+			Name: "/bin/sh",
+			Args: []string{"-c", `echo $$ > "$0" && exec "$@"`, "/run/foo/errand.pid", "/jobs/foo/bin/run", "arg"},
+		}))
+	})
+})
@@ -1,60 +1,160 @@
 package script
 
 import (
-	"fmt"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"code.cloudfoundry.org/clock"
 
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/applier/models"
+	boshcmd "github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
 	boshdrain "github.com/cloudfoundry/bosh-agent/v2/agent/script/drain"
+	boshcgroup "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
 	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
+const concreteJobScriptProviderLogTag = "ConcreteJobScriptProvider"
+
+// DefaultStopHookTimeout is the seconds after which pre-stop and post-stop
+// scripts are terminated when their job declares no timeout, so that they
+// cannot keep the jobs from being stopped
+const DefaultStopHookTimeout = 300
+
+// DefaultPeriodicScriptTimeout is the seconds after which periodic scripts
+// are terminated when their job declares no timeout, so that a hung run
+// does not keep the script from ever running again
+const DefaultPeriodicScriptTimeout = 600
+
 type ConcreteJobScriptProvider struct {
-	cmdRunner   boshsys.CmdRunner
-	fs          boshsys.FileSystem
-	dirProvider boshdir.Provider
-	timeService clock.Clock
-	logger      boshlog.Logger
+	scriptRunner boshcmd.Runner
+	fs           boshsys.FileSystem
+	dirProvider  boshdir.Provider
+	specService  boshas.V1Service
+	settings     boshsettings.Service
+	agentVersion string
+	timeService  clock.Clock
+	logger       boshlog.Logger
 }
 
 func NewConcreteJobScriptProvider(
-	cmdRunner boshsys.CmdRunner,
+	scriptRunner boshcmd.Runner,
 	fs boshsys.FileSystem,
 	dirProvider boshdir.Provider,
+	specService boshas.V1Service,
+	settings boshsettings.Service,
+	agentVersion string,
 	timeService clock.Clock,
 	logger boshlog.Logger,
 ) ConcreteJobScriptProvider {
 	return ConcreteJobScriptProvider{
-		cmdRunner:   cmdRunner,
-		fs:          fs,
-		dirProvider: dirProvider,
-		timeService: timeService,
-		logger:      logger,
+		scriptRunner: scriptRunner,
+		fs:           fs,
+		dirProvider:  dirProvider,
+		specService:  specService,
+		settings:     settings,
+		agentVersion: agentVersion,
+		timeService:  timeService,
+		logger:       logger,
 	}
 }
 
 func (p ConcreteJobScriptProvider) NewScript(jobName string, scriptName string, scriptEnv map[string]string) Script {
 	path := path.Join(p.dirProvider.JobBinDir(jobName), scriptName+ScriptExt)
+	logPath := filepath.Join(p.dirProvider.LogsDir(), jobName, scriptName)
 
-	stdoutLogFilename := fmt.Sprintf("%s.stdout.log", scriptName)
-	stdoutLogPath := filepath.Join(p.dirProvider.LogsDir(), jobName, stdoutLogFilename)
-
-	stderrLogFilename := fmt.Sprintf("%s.stderr.log", scriptName)
-	stderrLogPath := filepath.Join(p.dirProvider.LogsDir(), jobName, stderrLogFilename)
-
-	return NewScript(p.fs, p.cmdRunner, jobName, path, stdoutLogPath, stderrLogPath, scriptEnv)
+	return NewScript(p.fs, p.scriptRunner, p.timeService, jobName, path, logPath, p.env(jobName, scriptEnv), p.limits(jobName, scriptName))
 }
 
 func (p ConcreteJobScriptProvider) NewDrainScript(jobName string, params boshdrain.ScriptParams) CancellableScript {
 	path := path.Join(p.dirProvider.JobsDir(), jobName, "bin", "drain"+ScriptExt)
 
-	return boshdrain.NewConcreteScript(p.fs, p.cmdRunner, jobName, path, params, p.timeService, p.logger)
+	env := p.ScriptEnv(jobName, ReasonDrain)
+
+	logPath := filepath.Join(p.dirProvider.LogsDir(), jobName, "drain")
+
+	return boshdrain.NewConcreteScript(p.fs, p.scriptRunner, jobName, path, logPath, params, env, p.limits(jobName, "drain"), p.timeService, p.logger)
 }
 
 func (p ConcreteJobScriptProvider) NewParallelScript(scriptName string, scripts []Script) CancellableScript {
 	return NewParallelScript(scriptName, scripts, p.logger)
 }
+
+// ScriptEnv returns the environment of a script of a job that runs for reason
+func (p ConcreteJobScriptProvider) ScriptEnv(jobName string, reason string) map[string]string {
+	return p.env(jobName, map[string]string{ReasonEnvVar: reason})
+}
+
+// env adds the variables every script gets to the variables of the caller,
+// which cannot override them
+func (p ConcreteJobScriptProvider) env(jobName string, scriptEnv map[string]string) map[string]string {
+	spec, err := p.specService.Get()
+	if err != nil {
+		p.logger.Warn(concreteJobScriptProviderLogTag, "Not describing the instance to scripts of job %s: %s", jobName, err.Error())
+	}
+
+	env := map[string]string{}
+	for name, value := range scriptEnv {
+		env[name] = value
+	}
+
+	for name, value := range instanceEnv(spec, p.settings.GetSettings(), p.dirProvider, p.agentVersion, jobName, p.logger) {
+		env[name] = value
+	}
+
+	return env
+}
+
+// limits returns the limits the current spec declares for a script of
+// a job, with the default timeout of the script when it declares none
+func (p ConcreteJobScriptProvider) limits(jobName string, scriptName string) boshcmd.Limits {
+	limits := p.declaredLimits(jobName, scriptName)
+
+	if limits.Timeout == 0 {
+		limits.Timeout = defaultTimeout(scriptName)
+	}
+
+	return limits
+}
+
+func (p ConcreteJobScriptProvider) declaredLimits(jobName string, scriptName string) boshcmd.Limits {
+	spec, err := p.specService.Get()
+	if err != nil {
+		p.logger.Warn(concreteJobScriptProviderLogTag, "Not limiting script %s of job %s: %s", scriptName, jobName, err.Error())
+		return boshcmd.Limits{}
+	}
+
+	for _, job := range spec.Jobs() {
+		if job.BundleName() == jobName {
+			return CmdLimits(job.Scripts[scriptName])
+		}
+	}
+
+	return boshcmd.Limits{}
+}
+
+// CmdLimits returns the limits a script is run with for those declared in the spec
+func CmdLimits(limits models.ScriptLimits) boshcmd.Limits {
+	return boshcmd.Limits{
+		Timeout:     limits.Timeout,
+		GracePeriod: limits.GracePeriod,
+		User:        limits.User,
+		Resources:   boshcgroup.Limits(limits.Resources),
+	}
+}
+
+// defaultTimeout is zero for scripts that may run for as long as they take
+func defaultTimeout(scriptName string) int {
+	switch {
+	case scriptName == "pre-stop" || scriptName == "post-stop":
+		return DefaultStopHookTimeout
+	case strings.HasPrefix(scriptName, "periodic/"):
+		return DefaultPeriodicScriptTimeout
+	default:
+		return 0
+	}
+}
@@ -1,15 +1,26 @@
 package script_test
 
 import (
+	"errors"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	fakeaction "github.com/cloudfoundry/bosh-agent/v2/agent/action/fakes"
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	fakeas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/applier/models"
 	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
+	boshcmd "github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd/cmdfakes"
 	boshdrain "github.com/cloudfoundry/bosh-agent/v2/agent/script/drain"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/script/drain/drainfakes"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/script/scriptfakes"
+	boshcgroup "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
 	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
+	fakesettings "github.com/cloudfoundry/bosh-agent/v2/settings/fakes"
 	boshassert "github.com/cloudfoundry/bosh-utils/assert"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
@@ -18,19 +29,27 @@ import (
 var _ = Describe("ConcreteJobScriptProvider", func() {
 	var (
 		logger         boshlog.Logger
+		scriptRunner   *cmdfakes.FakeRunner
+		specService    *fakeas.FakeV1Service
+		settings       *fakesettings.FakeSettingsService
 		scriptProvider boshscript.ConcreteJobScriptProvider
 		scriptEnv      map[string]string
 	)
 
 	BeforeEach(func() {
-		runner := fakesys.NewFakeCmdRunner()
+		scriptRunner = &cmdfakes.FakeRunner{}
+		specService = fakeas.NewFakeV1Service()
+		settings = &fakesettings.FakeSettingsService{}
 		fs := fakesys.NewFakeFileSystem()
 		dirProvider := boshdir.NewProvider("/the/base/dir")
 		logger = boshlog.NewLogger(boshlog.LevelNone)
 		scriptProvider = boshscript.NewConcreteJobScriptProvider(
-			runner,
+			scriptRunner,
 			fs,
 			dirProvider,
+			specService,
+			settings,
+			"1.2.3",
 			&fakeaction.FakeClock{},
 			logger,
 		)
@@ -44,6 +63,172 @@ var _ = Describe("ConcreteJobScriptProvider", func() {
 			expPath := "/the/base/dir/jobs/myjob/bin/the-best-hook-ever" + boshscript.ScriptExt
 			Expect(script.Path()).To(boshassert.MatchPath(expPath))
 		})
+
+		It("runs the script with the limits declared in the spec", func() {
+			limits := models.ScriptLimits{Timeout: 600, User: "vcap", Resources: models.ResourceLimits{MemoryMax: "512M"}}
+			specService.Spec = boshas.V1ApplySpec{
+				JobSpec: boshas.JobSpec{
+					JobTemplateSpecs: []boshas.JobTemplateSpec{
+						{Name: "myjob", Scripts: map[string]models.ScriptLimits{"pre-start": limits}},
+					},
+				},
+				RenderedTemplatesArchiveSpec: &boshas.RenderedTemplatesArchiveSpec{},
+			}
+
+			Expect(scriptProvider.NewScript("myjob", "pre-start", scriptEnv).Run()).To(Succeed())
+
+			Expect(scriptRunner.RunComplexCommandCallCount()).To(Equal(1))
+			jobName, scriptName, _, runLimits := scriptRunner.RunComplexCommandArgsForCall(0)
+			Expect(jobName).To(Equal("myjob"))
+			Expect(scriptName).To(Equal("pre-start"))
+			Expect(runLimits).To(Equal(boshcmd.Limits{Timeout: 600, User: "vcap", Resources: boshcgroup.Limits{MemoryMax: "512M"}}))
+		})
+
+		It("logs the output of each run under the log directory of the job", func() {
+			script := scriptProvider.NewScript("myjob", "pre-start", scriptEnv)
+			Expect(script.Run()).To(Succeed())
+
+			output, found := script.(boshscript.LoggingScript).Output()
+			Expect(found).To(BeTrue())
+			Expect(output.StdoutPath).To(boshassert.MatchPath("/the/base/dir/sys/log/myjob/pre-start.00010101T000000.000000000Z.stdout.log"))
+			Expect(output.StderrPath).To(boshassert.MatchPath("/the/base/dir/sys/log/myjob/pre-start.00010101T000000.000000000Z.stderr.log"))
+		})
+
+		It("runs the script without limits when the spec cannot be read", func() {
+			specService.GetErr = errors.New("fake-get-error")
+
+			Expect(scriptProvider.NewScript("myjob", "pre-start", scriptEnv).Run()).To(Succeed())
+
+			_, _, _, runLimits := scriptRunner.RunComplexCommandArgsForCall(0)
+			Expect(runLimits).To(Equal(boshcmd.Limits{}))
+		})
+
+		It("terminates stop scripts after the default timeout when the spec declares none", func() {
+			limits := models.ScriptLimits{User: "vcap"}
+			specService.Spec = boshas.V1ApplySpec{
+				JobSpec: boshas.JobSpec{
+					JobTemplateSpecs: []boshas.JobTemplateSpec{
+						{Name: "myjob", Scripts: map[string]models.ScriptLimits{"pre-stop": limits, "post-stop": {Timeout: 30}}},
+					},
+				},
+				RenderedTemplatesArchiveSpec: &boshas.RenderedTemplatesArchiveSpec{},
+			}
+
+			Expect(scriptProvider.NewScript("myjob", "pre-stop", scriptEnv).Run()).To(Succeed())
+			Expect(scriptProvider.NewScript("myjob", "post-stop", scriptEnv).Run()).To(Succeed())
+
+			_, _, _, runLimits := scriptRunner.RunComplexCommandArgsForCall(0)
+			Expect(runLimits).To(Equal(boshcmd.Limits{Timeout: boshscript.DefaultStopHookTimeout, User: "vcap"}))
+
+			_, _, _, runLimits = scriptRunner.RunComplexCommandArgsForCall(1)
+			Expect(runLimits).To(Equal(boshcmd.Limits{Timeout: 30}))
+		})
+
+		It("terminates periodic scripts after the default timeout when the spec declares none", func() {
+			specService.Spec = boshas.V1ApplySpec{
+				JobSpec: boshas.JobSpec{
+					JobTemplateSpecs: []boshas.JobTemplateSpec{
+						{Name: "myjob", Scripts: map[string]models.ScriptLimits{"periodic/backup": {Timeout: 3600}}},
+					},
+				},
+				RenderedTemplatesArchiveSpec: &boshas.RenderedTemplatesArchiveSpec{},
+			}
+
+			Expect(scriptProvider.NewScript("myjob", "periodic/cleanup", scriptEnv).Run()).To(Succeed())
+			Expect(scriptProvider.NewScript("myjob", "periodic/backup", scriptEnv).Run()).To(Succeed())
+
+			_, _, _, runLimits := scriptRunner.RunComplexCommandArgsForCall(0)
+			Expect(runLimits).To(Equal(boshcmd.Limits{Timeout: boshscript.DefaultPeriodicScriptTimeout}))
+
+			_, _, _, runLimits = scriptRunner.RunComplexCommandArgsForCall(1)
+			Expect(runLimits).To(Equal(boshcmd.Limits{Timeout: 3600}))
+		})
+	})
+
+	Describe("environment", func() {
+		BeforeEach(func() {
+			index := 2
+			specService.Spec = boshas.V1ApplySpec{
+				Deployment:       "fake-deployment",
+				Name:             "fake-instance-group",
+				NodeID:           "fake-instance-id",
+				Index:            &index,
+				AvailabilityZone: "z1",
+				PersistentDisk:   1024,
+				NetworkSpecs: map[string]boshas.NetworkSpec{
+					"private-net": {Fields: map[string]interface{}{"ip": "10.0.0.5"}},
+					"dynamic":     {Fields: map[string]interface{}{"type": "dynamic"}},
+				},
+			}
+			settings.Settings = boshsettings.Settings{
+				Networks: boshsettings.Networks{
+					"dynamic": boshsettings.Network{IP: "192.168.0.9", Default: []string{"gateway"}},
+				},
+			}
+		})
+
+		expectedEnv := map[string]string{
+			"BOSH_DEPLOYMENT":             "fake-deployment",
+			"BOSH_INSTANCE_NAME":          "fake-instance-group",
+			"BOSH_INSTANCE_ID":            "fake-instance-id",
+			"BOSH_INSTANCE_INDEX":         "2",
+			"BOSH_AZ":                     "z1",
+			"BOSH_IP":                     "192.168.0.9",
+			"BOSH_NETWORK_PRIVATE_NET_IP": "10.0.0.5",
+			"BOSH_NETWORK_DYNAMIC_IP":     "192.168.0.9",
+			"BOSH_PERSISTENT_DISK_PATH":   filepath.Join("/the/base/dir", "store"),
+			"BOSH_JOB_NAME":               "myjob",
+			"BOSH_AGENT_VERSION":          "1.2.3",
+			"BOSH_SCRIPT_REASON":          "run_script",
+			"FAKE_CALLER_VAR":             "fake-value",
+		}
+
+		It("describes the instance and the job to scripts", func() {
+			env := map[string]string{
+				"BOSH_SCRIPT_REASON": "run_script",
+				"FAKE_CALLER_VAR":    "fake-value",
+				"BOSH_JOB_NAME":      "fake-overridden-job",
+			}
+
+			Expect(scriptProvider.NewScript("myjob", "pre-start", env).Run()).To(Succeed())
+
+			_, _, command, _ := scriptRunner.RunComplexCommandArgsForCall(0)
+			for name, value := range expectedEnv {
+				Expect(command.Env).To(HaveKeyWithValue(name, value))
+			}
+		})
+
+		It("describes the instance and the job to drain scripts", func() {
+			script := scriptProvider.NewDrainScript("myjob", &drainfakes.FakeScriptParams{})
+			Expect(script.(boshdrain.ConcreteScript).Env()).To(HaveKeyWithValue("BOSH_SCRIPT_REASON", "drain"))
+			Expect(script.(boshdrain.ConcreteScript).Env()).To(HaveKeyWithValue("BOSH_INSTANCE_ID", "fake-instance-id"))
+		})
+
+		It("returns the environment of scripts it does not create", func() {
+			env := scriptProvider.ScriptEnv("myjob", "run_script")
+			delete(expectedEnv, "FAKE_CALLER_VAR")
+			Expect(env).To(Equal(expectedEnv))
+		})
+
+		It("leaves out the IPs of networks whose names share a variable", func() {
+			specService.Spec.NetworkSpecs["private_net"] = boshas.NetworkSpec{Fields: map[string]interface{}{"ip": "10.0.1.5"}}
+
+			env := scriptProvider.ScriptEnv("myjob", "run_script")
+			Expect(env).ToNot(HaveKey("BOSH_NETWORK_PRIVATE_NET_IP"))
+			Expect(env).To(HaveKeyWithValue("BOSH_NETWORK_DYNAMIC_IP", "192.168.0.9"))
+		})
+
+		It("leaves out what does not apply to the instance", func() {
+			specService.Spec = boshas.V1ApplySpec{Deployment: "fake-deployment"}
+			settings.Settings = boshsettings.Settings{}
+
+			Expect(scriptProvider.ScriptEnv("myjob", "errand")).To(Equal(map[string]string{
+				"BOSH_DEPLOYMENT":    "fake-deployment",
+				"BOSH_JOB_NAME":      "myjob",
+				"BOSH_AGENT_VERSION": "1.2.3",
+				"BOSH_SCRIPT_REASON": "errand",
+			}))
+		})
 	})
 
 	Describe("NewDrainScript", func() {
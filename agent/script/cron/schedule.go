@@ -0,0 +1,169 @@
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a cron schedule with minute, hour, day of month, month and
+// day of week fields, e.g. "*/15 * * * *" or "30 2 * * 1-5". Fields may be
+// lists of values, ranges and steps. Like cron, a time matches when either
+// the day of month or the day of week matches if both are restricted.
+type Schedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	anyDay     bool
+	anyWeekday bool
+}
+
+func Parse(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+
+	if macro, found := macros[expression]; found {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return Schedule{}, bosherr.Errorf("Schedule '%s' must have 5 fields", expression)
+	}
+
+	var schedule Schedule
+	var err error
+
+	bounds := []struct {
+		name     string
+		bits     *uint64
+		min, max int
+	}{
+		{"minute", &schedule.minutes, 0, 59},
+		{"hour", &schedule.hours, 0, 23},
+		{"day of month", &schedule.days, 1, 31},
+		{"month", &schedule.months, 1, 12},
+		{"day of week", &schedule.weekdays, 0, 7},
+	}
+
+	for i, bound := range bounds {
+		*bound.bits, err = parseField(fields[i], bound.min, bound.max)
+		if err != nil {
+			return Schedule{}, bosherr.WrapErrorf(err, "Parsing %s of schedule '%s'", bound.name, expression)
+		}
+	}
+
+	// Sunday is both 0 and 7
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1
+	}
+
+	schedule.anyDay = strings.HasPrefix(fields[2], "*")
+	schedule.anyWeekday = strings.HasPrefix(fields[4], "*")
+
+	return schedule, nil
+}
+
+// Matches reports whether the schedule is due in the minute of t
+func (s Schedule) Matches(t time.Time) bool {
+	if !has(s.minutes, t.Minute()) || !has(s.hours, t.Hour()) || !has(s.months, int(t.Month())) {
+		return false
+	}
+
+	dayMatches := has(s.days, t.Day())
+	weekdayMatches := has(s.weekdays, int(t.Weekday()))
+
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekdayMatches
+	case s.anyWeekday:
+		return dayMatches
+	default:
+		return dayMatches || weekdayMatches
+	}
+}
+
+func has(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
+
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			value, err := strconv.Atoi(part[i+1:])
+			if err != nil || value < 1 {
+				return 0, bosherr.Errorf("Invalid step '%s'", part[i+1:])
+			}
+			rangePart, step = part[:i], value
+		}
+
+		first, last := min, max
+
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+
+			var err error
+			first, err = parseValue(bounds[0], min, max)
+			if err != nil {
+				return 0, err
+			}
+
+			last, err = parseValue(bounds[1], min, max)
+			if err != nil {
+				return 0, err
+			}
+
+			if first > last {
+				return 0, bosherr.Errorf("Invalid range '%s'", rangePart)
+			}
+		default:
+			value, err := parseValue(rangePart, min, max)
+			if err != nil {
+				return 0, err
+			}
+
+			first = value
+			// A single value only covers the rest of the range with a step, e.g. 5/15
+			if !strings.Contains(part, "/") {
+				last = value
+			}
+		}
+
+		for value := first; value <= last; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(value string, min, max int) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min || number > max {
+		return 0, bosherr.Errorf("Value '%s' must be between %d and %d", value, min, max)
+	}
+
+	return number, nil
+}
@@ -0,0 +1,54 @@
+package cron_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cron"
+)
+
+var _ = Describe("Schedule", func() {
+	// Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 30, 0, time.UTC)
+	}
+
+	DescribeTable("Matches",
+		func(expression string, t time.Time, expected bool) {
+			schedule, err := cron.Parse(expression)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule.Matches(t)).To(Equal(expected))
+		},
+		Entry("every minute", "* * * * *", at(19, 13, 7), true),
+		Entry("steps", "*/15 * * * *", at(19, 13, 45), true),
+		Entry("steps not due", "*/15 * * * *", at(19, 13, 46), false),
+		Entry("steps from a value", "5/20 * * * *", at(19, 13, 45), true),
+		Entry("lists and ranges", "0,30 8-17 * * *", at(19, 17, 30), true),
+		Entry("outside of range", "0,30 8-17 * * *", at(19, 18, 0), false),
+		Entry("ranges with steps", "0 0-12/6 * * *", at(19, 6, 0), true),
+		Entry("day of week", "30 2 * * 1-5", at(19, 2, 30), true),
+		Entry("weekend", "30 2 * * 6,0", at(19, 2, 30), false),
+		Entry("sunday as 7", "0 0 * * 7", at(18, 0, 0), true),
+		Entry("day of month", "0 0 19 10 *", at(19, 0, 0), true),
+		Entry("day of month or day of week", "0 0 1 * 1", at(19, 0, 0), true),
+		Entry("neither day of month nor day of week", "0 0 1 * 2", at(19, 0, 0), false),
+		Entry("macros", "@daily", at(19, 0, 0), true),
+		Entry("hourly", "@hourly", at(19, 5, 1), false),
+	)
+
+	DescribeTable("invalid schedules",
+		func(expression string, message string) {
+			_, err := cron.Parse(expression)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(message))
+		},
+		Entry("too few fields", "* * * *", "Schedule '* * * *' must have 5 fields"),
+		Entry("out of bounds", "60 * * * *", "Parsing minute of schedule '60 * * * *': Value '60' must be between 0 and 59"),
+		Entry("zero day", "* * 0 * *", "Value '0' must be between 1 and 31"),
+		Entry("invalid step", "*/0 * * * *", "Invalid step '0'"),
+		Entry("inverted range", "* 5-1 * * *", "Invalid range '5-1'"),
+		Entry("names", "* * * * MON", "Value 'MON' must be between 0 and 7"),
+	)
+})
@@ -1,11 +1,10 @@
 package drain
 
 import (
-	"strconv"
-	"strings"
 	"time"
 
 	"code.cloudfoundry.org/clock"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -14,25 +13,33 @@ import (
 
 type ConcreteScript struct {
 	fs     boshsys.FileSystem
-	runner boshsys.CmdRunner
+	runner cmd.Runner
 
-	tag    string
-	path   string
-	params ScriptParams
+	tag     string
+	path    string
+	logPath string
+	params  ScriptParams
+	env     map[string]string
+	limits  cmd.Limits
 
 	timeService clock.Clock
 	logTag      string
 	logger      boshlog.Logger
 
 	cancelCh chan struct{}
+	progress *progressRecorder
+	output   *outputlog.LastOutput
 }
 
 func NewConcreteScript(
 	fs boshsys.FileSystem,
-	runner boshsys.CmdRunner,
+	runner cmd.Runner,
 	tag string,
 	path string,
+	logPath string,
 	params ScriptParams,
+	env map[string]string,
+	limits cmd.Limits,
 	timeService clock.Clock,
 	logger boshlog.Logger,
 ) ConcreteScript {
@@ -40,9 +47,12 @@ func NewConcreteScript(
 		fs:     fs,
 		runner: runner,
 
-		tag:    tag,
-		path:   path,
-		params: params,
+		tag:     tag,
+		path:    path,
+		logPath: logPath,
+		params:  params,
+		env:     env,
+		limits:  limits,
 
 		timeService: timeService,
 
@@ -50,33 +60,55 @@ func NewConcreteScript(
 		logger: logger,
 
 		cancelCh: make(chan struct{}, 1),
+		progress: &progressRecorder{},
+		output:   &outputlog.LastOutput{},
 	}
 }
 
-func (s ConcreteScript) Tag() string          { return s.tag }
-func (s ConcreteScript) Path() string         { return s.path }
-func (s ConcreteScript) Params() ScriptParams { return s.params }
-func (s ConcreteScript) Exists() bool         { return s.fs.FileExists(s.path) }
+func (s ConcreteScript) Tag() string            { return s.tag }
+func (s ConcreteScript) Path() string           { return s.path }
+func (s ConcreteScript) Params() ScriptParams   { return s.params }
+func (s ConcreteScript) Env() map[string]string { return s.env }
+func (s ConcreteScript) Exists() bool           { return s.fs.FileExists(s.path) }
 
 func (s ConcreteScript) Run() error {
 	params := s.params
 
 	for {
-		value, err := s.runOnce(params)
+		progress, err := s.runOnce(params)
 		if err != nil {
 			return err
 		}
 
-		if value < 0 {
-			s.timeService.Sleep(time.Duration(-value) * time.Second)
-			params = params.ToStatusParams()
-		} else {
-			s.timeService.Sleep(time.Duration(value) * time.Second)
+		wait := time.Duration(progress.WaitSeconds) * time.Second
+
+		if progress.Done {
+			s.timeService.Sleep(wait)
 			return nil
 		}
+
+		// Unlike a negative integer the JSON protocol allows asking
+		// to be run again without waiting, which would busy loop
+		if wait < time.Second {
+			wait = time.Second
+		}
+
+		s.timeService.Sleep(wait)
+		params = params.ToStatusParams()
 	}
 }
 
+// Progress returns what the script reported on its last run
+// when it uses the JSON protocol
+func (s ConcreteScript) Progress() (Progress, bool) {
+	return s.progress.last()
+}
+
+// Output returns where the last run of the script logged its output
+func (s ConcreteScript) Output() (outputlog.Output, bool) {
+	return s.output.Get()
+}
+
 func (s ConcreteScript) Cancel() error {
 	select {
 	case s.cancelCh <- struct{}{}:
@@ -85,37 +117,36 @@ func (s ConcreteScript) Cancel() error {
 	return nil
 }
 
-func (s ConcreteScript) runOnce(params ScriptParams) (int, error) {
-	jobChange := params.JobChange()
-	hashChange := params.HashChange()
-	updatedPkgs := params.UpdatedPackages()
-
-	command := cmd.BuildCommand(s.path)
-
-	jobState, err := params.JobState()
+// Preview returns how the script would be invoked with its params
+func (s ConcreteScript) Preview() (Invocation, error) {
+	command, err := s.command(s.params)
 	if err != nil {
-		return 0, bosherr.WrapError(err, "Getting job state")
+		return Invocation{}, err
 	}
 
-	if jobState != "" {
-		command.Env["BOSH_JOB_STATE"] = jobState
-	}
+	return Invocation{Path: s.path, Args: args(s.params), Env: command.Env}, nil
+}
 
-	jobNextState, err := params.JobNextState()
+func (s ConcreteScript) runOnce(params ScriptParams) (Progress, error) {
+	command, err := s.command(params)
 	if err != nil {
-		return 0, bosherr.WrapError(err, "Getting job next state")
+		return Progress{}, err
 	}
 
-	if jobNextState != "" {
-		command.Env["BOSH_JOB_NEXT_STATE"] = jobNextState
+	outputLog, err := outputlog.New(s.fs, s.logPath, s.timeService.Now())
+	if err != nil {
+		return Progress{}, bosherr.WrapError(err, "Logging drain script output")
 	}
+	defer func() {
+		_ = outputLog.Close()
+	}()
 
-	command.Args = append(command.Args, jobChange, hashChange)
-	command.Args = append(command.Args, updatedPkgs...)
+	command.Stdout = outputLog.Stdout.Writer()
+	command.Stderr = outputLog.Stderr.Writer()
 
-	process, err := s.runner.RunComplexCommandAsync(command)
+	process, err := s.runner.RunComplexCommandAsync(s.tag, "drain", command, s.limits)
 	if err != nil {
-		return 0, bosherr.WrapError(err, "Running drain script")
+		return Progress{}, bosherr.WrapError(err, "Running drain script")
 	}
 
 	var result boshsys.Result
@@ -137,22 +168,74 @@ func (s ConcreteScript) runOnce(params ScriptParams) (int, error) {
 		}
 	}
 
+	output, err := outputLog.Output()
+	if err != nil {
+		return Progress{}, bosherr.WrapError(err, "Reading drain script output")
+	}
+
+	s.output.Record(output)
+
 	if isCanceled {
 		if result.Error != nil {
-			return 0, bosherr.WrapError(result.Error, "Script was cancelled by user request")
+			return Progress{}, bosherr.WrapError(result.Error, "Script was cancelled by user request")
 		}
 
-		return 0, bosherr.Error("Script was cancelled by user request")
+		return Progress{}, bosherr.Error("Script was cancelled by user request")
 	}
 
 	if result.Error != nil && result.ExitStatus == -1 {
-		return 0, bosherr.WrapError(result.Error, "Running drain script")
+		return Progress{}, bosherr.WrapError(result.Error, "Running drain script")
+	}
+
+	// Drain scripts only print their result, so the end of the output is enough
+	progress, isJSON, err := parseOutput(output.Stdout)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	if isJSON {
+		s.progress.record(progress)
+		s.logger.Info(s.logTag, "Drain script of '%s' is %g%% done, waiting %d seconds: %s",
+			s.tag, progress.Progress, progress.WaitSeconds, progress.Message)
+	}
+
+	return progress, nil
+}
+
+// command builds the command running the script with params
+func (s ConcreteScript) command(params ScriptParams) (boshsys.Command, error) {
+	command := cmd.BuildCommand(s.path)
+
+	for key, val := range s.env {
+		command.Env[key] = val
+	}
+
+	jobState, err := params.JobState()
+	if err != nil {
+		return boshsys.Command{}, bosherr.WrapError(err, "Getting job state")
 	}
 
-	value, err := strconv.Atoi(strings.TrimSpace(result.Stdout))
+	if jobState != "" {
+		command.Env["BOSH_JOB_STATE"] = jobState
+	}
+
+	jobNextState, err := params.JobNextState()
 	if err != nil {
-		return 0, bosherr.WrapError(err, "Script did not return a signed integer")
+		return boshsys.Command{}, bosherr.WrapError(err, "Getting job next state")
 	}
 
-	return value, nil
+	if jobNextState != "" {
+		command.Env["BOSH_JOB_NEXT_STATE"] = jobNextState
+	}
+
+	command.Env[ProtocolEnvVar] = ProtocolJSON
+
+	command.Args = append(command.Args, args(params)...)
+
+	return command, nil
+}
+
+// args describe the change to the job the script is run for
+func args(params ScriptParams) []string {
+	return append([]string{params.JobChange(), params.HashChange()}, params.UpdatedPackages()...)
 }
@@ -2,6 +2,7 @@ package drain_test
 
 import (
 	"errors"
+	"io"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -11,9 +12,12 @@ import (
 
 	fakeaction "github.com/cloudfoundry/bosh-agent/v2/agent/action/fakes"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
 	. "github.com/cloudfoundry/bosh-agent/v2/agent/script/drain"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/script/drain/drainfakes"
 	boshenv "github.com/cloudfoundry/bosh-agent/v2/agent/script/pathenv"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup/cgroupfakes"
 	"github.com/cloudfoundry/bosh-utils/crypto"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -51,7 +55,7 @@ var _ = Describe("ConcreteScript", func() {
 
 	JustBeforeEach(func() {
 		logger := boshlog.NewLogger(boshlog.LevelNone)
-		script = NewConcreteScript(fs, runner, "my-tag", "/fake/script", params, fakeClock, logger)
+		script = NewConcreteScript(fs, cmd.NewRunner(outputtingCmdRunner{runner}, &cgroupfakes.FakeManager{}, fakeClock, logger), "my-tag", "/fake/script", "/fake/logs/my-tag/drain", params, map[string]string{"BOSH_JOB_NAME": "my-tag"}, cmd.Limits{}, fakeClock, logger)
 	})
 
 	Describe("Tag", func() {
@@ -82,6 +86,57 @@ var _ = Describe("ConcreteScript", func() {
 		})
 	})
 
+	Describe("Preview", func() {
+		BeforeEach(func() {
+			oldSpec := exampleSpec()
+			newSpec := exampleSpec()
+			newSpec.ConfigurationHash = "new-config-hash"
+
+			s := newSpec.PackageSpecs["foo"]
+			s.Sha1 = crypto.MustParseMultipleDigest("sha1:fooupdatedsha1")
+			newSpec.PackageSpecs["foo"] = s
+
+			params = NewUpdateParams(oldSpec, newSpec)
+		})
+
+		It("returns the arguments and environment the script would be run with", func() {
+			invocation, err := script.Preview()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(invocation).To(Equal(Invocation{
+				Path: "/fake/script",
+				Args: []string{"job_changed", "hash_changed", "foo"},
+				Env: map[string]string{
+					"PATH":                boshenv.Path(),
+					"BOSH_JOB_NAME":       "my-tag",
+					"BOSH_JOB_STATE":      "{\"persistent_disk\":42}",
+					"BOSH_JOB_NEXT_STATE": "{\"persistent_disk\":42}",
+					"BOSH_DRAIN_PROTOCOL": "json",
+				},
+			}))
+		})
+
+		It("does not run the script", func() {
+			_, err := script.Preview()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunComplexCommands).To(BeEmpty())
+		})
+
+		Context("when the job state cannot be determined", func() {
+			BeforeEach(func() {
+				fakeParams := &drainfakes.FakeScriptParams{}
+				fakeParams.JobStateReturns("", errors.New("fake-job-state-error"))
+				params = fakeParams
+			})
+
+			It("returns an error", func() {
+				_, err := script.Preview()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-job-state-error"))
+			})
+		})
+	})
+
 	Describe("Run", func() {
 		BeforeEach(func() {
 			oldSpec := exampleSpec()
@@ -112,8 +167,10 @@ var _ = Describe("ConcreteScript", func() {
 					Args: []string{"/fake/script", "job_changed", "hash_unchanged", "bar", "foo"},
 					Env: map[string]string{
 						"PATH":                boshenv.Path(),
+						"BOSH_JOB_NAME":       "my-tag",
 						"BOSH_JOB_STATE":      "{\"persistent_disk\":42}",
 						"BOSH_JOB_NEXT_STATE": "{\"persistent_disk\":42}",
+						"BOSH_DRAIN_PROTOCOL": "json",
 					},
 				}
 			} else {
@@ -122,14 +179,46 @@ var _ = Describe("ConcreteScript", func() {
 					Args: []string{"job_changed", "hash_unchanged", "bar", "foo"},
 					Env: map[string]string{
 						"PATH":                boshenv.Path(),
+						"BOSH_JOB_NAME":       "my-tag",
 						"BOSH_JOB_STATE":      "{\"persistent_disk\":42}",
 						"BOSH_JOB_NEXT_STATE": "{\"persistent_disk\":42}",
+						"BOSH_DRAIN_PROTOCOL": "json",
 					},
 				}
 			}
 
 			Expect(len(runner.RunComplexCommands)).To(Equal(1))
-			Expect(runner.RunComplexCommands[0]).To(Equal(expectedCmd))
+			actualCmd := runner.RunComplexCommands[0]
+			actualCmd.Stdout, actualCmd.Stderr = nil, nil
+			Expect(actualCmd).To(Equal(expectedCmd))
+		})
+
+		It("logs the output of every invocation to its own files", func() {
+			fakeClock.NowReturns(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+			runner.AddProcess(jobChangedFullCommand,
+				&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "1", Stderr: "fake-stderr"}})
+
+			_, found := script.Output()
+			Expect(found).To(BeFalse())
+
+			Expect(script.Run()).To(Succeed())
+
+			output, found := script.Output()
+			Expect(found).To(BeTrue())
+			Expect(output).To(Equal(outputlog.Output{
+				StdoutPath: "/fake/logs/my-tag/drain.20261019T120000.000000000Z.stdout.log",
+				StderrPath: "/fake/logs/my-tag/drain.20261019T120000.000000000Z.stderr.log",
+				Stdout:     "1",
+			}))
+			Expect(fs.ReadFileString(output.StdoutPath)).To(Equal("1"))
+		})
+
+		It("sets the environment of the job", func() {
+			runner.AddProcess(jobChangedFullCommand,
+				&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "1"}})
+			Expect(script.Run()).To(Succeed())
+
+			Expect(runner.RunComplexCommands[0].Env).To(HaveKeyWithValue("BOSH_JOB_NAME", "my-tag"))
 		})
 
 		It("sets the PATH environment variable", func() {
@@ -192,6 +281,74 @@ var _ = Describe("ConcreteScript", func() {
 			Expect(err).To(HaveOccurred())
 		})
 
+		Describe("JSON protocol", func() {
+			It("sleeps then calls the script again until it is done", func() {
+				runner.AddProcess(jobChangedFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: `{"wait_seconds":5,"progress":40,"message":"2 of 5 queues drained","done":false}`}})
+				runner.AddProcess(jobCheckStatusFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: `{"wait_seconds":3,"progress":100,"done":true}` + "\n"}})
+
+				err := script.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeClock.SleepCallCount()).To(Equal(2))
+				Expect(fakeClock.SleepArgsForCall(0)).To(Equal(5 * time.Second))
+				Expect(fakeClock.SleepArgsForCall(1)).To(Equal(3 * time.Second))
+			})
+
+			It("reports the progress of the last run", func() {
+				_, reported := script.Progress()
+				Expect(reported).To(BeFalse())
+
+				runner.AddProcess(jobChangedFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: `{"wait_seconds":0,"progress":40,"message":"2 of 5 queues drained","done":true}`}})
+
+				Expect(script.Run()).To(Succeed())
+
+				progress, reported := script.Progress()
+				Expect(reported).To(BeTrue())
+				Expect(progress).To(Equal(Progress{Progress: 40, Message: "2 of 5 queues drained", Done: true}))
+			})
+
+			It("waits at least a second before calling the script again", func() {
+				runner.AddProcess(jobChangedFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: `{"wait_seconds":0,"done":false}`}})
+				runner.AddProcess(jobCheckStatusFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: `{"done":true}`}})
+
+				Expect(script.Run()).To(Succeed())
+				Expect(fakeClock.SleepArgsForCall(0)).To(Equal(1 * time.Second))
+			})
+
+			It("does not report progress for integer output", func() {
+				runner.AddProcess(jobChangedFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: "0"}})
+
+				Expect(script.Run()).To(Succeed())
+
+				_, reported := script.Progress()
+				Expect(reported).To(BeFalse())
+			})
+
+			It("returns error with invalid JSON", func() {
+				runner.AddProcess(jobChangedFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: `{"done":`}})
+
+				err := script.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Script did not return valid JSON"))
+			})
+
+			It("returns error with invalid progress", func() {
+				runner.AddProcess(jobChangedFullCommand,
+					&fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: `{"wait_seconds":-5,"done":false}`}})
+
+				err := script.Run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Wait seconds must not be negative"))
+			})
+		})
+
 		It("returns error when running command errors", func() {
 			runner.AddProcess(jobChangedFullCommand,
 				&fakesys.FakeProcess{WaitResult: boshsys.Result{Error: errors.New("woops")}})
@@ -391,3 +548,39 @@ var _ = Describe("ConcreteScript", func() {
 		}
 	}
 })
+
+// outputtingCmdRunner makes processes write their stdout to the command's
+// writer like real processes do, the fake only returns it as a result
+type outputtingCmdRunner struct {
+	*fakesys.FakeCmdRunner
+}
+
+func (r outputtingCmdRunner) RunComplexCommandAsync(command boshsys.Command) (boshsys.Process, error) {
+	process, err := r.FakeCmdRunner.RunComplexCommandAsync(command)
+	if err != nil {
+		return nil, err
+	}
+
+	return outputtingProcess{Process: process, stdout: command.Stdout}, nil
+}
+
+type outputtingProcess struct {
+	boshsys.Process
+	stdout io.Writer
+}
+
+func (p outputtingProcess) Wait() <-chan boshsys.Result {
+	processResults := p.Process.Wait()
+	results := make(chan boshsys.Result, 1)
+
+	go func() {
+		result := <-processResults
+		if p.stdout != nil {
+			_, _ = io.WriteString(p.stdout, result.Stdout)
+			result.Stdout = ""
+		}
+		results <- result
+	}()
+
+	return results
+}
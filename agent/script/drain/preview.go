@@ -0,0 +1,14 @@
+package drain
+
+// Invocation is what a drain script receives when it is run
+type Invocation struct {
+	Path string            `json:"path"`
+	Args []string          `json:"args"`
+	Env  map[string]string `json:"env"`
+}
+
+// Previewer is implemented by drain scripts which can tell
+// how they would be invoked without running
+type Previewer interface {
+	Preview() (Invocation, error)
+}
@@ -0,0 +1,104 @@
+package drain
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	// ProtocolEnvVar tells drain scripts which output formats the agent
+	// understands. Scripts opt into the JSON protocol by printing a JSON
+	// object instead of a signed integer.
+	ProtocolEnvVar = "BOSH_DRAIN_PROTOCOL"
+	ProtocolJSON   = "json"
+)
+
+// Progress is reported by drain scripts using the JSON protocol, e.g.
+// {"wait_seconds":10,"progress":40,"message":"2 of 5 queues drained","done":false}
+type Progress struct {
+	// Seconds to wait before the script is run again to check the
+	// status of the drain, or before the job is stopped when done
+	WaitSeconds int `json:"wait_seconds"`
+
+	// Percentage of the drain that is complete
+	Progress float64 `json:"progress"`
+
+	Message string `json:"message,omitempty"`
+	Done    bool   `json:"done"`
+}
+
+func (p Progress) Validate() error {
+	if p.WaitSeconds < 0 {
+		return bosherr.Errorf("Wait seconds must not be negative")
+	}
+
+	if p.Progress < 0 || p.Progress > 100 {
+		return bosherr.Errorf("Progress must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// ProgressReporter is implemented by drain scripts which report the
+// progress of their last run using the JSON protocol
+type ProgressReporter interface {
+	Progress() (Progress, bool)
+}
+
+// parseOutput reads either the JSON protocol or a signed integer where
+// negative values ask for the script to be run again after that many seconds
+func parseOutput(stdout string) (Progress, bool, error) {
+	output := strings.TrimSpace(stdout)
+
+	if strings.HasPrefix(output, "{") {
+		var progress Progress
+
+		err := json.Unmarshal([]byte(output), &progress)
+		if err != nil {
+			return Progress{}, false, bosherr.WrapError(err, "Script did not return valid JSON")
+		}
+
+		err = progress.Validate()
+		if err != nil {
+			return Progress{}, false, bosherr.WrapError(err, "Script returned invalid progress")
+		}
+
+		return progress, true, nil
+	}
+
+	value, err := strconv.Atoi(output)
+	if err != nil {
+		return Progress{}, false, bosherr.WrapError(err, "Script did not return a signed integer")
+	}
+
+	if value < 0 {
+		return Progress{WaitSeconds: -value}, false, nil
+	}
+
+	return Progress{WaitSeconds: value, Done: true}, false, nil
+}
+
+type progressRecorder struct {
+	lock     sync.Mutex
+	progress Progress
+	reported bool
+}
+
+func (r *progressRecorder) record(progress Progress) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.progress = progress
+	r.reported = true
+}
+
+func (r *progressRecorder) last() (Progress, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.progress, r.reported
+}
@@ -0,0 +1,136 @@
+package script
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
+	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
+	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// Environment every job script gets besides PATH. Scripts do not
+// inherit the environment of the agent.
+//
+//	BOSH_DEPLOYMENT            name of the deployment
+//	BOSH_INSTANCE_NAME         name of the instance group
+//	BOSH_INSTANCE_ID           ID of the instance
+//	BOSH_INSTANCE_INDEX        index of the instance
+//	BOSH_AZ                    availability zone of the instance
+//	BOSH_IP                    IP of the instance on its default network
+//	BOSH_NETWORK_<NAME>_IP     IP of the instance on each network, e.g. BOSH_NETWORK_DEFAULT_IP
+//	BOSH_PERSISTENT_DISK_PATH  where the persistent disk is mounted if the instance has one
+//	BOSH_JOB_NAME              name of the job the script belongs to
+//	BOSH_AGENT_VERSION         version of the agent
+//	BOSH_SCRIPT_REASON         why the script runs, e.g. run_script or drain
+//
+// Variables that do not apply, e.g. the index of an instance without
+// one, are not set.
+const (
+	DeploymentEnvVar     = "BOSH_DEPLOYMENT"
+	InstanceNameEnvVar   = "BOSH_INSTANCE_NAME"
+	InstanceIDEnvVar     = "BOSH_INSTANCE_ID"
+	InstanceIndexEnvVar  = "BOSH_INSTANCE_INDEX"
+	AZEnvVar             = "BOSH_AZ"
+	IPEnvVar             = "BOSH_IP"
+	PersistentDiskEnvVar = "BOSH_PERSISTENT_DISK_PATH"
+	JobNameEnvVar        = "BOSH_JOB_NAME"
+	AgentVersionEnvVar   = "BOSH_AGENT_VERSION"
+	ReasonEnvVar         = "BOSH_SCRIPT_REASON"
+)
+
+const envLogTag = "ScriptEnv"
+
+// Reasons for running a script
+const (
+	ReasonRunScript = "run_script"
+	ReasonDrain     = "drain"
+	ReasonStop      = "stop"
+	ReasonPeriodic  = "periodic"
+	ReasonErrand    = "errand"
+)
+
+// NetworkIPEnvVar returns the variable holding the IP of the instance on
+// a network, e.g. BOSH_NETWORK_PRIVATE_NET_IP for network private-net.
+// Networks whose names only differ in punctuation, e.g. a-b and a_b,
+// share a variable so it is not set for any of them.
+func NetworkIPEnvVar(network string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, network)
+
+	return fmt.Sprintf("BOSH_NETWORK_%s_IP", name)
+}
+
+func instanceEnv(
+	spec boshas.V1ApplySpec,
+	settings boshsettings.Settings,
+	dirProvider boshdir.Provider,
+	agentVersion string,
+	jobName string,
+	logger boshlog.Logger,
+) map[string]string {
+	env := map[string]string{
+		JobNameEnvVar: jobName,
+	}
+
+	set := func(name, value string) {
+		if value != "" {
+			env[name] = value
+		}
+	}
+
+	set(DeploymentEnvVar, spec.Deployment)
+	set(InstanceNameEnvVar, spec.Name)
+	set(InstanceIDEnvVar, spec.NodeID)
+	set(AZEnvVar, spec.AvailabilityZone)
+	set(AgentVersionEnvVar, agentVersion)
+
+	if spec.Index != nil {
+		env[InstanceIndexEnvVar] = fmt.Sprintf("%d", *spec.Index)
+	}
+
+	if spec.PersistentDisk > 0 {
+		env[PersistentDiskEnvVar] = dirProvider.StoreDir()
+	}
+
+	networkIPs := map[string]string{}
+	for name, network := range spec.NetworkSpecs {
+		ip, _ := network.Fields["ip"].(string)
+		networkIPs[name] = ip
+	}
+
+	// Settings know the IPs of dynamic networks
+	for name, network := range settings.Networks {
+		if network.IP != "" || networkIPs[name] == "" {
+			networkIPs[name] = network.IP
+		}
+	}
+
+	networksOfVar := map[string][]string{}
+	for name := range networkIPs {
+		networksOfVar[NetworkIPEnvVar(name)] = append(networksOfVar[NetworkIPEnvVar(name)], name)
+	}
+
+	for envVar, networks := range networksOfVar {
+		if len(networks) > 1 {
+			sort.Strings(networks)
+			logger.Warn(envLogTag, "Not setting %s for job %s, it would hold the IP of each of the networks %s", envVar, jobName, strings.Join(networks, ", "))
+			continue
+		}
+
+		set(envVar, networkIPs[networks[0]])
+	}
+
+	if ip, found := settings.Networks.DefaultIP(); found {
+		set(IPEnvVar, ip)
+	}
+
+	return env
+}
@@ -1,51 +1,58 @@
 package script
 
 import (
-	"os"
 	"path/filepath"
+	"strings"
 
+	"code.cloudfoundry.org/clock"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
-const (
-	fileOpenFlag int         = os.O_RDWR | os.O_CREATE | os.O_APPEND
-	fileOpenPerm os.FileMode = os.FileMode(0640)
-)
-
 type GenericScript struct {
-	fs     boshsys.FileSystem
-	runner boshsys.CmdRunner
+	fs          boshsys.FileSystem
+	runner      cmd.Runner
+	timeService clock.Clock
 
 	tag  string
 	path string
 
-	stdoutLogPath string
-	stderrLogPath string
+	// Each run logs its output next to this path
+	logPath string
 
-	env map[string]string
+	env    map[string]string
+	limits cmd.Limits
+
+	output *outputlog.LastOutput
 }
 
 func NewScript(
 	fs boshsys.FileSystem,
-	runner boshsys.CmdRunner,
+	runner cmd.Runner,
+	timeService clock.Clock,
 	tag string,
 	path string,
-	stdoutLogPath string,
-	stderrLogPath string,
+	logPath string,
 	env map[string]string,
+	limits cmd.Limits,
 ) GenericScript {
 	return GenericScript{
-		fs:     fs,
-		runner: runner,
+		fs:          fs,
+		runner:      runner,
+		timeService: timeService,
 
 		tag:  tag,
 		path: path,
 
-		stdoutLogPath: stdoutLogPath,
-		stderrLogPath: stderrLogPath,
+		logPath: logPath,
+
+		env:    env,
+		limits: limits,
 
-		env: env,
+		output: &outputlog.LastOutput{},
 	}
 }
 
@@ -53,47 +60,41 @@ func (s GenericScript) Tag() string  { return s.tag }
 func (s GenericScript) Path() string { return s.path }
 func (s GenericScript) Exists() bool { return s.fs.FileExists(s.path) }
 
-func (s GenericScript) Run() error {
-	err := s.ensureContainingDir(s.stdoutLogPath)
-	if err != nil {
-		return err
-	}
-
-	err = s.ensureContainingDir(s.stderrLogPath)
-	if err != nil {
-		return err
-	}
-
-	stdoutFile, err := s.fs.OpenFile(s.stdoutLogPath, fileOpenFlag, fileOpenPerm)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = stdoutFile.Close()
-	}()
+// Output returns where the last run logged its output
+func (s GenericScript) Output() (outputlog.Output, bool) { return s.output.Get() }
 
-	stderrFile, err := s.fs.OpenFile(s.stderrLogPath, fileOpenFlag, fileOpenPerm)
+func (s GenericScript) Run() error {
+	outputLog, err := outputlog.New(s.fs, s.logPath, s.timeService.Now())
 	if err != nil {
 		return err
 	}
 	defer func() {
-		_ = stderrFile.Close()
+		_ = outputLog.Close()
 	}()
 
 	command := cmd.BuildCommand(s.path)
-	command.Stdout = stdoutFile
-	command.Stderr = stderrFile
+	command.Stdout = outputLog.Stdout.Writer()
+	command.Stderr = outputLog.Stderr.Writer()
 
 	for key, val := range s.env {
 		command.Env[key] = val
 	}
 
-	_, _, _, err = s.runner.RunComplexCommand(command)
+	_, _, _, runErr := s.runner.RunComplexCommand(s.tag, s.name(), command, s.limits)
+
+	output, err := outputLog.Output()
+	if err != nil {
+		if runErr != nil {
+			return runErr
+		}
+		return bosherr.WrapErrorf(err, "Reading output of %s", s.path)
+	}
+
+	s.output.Record(output)
 
-	return err
+	return runErr
 }
 
-func (s GenericScript) ensureContainingDir(fullLogFilename string) error {
-	dir, _ := filepath.Split(fullLogFilename)
-	return s.fs.MkdirAll(dir, os.FileMode(0750))
+func (s GenericScript) name() string {
+	return strings.TrimSuffix(filepath.Base(s.path), ScriptExt)
 }
@@ -3,14 +3,21 @@ package script_test
 import (
 	"errors"
 	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"runtime"
 
+	"code.cloudfoundry.org/clock/fakeclock"
+
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
 	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
 	boshenv "github.com/cloudfoundry/bosh-agent/v2/agent/script/pathenv"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup/cgroupfakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 )
 
@@ -19,6 +26,8 @@ var _ = Describe("GenericScript", func() {
 		fs            *fakesys.FakeFileSystem
 		cmdRunner     *fakesys.FakeCmdRunner
 		genericScript boshscript.GenericScript
+		timeService   *fakeclock.FakeClock
+		logPath       string
 		stdoutLogPath string
 		stderrLogPath string
 		fullCommand   string
@@ -28,8 +37,10 @@ var _ = Describe("GenericScript", func() {
 	BeforeEach(func() {
 		fs = fakesys.NewFakeFileSystem()
 		cmdRunner = fakesys.NewFakeCmdRunner()
-		stdoutLogPath = filepath.Join("base", "stdout", "logdir", "stdout.log")
-		stderrLogPath = filepath.Join("base", "stderr", "logdir", "stderr.log")
+		timeService = fakeclock.NewFakeClock(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+		logPath = filepath.Join("base", "logdir", "my-script")
+		stdoutLogPath = logPath + ".20261019T120000.000000000Z.stdout.log"
+		stderrLogPath = logPath + ".20261019T120000.000000000Z.stderr.log"
 		scriptEnv = map[string]string{
 			"FOO":           "foo",
 			"BAR":           "bar",
@@ -37,12 +48,13 @@ var _ = Describe("GenericScript", func() {
 		}
 		genericScript = boshscript.NewScript(
 			fs,
-			cmdRunner,
+			cmd.NewRunner(cmdRunner, &cgroupfakes.FakeManager{}, timeService, boshlog.NewLogger(boshlog.LevelNone)),
+			timeService,
 			"my-tag",
 			"/path-to-script",
-			stdoutLogPath,
-			stderrLogPath,
+			logPath,
 			scriptEnv,
+			cmd.Limits{},
 		)
 		if runtime.GOOS == "windows" {
 			fullCommand = "powershell /path-to-script"
@@ -84,7 +96,7 @@ var _ = Describe("GenericScript", func() {
 
 			err := genericScript.Run()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(Equal("fake-mkdir-all-error"))
+			Expect(err.Error()).To(ContainSubstring("fake-mkdir-all-error"))
 		})
 
 		It("returns an error if it fails to open stdout/stderr log file", func() {
@@ -92,7 +104,15 @@ var _ = Describe("GenericScript", func() {
 
 			err := genericScript.Run()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(Equal("fake-open-file-error"))
+			Expect(err.Error()).To(ContainSubstring("fake-open-file-error"))
+		})
+
+		It("hands the script its log files so processes it leaves running do not block it", func() {
+			Expect(genericScript.Run()).To(Succeed())
+			Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+			cmd := cmdRunner.RunComplexCommands[0]
+			Expect(cmd.Stdout).To(BeAssignableToTypeOf(&fakesys.FakeFile{}))
+			Expect(cmd.Stderr).To(BeAssignableToTypeOf(&fakesys.FakeFile{}))
 		})
 
 		It("sets the PATH environment variable", func() {
@@ -134,6 +154,44 @@ var _ = Describe("GenericScript", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(stderr).To(Equal("fake-stderr"))
 			})
+
+			It("saves stdout/stderr to the log files of every run", func() {
+				err := genericScript.Run()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.ReadFileString(logPath + ".stdout.log")).To(Equal("fake-stdout"))
+				Expect(fs.ReadFileString(logPath + ".stderr.log")).To(Equal("fake-stderr"))
+			})
+
+			It("reports where the output was logged and how it ended", func() {
+				_, found := genericScript.Output()
+				Expect(found).To(BeFalse())
+
+				Expect(genericScript.Run()).To(Succeed())
+
+				output, found := genericScript.Output()
+				Expect(found).To(BeTrue())
+				Expect(output).To(Equal(outputlog.Output{
+					StdoutPath: stdoutLogPath,
+					StderrPath: stderrLogPath,
+					Stdout:     "fake-stdout",
+					Stderr:     "fake-stderr",
+				}))
+			})
+
+			It("keeps the output of earlier runs", func() {
+				Expect(genericScript.Run()).To(Succeed())
+
+				timeService.Increment(time.Minute)
+				cmdRunner.AddCmdResult(fullCommand, fakesys.FakeCmdResult{Stdout: "next-stdout"})
+				Expect(genericScript.Run()).To(Succeed())
+
+				Expect(fs.ReadFileString(stdoutLogPath)).To(Equal("fake-stdout"))
+				Expect(fs.ReadFileString(logPath + ".20261019T120100.000000000Z.stdout.log")).To(Equal("next-stdout"))
+
+				output, _ := genericScript.Output()
+				Expect(output.StdoutPath).To(Equal(logPath + ".20261019T120100.000000000Z.stdout.log"))
+			})
 		})
 
 		Context("when command fails", func() {
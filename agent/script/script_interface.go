@@ -1,6 +1,7 @@
 package script
 
 import (
+	"github.com/cloudfoundry/bosh-agent/v2/agent/outputlog"
 	boshdrain "github.com/cloudfoundry/bosh-agent/v2/agent/script/drain"
 )
 
@@ -12,6 +13,10 @@ type JobScriptProvider interface {
 	NewScript(jobName string, scriptName string, scriptEnv map[string]string) Script
 	NewDrainScript(jobName string, params boshdrain.ScriptParams) CancellableScript
 	NewParallelScript(scriptName string, scripts []Script) CancellableScript
+
+	// ScriptEnv returns the environment for running a script of a job
+	// that the provider does not create, e.g. an errand
+	ScriptEnv(jobName string, reason string) map[string]string
 }
 
 //counterfeiter:generate . Script
@@ -24,6 +29,17 @@ type Script interface {
 	Run() error
 }
 
+//counterfeiter:generate . LoggingScript
+
+// LoggingScript is a script that logs the output of each run to files
+type LoggingScript interface {
+	Script
+
+	// Output returns where the last run logged its output,
+	// false if the script did not run
+	Output() (outputlog.Output, bool)
+}
+
 //counterfeiter:generate . CancellableScript
 
 type CancellableScript interface {
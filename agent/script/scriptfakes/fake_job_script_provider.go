@@ -46,6 +46,18 @@ type FakeJobScriptProvider struct {
 	newScriptReturnsOnCall map[int]struct {
 		result1 script.Script
 	}
+	ScriptEnvStub        func(string, string) map[string]string
+	scriptEnvMutex       sync.RWMutex
+	scriptEnvArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	scriptEnvReturns struct {
+		result1 map[string]string
+	}
+	scriptEnvReturnsOnCall map[int]struct {
+		result1 map[string]string
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -242,6 +254,68 @@ func (fake *FakeJobScriptProvider) NewScriptReturnsOnCall(i int, result1 script.
 	}{result1}
 }
 
+func (fake *FakeJobScriptProvider) ScriptEnv(arg1 string, arg2 string) map[string]string {
+	fake.scriptEnvMutex.Lock()
+	ret, specificReturn := fake.scriptEnvReturnsOnCall[len(fake.scriptEnvArgsForCall)]
+	fake.scriptEnvArgsForCall = append(fake.scriptEnvArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ScriptEnvStub
+	fakeReturns := fake.scriptEnvReturns
+	fake.recordInvocation("ScriptEnv", []interface{}{arg1, arg2})
+	fake.scriptEnvMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeJobScriptProvider) ScriptEnvCallCount() int {
+	fake.scriptEnvMutex.RLock()
+	defer fake.scriptEnvMutex.RUnlock()
+	return len(fake.scriptEnvArgsForCall)
+}
+
+func (fake *FakeJobScriptProvider) ScriptEnvCalls(stub func(string, string) map[string]string) {
+	fake.scriptEnvMutex.Lock()
+	defer fake.scriptEnvMutex.Unlock()
+	fake.ScriptEnvStub = stub
+}
+
+func (fake *FakeJobScriptProvider) ScriptEnvArgsForCall(i int) (string, string) {
+	fake.scriptEnvMutex.RLock()
+	defer fake.scriptEnvMutex.RUnlock()
+	argsForCall := fake.scriptEnvArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeJobScriptProvider) ScriptEnvReturns(result1 map[string]string) {
+	fake.scriptEnvMutex.Lock()
+	defer fake.scriptEnvMutex.Unlock()
+	fake.ScriptEnvStub = nil
+	fake.scriptEnvReturns = struct {
+		result1 map[string]string
+	}{result1}
+}
+
+func (fake *FakeJobScriptProvider) ScriptEnvReturnsOnCall(i int, result1 map[string]string) {
+	fake.scriptEnvMutex.Lock()
+	defer fake.scriptEnvMutex.Unlock()
+	fake.ScriptEnvStub = nil
+	if fake.scriptEnvReturnsOnCall == nil {
+		fake.scriptEnvReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+		})
+	}
+	fake.scriptEnvReturnsOnCall[i] = struct {
+		result1 map[string]string
+	}{result1}
+}
+
 func (fake *FakeJobScriptProvider) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -251,6 +325,8 @@ func (fake *FakeJobScriptProvider) Invocations() map[string][][]interface{} {
 	defer fake.newParallelScriptMutex.RUnlock()
 	fake.newScriptMutex.RLock()
 	defer fake.newScriptMutex.RUnlock()
+	fake.scriptEnvMutex.RLock()
+	defer fake.scriptEnvMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -69,6 +69,13 @@ func (service asyncTaskService) StartTask(task Task) {
 	}
 
 	recordedTask := <-taskChan
+
+	// Concurrent tasks do not queue up behind the other tasks
+	if recordedTask.Concurrent {
+		go service.processConcurrentTask(recordedTask)
+		return
+	}
+
 	service.taskChan <- recordedTask
 }
 
@@ -99,28 +106,38 @@ func (service asyncTaskService) processTasks() {
 
 	for {
 		task := <-service.taskChan
+		service.processTask(task)
+	}
+}
+
+func (service asyncTaskService) processConcurrentTask(task Task) {
+	defer service.logger.HandlePanic("Task Service Process Concurrent Task")
+
+	service.processTask(task)
+}
 
-		value, err := task.Func()
-		if err != nil {
-			task.Error = err
-			task.State = StateFailed
-			service.logger.Error("Task Service", "Failed processing task #%s got: %s", task.ID, err.Error())
-		} else {
-			task.Value = value
-			task.State = StateDone
-		}
-
-		if task.EndFunc != nil {
-			task.EndFunc(task)
-		}
-
-		// Nil to prevent to memory leaks in case these are closures.
-		task.Func = nil
-		task.CancelFunc = nil
-		task.EndFunc = nil
-
-		service.taskSem <- func() {
-			service.currentTasks[task.ID] = task
-		}
+func (service asyncTaskService) processTask(task Task) {
+	value, err := task.Func()
+	if err != nil {
+		task.Error = err
+		task.State = StateFailed
+		service.logger.Error("Task Service", "Failed processing task #%s got: %s", task.ID, err.Error())
+	} else {
+		task.Value = value
+		task.State = StateDone
+	}
+
+	if task.EndFunc != nil {
+		task.EndFunc(task)
+	}
+
+	// Nil to prevent to memory leaks in case these are closures.
+	task.Func = nil
+	task.CancelFunc = nil
+	task.EndFunc = nil
+	task.ProgressFunc = nil
+
+	service.taskSem <- func() {
+		service.currentTasks[task.ID] = task
 	}
 }
@@ -202,6 +202,36 @@ func init() { //nolint:funlen,gochecknoinits
 
 				taskChannel <- true
 			}, SpecTimeout(time.Second*5))
+
+			It("runs concurrent tasks while another task is running", func() {
+				release := make(chan struct{})
+				defer close(release)
+
+				blockingFunc := func() (interface{}, error) {
+					<-release
+					return nil, nil
+				}
+				blockingTask, err := service.CreateTask(blockingFunc, nil, nil)
+				Expect(err).ToNot(HaveOccurred())
+				service.StartTask(blockingTask)
+
+				queuedTask, err := service.CreateTask(func() (interface{}, error) { return nil, nil }, nil, nil)
+				Expect(err).ToNot(HaveOccurred())
+				service.StartTask(queuedTask)
+
+				concurrentTask, err := service.CreateTask(func() (interface{}, error) { return "fake-value", nil }, nil, nil)
+				Expect(err).ToNot(HaveOccurred())
+				concurrentTask.Concurrent = true
+				service.StartTask(concurrentTask)
+
+				Eventually(func() State {
+					task, _ := service.FindTaskWithID(concurrentTask.ID)
+					return task.State
+				}).Should(Equal(StateDone))
+
+				task, _ := service.FindTaskWithID(queuedTask.ID)
+				Expect(task.State).To(Equal(StateRunning))
+			})
 		})
 
 		Describe("CreateTask", func() {
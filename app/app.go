@@ -11,6 +11,7 @@ import (
 
 	boshagent "github.com/cloudfoundry/bosh-agent/v2/agent"
 	boshaction "github.com/cloudfoundry/bosh-agent/v2/agent/action"
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
 	boshapplier "github.com/cloudfoundry/bosh-agent/v2/agent/applier"
 	boshas "github.com/cloudfoundry/bosh-agent/v2/agent/applier/applyspec"
 	boshbc "github.com/cloudfoundry/bosh-agent/v2/agent/applier/bundlecollection"
@@ -23,13 +24,16 @@ import (
 	httpblobprovider "github.com/cloudfoundry/bosh-agent/v2/agent/httpblobprovider"
 	"github.com/cloudfoundry/bosh-agent/v2/agent/httpblobprovider/blobstore_delegator"
 	boshscript "github.com/cloudfoundry/bosh-agent/v2/agent/script"
+	boshscriptcmd "github.com/cloudfoundry/bosh-agent/v2/agent/script/cmd"
 	boshtask "github.com/cloudfoundry/bosh-agent/v2/agent/task"
 	boshinf "github.com/cloudfoundry/bosh-agent/v2/infrastructure"
 	boshjobsuper "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
 	boshmonit "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/monit"
+	boshprobe "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
 	boshmbus "github.com/cloudfoundry/bosh-agent/v2/mbus"
 	boshnotif "github.com/cloudfoundry/bosh-agent/v2/notification"
 	boshplatform "github.com/cloudfoundry/bosh-agent/v2/platform"
+	boshvitals "github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
 	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
 	boshdirs "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
 	boshsigar "github.com/cloudfoundry/bosh-agent/v2/sigar"
@@ -155,12 +159,23 @@ func (app *app) Setup(opts Options) error {
 		return bosherr.WrapError(err, "Getting monit client")
 	}
 
+	probeManager := boshprobe.NewManager(
+		boshprobe.NewChecker(app.platform.GetRunner()),
+		app.platform.GetFs(),
+		timeService,
+		app.logger,
+	)
+
+	cgroupManager := boshjobsuper.NewCgroupManager(opts.JobSupervisor, app.platform.GetFs(), app.platform.GetRunner(), app.logger)
+
 	jobSupervisorProvider := boshjobsuper.NewProvider(
 		app.platform,
 		monitClient,
 		app.logger,
 		app.dirProvider,
 		mbusHandler,
+		probeManager,
+		cgroupManager,
 	)
 
 	jobSupervisor, err := jobSupervisorProvider.Get(opts.JobSupervisor)
@@ -198,14 +213,36 @@ func (app *app) Setup(opts Options) error {
 		app.dirProvider.BoshDir(),
 	)
 
-	jobScriptProvider := boshscript.NewConcreteJobScriptProvider(
+	scriptRunner := boshscriptcmd.NewRunner(
 		app.platform.GetRunner(),
+		cgroupManager,
+		timeService,
+		app.logger,
+	)
+
+	jobScriptProvider := boshscript.NewConcreteJobScriptProvider(
+		scriptRunner,
 		app.platform.GetFs(),
 		app.platform.GetDirProvider(),
+		specService,
+		settingsService,
+		opts.VersionLabel,
 		timeService,
 		app.logger,
 	)
 
+	var vitalsHistory boshvitals.History
+	agentEnv := settingsService.GetSettings().Env
+	if !agentEnv.Bosh.Agent.VitalsHistory.Disabled {
+		vitalsHistory = boshvitals.NewFileHistory(
+			app.platform.GetFs(),
+			filepath.Join(app.dirProvider.BoshDir(), "vitals_history.json"),
+			agentEnv.GetVitalsHistoryRetention(),
+			agentEnv.GetVitalsHistoryInterval(),
+			timeService,
+		)
+	}
+
 	actionFactory := boshaction.NewFactory(
 		settingsService,
 		app.platform,
@@ -219,6 +256,9 @@ func (app *app) Setup(opts Options) error {
 		jobScriptProvider,
 		app.logger,
 		blobstoreDelegator,
+		vitalsHistory,
+		probeManager,
+		cgroupManager,
 	)
 
 	actionRunner := boshaction.NewRunner()
@@ -249,6 +289,16 @@ func (app *app) Setup(opts Options) error {
 		uuidGen,
 		timeService,
 		startManager,
+		boshagent.JobStateWatchOptions{
+			PollInterval: agentEnv.GetJobStatePollInterval(),
+			Debounce:     agentEnv.GetJobStateDebounce(),
+		},
+		vitalsHistory,
+		agentEnv.GetVitalsHistoryInterval(),
+		boshalert.NewAggregator(agentEnv.GetAlertDedupWindow(), agentEnv.GetAlertMaxPerService(), timeService),
+		boshalert.NewThresholdEvaluator(agentEnv.GetAlertThresholds(), timeService),
+		boshagent.NewKmsgWatcher("/dev/kmsg", app.platform.GetFs(), jobSupervisor, timeService, app.logger),
+		boshagent.NewPeriodicScriptScheduler(specService, jobSupervisor, jobScriptProvider, timeService, app.logger),
 	)
 
 	return nil
@@ -322,6 +372,7 @@ func (app *app) buildApplierAndCompiler(
 		app.platform.GetRunner(),
 		dirProvider.LogsDir(),
 		10*1024, // 10 Kb
+		timeService,
 	)
 
 	compiler := boshcomp.NewConcreteCompiler(
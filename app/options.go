@@ -16,6 +16,9 @@ type Options struct {
 	JobSupervisor      string
 	ConfigPath         string
 	VersionCheck       bool
+
+	// Set by main rather than parsed from the arguments
+	VersionLabel string
 }
 
 func ParseOptions(args []string) (Options, error) {
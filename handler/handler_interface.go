@@ -10,4 +10,8 @@ type Handler interface {
 	RegisterAdditionalFunc(handlerFunc Func)
 
 	Send(target Target, topic Topic, message interface{}) error
+
+	// Publish sends message to subject as is, e.g. to a subject
+	// a client returned by an action subscribed to
+	Publish(subject string, message interface{}) error
 }
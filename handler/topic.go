@@ -6,4 +6,5 @@ const (
 	Heartbeat = Topic("heartbeat")
 	Alert     = Topic("alert")
 	Shutdown  = Topic("shutdown")
+	TailLogs  = Topic("tail_logs")
 )
@@ -0,0 +1,13 @@
+package alertserver_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAlertServer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Alert Server Suite")
+}
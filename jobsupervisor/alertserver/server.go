@@ -0,0 +1,259 @@
+package alertserver
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	serverLogTag = "alertServer"
+
+	defaultEvent = "job alert"
+
+	maxRequestSize = 32 * 1024
+)
+
+// AlertHandler receives the alerts raised through the server
+type AlertHandler func(boshalert.MonitAlert) error
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Server
+
+type Server interface {
+	// Serve blocks accepting alerts from local processes. Callers
+	// authenticate with the token written to the token file.
+	Serve(handler AlertHandler) error
+}
+
+// Request is the JSON body posted to /alerts
+type Request struct {
+	ID       string `json:"id"`
+	Service  string `json:"service"`
+	Event    string `json:"event"`
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+}
+
+func (r Request) Validate() error {
+	if r.Service == "" {
+		return bosherr.Error("Missing 'service'")
+	}
+
+	if r.Summary == "" {
+		return bosherr.Error("Missing 'summary'")
+	}
+
+	if r.Severity != "" {
+		if _, found := boshalert.ParseSeverityLevel(r.Severity); !found {
+			return bosherr.Errorf("Unknown severity '%s'", r.Severity)
+		}
+	}
+
+	return nil
+}
+
+type server struct {
+	fs          boshsys.FileSystem
+	tokenPath   string
+	port        int
+	timeService clock.Clock
+	logger      boshlog.Logger
+}
+
+func NewServer(
+	fs boshsys.FileSystem,
+	tokenPath string,
+	port int,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) Server {
+	return server{
+		fs:          fs,
+		tokenPath:   tokenPath,
+		port:        port,
+		timeService: timeService,
+		logger:      logger,
+	}
+}
+
+func (s server) Serve(handler AlertHandler) error {
+	token, err := s.writeToken()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", s.port))
+	if err != nil {
+		return bosherr.WrapError(err, "Listening for alerts")
+	}
+
+	s.logger.Info(serverLogTag, "Accepting alerts on %s", listener.Addr())
+
+	err = http.Serve(listener, NewHandler(token, handler, s.timeService, s.logger))
+	if err != nil {
+		return bosherr.WrapError(err, "Serving alerts")
+	}
+
+	return nil
+}
+
+// writeToken generates a new token on every start so that only
+// processes able to read the token file can raise alerts
+func (s server) writeToken() (string, error) {
+	bytes := make([]byte, 32)
+
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Generating alert token")
+	}
+
+	token := hex.EncodeToString(bytes)
+
+	// The token is written to a file created readable only by its owner
+	// and group and then moved into place, so that it is never readable
+	// by anyone else, not even for a moment
+	tmpPath := s.tokenPath + ".tmp"
+
+	err = s.fs.RemoveAll(tmpPath)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Removing stale alert token")
+	}
+
+	file, err := s.fs.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0640)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Creating alert token")
+	}
+
+	_, err = file.Write([]byte(token))
+	if err != nil {
+		_ = file.Close()
+		return "", bosherr.WrapError(err, "Writing alert token")
+	}
+
+	err = file.Close()
+	if err != nil {
+		return "", bosherr.WrapError(err, "Writing alert token")
+	}
+
+	err = s.fs.Chown(tmpPath, "root:vcap")
+	if err != nil {
+		return "", bosherr.WrapError(err, "Changing alert token ownership")
+	}
+
+	err = s.fs.Rename(tmpPath, s.tokenPath)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Moving alert token into place")
+	}
+
+	return token, nil
+}
+
+type alertHandler struct {
+	token       string
+	handler     AlertHandler
+	timeService clock.Clock
+	logger      boshlog.Logger
+}
+
+// NewHandler accepts alerts posted as JSON to /alerts with
+// the token passed as a bearer token
+func NewHandler(token string, handler AlertHandler, timeService clock.Clock, logger boshlog.Logger) http.Handler {
+	return alertHandler{
+		token:       token,
+		handler:     handler,
+		timeService: timeService,
+		logger:      logger,
+	}
+}
+
+func (h alertHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.URL.Path != "/alerts" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorized(r) {
+		h.logger.Warn(serverLogTag, "Rejected unauthorized alert from %s", r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var request Request
+
+	err := json.NewDecoder(io.LimitReader(r.Body, maxRequestSize)).Decode(&request)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid alert: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	err = request.Validate()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid alert: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	err = h.handler(h.monitAlert(request))
+	if err != nil {
+		h.logger.Error(serverLogTag, "Handling alert for %s: %s", request.Service, err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h alertHandler) authorized(r *http.Request) bool {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+func (h alertHandler) monitAlert(request Request) boshalert.MonitAlert {
+	now := h.timeService.Now()
+
+	alert := boshalert.MonitAlert{
+		ID:          request.ID,
+		Service:     request.Service,
+		Event:       request.Event,
+		Action:      "alert",
+		Date:        now.Format(time.RFC1123Z),
+		Description: request.Summary,
+		Severity:    request.Severity,
+	}
+
+	if alert.ID == "" {
+		alert.ID = fmt.Sprintf("%s-%d", request.Service, now.UnixNano())
+	}
+
+	if alert.Event == "" {
+		alert.Event = defaultEvent
+	}
+
+	return alert
+}
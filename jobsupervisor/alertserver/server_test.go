@@ -0,0 +1,192 @@
+package alertserver_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/alertserver"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		timeService *fakeclock.FakeClock
+		alerts      []boshalert.MonitAlert
+		handlerErr  error
+		handler     http.Handler
+	)
+
+	BeforeEach(func() {
+		timeService = fakeclock.NewFakeClock(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+		alerts = nil
+		handlerErr = nil
+
+		handler = alertserver.NewHandler("fake-token", func(alert boshalert.MonitAlert) error {
+			alerts = append(alerts, alert)
+			return handlerErr
+		}, timeService, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	post := func(path string, token string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	It("passes the alert to the handler", func() {
+		response := post("/alerts", "fake-token", `{"service":"fake-job","severity":"warning","summary":"queue is backing up"}`)
+		Expect(response.Code).To(Equal(http.StatusAccepted))
+
+		Expect(alerts).To(Equal([]boshalert.MonitAlert{{
+			ID:          fmt.Sprintf("fake-job-%d", timeService.Now().UnixNano()),
+			Service:     "fake-job",
+			Event:       "job alert",
+			Action:      "alert",
+			Date:        "Thu, 01 Jan 2026 12:00:00 +0000",
+			Description: "queue is backing up",
+			Severity:    "warning",
+		}}))
+	})
+
+	It("keeps the id and event given with the alert", func() {
+		response := post("/alerts", "fake-token", `{"id":"fake-id","service":"fake-job","event":"queue full","summary":"queue is full"}`)
+		Expect(response.Code).To(Equal(http.StatusAccepted))
+
+		Expect(alerts).To(HaveLen(1))
+		Expect(alerts[0].ID).To(Equal("fake-id"))
+		Expect(alerts[0].Event).To(Equal("queue full"))
+		Expect(alerts[0].Severity).To(BeEmpty())
+	})
+
+	It("rejects requests without the token", func() {
+		Expect(post("/alerts", "", `{"service":"fake-job","summary":"fake-summary"}`).Code).To(Equal(http.StatusUnauthorized))
+		Expect(post("/alerts", "wrong-token", `{"service":"fake-job","summary":"fake-summary"}`).Code).To(Equal(http.StatusUnauthorized))
+		Expect(alerts).To(BeEmpty())
+	})
+
+	It("rejects invalid alerts", func() {
+		Expect(post("/alerts", "fake-token", `not-json`).Code).To(Equal(http.StatusBadRequest))
+		Expect(post("/alerts", "fake-token", `{"summary":"fake-summary"}`).Code).To(Equal(http.StatusBadRequest))
+		Expect(post("/alerts", "fake-token", `{"service":"fake-job"}`).Code).To(Equal(http.StatusBadRequest))
+
+		response := post("/alerts", "fake-token", `{"service":"fake-job","summary":"fake-summary","severity":"bogus"}`)
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+		Expect(response.Body.String()).To(ContainSubstring("Unknown severity 'bogus'"))
+
+		Expect(alerts).To(BeEmpty())
+	})
+
+	It("only accepts alerts posted to /alerts", func() {
+		Expect(post("/other", "fake-token", `{}`).Code).To(Equal(http.StatusNotFound))
+
+		request := httptest.NewRequest(http.MethodGet, "/alerts", nil)
+		request.Header.Set("Authorization", "Bearer fake-token")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("returns an error when the alert cannot be handled", func() {
+		handlerErr = errors.New("fake-handler-err")
+
+		response := post("/alerts", "fake-token", `{"service":"fake-job","summary":"fake-summary"}`)
+		Expect(response.Code).To(Equal(http.StatusInternalServerError))
+	})
+})
+
+var _ = Describe("Server", func() {
+	var (
+		fs     *fakesys.FakeFileSystem
+		port   int
+		server alertserver.Server
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		Expect(fs.MkdirAll("/var/vcap/bosh", 0755)).To(Succeed())
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		port = listener.Addr().(*net.TCPAddr).Port
+		Expect(listener.Close()).To(Succeed())
+
+		server = alertserver.NewServer(fs, "/var/vcap/bosh/alert_token", port, fakeclock.NewFakeClock(time.Now()), boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	It("accepts alerts authenticated with the token it writes", func() {
+		alerts := make(chan boshalert.MonitAlert, 1)
+
+		go func() {
+			defer GinkgoRecover()
+			_ = server.Serve(func(alert boshalert.MonitAlert) error {
+				alerts <- alert
+				return nil
+			})
+		}()
+
+		var token string
+		Eventually(func() string {
+			token, _ = fs.ReadFileString("/var/vcap/bosh/alert_token")
+			return token
+		}).ShouldNot(BeEmpty())
+
+		var response *http.Response
+		Eventually(func() error {
+			request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://127.0.0.1:%d/alerts", port), strings.NewReader(`{"service":"fake-job","summary":"fake-summary"}`))
+			Expect(err).ToNot(HaveOccurred())
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			response, err = http.DefaultClient.Do(request)
+			return err
+		}).Should(Succeed())
+		Expect(response.Body.Close()).To(Succeed())
+
+		Expect(response.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(<-alerts).To(HaveField("Service", "fake-job"))
+
+		stat := fs.GetFileTestStat("/var/vcap/bosh/alert_token")
+		Expect(stat.FileMode).To(Equal(os.FileMode(0640)))
+		Expect(stat.Username).To(Equal("root"))
+		Expect(stat.Groupname).To(Equal("vcap"))
+		Expect(fs.FileExists("/var/vcap/bosh/alert_token.tmp")).To(BeFalse())
+	})
+
+	It("creates the token readable only by its owner and group before writing it", func() {
+		fs.RenameError = errors.New("fake-rename-err")
+
+		err := server.Serve(func(boshalert.MonitAlert) error { return nil })
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-rename-err"))
+
+		stat := fs.GetFileTestStat("/var/vcap/bosh/alert_token.tmp")
+		Expect(stat.FileMode).To(Equal(os.FileMode(0640)))
+		Expect(stat.Flags & os.O_EXCL).To(Equal(os.O_EXCL))
+		Expect(fs.FileExists("/var/vcap/bosh/alert_token")).To(BeFalse())
+	})
+
+	It("returns an error when the token cannot be written", func() {
+		fs.OpenFileErr = errors.New("fake-open-err")
+
+		err := server.Serve(func(boshalert.MonitAlert) error { return nil })
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-open-err"))
+	})
+})
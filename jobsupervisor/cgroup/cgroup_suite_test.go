@@ -0,0 +1,13 @@
+package cgroup_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCgroup(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cgroup Suite")
+}
@@ -0,0 +1,403 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package cgroupfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	"github.com/cloudfoundry/bosh-utils/system"
+)
+
+type FakeManager struct {
+	AddJobStub        func(string, string) error
+	addJobMutex       sync.RWMutex
+	addJobArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	addJobReturns struct {
+		result1 error
+	}
+	addJobReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ConfineScriptStub        func(string, string, cgroup.Limits, system.Command) (system.Command, error)
+	confineScriptMutex       sync.RWMutex
+	confineScriptArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 cgroup.Limits
+		arg4 system.Command
+	}
+	confineScriptReturns struct {
+		result1 system.Command
+		result2 error
+	}
+	confineScriptReturnsOnCall map[int]struct {
+		result1 system.Command
+		result2 error
+	}
+	JobLauncherStub        func(string) string
+	jobLauncherMutex       sync.RWMutex
+	jobLauncherArgsForCall []struct {
+		arg1 string
+	}
+	jobLauncherReturns struct {
+		result1 string
+	}
+	jobLauncherReturnsOnCall map[int]struct {
+		result1 string
+	}
+	LimitsStub        func() map[string]cgroup.Limits
+	limitsMutex       sync.RWMutex
+	limitsArgsForCall []struct {
+	}
+	limitsReturns struct {
+		result1 map[string]cgroup.Limits
+	}
+	limitsReturnsOnCall map[int]struct {
+		result1 map[string]cgroup.Limits
+	}
+	RemoveAllJobsStub        func() error
+	removeAllJobsMutex       sync.RWMutex
+	removeAllJobsArgsForCall []struct {
+	}
+	removeAllJobsReturns struct {
+		result1 error
+	}
+	removeAllJobsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeManager) AddJob(arg1 string, arg2 string) error {
+	fake.addJobMutex.Lock()
+	ret, specificReturn := fake.addJobReturnsOnCall[len(fake.addJobArgsForCall)]
+	fake.addJobArgsForCall = append(fake.addJobArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.AddJobStub
+	fakeReturns := fake.addJobReturns
+	fake.recordInvocation("AddJob", []interface{}{arg1, arg2})
+	fake.addJobMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeManager) AddJobCallCount() int {
+	fake.addJobMutex.RLock()
+	defer fake.addJobMutex.RUnlock()
+	return len(fake.addJobArgsForCall)
+}
+
+func (fake *FakeManager) AddJobCalls(stub func(string, string) error) {
+	fake.addJobMutex.Lock()
+	defer fake.addJobMutex.Unlock()
+	fake.AddJobStub = stub
+}
+
+func (fake *FakeManager) AddJobArgsForCall(i int) (string, string) {
+	fake.addJobMutex.RLock()
+	defer fake.addJobMutex.RUnlock()
+	argsForCall := fake.addJobArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeManager) AddJobReturns(result1 error) {
+	fake.addJobMutex.Lock()
+	defer fake.addJobMutex.Unlock()
+	fake.AddJobStub = nil
+	fake.addJobReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeManager) AddJobReturnsOnCall(i int, result1 error) {
+	fake.addJobMutex.Lock()
+	defer fake.addJobMutex.Unlock()
+	fake.AddJobStub = nil
+	if fake.addJobReturnsOnCall == nil {
+		fake.addJobReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.addJobReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeManager) ConfineScript(arg1 string, arg2 string, arg3 cgroup.Limits, arg4 system.Command) (system.Command, error) {
+	fake.confineScriptMutex.Lock()
+	ret, specificReturn := fake.confineScriptReturnsOnCall[len(fake.confineScriptArgsForCall)]
+	fake.confineScriptArgsForCall = append(fake.confineScriptArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 cgroup.Limits
+		arg4 system.Command
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ConfineScriptStub
+	fakeReturns := fake.confineScriptReturns
+	fake.recordInvocation("ConfineScript", []interface{}{arg1, arg2, arg3, arg4})
+	fake.confineScriptMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeManager) ConfineScriptCallCount() int {
+	fake.confineScriptMutex.RLock()
+	defer fake.confineScriptMutex.RUnlock()
+	return len(fake.confineScriptArgsForCall)
+}
+
+func (fake *FakeManager) ConfineScriptCalls(stub func(string, string, cgroup.Limits, system.Command) (system.Command, error)) {
+	fake.confineScriptMutex.Lock()
+	defer fake.confineScriptMutex.Unlock()
+	fake.ConfineScriptStub = stub
+}
+
+func (fake *FakeManager) ConfineScriptArgsForCall(i int) (string, string, cgroup.Limits, system.Command) {
+	fake.confineScriptMutex.RLock()
+	defer fake.confineScriptMutex.RUnlock()
+	argsForCall := fake.confineScriptArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeManager) ConfineScriptReturns(result1 system.Command, result2 error) {
+	fake.confineScriptMutex.Lock()
+	defer fake.confineScriptMutex.Unlock()
+	fake.ConfineScriptStub = nil
+	fake.confineScriptReturns = struct {
+		result1 system.Command
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeManager) ConfineScriptReturnsOnCall(i int, result1 system.Command, result2 error) {
+	fake.confineScriptMutex.Lock()
+	defer fake.confineScriptMutex.Unlock()
+	fake.ConfineScriptStub = nil
+	if fake.confineScriptReturnsOnCall == nil {
+		fake.confineScriptReturnsOnCall = make(map[int]struct {
+			result1 system.Command
+			result2 error
+		})
+	}
+	fake.confineScriptReturnsOnCall[i] = struct {
+		result1 system.Command
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeManager) JobLauncher(arg1 string) string {
+	fake.jobLauncherMutex.Lock()
+	ret, specificReturn := fake.jobLauncherReturnsOnCall[len(fake.jobLauncherArgsForCall)]
+	fake.jobLauncherArgsForCall = append(fake.jobLauncherArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.JobLauncherStub
+	fakeReturns := fake.jobLauncherReturns
+	fake.recordInvocation("JobLauncher", []interface{}{arg1})
+	fake.jobLauncherMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeManager) JobLauncherCallCount() int {
+	fake.jobLauncherMutex.RLock()
+	defer fake.jobLauncherMutex.RUnlock()
+	return len(fake.jobLauncherArgsForCall)
+}
+
+func (fake *FakeManager) JobLauncherCalls(stub func(string) string) {
+	fake.jobLauncherMutex.Lock()
+	defer fake.jobLauncherMutex.Unlock()
+	fake.JobLauncherStub = stub
+}
+
+func (fake *FakeManager) JobLauncherArgsForCall(i int) string {
+	fake.jobLauncherMutex.RLock()
+	defer fake.jobLauncherMutex.RUnlock()
+	argsForCall := fake.jobLauncherArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeManager) JobLauncherReturns(result1 string) {
+	fake.jobLauncherMutex.Lock()
+	defer fake.jobLauncherMutex.Unlock()
+	fake.JobLauncherStub = nil
+	fake.jobLauncherReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeManager) JobLauncherReturnsOnCall(i int, result1 string) {
+	fake.jobLauncherMutex.Lock()
+	defer fake.jobLauncherMutex.Unlock()
+	fake.JobLauncherStub = nil
+	if fake.jobLauncherReturnsOnCall == nil {
+		fake.jobLauncherReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.jobLauncherReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeManager) Limits() map[string]cgroup.Limits {
+	fake.limitsMutex.Lock()
+	ret, specificReturn := fake.limitsReturnsOnCall[len(fake.limitsArgsForCall)]
+	fake.limitsArgsForCall = append(fake.limitsArgsForCall, struct {
+	}{})
+	stub := fake.LimitsStub
+	fakeReturns := fake.limitsReturns
+	fake.recordInvocation("Limits", []interface{}{})
+	fake.limitsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeManager) LimitsCallCount() int {
+	fake.limitsMutex.RLock()
+	defer fake.limitsMutex.RUnlock()
+	return len(fake.limitsArgsForCall)
+}
+
+func (fake *FakeManager) LimitsCalls(stub func() map[string]cgroup.Limits) {
+	fake.limitsMutex.Lock()
+	defer fake.limitsMutex.Unlock()
+	fake.LimitsStub = stub
+}
+
+func (fake *FakeManager) LimitsReturns(result1 map[string]cgroup.Limits) {
+	fake.limitsMutex.Lock()
+	defer fake.limitsMutex.Unlock()
+	fake.LimitsStub = nil
+	fake.limitsReturns = struct {
+		result1 map[string]cgroup.Limits
+	}{result1}
+}
+
+func (fake *FakeManager) LimitsReturnsOnCall(i int, result1 map[string]cgroup.Limits) {
+	fake.limitsMutex.Lock()
+	defer fake.limitsMutex.Unlock()
+	fake.LimitsStub = nil
+	if fake.limitsReturnsOnCall == nil {
+		fake.limitsReturnsOnCall = make(map[int]struct {
+			result1 map[string]cgroup.Limits
+		})
+	}
+	fake.limitsReturnsOnCall[i] = struct {
+		result1 map[string]cgroup.Limits
+	}{result1}
+}
+
+func (fake *FakeManager) RemoveAllJobs() error {
+	fake.removeAllJobsMutex.Lock()
+	ret, specificReturn := fake.removeAllJobsReturnsOnCall[len(fake.removeAllJobsArgsForCall)]
+	fake.removeAllJobsArgsForCall = append(fake.removeAllJobsArgsForCall, struct {
+	}{})
+	stub := fake.RemoveAllJobsStub
+	fakeReturns := fake.removeAllJobsReturns
+	fake.recordInvocation("RemoveAllJobs", []interface{}{})
+	fake.removeAllJobsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeManager) RemoveAllJobsCallCount() int {
+	fake.removeAllJobsMutex.RLock()
+	defer fake.removeAllJobsMutex.RUnlock()
+	return len(fake.removeAllJobsArgsForCall)
+}
+
+func (fake *FakeManager) RemoveAllJobsCalls(stub func() error) {
+	fake.removeAllJobsMutex.Lock()
+	defer fake.removeAllJobsMutex.Unlock()
+	fake.RemoveAllJobsStub = stub
+}
+
+func (fake *FakeManager) RemoveAllJobsReturns(result1 error) {
+	fake.removeAllJobsMutex.Lock()
+	defer fake.removeAllJobsMutex.Unlock()
+	fake.RemoveAllJobsStub = nil
+	fake.removeAllJobsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeManager) RemoveAllJobsReturnsOnCall(i int, result1 error) {
+	fake.removeAllJobsMutex.Lock()
+	defer fake.removeAllJobsMutex.Unlock()
+	fake.RemoveAllJobsStub = nil
+	if fake.removeAllJobsReturnsOnCall == nil {
+		fake.removeAllJobsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeAllJobsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeManager) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.addJobMutex.RLock()
+	defer fake.addJobMutex.RUnlock()
+	fake.confineScriptMutex.RLock()
+	defer fake.confineScriptMutex.RUnlock()
+	fake.jobLauncherMutex.RLock()
+	defer fake.jobLauncherMutex.RUnlock()
+	fake.limitsMutex.RLock()
+	defer fake.limitsMutex.RUnlock()
+	fake.removeAllJobsMutex.RLock()
+	defer fake.removeAllJobsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeManager) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ cgroup.Manager = new(FakeManager)
@@ -0,0 +1,186 @@
+package cgroup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	minWeight = 1
+	maxWeight = 10000
+)
+
+// Limits are the contents of a job's resources.yml which jobs
+// usually render from their spec properties, e.g.
+//
+//	cpu_weight: 200
+//	memory_max: 2G
+//	memory_high: 1536M
+//	io_weight: 100
+//	pids_max: 4096
+//
+// Limits that are not set are not restricted.
+type Limits struct {
+	CPUWeight  int    `yaml:"cpu_weight" json:"cpu_weight,omitempty"`
+	MemoryMax  string `yaml:"memory_max" json:"memory_max,omitempty"`
+	MemoryHigh string `yaml:"memory_high" json:"memory_high,omitempty"`
+	IOWeight   int    `yaml:"io_weight" json:"io_weight,omitempty"`
+	PidsMax    int    `yaml:"pids_max" json:"pids_max,omitempty"`
+}
+
+// Declared reports whether any limit is set
+func (l Limits) Declared() bool {
+	return l != Limits{}
+}
+
+func (l Limits) Validate() error {
+	if l.CPUWeight != 0 && (l.CPUWeight < minWeight || l.CPUWeight > maxWeight) {
+		return bosherr.Errorf("cpu_weight must be between %d and %d", minWeight, maxWeight)
+	}
+
+	if l.IOWeight != 0 && (l.IOWeight < minWeight || l.IOWeight > maxWeight) {
+		return bosherr.Errorf("io_weight must be between %d and %d", minWeight, maxWeight)
+	}
+
+	if l.PidsMax < 0 {
+		return bosherr.Error("pids_max must not be negative")
+	}
+
+	if _, err := ParseBytes(l.MemoryMax); err != nil {
+		return bosherr.WrapError(err, "Parsing memory_max")
+	}
+
+	if _, err := ParseBytes(l.MemoryHigh); err != nil {
+		return bosherr.WrapError(err, "Parsing memory_high")
+	}
+
+	return nil
+}
+
+// ParseBytes parses sizes like 512M or 2G into bytes; empty and "max" are unlimited (-1)
+func ParseBytes(size string) (int64, error) {
+	size = strings.TrimSpace(size)
+	if size == "" || size == "max" {
+		return -1, nil
+	}
+
+	multiplier := int64(1)
+
+	switch strings.ToUpper(size[len(size)-1:]) {
+	case "K":
+		multiplier = 1 << 10
+	case "M":
+		multiplier = 1 << 20
+	case "G":
+		multiplier = 1 << 30
+	case "T":
+		multiplier = 1 << 40
+	}
+
+	if multiplier > 1 {
+		size = size[:len(size)-1]
+	}
+
+	value, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || value < 0 {
+		return 0, bosherr.Errorf("Invalid size '%s'", size)
+	}
+
+	return value * multiplier, nil
+}
+
+// readLimits reads the limits declared by a job; jobs without resources.yml have none
+func readLimits(fs boshsys.FileSystem, jobName string, resourcesFilePath string) (Limits, error) {
+	var limits Limits
+
+	if !fs.FileExists(resourcesFilePath) {
+		return limits, nil
+	}
+
+	contents, err := fs.ReadFile(resourcesFilePath)
+	if err != nil {
+		return limits, bosherr.WrapErrorf(err, "Reading resources for job %s", jobName)
+	}
+
+	err = yaml.Unmarshal(contents, &limits)
+	if err != nil {
+		return limits, bosherr.WrapErrorf(err, "Parsing resources for job %s", jobName)
+	}
+
+	err = limits.Validate()
+	if err != nil {
+		return limits, bosherr.WrapErrorf(err, "Validating resources for job %s", jobName)
+	}
+
+	return limits, nil
+}
+
+// SliceName returns the systemd slice of a job, nested in bosh.slice.
+// Dashes separate slice levels so they are escaped like systemd-escape does.
+func SliceName(jobName string) string {
+	var escaped strings.Builder
+
+	for i := 0; i < len(jobName); i++ {
+		c := jobName[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == ':' || (c == '.' && i > 0) {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, `\x%02x`, c)
+		}
+	}
+
+	return "bosh-" + escaped.String() + ".slice"
+}
+
+// JobName returns the job owning the cgroup at cgroupPath, relative to the
+// root of the cgroup hierarchy, or "" if it does not belong to a job.
+// Both the cgroups of NewManager and the slices of NewSystemdManager are known.
+func JobName(cgroupPath string) string {
+	parts := strings.Split(strings.Trim(cgroupPath, "/"), "/")
+
+	for i, part := range parts {
+		if part == boshCgroup && i+1 < len(parts) {
+			if parts[i+1] != scriptsCgroup {
+				return parts[i+1]
+			}
+
+			// Scripts have a cgroup named after their job and script
+			if i+2 < len(parts) {
+				jobName, _, _ := strings.Cut(parts[i+2], ".")
+				return jobName
+			}
+
+			return ""
+		}
+
+		if escaped, found := strings.CutPrefix(part, "bosh-"); found && strings.HasSuffix(escaped, ".slice") {
+			return unescapeSliceName(strings.TrimSuffix(escaped, ".slice"))
+		}
+	}
+
+	return ""
+}
+
+func unescapeSliceName(escaped string) string {
+	var jobName strings.Builder
+
+	for i := 0; i < len(escaped); i++ {
+		if escaped[i] == '\\' && i+3 < len(escaped) && escaped[i+1] == 'x' {
+			if c, err := strconv.ParseUint(escaped[i+2:i+4], 16, 8); err == nil {
+				jobName.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+
+		jobName.WriteByte(escaped[i])
+	}
+
+	return jobName.String()
+}
@@ -0,0 +1,66 @@
+package cgroup_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+)
+
+var _ = Describe("Limits", func() {
+	DescribeTable("ParseBytes",
+		func(size string, expected int64) {
+			Expect(cgroup.ParseBytes(size)).To(Equal(expected))
+		},
+		Entry("unset", "", int64(-1)),
+		Entry("max", "max", int64(-1)),
+		Entry("bytes", "1024", int64(1024)),
+		Entry("kilobytes", "4K", int64(4096)),
+		Entry("megabytes", "512M", int64(512<<20)),
+		Entry("gigabytes", "2g", int64(2<<30)),
+	)
+
+	It("rejects invalid sizes", func() {
+		_, err := cgroup.ParseBytes("lots")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Invalid size 'lots'"))
+	})
+
+	DescribeTable("Validate",
+		func(limits cgroup.Limits, expectedErr string) {
+			err := limits.Validate()
+			if expectedErr == "" {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(expectedErr))
+			}
+		},
+		Entry("no limits", cgroup.Limits{}, ""),
+		Entry("all limits", cgroup.Limits{CPUWeight: 200, MemoryMax: "2G", MemoryHigh: "1G", IOWeight: 50, PidsMax: 100}, ""),
+		Entry("cpu weight too large", cgroup.Limits{CPUWeight: 10001}, "cpu_weight must be between 1 and 10000"),
+		Entry("io weight negative", cgroup.Limits{IOWeight: -1}, "io_weight must be between 1 and 10000"),
+		Entry("negative pids", cgroup.Limits{PidsMax: -1}, "pids_max must not be negative"),
+		Entry("invalid memory", cgroup.Limits{MemoryHigh: "1X"}, "Parsing memory_high"),
+	)
+
+	DescribeTable("SliceName",
+		func(jobName string, expected string) {
+			Expect(cgroup.SliceName(jobName)).To(Equal(expected))
+		},
+		Entry("plain", "nats", "bosh-nats.slice"),
+		Entry("dashes", "cloud-controller", `bosh-cloud\x2dcontroller.slice`),
+	)
+
+	DescribeTable("JobName",
+		func(cgroupPath string, expected string) {
+			Expect(cgroup.JobName(cgroupPath)).To(Equal(expected))
+		},
+		Entry("job cgroup", "/bosh/nats", "nats"),
+		Entry("script cgroup", "/bosh/scripts/nats.pre-start", "nats"),
+		Entry("job slice", "/bosh.slice/bosh-nats.slice/nats.service", "nats"),
+		Entry("escaped job slice", `/bosh.slice/bosh-cloud\x2dcontroller.slice/run-r1.scope`, "cloud-controller"),
+		Entry("other cgroup", "/system.slice/sshd.service", ""),
+		Entry("root cgroup", "/", ""),
+	)
+})
@@ -0,0 +1,273 @@
+package cgroup
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	managerLogTag = "cgroupManager"
+
+	// Subtree of the cgroup v2 hierarchy holding one cgroup per job
+	boshCgroup = "bosh"
+
+	// Subtree of boshCgroup holding one cgroup per script of a job
+	scriptsCgroup = "scripts"
+)
+
+var controllers = []string{"cpu", "io", "memory", "pids"}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Manager
+
+type Manager interface {
+	// AddJob creates the cgroup of jobName with the limits declared in resourcesFilePath
+	AddJob(jobName string, resourcesFilePath string) error
+	RemoveAllJobs() error
+
+	// JobLauncher returns the command that, followed by a program and its
+	// arguments, starts the program in the cgroup of jobName, or "" when
+	// jobName declares no limits or its programs cannot be confined
+	JobLauncher(jobName string) string
+
+	// Limits returns the limits of each job keyed by job name
+	Limits() map[string]Limits
+
+	// ConfineScript returns command changed to run scriptName of jobName with limits
+	ConfineScript(jobName string, scriptName string, limits Limits, command boshsys.Command) (boshsys.Command, error)
+}
+
+type concreteManager struct {
+	fs     boshsys.FileSystem
+	root   string
+	logger boshlog.Logger
+
+	mutex  sync.Mutex
+	limits map[string]Limits
+}
+
+// NewManager manages job cgroups in the cgroup v2 hierarchy mounted at root,
+// which must not be managed by anything else, e.g. systemd, which is left to
+// NewSystemdManager. Limits are still reported on systems without cgroup v2
+// but are not applied.
+func NewManager(fs boshsys.FileSystem, root string, logger boshlog.Logger) Manager {
+	return &concreteManager{
+		fs:     fs,
+		root:   root,
+		logger: logger,
+		limits: map[string]Limits{},
+	}
+}
+
+func (m *concreteManager) AddJob(jobName string, resourcesFilePath string) error {
+	limits, err := readLimits(m.fs, jobName, resourcesFilePath)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	m.limits[jobName] = limits
+	m.mutex.Unlock()
+
+	if !m.available() {
+		m.logger.Info(managerLogTag, "Not limiting resources of job %s, cgroup v2 is not available", jobName)
+		return nil
+	}
+
+	err = m.enableControllers(m.root)
+	if err != nil {
+		return err
+	}
+
+	err = m.enableControllers(path.Join(m.root, boshCgroup))
+	if err != nil {
+		return err
+	}
+
+	jobCgroup := m.jobCgroup(jobName)
+
+	err = m.fs.MkdirAll(jobCgroup, 0755)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Creating cgroup for job %s", jobName)
+	}
+
+	// Jobs are added again on every apply so unset limits are
+	// reset in case they were set previously
+	return m.writeLimits(jobCgroup, "job "+jobName, limits)
+}
+
+// RemoveAllJobs forgets about the jobs' limits. Their cgroups are kept
+// because cgroups still containing processes cannot be removed.
+func (m *concreteManager) RemoveAllJobs() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.limits = map[string]Limits{}
+
+	return nil
+}
+
+// JobLauncher has the program join the cgroup of the job before it starts so
+// none of its children escape. It still starts when it cannot join the cgroup.
+// The arguments of the program are embedded in monit start programs, which
+// cannot quote "$@", so an empty IFS keeps them from being split instead.
+func (m *concreteManager) JobLauncher(jobName string) string {
+	m.mutex.Lock()
+	limits := m.limits[jobName]
+	m.mutex.Unlock()
+
+	if !limits.Declared() || !m.available() {
+		return ""
+	}
+
+	return `/bin/sh -c 'set -f; IFS=; echo $$ > $0; exec $@' ` + path.Join(m.jobCgroup(jobName), "cgroup.procs")
+}
+
+func (m *concreteManager) Limits() map[string]Limits {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	limits := map[string]Limits{}
+	for jobName, jobLimits := range m.limits {
+		limits[jobName] = jobLimits
+	}
+
+	return limits
+}
+
+// ConfineScript creates a cgroup for the script next to the cgroups of the
+// jobs. The script joins it before it starts so none of its children escape.
+func (m *concreteManager) ConfineScript(jobName string, scriptName string, limits Limits, command boshsys.Command) (boshsys.Command, error) {
+	if !m.available() {
+		m.logger.Info(managerLogTag, "Not limiting resources of script %s of job %s, cgroup v2 is not available", scriptName, jobName)
+		return command, nil
+	}
+
+	for _, cgroupPath := range []string{m.root, path.Join(m.root, boshCgroup), path.Join(m.root, boshCgroup, scriptsCgroup)} {
+		err := m.enableControllers(cgroupPath)
+		if err != nil {
+			return command, err
+		}
+	}
+
+	scriptCgroup := path.Join(m.root, boshCgroup, scriptsCgroup, jobName+"."+scriptName)
+
+	err := m.fs.MkdirAll(scriptCgroup, 0755)
+	if err != nil {
+		return command, bosherr.WrapErrorf(err, "Creating cgroup for script %s of job %s", scriptName, jobName)
+	}
+
+	err = m.writeLimits(scriptCgroup, fmt.Sprintf("script %s of job %s", scriptName, jobName), limits)
+	if err != nil {
+		return command, err
+	}
+
+	return wrapCommand(command, "/bin/sh", "-c", `echo $$ > "$0" && exec "$@"`, path.Join(scriptCgroup, "cgroup.procs")), nil
+}
+
+func (m *concreteManager) writeLimits(cgroupPath string, owner string, limits Limits) error {
+	memoryMax, _ := ParseBytes(limits.MemoryMax)
+	memoryHigh, _ := ParseBytes(limits.MemoryHigh)
+
+	files := []struct {
+		name  string
+		value string
+	}{
+		{"cpu.weight", weight(limits.CPUWeight)},
+		{"memory.max", maxValue(memoryMax)},
+		{"memory.high", maxValue(memoryHigh)},
+		{"io.weight", "default " + weight(limits.IOWeight)},
+		{"pids.max", pidsMax(limits.PidsMax)},
+	}
+
+	for _, file := range files {
+		m.logger.Debug(managerLogTag, "Setting %s of %s to %s", file.name, owner, file.value)
+
+		err := m.fs.WriteFileString(path.Join(cgroupPath, file.name), file.value)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Setting %s of %s", file.name, owner)
+		}
+	}
+
+	return nil
+}
+
+func (m *concreteManager) available() bool {
+	return m.fs.FileExists(path.Join(m.root, "cgroup.controllers"))
+}
+
+// enableControllers makes the controllers available to child cgroups of cgroupPath
+func (m *concreteManager) enableControllers(cgroupPath string) error {
+	err := m.fs.MkdirAll(cgroupPath, 0755)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Creating cgroup %s", cgroupPath)
+	}
+
+	available, err := m.fs.ReadFileString(path.Join(m.root, "cgroup.controllers"))
+	if err != nil {
+		return bosherr.WrapError(err, "Reading available cgroup controllers")
+	}
+
+	enable := []string{}
+	for _, controller := range controllers {
+		for _, name := range strings.Fields(available) {
+			if name == controller {
+				enable = append(enable, "+"+controller)
+			}
+		}
+	}
+
+	if len(enable) == 0 {
+		return nil
+	}
+
+	err = m.fs.WriteFileString(path.Join(cgroupPath, "cgroup.subtree_control"), strings.Join(enable, " "))
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Enabling controllers of cgroup %s", cgroupPath)
+	}
+
+	return nil
+}
+
+func (m *concreteManager) jobCgroup(jobName string) string {
+	return path.Join(m.root, boshCgroup, jobName)
+}
+
+// wrapCommand runs command as the last arguments of name
+func wrapCommand(command boshsys.Command, name string, args ...string) boshsys.Command {
+	wrapped := command
+	wrapped.Name = name
+	wrapped.Args = append(append(args, command.Name), command.Args...)
+
+	return wrapped
+}
+
+func weight(value int) string {
+	if value == 0 {
+		return "100"
+	}
+
+	return strconv.Itoa(value)
+}
+
+func pidsMax(value int) string {
+	if value == 0 {
+		return "max"
+	}
+
+	return strconv.Itoa(value)
+}
+
+func maxValue(value int64) string {
+	if value < 0 {
+		return "max"
+	}
+
+	return strconv.FormatInt(value, 10)
+}
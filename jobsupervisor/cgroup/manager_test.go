@@ -0,0 +1,134 @@
+package cgroup_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("Manager", func() {
+	var (
+		fs      *fakesys.FakeFileSystem
+		manager cgroup.Manager
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		manager = cgroup.NewManager(fs, "/sys/fs/cgroup", boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	Context("when cgroup v2 is available", func() {
+		BeforeEach(func() {
+			Expect(fs.WriteFileString("/sys/fs/cgroup/cgroup.controllers", "cpuset cpu io memory hugetlb pids rdma\n")).To(Succeed())
+		})
+
+		It("creates a cgroup for the job with its limits", func() {
+			Expect(fs.WriteFileString("/var/vcap/jobs/nats/resources.yml", "cpu_weight: 200\nmemory_max: 1G\nmemory_high: 768M\nio_weight: 50\npids_max: 1024\n")).To(Succeed())
+
+			Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+
+			Expect(fs.ReadFileString("/sys/fs/cgroup/cgroup.subtree_control")).To(Equal("+cpu +io +memory +pids"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/cgroup.subtree_control")).To(Equal("+cpu +io +memory +pids"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/nats/cpu.weight")).To(Equal("200"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/nats/memory.max")).To(Equal("1073741824"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/nats/memory.high")).To(Equal("805306368"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/nats/io.weight")).To(Equal("default 50"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/nats/pids.max")).To(Equal("1024"))
+
+			Expect(manager.Limits()).To(Equal(map[string]cgroup.Limits{
+				"nats": {CPUWeight: 200, MemoryMax: "1G", MemoryHigh: "768M", IOWeight: 50, PidsMax: 1024},
+			}))
+		})
+
+		It("resets limits of jobs without resources", func() {
+			Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/nats/cpu.weight")).To(Equal("100"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/nats/memory.max")).To(Equal("max"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/nats/memory.high")).To(Equal("max"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/nats/io.weight")).To(Equal("default 100"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/nats/pids.max")).To(Equal("max"))
+		})
+
+		It("starts job programs in the cgroup of their job", func() {
+			Expect(fs.WriteFileString("/var/vcap/jobs/nats/resources.yml", "pids_max: 10\n")).To(Succeed())
+			Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+
+			Expect(manager.JobLauncher("nats")).To(Equal(`/bin/sh -c 'set -f; IFS=; echo $$ > $0; exec $@' /sys/fs/cgroup/bosh/nats/cgroup.procs`))
+		})
+
+		It("starts programs of jobs without limits as they are", func() {
+			Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+
+			Expect(manager.JobLauncher("nats")).To(BeEmpty())
+		})
+
+		It("returns an error when setting a limit fails", func() {
+			fs.WriteFileErrors["/sys/fs/cgroup/bosh/nats/memory.max"] = errors.New("fake-write-err")
+
+			err := manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Setting memory.max of job nats"))
+		})
+
+		It("runs scripts in their own cgroup with their limits", func() {
+			command := boshsys.Command{Name: "/var/vcap/jobs/nats/bin/pre-start", Args: []string{"arg"}, Env: map[string]string{"FOO": "bar"}}
+
+			confined, err := manager.ConfineScript("nats", "pre-start", cgroup.Limits{MemoryMax: "512M"}, command)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/scripts/cgroup.subtree_control")).To(Equal("+cpu +io +memory +pids"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/scripts/nats.pre-start/memory.max")).To(Equal("536870912"))
+			Expect(fs.ReadFileString("/sys/fs/cgroup/bosh/scripts/nats.pre-start/cpu.weight")).To(Equal("100"))
+
+			Expect(confined).To(Equal(boshsys.Command{
+				Name: "/bin/sh",
+				Args: []string{
+					"-c", `echo $$ > "$0" && exec "$@"`, "/sys/fs/cgroup/bosh/scripts/nats.pre-start/cgroup.procs",
+					"/var/vcap/jobs/nats/bin/pre-start", "arg",
+				},
+				Env: map[string]string{"FOO": "bar"},
+			}))
+		})
+
+		It("forgets limits when all jobs are removed", func() {
+			Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+
+			Expect(manager.RemoveAllJobs()).To(Succeed())
+			Expect(manager.Limits()).To(BeEmpty())
+		})
+	})
+
+	Context("when cgroup v2 is not available", func() {
+		It("only reports limits", func() {
+			Expect(fs.WriteFileString("/var/vcap/jobs/nats/resources.yml", "pids_max: 10\n")).To(Succeed())
+
+			Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+			Expect(manager.JobLauncher("nats")).To(BeEmpty())
+
+			Expect(fs.FileExists("/sys/fs/cgroup/bosh/nats")).To(BeFalse())
+			Expect(manager.Limits()).To(Equal(map[string]cgroup.Limits{"nats": {PidsMax: 10}}))
+		})
+
+		It("runs scripts unconfined", func() {
+			command := boshsys.Command{Name: "/var/vcap/jobs/nats/bin/pre-start"}
+
+			Expect(manager.ConfineScript("nats", "pre-start", cgroup.Limits{PidsMax: 10}, command)).To(Equal(command))
+			Expect(fs.FileExists("/sys/fs/cgroup/bosh/scripts")).To(BeFalse())
+		})
+	})
+
+	It("returns an error for invalid resources", func() {
+		Expect(fs.WriteFileString("/var/vcap/jobs/nats/resources.yml", "cpu_weight: 0\nmemory_max: lots\n")).To(Succeed())
+
+		err := manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Validating resources for job nats"))
+	})
+})
@@ -0,0 +1,180 @@
+package cgroup
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const systemdManagerLogTag = "systemdCgroupManager"
+
+type systemdManager struct {
+	fs       boshsys.FileSystem
+	runner   boshsys.CmdRunner
+	unitsDir string
+	logger   boshlog.Logger
+
+	mutex  sync.Mutex
+	limits map[string]Limits
+
+	// Contents of the slices as systemd last loaded them
+	loadedSlices map[string]string
+}
+
+// NewSystemdManager limits jobs with a slice per job which systemd turns
+// into the job's cgroup, so the agent never writes to the cgroup hierarchy
+// systemd owns. Units of a job are placed in the slice named SliceName(job)
+// and other programs of the job are started in a scope inside it.
+func NewSystemdManager(fs boshsys.FileSystem, runner boshsys.CmdRunner, unitsDir string, logger boshlog.Logger) Manager {
+	return &systemdManager{
+		fs:           fs,
+		runner:       runner,
+		unitsDir:     unitsDir,
+		logger:       logger,
+		limits:       map[string]Limits{},
+		loadedSlices: map[string]string{},
+	}
+}
+
+func (m *systemdManager) AddJob(jobName string, resourcesFilePath string) error {
+	limits, err := readLimits(m.fs, jobName, resourcesFilePath)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	m.limits[jobName] = limits
+	m.mutex.Unlock()
+
+	slice := SliceName(jobName)
+	contents := systemdSliceFile(jobName, limits)
+
+	m.logger.Debug(systemdManagerLogTag, "Writing slice %s for job %s", slice, jobName)
+
+	err = m.fs.WriteFileString(path.Join(m.unitsDir, slice), contents)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing slice %s", slice)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Programs may be started in the slice before anything else reloads systemd
+	if m.loadedSlices[slice] == contents {
+		return nil
+	}
+
+	_, _, _, err = m.runner.RunCommand("systemctl", "daemon-reload")
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Loading slice %s", slice)
+	}
+
+	m.loadedSlices[slice] = contents
+
+	return nil
+}
+
+func (m *systemdManager) RemoveAllJobs() error {
+	m.mutex.Lock()
+	m.limits = map[string]Limits{}
+	m.mutex.Unlock()
+
+	slices, err := m.fs.Glob(path.Join(m.unitsDir, "bosh-*.slice"))
+	if err != nil {
+		return bosherr.WrapError(err, "Listing slices")
+	}
+
+	for _, slice := range slices {
+		err = m.fs.RemoveAll(slice)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Removing slice %s", path.Base(slice))
+		}
+	}
+
+	return nil
+}
+
+// JobLauncher starts programs in a transient scope inside the slice of their
+// job. Units of jobs supervised by systemd are placed in the slice directly.
+func (m *systemdManager) JobLauncher(jobName string) string {
+	m.mutex.Lock()
+	limits := m.limits[jobName]
+	m.mutex.Unlock()
+
+	if !limits.Declared() {
+		return ""
+	}
+
+	return "systemd-run --scope --quiet --collect --slice=" + SliceName(jobName)
+}
+
+func (m *systemdManager) Limits() map[string]Limits {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	limits := map[string]Limits{}
+	for jobName, jobLimits := range m.limits {
+		limits[jobName] = jobLimits
+	}
+
+	return limits
+}
+
+// ConfineScript runs the script in a transient scope inside the slice of
+// its job so it is limited by both the job's and its own limits.
+func (m *systemdManager) ConfineScript(jobName string, scriptName string, limits Limits, command boshsys.Command) (boshsys.Command, error) {
+	args := []string{"--scope", "--quiet", "--collect", "--slice=" + SliceName(jobName)}
+
+	for _, property := range systemdProperties(limits) {
+		args = append(args, "--property="+property)
+	}
+
+	m.logger.Debug(systemdManagerLogTag, "Running script %s of job %s in a scope", scriptName, jobName)
+
+	return wrapCommand(command, "systemd-run", args...), nil
+}
+
+func systemdSliceFile(jobName string, limits Limits) string {
+	var slice strings.Builder
+
+	slice.WriteString("[Unit]\n")
+	fmt.Fprintf(&slice, "Description=BOSH job %s\n", jobName)
+	slice.WriteString("\n[Slice]\n")
+
+	for _, property := range systemdProperties(limits) {
+		slice.WriteString(property + "\n")
+	}
+
+	return slice.String()
+}
+
+func systemdProperties(limits Limits) []string {
+	properties := []string{}
+
+	if limits.CPUWeight != 0 {
+		properties = append(properties, fmt.Sprintf("CPUWeight=%d", limits.CPUWeight))
+	}
+
+	if memoryMax, _ := ParseBytes(limits.MemoryMax); memoryMax >= 0 {
+		properties = append(properties, fmt.Sprintf("MemoryMax=%d", memoryMax))
+	}
+
+	if memoryHigh, _ := ParseBytes(limits.MemoryHigh); memoryHigh >= 0 {
+		properties = append(properties, fmt.Sprintf("MemoryHigh=%d", memoryHigh))
+	}
+
+	if limits.IOWeight != 0 {
+		properties = append(properties, fmt.Sprintf("IOWeight=%d", limits.IOWeight))
+	}
+
+	if limits.PidsMax != 0 {
+		properties = append(properties, fmt.Sprintf("TasksMax=%d", limits.PidsMax))
+	}
+
+	return properties
+}
@@ -0,0 +1,101 @@
+package cgroup_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("SystemdManager", func() {
+	var (
+		fs      *fakesys.FakeFileSystem
+		runner  *fakesys.FakeCmdRunner
+		manager cgroup.Manager
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		runner = fakesys.NewFakeCmdRunner()
+		manager = cgroup.NewSystemdManager(fs, runner, "/etc/systemd/system", boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	It("writes a slice with the limits of the job", func() {
+		Expect(fs.WriteFileString("/var/vcap/jobs/nats/resources.yml", "cpu_weight: 200\nmemory_max: 1G\nio_weight: 50\npids_max: 1024\n")).To(Succeed())
+
+		Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+
+		Expect(fs.ReadFileString("/etc/systemd/system/bosh-nats.slice")).To(Equal(`[Unit]
+Description=BOSH job nats
+
+[Slice]
+CPUWeight=200
+MemoryMax=1073741824
+IOWeight=50
+TasksMax=1024
+`))
+		Expect(manager.Limits()).To(Equal(map[string]cgroup.Limits{
+			"nats": {CPUWeight: 200, MemoryMax: "1G", IOWeight: 50, PidsMax: 1024},
+		}))
+	})
+
+	It("has systemd load slices that changed", func() {
+		Expect(fs.WriteFileString("/var/vcap/jobs/nats/resources.yml", "pids_max: 10\n")).To(Succeed())
+
+		Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+		Expect(runner.RunCommands).To(Equal([][]string{{"systemctl", "daemon-reload"}}))
+
+		Expect(manager.RemoveAllJobs()).To(Succeed())
+		Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+		Expect(runner.RunCommands).To(HaveLen(1))
+
+		Expect(fs.WriteFileString("/var/vcap/jobs/nats/resources.yml", "pids_max: 20\n")).To(Succeed())
+		Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+		Expect(runner.RunCommands).To(HaveLen(2))
+	})
+
+	It("returns an error when systemd cannot load the slice", func() {
+		runner.AddCmdResult("systemctl daemon-reload", fakesys.FakeCmdResult{Error: errors.New("fake-reload-err")})
+
+		err := manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Loading slice bosh-nats.slice"))
+	})
+
+	It("starts programs of jobs with limits in a scope inside the slice of their job", func() {
+		Expect(fs.WriteFileString("/var/vcap/jobs/nats/resources.yml", "pids_max: 10\n")).To(Succeed())
+		Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+		Expect(manager.AddJob("redis", "/var/vcap/jobs/redis/resources.yml")).To(Succeed())
+
+		Expect(manager.JobLauncher("nats")).To(Equal("systemd-run --scope --quiet --collect --slice=bosh-nats.slice"))
+		Expect(manager.JobLauncher("redis")).To(BeEmpty())
+	})
+
+	It("removes all slices", func() {
+		Expect(manager.AddJob("nats", "/var/vcap/jobs/nats/resources.yml")).To(Succeed())
+		fs.SetGlob("/etc/systemd/system/bosh-*.slice", []string{"/etc/systemd/system/bosh-nats.slice"})
+
+		Expect(manager.RemoveAllJobs()).To(Succeed())
+		Expect(fs.FileExists("/etc/systemd/system/bosh-nats.slice")).To(BeFalse())
+		Expect(manager.Limits()).To(BeEmpty())
+	})
+	It("runs scripts in a scope inside the slice of their job", func() {
+		command := boshsys.Command{Name: "/var/vcap/jobs/nats/bin/pre-start", Args: []string{"arg"}}
+
+		confined, err := manager.ConfineScript("nats", "pre-start", cgroup.Limits{CPUWeight: 50, MemoryMax: "1G"}, command)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(confined).To(Equal(boshsys.Command{
+			Name: "systemd-run",
+			Args: []string{
+				"--scope", "--quiet", "--collect", "--slice=bosh-nats.slice",
+				"--property=CPUWeight=50", "--property=MemoryMax=1073741824",
+				"/var/vcap/jobs/nats/bin/pre-start", "arg",
+			},
+		}))
+	})
+})
@@ -24,15 +24,27 @@ func (s *dummyJobSupervisor) Stop() error {
 	return nil
 }
 
-func (s *dummyJobSupervisor) StopAndWait() error {
+func (s *dummyJobSupervisor) StopAndWait() ([]JobStopResult, error) {
 	s.status = "stopped"
-	return nil
+	return []JobStopResult{}, nil
 }
 
 func (s *dummyJobSupervisor) Unmonitor() error {
 	return nil
 }
 
+func (s *dummyJobSupervisor) StartJob(name string) error {
+	return nil
+}
+
+func (s *dummyJobSupervisor) StopJob(name string) error {
+	return nil
+}
+
+func (s *dummyJobSupervisor) JobStatuses() map[string]string {
+	return map[string]string{}
+}
+
 func (s *dummyJobSupervisor) Status() (status string) {
 	return s.status
 }
@@ -49,6 +61,14 @@ func (s *dummyJobSupervisor) RemoveAllJobs() error {
 	return nil
 }
 
+func (s *dummyJobSupervisor) SetJobDependencies(dependencies JobDependencies) error {
+	return nil
+}
+
+func (s *dummyJobSupervisor) SetJobStopPolicies(policies JobStopPolicies) error {
+	return nil
+}
+
 func (s *dummyJobSupervisor) MonitorJobFailures(handler JobFailureHandler) error {
 	return nil
 }
@@ -91,8 +91,8 @@ func (d *dummyNatsJobSupervisor) Stop() error {
 	return nil
 }
 
-func (d *dummyNatsJobSupervisor) StopAndWait() error {
-	return d.Stop()
+func (d *dummyNatsJobSupervisor) StopAndWait() ([]JobStopResult, error) {
+	return []JobStopResult{}, d.Stop()
 }
 
 func (d *dummyNatsJobSupervisor) Unmonitor() error {
@@ -103,6 +103,26 @@ func (d *dummyNatsJobSupervisor) RemoveAllJobs() error {
 	return nil
 }
 
+func (d *dummyNatsJobSupervisor) SetJobDependencies(dependencies JobDependencies) error {
+	return nil
+}
+
+func (d *dummyNatsJobSupervisor) SetJobStopPolicies(policies JobStopPolicies) error {
+	return nil
+}
+
+func (d *dummyNatsJobSupervisor) StartJob(name string) error {
+	return nil
+}
+
+func (d *dummyNatsJobSupervisor) StopJob(name string) error {
+	return nil
+}
+
+func (d *dummyNatsJobSupervisor) JobStatuses() map[string]string {
+	return map[string]string{}
+}
+
 func (d *dummyNatsJobSupervisor) Status() string {
 	return d.status
 }
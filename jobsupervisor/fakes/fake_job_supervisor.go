@@ -16,17 +16,31 @@ type FakeJobSupervisor struct {
 	RemovedAllJobs    bool
 	RemovedAllJobsErr error
 
+	JobDependencies       boshjobsuper.JobDependencies
+	SetJobDependenciesErr error
+
+	JobStopPolicies       boshjobsuper.JobStopPolicies
+	SetJobStopPoliciesErr error
+
 	Started  bool
 	StartErr error
 
 	Stopped          bool
 	StopErr          error
 	StoppedAndWaited bool
+	JobStopResults   []boshjobsuper.JobStopResult
 
 	Unmonitored  bool
 	UnmonitorErr error
 
+	StartedJobs []string
+	StartJobErr error
+
+	StoppedJobs []string
+	StopJobErr  error
+
 	StatusStatus    string
+	JobStatusesMap  map[string]string
 	ProcessesStatus []boshjobsuper.Process
 	ProcessesError  error
 
@@ -70,6 +84,16 @@ func (m *FakeJobSupervisor) RemoveAllJobs() error {
 	return m.RemovedAllJobsErr
 }
 
+func (m *FakeJobSupervisor) SetJobDependencies(dependencies boshjobsuper.JobDependencies) error {
+	m.JobDependencies = dependencies
+	return m.SetJobDependenciesErr
+}
+
+func (m *FakeJobSupervisor) SetJobStopPolicies(policies boshjobsuper.JobStopPolicies) error {
+	m.JobStopPolicies = policies
+	return m.SetJobStopPoliciesErr
+}
+
 func (m *FakeJobSupervisor) Start() error {
 	m.Started = true
 	return m.StartErr
@@ -80,10 +104,10 @@ func (m *FakeJobSupervisor) Stop() error {
 	return m.StopErr
 }
 
-func (m *FakeJobSupervisor) StopAndWait() error {
+func (m *FakeJobSupervisor) StopAndWait() ([]boshjobsuper.JobStopResult, error) {
 	m.Stopped = true
 	m.StoppedAndWaited = true
-	return m.StopErr
+	return m.JobStopResults, m.StopErr
 }
 
 func (m *FakeJobSupervisor) Unmonitor() error {
@@ -91,6 +115,20 @@ func (m *FakeJobSupervisor) Unmonitor() error {
 	return m.UnmonitorErr
 }
 
+func (m *FakeJobSupervisor) StartJob(name string) error {
+	m.StartedJobs = append(m.StartedJobs, name)
+	return m.StartJobErr
+}
+
+func (m *FakeJobSupervisor) StopJob(name string) error {
+	m.StoppedJobs = append(m.StoppedJobs, name)
+	return m.StopJobErr
+}
+
+func (m *FakeJobSupervisor) JobStatuses() map[string]string {
+	return m.JobStatusesMap
+}
+
 func (m *FakeJobSupervisor) Status() string {
 	return m.StatusStatus
 }
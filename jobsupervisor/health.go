@@ -1,5 +1,251 @@
 package jobsupervisor
 
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// maxHealthHistory is the number of state transitions kept per job
+const maxHealthHistory = 10
+
+// Health is the document written to InstanceDir()/health.json
 type Health struct {
-	State string `json:"state"`
+	State     string               `json:"state"`
+	UpdatedAt *time.Time           `json:"updated_at,omitempty"`
+	Jobs      map[string]JobHealth `json:"jobs,omitempty"`
+	Probes    []probe.Result       `json:"probes,omitempty"`
+}
+
+type JobHealth struct {
+	State          string                   `json:"state"`
+	LastTransition time.Time                `json:"last_transition"`
+	Restarts       int                      `json:"restarts"`
+	LastFailure    *Failure                 `json:"last_failure,omitempty"`
+	Processes      map[string]ProcessHealth `json:"processes,omitempty"`
+	History        []Transition             `json:"history,omitempty"`
+}
+
+type ProcessHealth struct {
+	State          string    `json:"state"`
+	LastTransition time.Time `json:"last_transition"`
+	PID            int       `json:"pid,omitempty"`
+	Restarts       int       `json:"restarts"`
+	LastFailure    *Failure  `json:"last_failure,omitempty"`
+}
+
+// Failure is the last alert raised for a process
+type Failure struct {
+	Event       string    `json:"event"`
+	Description string    `json:"description,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+type Transition struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+}
+
+// ReadHealth returns the recorded health or an unknown state
+// when none has been recorded yet
+func ReadHealth(fs boshsys.FileSystem, path string) (Health, error) {
+	health := Health{State: "unknown"}
+
+	if !fs.FileExists(path) {
+		return health, nil
+	}
+
+	contents, err := fs.ReadFile(path)
+	if err != nil {
+		return health, bosherr.WrapError(err, "Reading health")
+	}
+
+	err = json.Unmarshal(contents, &health)
+	if err != nil {
+		return health, bosherr.WrapError(err, "Unmarshalling health")
+	}
+
+	return health, nil
+}
+
+// writeHealth replaces the health file atomically so that
+// readers never see a partially written document
+func writeHealth(fs boshsys.FileSystem, path string, health Health) error {
+	contents, err := json.Marshal(health)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling health")
+	}
+
+	err = fs.WriteFile(path+".tmp", contents)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing health")
+	}
+
+	err = fs.Rename(path+".tmp", path)
+	if err != nil {
+		return bosherr.WrapError(err, "Renaming health")
+	}
+
+	return nil
+}
+
+// healthTracker derives transitions, restarts and failures
+// from successive observations of the jobs and their processes
+type healthTracker struct {
+	timeService clock.Clock
+
+	lock     sync.Mutex
+	previous *Health
+	failures map[string]Failure
+
+	// Restarts of each process reported since the last observation
+	restarts map[string]int
+}
+
+func newHealthTracker(timeService clock.Clock) *healthTracker {
+	return &healthTracker{
+		timeService: timeService,
+		failures:    map[string]Failure{},
+		restarts:    map[string]int{},
+	}
+}
+
+// load continues from the health recorded before the agent restarted
+func (t *healthTracker) load(health Health) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.previous != nil {
+		return
+	}
+
+	t.previous = &health
+
+	for _, job := range health.Jobs {
+		for name, process := range job.Processes {
+			if process.LastFailure != nil {
+				t.failures[name] = *process.LastFailure
+			}
+		}
+	}
+}
+
+func (t *healthTracker) loaded() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.previous != nil
+}
+
+// recordFailure records the alert as the last failure of the process and
+// counts the restart when the supervisor restarted the process in response.
+// Monit does not report how often it restarted a process in its status.
+func (t *healthTracker) recordFailure(alert boshalert.MonitAlert) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.failures[alert.Service] = Failure{
+		Event:       alert.Event,
+		Description: alert.Description,
+		At:          t.timeService.Now(),
+	}
+
+	if strings.EqualFold(alert.Action, "restart") {
+		t.restarts[alert.Service]++
+	}
+}
+
+func (t *healthTracker) observe(state string, jobStates map[string]string, processes []Process, probes []probe.Result) Health {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.timeService.Now()
+
+	previous := Health{}
+	if t.previous != nil {
+		previous = *t.previous
+	}
+
+	processesByJob := map[string][]Process{}
+	for _, process := range processes {
+		if process.Job != "" {
+			processesByJob[process.Job] = append(processesByJob[process.Job], process)
+		}
+	}
+
+	health := Health{
+		State:     state,
+		UpdatedAt: &now,
+		Jobs:      map[string]JobHealth{},
+		Probes:    probes,
+	}
+
+	for job, jobState := range jobStates {
+		previousJob, found := previous.Jobs[job]
+
+		jobHealth := JobHealth{
+			State:          jobState,
+			LastTransition: now,
+			Restarts:       previousJob.Restarts,
+			LastFailure:    previousJob.LastFailure,
+			Processes:      map[string]ProcessHealth{},
+			History:        previousJob.History,
+		}
+
+		if found && previousJob.State == jobState {
+			jobHealth.LastTransition = previousJob.LastTransition
+		} else if found {
+			jobHealth.History = append(jobHealth.History, Transition{From: previousJob.State, To: jobState, At: now})
+			if len(jobHealth.History) > maxHealthHistory {
+				jobHealth.History = jobHealth.History[len(jobHealth.History)-maxHealthHistory:]
+			}
+		}
+
+		for _, process := range processesByJob[job] {
+			previousProcess, found := previousJob.Processes[process.Name]
+
+			processHealth := ProcessHealth{
+				State:          process.State,
+				LastTransition: now,
+				PID:            process.PID,
+				Restarts:       previousProcess.Restarts,
+			}
+
+			if found && previousProcess.State == process.State {
+				processHealth.LastTransition = previousProcess.LastTransition
+			}
+
+			// Pids also change when jobs are stopped and started again
+			// so only restarts reported by the supervisor are counted
+			if restarts := t.restarts[process.Name]; restarts > 0 {
+				processHealth.Restarts += restarts
+				jobHealth.Restarts += restarts
+				delete(t.restarts, process.Name)
+			}
+
+			if failure, found := t.failures[process.Name]; found {
+				processHealth.LastFailure = &failure
+				if jobHealth.LastFailure == nil || failure.At.After(jobHealth.LastFailure.At) {
+					jobHealth.LastFailure = &failure
+				}
+			}
+
+			jobHealth.Processes[process.Name] = processHealth
+		}
+
+		health.Jobs[job] = jobHealth
+	}
+
+	t.previous = &health
+
+	return health
 }
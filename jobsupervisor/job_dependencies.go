@@ -0,0 +1,143 @@
+package jobsupervisor
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// JobDependencies maps each co-located job to the jobs it depends on
+type JobDependencies map[string][]string
+
+// Declared is true when any of the jobs depends on another job
+func (d JobDependencies) Declared() bool {
+	for _, dependencies := range d {
+		if len(dependencies) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StartOrder returns the jobs ordered so that every job comes after the jobs
+// it depends on. It fails when a job depends on a job that is not co-located
+// or when the dependencies form a cycle.
+func (d JobDependencies) StartOrder() ([]string, error) {
+	jobs := []string{}
+	for job := range d {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+
+	order := []string{}
+	visited := map[string]bool{}
+	path := []string{}
+
+	var visit func(job string) error
+	visit = func(job string) error {
+		for i, pathJob := range path {
+			if pathJob == job {
+				return bosherr.Errorf("Job dependencies form a cycle: %s", strings.Join(append(append([]string{}, path[i:]...), job), " -> "))
+			}
+		}
+
+		if visited[job] {
+			return nil
+		}
+
+		path = append(path, job)
+
+		dependencies := append([]string{}, d[job]...)
+		sort.Strings(dependencies)
+
+		for _, dependency := range dependencies {
+			if _, found := d[dependency]; !found {
+				return bosherr.Errorf("Job '%s' depends on '%s' which is not co-located", job, dependency)
+			}
+
+			err := visit(dependency)
+			if err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		visited[job] = true
+		order = append(order, job)
+
+		return nil
+	}
+
+	for _, job := range jobs {
+		err := visit(job)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// HasDependents is true when another job depends on the job
+func (d JobDependencies) HasDependents(job string) bool {
+	for _, dependencies := range d {
+		if containsString(dependencies, job) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func loadJobDependencies(fs boshsys.FileSystem, path string) (JobDependencies, error) {
+	dependencies := JobDependencies{}
+
+	if !fs.FileExists(path) {
+		return dependencies, nil
+	}
+
+	contents, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading job dependencies")
+	}
+
+	err = json.Unmarshal(contents, &dependencies)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmarshalling job dependencies")
+	}
+
+	return dependencies, nil
+}
+
+func (d JobDependencies) save(fs boshsys.FileSystem, path string) error {
+	contents, err := json.Marshal(d)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling job dependencies")
+	}
+
+	err = fs.WriteFile(path, contents)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing job dependencies")
+	}
+
+	return nil
+}
+
+// orderedServices returns the jobs in start order and the services of each
+func orderedServices(dependencies JobDependencies, jobs jobServices) ([]string, [][]string, error) {
+	order, err := dependencies.StartOrder()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	services := [][]string{}
+	for _, job := range order {
+		services = append(services, jobs[job])
+	}
+
+	return order, services, nil
+}
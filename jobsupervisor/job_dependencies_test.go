@@ -0,0 +1,53 @@
+package jobsupervisor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+)
+
+var _ = Describe("JobDependencies", func() {
+	Describe("StartOrder", func() {
+		It("orders jobs after the jobs they depend on", func() {
+			dependencies := JobDependencies{
+				"app":    {"proxy", "db"},
+				"proxy":  {"db"},
+				"db":     nil,
+				"agent":  nil,
+				"worker": {"db"},
+			}
+
+			order, err := dependencies.StartOrder()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(order).To(Equal([]string{"agent", "db", "proxy", "app", "worker"}))
+		})
+
+		It("returns an error when the dependencies form a cycle", func() {
+			dependencies := JobDependencies{
+				"a": {"b"},
+				"b": {"c"},
+				"c": {"a"},
+			}
+
+			_, err := dependencies.StartOrder()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Job dependencies form a cycle: a -> b -> c -> a"))
+		})
+
+		It("returns an error when a job depends on a job that is not co-located", func() {
+			_, err := JobDependencies{"app": {"db"}}.StartOrder()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Job 'app' depends on 'db' which is not co-located"))
+		})
+	})
+
+	It("knows whether dependencies are declared and which jobs have dependents", func() {
+		Expect(JobDependencies{"app": nil, "db": nil}.Declared()).To(BeFalse())
+
+		dependencies := JobDependencies{"app": {"db"}, "db": nil}
+		Expect(dependencies.Declared()).To(BeTrue())
+		Expect(dependencies.HasDependents("db")).To(BeTrue())
+		Expect(dependencies.HasDependents("app")).To(BeFalse())
+	})
+})
@@ -0,0 +1,209 @@
+package jobsupervisor
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// jobServices maps job names to the services (processes) supervised for them
+type jobServices map[string][]string
+
+// jobNameForConfig returns the job a monit file belongs to. Jobs with
+// additional <label>.monit files are added as <job>_<label>.
+func jobNameForConfig(jobName string, configPath string) string {
+	base := filepath.Base(configPath)
+	if base == "monit" {
+		return jobName
+	}
+
+	return strings.TrimSuffix(jobName, "_"+strings.TrimSuffix(base, ".monit"))
+}
+
+// monitProcessNames returns the names of the processes checked in a monit file
+func monitProcessNames(contents string) []string {
+	names := []string{}
+
+	for _, line := range strings.Split(contents, "\n") {
+		if matches := monitCheckProcessRegexp.FindStringSubmatch(strings.TrimSpace(line)); matches != nil {
+			names = append(names, matches[1])
+		}
+	}
+
+	return names
+}
+
+func (j jobServices) add(jobName string, services ...string) {
+	for _, service := range services {
+		if !containsString(j[jobName], service) {
+			j[jobName] = append(j[jobName], service)
+		}
+	}
+}
+
+// resolve returns the services of the named job, or the named service itself
+func (j jobServices) resolve(name string) ([]string, error) {
+	if services, found := j[name]; found {
+		return services, nil
+	}
+
+	for _, services := range j {
+		if containsString(services, name) {
+			return []string{name}, nil
+		}
+	}
+
+	return nil, bosherr.Errorf("Unknown job or process '%s'", name)
+}
+
+// jobOf returns the job a service belongs to, or empty if it is unknown
+func (j jobServices) jobOf(service string) string {
+	for job, services := range j {
+		if containsString(services, service) {
+			return job
+		}
+	}
+
+	return ""
+}
+
+// statuses combines the state of each service into the state of its job
+func (j jobServices) statuses(serviceStates map[string]string) map[string]string {
+	statuses := map[string]string{}
+
+	for job, services := range j {
+		status := "running"
+		stopped := 0
+
+		for _, service := range services {
+			switch serviceStates[service] {
+			case "running":
+			case "stopped":
+				stopped++
+			case "starting":
+				if status == "running" {
+					status = "starting"
+				}
+			default:
+				status = "failing"
+			}
+		}
+
+		if len(services) > 0 && stopped == len(services) {
+			status = "stopped"
+		} else if stopped > 0 && status == "running" {
+			status = "failing"
+		}
+
+		statuses[job] = status
+	}
+
+	return statuses
+}
+
+func loadJobServices(fs boshsys.FileSystem, path string) (jobServices, error) {
+	services := jobServices{}
+
+	if !fs.FileExists(path) {
+		return services, nil
+	}
+
+	contents, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading job services")
+	}
+
+	err = json.Unmarshal(contents, &services)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmarshalling job services")
+	}
+
+	return services, nil
+}
+
+func (j jobServices) save(fs boshsys.FileSystem, path string) error {
+	contents, err := json.Marshal(j)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling job services")
+	}
+
+	err = fs.WriteFile(path, contents)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing job services")
+	}
+
+	return nil
+}
+
+// stoppedServices are services that were stopped individually
+// and therefore should not be considered failing
+type stoppedServices struct {
+	fs   boshsys.FileSystem
+	path string
+}
+
+func (s stoppedServices) load() map[string]bool {
+	stopped := map[string]bool{}
+
+	contents, err := s.fs.ReadFile(s.path)
+	if err != nil {
+		return stopped
+	}
+
+	var names []string
+
+	_ = json.Unmarshal(contents, &names)
+
+	for _, name := range names {
+		stopped[name] = true
+	}
+
+	return stopped
+}
+
+func (s stoppedServices) update(add []string, remove []string) error {
+	stopped := s.load()
+
+	for _, name := range add {
+		stopped[name] = true
+	}
+	for _, name := range remove {
+		delete(stopped, name)
+	}
+
+	names := []string{}
+	for name := range stopped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	contents, err := json.Marshal(names)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling stopped services")
+	}
+
+	err = s.fs.WriteFile(s.path, contents)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing stopped services")
+	}
+
+	return nil
+}
+
+func (s stoppedServices) clear() error {
+	return s.fs.RemoveAll(s.path)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,255 @@
+package jobsupervisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	JobStopEscalationNone = "none"
+	JobStopEscalationKill = "kill"
+
+	JobStopped = "stopped"
+	JobKilled  = "killed"
+
+	defaultJobStopTimeout     = 5 * time.Minute
+	defaultJobStopGracePeriod = 10 * time.Second
+)
+
+// JobStopPolicy is how long StopAndWait waits for a job to stop
+// and what it does when the job does not stop in time
+type JobStopPolicy struct {
+	// Seconds to wait for the job to stop, 5 minutes when zero
+	Timeout int `json:"timeout,omitempty"`
+
+	// "kill" sends SIGTERM to the process tree of each of the job's processes
+	// once the timeout passes and SIGKILL after the grace period; "none", the
+	// default, fails the stop instead
+	Escalation string `json:"escalation,omitempty"`
+
+	// Seconds between SIGTERM and SIGKILL, 10 seconds when zero
+	GracePeriod int `json:"grace_period,omitempty"`
+}
+
+func (p JobStopPolicy) Validate() error {
+	if p.Timeout < 0 {
+		return bosherr.Errorf("Stop timeout must not be negative")
+	}
+
+	if p.GracePeriod < 0 {
+		return bosherr.Errorf("Stop grace period must not be negative")
+	}
+
+	switch p.Escalation {
+	case "", JobStopEscalationNone, JobStopEscalationKill:
+	default:
+		return bosherr.Errorf("Unknown stop escalation '%s'", p.Escalation)
+	}
+
+	return nil
+}
+
+func (p JobStopPolicy) timeout() time.Duration {
+	if p.Timeout == 0 {
+		return defaultJobStopTimeout
+	}
+
+	return time.Duration(p.Timeout) * time.Second
+}
+
+func (p JobStopPolicy) gracePeriod() time.Duration {
+	if p.GracePeriod == 0 {
+		return defaultJobStopGracePeriod
+	}
+
+	return time.Duration(p.GracePeriod) * time.Second
+}
+
+func (p JobStopPolicy) kills() bool {
+	return p.Escalation == JobStopEscalationKill
+}
+
+// wait is how long stopping the job may take at most
+func (p JobStopPolicy) wait() time.Duration {
+	if p.kills() {
+		return p.timeout() + p.gracePeriod()
+	}
+
+	return p.timeout()
+}
+
+// JobStopPolicies maps job names to their stop policy;
+// jobs without a policy use the defaults
+type JobStopPolicies map[string]JobStopPolicy
+
+// Validate returns the first invalid policy in job name order
+func (p JobStopPolicies) Validate() error {
+	jobs := []string{}
+	for job := range p {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+
+	for _, job := range jobs {
+		err := p[job].Validate()
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Validating stop policy of job '%s'", job)
+		}
+	}
+
+	return nil
+}
+
+// longestWait is how long stopping all jobs may take at most
+func (p JobStopPolicies) longestWait() time.Duration {
+	longest := defaultJobStopTimeout
+
+	for _, policy := range p {
+		if wait := policy.wait(); wait > longest {
+			longest = wait
+		}
+	}
+
+	return longest
+}
+
+// waitOf is how long stopping the job may take at most
+func (p JobStopPolicies) waitOf(job string) time.Duration {
+	if policy, found := p[job]; found {
+		return policy.wait()
+	}
+
+	return defaultJobStopTimeout
+}
+
+// JobStopResult reports how a job was stopped by StopAndWait
+type JobStopResult struct {
+	Job string `json:"job"`
+
+	// "stopped" when the job stopped by itself, "killed"
+	// when its processes were killed after the timeout
+	Result string `json:"result"`
+
+	// Seconds it took for the job to stop
+	Duration float64 `json:"duration"`
+}
+
+func newJobStopResult(job string, result string, duration time.Duration) JobStopResult {
+	return JobStopResult{Job: job, Result: result, Duration: duration.Seconds()}
+}
+
+func sortJobStopResults(results []JobStopResult) []JobStopResult {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Job < results[j].Job
+	})
+
+	return results
+}
+
+func loadJobStopPolicies(fs boshsys.FileSystem, path string) (JobStopPolicies, error) {
+	policies := JobStopPolicies{}
+
+	if !fs.FileExists(path) {
+		return policies, nil
+	}
+
+	contents, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading job stop policies")
+	}
+
+	err = json.Unmarshal(contents, &policies)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmarshalling job stop policies")
+	}
+
+	return policies, nil
+}
+
+func (p JobStopPolicies) save(fs boshsys.FileSystem, path string) error {
+	contents, err := json.Marshal(p)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling job stop policies")
+	}
+
+	err = fs.WriteFile(path, contents)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing job stop policies")
+	}
+
+	return nil
+}
+
+// describeTimeout formats timeouts the way they are reported in errors
+func describeTimeout(timeout time.Duration) string {
+	if timeout == time.Minute {
+		return "1 minute"
+	}
+
+	if timeout%time.Minute == 0 {
+		return fmt.Sprintf("%d minutes", int(timeout.Minutes()))
+	}
+
+	return timeout.String()
+}
+
+// processTree returns pid followed by all of its descendants
+func processTree(fs boshsys.FileSystem, pid int) []int {
+	stats, err := fs.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return []int{pid}
+	}
+
+	children := map[int][]int{}
+
+	for _, stat := range stats {
+		contents, err := fs.ReadFileString(stat)
+		if err != nil {
+			continue
+		}
+
+		// Format is "pid (comm) state ppid ..." where comm may contain spaces
+		i := strings.LastIndex(contents, ")")
+		if i < 0 {
+			continue
+		}
+
+		fields := strings.Fields(contents[i+1:])
+		if len(fields) < 2 {
+			continue
+		}
+
+		child, err := strconv.Atoi(strings.Fields(contents)[0])
+		if err != nil {
+			continue
+		}
+
+		parent, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		children[parent] = append(children[parent], child)
+	}
+
+	tree := []int{pid}
+	seen := map[int]bool{pid: true}
+
+	for i := 0; i < len(tree); i++ {
+		for _, child := range children[tree[i]] {
+			if !seen[child] {
+				seen[child] = true
+				tree = append(tree, child)
+			}
+		}
+	}
+
+	return tree
+}
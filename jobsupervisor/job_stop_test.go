@@ -0,0 +1,40 @@
+package jobsupervisor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+)
+
+var _ = Describe("JobStopPolicies", func() {
+	Describe("Validate", func() {
+		It("accepts policies using the defaults", func() {
+			policies := JobStopPolicies{
+				"app": {},
+				"db":  {Timeout: 60, Escalation: "kill", GracePeriod: 5},
+				"web": {Timeout: 30, Escalation: "none"},
+			}
+
+			Expect(policies.Validate()).To(Succeed())
+		})
+
+		It("returns an error for an unknown escalation", func() {
+			err := JobStopPolicies{"db": {Escalation: "terminate"}}.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Validating stop policy of job 'db': Unknown stop escalation 'terminate'"))
+		})
+
+		It("returns an error for a negative timeout", func() {
+			err := JobStopPolicies{"db": {Timeout: -1}}.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Stop timeout must not be negative"))
+		})
+
+		It("returns an error for a negative grace period", func() {
+			err := JobStopPolicies{"db": {Escalation: "kill", GracePeriod: -1}}.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Stop grace period must not be negative"))
+		})
+	})
+})
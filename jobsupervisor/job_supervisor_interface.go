@@ -6,7 +6,9 @@ import (
 
 type Process struct {
 	Name   string       `json:"name"`
+	Job    string       `json:"job,omitempty"`
 	State  string       `json:"state"`
+	PID    int          `json:"pid,omitempty"`
 	Uptime UptimeVitals `json:"uptime,omitempty"`
 	Memory MemoryVitals `json:"mem,omitempty"`
 	CPU    CPUVitals    `json:"cpu,omitempty"`
@@ -33,7 +35,8 @@ type JobSupervisor interface {
 	// Actions taken on all services
 	Start() error
 	Stop() error
-	StopAndWait() error
+	// StopAndWait reports how each job was stopped
+	StopAndWait() ([]JobStopResult, error)
 
 	// Start and Stop should still function after Unmonitor.
 	// Calling Start after Unmonitor should re-monitor all jobs.
@@ -41,11 +44,25 @@ type JobSupervisor interface {
 	// (Monit complies to above requirements.)
 	Unmonitor() error
 
+	// Actions taken on the services of a single job, or on a
+	// single service when name is not the name of a job.
+	// Services stopped by StopJob are not considered failing.
+	StartJob(name string) error
+	StopJob(name string) error
+
 	Status() string
+	// JobStatuses returns the status of each job keyed by job name
+	JobStatuses() map[string]string
 	Processes() ([]Process, error)
 	// Job management
 	AddJob(jobName string, jobIndex int, configPath string) error
 	RemoveAllJobs() error
+	// SetJobDependencies makes Start start the jobs in dependency order
+	// and StopAndWait stop them in reverse order
+	SetJobDependencies(dependencies JobDependencies) error
+	// SetJobStopPolicies sets how long StopAndWait waits for each job
+	// and whether it kills the processes of jobs that do not stop
+	SetJobStopPolicies(policies JobStopPolicies) error
 
 	MonitorJobFailures(handler JobFailureHandler) error
 	HealthRecorder(status string)
@@ -27,6 +27,7 @@ type serviceTag struct {
 	Status        int       `xml:"status"`
 	StatusMessage string    `xml:"status_message"`
 	Monitor       int       `xml:"monitor"`
+	PID           int       `xml:"pid"`
 	Uptime        int       `xml:"uptime"`
 	Children      int       `xml:"children"`
 	Memory        memoryTag `xml:"memory"`
@@ -109,6 +110,7 @@ func (status status) ServicesInGroup(name string) (services []Service) {
 				Errored:              serviceTag.Status > 0 && serviceTag.StatusMessage != "",
 				StatusMessage:        serviceTag.StatusMessage,
 				Monitored:            serviceTag.Monitor > 0,
+				PID:                  serviceTag.PID,
 				Uptime:               serviceTag.Uptime,
 				MemoryPercentTotal:   serviceTag.Memory.PercentTotal,
 				MemoryKilobytesTotal: serviceTag.Memory.KilobyteTotal,
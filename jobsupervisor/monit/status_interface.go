@@ -19,6 +19,7 @@ type Service struct {
 	Pending              bool
 	Status               string
 	StatusMessage        string
+	PID                  int
 	Uptime               int
 	MemoryPercentTotal   float64
 	MemoryKilobytesTotal int
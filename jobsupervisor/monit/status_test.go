@@ -98,6 +98,7 @@ var _ = Describe("status", func() {
 					Pending:              false,
 					Status:               "running",
 					StatusMessage:        "",
+					PID:                  1,
 					Uptime:               880183,
 					MemoryPercentTotal:   0,
 					MemoryKilobytesTotal: 4004,
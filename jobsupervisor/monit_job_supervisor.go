@@ -3,6 +3,8 @@ package jobsupervisor
 import (
 	"fmt"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,7 +13,9 @@ import (
 	"github.com/pivotal/go-smtpd/smtpd"
 
 	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	boshcgroup "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
 	boshmonit "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/monit"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
 	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -30,6 +34,8 @@ type monitJobSupervisor struct {
 	reloadOptions         MonitReloadOptions
 	timeService           clock.Clock
 	serviceManager        servicemanager.ServiceManager
+	cgroupManager         boshcgroup.Manager
+	probeManager          probe.Manager
 }
 
 type MonitReloadOptions struct {
@@ -54,6 +60,8 @@ func NewMonitJobSupervisor(
 	reloadOptions MonitReloadOptions,
 	timeService clock.Clock,
 	serviceManager servicemanager.ServiceManager,
+	cgroupManager boshcgroup.Manager,
+	probeManager probe.Manager,
 ) JobSupervisor {
 	return &monitJobSupervisor{
 		fs:                    fs,
@@ -65,6 +73,8 @@ func NewMonitJobSupervisor(
 		reloadOptions:         reloadOptions,
 		timeService:           timeService,
 		serviceManager:        serviceManager,
+		cgroupManager:         cgroupManager,
+		probeManager:          probeManager,
 	}
 }
 
@@ -136,12 +146,21 @@ func (m monitJobSupervisor) incarnationChanged(incarnation int) bool {
 }
 
 func (m monitJobSupervisor) Start() error {
+	started, err := m.startInOrder()
+	if err != nil {
+		return err
+	}
+
 	services, err := m.client.ServicesInGroup("vcap")
 	if err != nil {
 		return bosherr.WrapError(err, "Getting vcap services")
 	}
 
 	for _, service := range services {
+		if containsString(started, service) {
+			continue
+		}
+
 		m.logger.Debug(monitJobSupervisorLogTag, "Starting service %s", service)
 		err = m.client.StartService(service)
 		if err != nil {
@@ -154,9 +173,387 @@ func (m monitJobSupervisor) Start() error {
 		return bosherr.WrapError(err, "Removing stopped File")
 	}
 
+	err = m.stoppedServices().clear()
+	if err != nil {
+		return bosherr.WrapError(err, "Removing stopped services")
+	}
+
 	return nil
 }
 
+// startInOrder starts the jobs with declared dependencies one after another,
+// waiting for the services of jobs that others depend on to run and for their
+// probes to pass. It returns the services it started.
+func (m monitJobSupervisor) startInOrder() ([]string, error) {
+	dependencies, err := loadJobDependencies(m.fs, m.jobDependenciesPath())
+	if err != nil || !dependencies.Declared() {
+		return nil, err
+	}
+
+	jobs, err := loadJobServices(m.fs, m.jobServicesPath())
+	if err != nil {
+		return nil, err
+	}
+
+	order, orderedServices, err := orderedServices(dependencies, jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	started := []string{}
+
+	for i, job := range order {
+		for _, service := range orderedServices[i] {
+			m.logger.Debug(monitJobSupervisorLogTag, "Starting service %s of %s", service, job)
+			err = m.client.StartService(service)
+			if err != nil {
+				return nil, bosherr.WrapErrorf(err, "Starting service %s", service)
+			}
+			started = append(started, service)
+		}
+
+		if dependencies.HasDependents(job) {
+			err = m.waitForServicesToRun(job, orderedServices[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return started, nil
+}
+
+func (m monitJobSupervisor) waitForServicesToRun(name string, services []string) error {
+	timer := m.timeService.NewTimer(5 * time.Minute)
+
+	for {
+		allServices, err := m.checkServices()
+		if err != nil {
+			return err
+		}
+
+		servicesToRun := m.filterServices(allServices, func(service boshmonit.Service) bool {
+			return containsString(services, service.Name) && (!service.Monitored || service.Pending || service.Status != "running")
+		})
+
+		// Jobs declaring probes are only ready for their dependents
+		// once their probes pass, running processes may still be starting up
+		if len(servicesToRun) == 0 && m.probeManager.Ready(name) {
+			m.logger.Debug(monitJobSupervisorLogTag, "Services of '%s' are running", name)
+			return nil
+		}
+
+		select {
+		case <-timer.C():
+			if len(servicesToRun) == 0 {
+				return bosherr.Errorf("Timed out waiting for probes of '%s' to pass after 5 minutes", name)
+			}
+			return bosherr.Errorf("Timed out waiting for services '%s' of '%s' to run after 5 minutes", strings.Join(servicesToRun, ", "), name)
+		default:
+		}
+
+		m.logger.Debug(monitJobSupervisorLogTag, "Waiting for '%v' of '%s' to run and its probes to pass", servicesToRun, name)
+		m.timeService.Sleep(500 * time.Millisecond)
+	}
+}
+
+func (m monitJobSupervisor) StartJob(name string) error {
+	services, err := m.jobServices(name)
+	if err != nil {
+		return err
+	}
+
+	// Services of other jobs stay stopped
+	if m.fs.FileExists(m.stoppedFilePath()) {
+		allServices, err := m.client.ServicesInGroup("vcap")
+		if err != nil {
+			return bosherr.WrapError(err, "Getting vcap services")
+		}
+
+		err = m.stoppedServices().update(allServices, nil)
+		if err != nil {
+			return err
+		}
+
+		err = m.fs.RemoveAll(m.stoppedFilePath())
+		if err != nil {
+			return bosherr.WrapError(err, "Removing stopped File")
+		}
+	}
+
+	for _, service := range services {
+		m.logger.Debug(monitJobSupervisorLogTag, "Starting service %s", service)
+		err = m.client.StartService(service)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Starting service %s", service)
+		}
+	}
+
+	return m.stoppedServices().update(nil, services)
+}
+
+func (m monitJobSupervisor) StopJob(name string) error {
+	services, err := m.jobServices(name)
+	if err != nil {
+		return err
+	}
+
+	timer := m.timeService.NewTimer(5 * time.Minute)
+
+	for _, service := range services {
+		m.logger.Debug(monitJobSupervisorLogTag, "Stopping service %s", service)
+		err = m.client.StopService(service)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Stopping service %s", service)
+		}
+	}
+
+	err = m.stoppedServices().update(services, nil)
+	if err != nil {
+		return err
+	}
+
+	return m.waitForServicesToStop(name, services, timer)
+}
+
+func (m monitJobSupervisor) waitForServicesToStop(name string, services []string, timer clock.Timer) error {
+	for {
+		allServices, err := m.checkServices()
+		if err != nil {
+			return err
+		}
+
+		erroredServices := m.filterServices(allServices, func(service boshmonit.Service) bool {
+			return containsString(services, service.Name) && service.Errored
+		})
+		servicesToStop := m.filterServices(allServices, func(service boshmonit.Service) bool {
+			return containsString(services, service.Name) && (service.Monitored || service.Pending)
+		})
+
+		if len(erroredServices) > 0 {
+			return bosherr.Errorf("Stopping services '%v' errored", erroredServices)
+		}
+
+		if len(servicesToStop) == 0 {
+			m.logger.Debug(monitJobSupervisorLogTag, "Successfully stopped services of '%s'", name)
+			return nil
+		}
+
+		select {
+		case <-timer.C():
+			return bosherr.Errorf("Timed out waiting for services '%s' to stop after 5 minutes", strings.Join(servicesToStop, ", "))
+		default:
+		}
+
+		m.logger.Debug(monitJobSupervisorLogTag, "Waiting for '%v' to stop", servicesToStop)
+		m.timeService.Sleep(500 * time.Millisecond)
+	}
+}
+
+// stopOrder returns the jobs in the order they start in and their
+// services, or nothing when the jobs declare no dependencies
+func (m monitJobSupervisor) stopOrder() ([]string, [][]string, error) {
+	dependencies, err := loadJobDependencies(m.fs, m.jobDependenciesPath())
+	if err != nil || !dependencies.Declared() {
+		return nil, nil, err
+	}
+
+	jobs, err := loadJobServices(m.fs, m.jobServicesPath())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return orderedServices(dependencies, jobs)
+}
+
+// stopInOrder stops the jobs one at a time in the reverse of the order
+// they start in
+func (m monitJobSupervisor) stopInOrder(
+	order []string,
+	orderedServices [][]string,
+	policies JobStopPolicies,
+	timer clock.Timer,
+	timeout time.Duration,
+) ([]JobStopResult, error) {
+	results := []JobStopResult{}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		stoppedAt := m.timeService.Now()
+
+		for _, service := range orderedServices[i] {
+			m.logger.Debug(monitJobSupervisorLogTag, "Stopping service %s of %s", service, order[i])
+			err := m.client.StopService(service)
+			if err != nil {
+				return nil, bosherr.WrapErrorf(err, "Stopping service %s", service)
+			}
+		}
+
+		jobResults, err := m.waitForJobsToStop(jobServices{order[i]: orderedServices[i]}, false, policies, stoppedAt, timer, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, jobResults...)
+	}
+
+	return results, nil
+}
+
+// waitForJobsToStop waits for the services of each job to stop and kills the
+// processes of jobs that do not stop within the timeout of their stop policy.
+// With otherServices, vcap services which do not belong to any of the jobs are
+// waited for as if they were jobs of their own.
+func (m monitJobSupervisor) waitForJobsToStop(
+	jobs jobServices,
+	otherServices bool,
+	policies JobStopPolicies,
+	stoppedAt time.Time,
+	timer clock.Timer,
+	timeout time.Duration,
+) ([]JobStopResult, error) {
+	pending := jobServices{}
+	for job, services := range jobs {
+		if len(services) > 0 {
+			pending[job] = services
+		}
+	}
+
+	results := []JobStopResult{}
+
+	for first := true; ; first = false {
+		allServices, err := m.checkServices()
+		if err != nil {
+			return nil, err
+		}
+
+		if otherServices && first {
+			for _, service := range allServices {
+				if jobs.jobOf(service.Name) == "" {
+					pending.add(service.Name, service.Name)
+				}
+			}
+		}
+
+		erroredServices := m.filterServices(allServices, func(service boshmonit.Service) bool {
+			return pending.jobOf(service.Name) != "" && service.Errored
+		})
+
+		if len(erroredServices) > 0 {
+			return nil, bosherr.Errorf("Stopping services '%v' errored", erroredServices)
+		}
+
+		names := []string{}
+		for job := range pending {
+			names = append(names, job)
+		}
+		sort.Strings(names)
+
+		for _, job := range names {
+			servicesToStop := []boshmonit.Service{}
+			serviceNames := []string{}
+			for _, service := range allServices {
+				if containsString(pending[job], service.Name) && (service.Monitored || service.Pending) {
+					servicesToStop = append(servicesToStop, service)
+					serviceNames = append(serviceNames, service.Name)
+				}
+			}
+
+			if len(servicesToStop) == 0 {
+				m.logger.Debug(monitJobSupervisorLogTag, "Successfully stopped services of '%s'", job)
+				results = append(results, newJobStopResult(job, JobStopped, m.timeService.Since(stoppedAt)))
+				delete(pending, job)
+				continue
+			}
+
+			policy, found := policies[job]
+			if !found || m.timeService.Since(stoppedAt) < policy.timeout() {
+				continue
+			}
+
+			if !policy.kills() {
+				return nil, bosherr.Errorf("Timed out waiting for services '%s' of '%s' to stop after %s",
+					strings.Join(serviceNames, ", "), job, describeTimeout(policy.timeout()))
+			}
+
+			err = m.killServices(job, servicesToStop, policy.gracePeriod())
+			if err != nil {
+				return nil, err
+			}
+
+			results = append(results, newJobStopResult(job, JobKilled, m.timeService.Since(stoppedAt)))
+			delete(pending, job)
+		}
+
+		if len(pending) == 0 {
+			return sortJobStopResults(results), nil
+		}
+
+		servicesToStop := m.filterServices(allServices, func(service boshmonit.Service) bool {
+			return pending.jobOf(service.Name) != "" && (service.Monitored || service.Pending)
+		})
+
+		select {
+		case <-timer.C():
+			return nil, bosherr.Errorf("Timed out waiting for services '%s' to stop after %s", strings.Join(servicesToStop, ", "), describeTimeout(timeout))
+		default:
+		}
+
+		m.logger.Debug(monitJobSupervisorLogTag, "Waiting for '%v' to stop", servicesToStop)
+		m.timeService.Sleep(500 * time.Millisecond)
+	}
+}
+
+// killServices sends SIGTERM to the process trees of the services, SIGKILL to
+// what is left of them after the grace period, and stops monitoring them
+func (m monitJobSupervisor) killServices(job string, services []boshmonit.Service, gracePeriod time.Duration) error {
+	pids := []int{}
+	for _, service := range services {
+		if service.PID > 0 {
+			pids = append(pids, processTree(m.fs, service.PID)...)
+		}
+	}
+
+	m.logger.Warn(monitJobSupervisorLogTag, "Job '%s' did not stop in time, killing processes %v", job, pids)
+
+	if len(pids) > 0 {
+		m.signal("TERM", pids)
+		m.timeService.Sleep(gracePeriod)
+
+		remaining := []int{}
+		for _, pid := range pids {
+			if m.fs.FileExists(fmt.Sprintf("/proc/%d", pid)) {
+				remaining = append(remaining, pid)
+			}
+		}
+
+		if len(remaining) > 0 {
+			m.signal("KILL", remaining)
+		}
+	}
+
+	for _, service := range services {
+		err := m.client.UnmonitorService(service.Name)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Unmonitoring service %s", service.Name)
+		}
+	}
+
+	return nil
+}
+
+func (m monitJobSupervisor) signal(signal string, pids []int) {
+	args := []string{"-" + signal}
+	for _, pid := range pids {
+		args = append(args, strconv.Itoa(pid))
+	}
+
+	// Processes which already exited make kill fail
+	_, _, _, err := m.runner.RunCommand("kill", args...)
+	if err != nil {
+		m.logger.Debug(monitJobSupervisorLogTag, "Sending SIG%s to %v: %s", signal, pids, err.Error())
+	}
+}
+
 func (m monitJobSupervisor) Stop() error {
 	services, err := m.client.ServicesInGroup("vcap")
 	if err != nil {
@@ -179,13 +576,30 @@ func (m monitJobSupervisor) Stop() error {
 	return nil
 }
 
-func (m monitJobSupervisor) StopAndWait() error {
-	timer := m.timeService.NewTimer(5 * time.Minute)
+func (m monitJobSupervisor) StopAndWait() ([]JobStopResult, error) {
+	policies, err := loadJobStopPolicies(m.fs, m.jobStopPoliciesPath())
+	if err != nil {
+		return nil, err
+	}
+
+	order, orderedServices, err := m.stopOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	// Jobs stopped in order are waited for one after the other,
+	// before the remaining jobs are waited for together
+	timeout := policies.longestWait()
+	for _, job := range order {
+		timeout += policies.waitOf(job)
+	}
+
+	timer := m.timeService.NewTimer(timeout)
 
 	for {
 		services, err := m.checkServices()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		pendingServices := m.filterServices(services, func(service boshmonit.Service) bool {
@@ -198,14 +612,21 @@ func (m monitJobSupervisor) StopAndWait() error {
 
 		select {
 		case <-timer.C():
-			return bosherr.Errorf("Timed out waiting for services '%s' to no longer be pending after 5 minutes", strings.Join(pendingServices, ", "))
+			return nil, bosherr.Errorf("Timed out waiting for services '%s' to no longer be pending after %s", strings.Join(pendingServices, ", "), describeTimeout(timeout))
 		default:
 		}
 
 		m.timeService.Sleep(500 * time.Millisecond)
 	}
 
-	_, _, _, err := m.runner.RunCommand("monit", "stop", "-g", "vcap")
+	results, err := m.stopInOrder(order, orderedServices, policies, timer, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	stoppedAt := m.timeService.Now()
+
+	_, _, _, err = m.runner.RunCommand("monit", "stop", "-g", "vcap")
 	if err != nil {
 		stdout, stderr, _, summaryError := m.runner.RunCommand("monit", "summary")
 		if summaryError != nil {
@@ -214,44 +635,41 @@ func (m monitJobSupervisor) StopAndWait() error {
 			m.logger.Error(monitJobSupervisorLogTag, "Failed to stop jobs: %s. Current monit summary:\nstdout:\n%sstderr:\n%s", err.Error(), stdout, stderr)
 		}
 
-		return bosherr.WrapErrorf(err, "Stop all services")
+		return nil, bosherr.WrapErrorf(err, "Stop all services")
 	}
 
 	err = m.fs.WriteFileString(m.stoppedFilePath(), "")
 	if err != nil {
-		return bosherr.WrapError(err, "Creating stopped File")
+		return nil, bosherr.WrapError(err, "Creating stopped File")
 	}
+
+	jobs, err := loadJobServices(m.fs, m.jobServicesPath())
+	if err != nil {
+		return nil, err
+	}
+
 	m.logger.Debug(monitJobSupervisorLogTag, "Waiting for services to stop")
-	for {
-		services, err := m.checkServices()
-		if err != nil {
-			return err
-		}
-		erroredServices := m.filterServices(services, func(service boshmonit.Service) bool {
-			return service.Errored
-		})
-		servicesToStop := m.filterServices(services, func(service boshmonit.Service) bool {
-			return service.Monitored || service.Pending
-		})
 
-		if len(erroredServices) > 0 {
-			return bosherr.Errorf("Stopping services '%v' errored", erroredServices)
-		}
+	remaining, err := m.waitForJobsToStop(jobs, true, policies, stoppedAt, timer, timeout)
+	if err != nil {
+		return nil, err
+	}
 
-		if len(servicesToStop) == 0 {
-			m.logger.Debug(monitJobSupervisorLogTag, "Successfully stopped all services")
-			return nil
+	// Jobs stopped in order were already reported
+	for _, result := range remaining {
+		reported := false
+		for _, stopped := range results {
+			reported = reported || stopped.Job == result.Job
 		}
 
-		select {
-		case <-timer.C():
-			return bosherr.Errorf("Timed out waiting for services '%s' to stop after 5 minutes", strings.Join(servicesToStop, ", "))
-		default:
+		if !reported {
+			results = append(results, result)
 		}
-
-		m.logger.Debug(monitJobSupervisorLogTag, "Waiting for '%v' to stop", servicesToStop)
-		m.timeService.Sleep(500 * time.Millisecond)
 	}
+
+	m.logger.Debug(monitJobSupervisorLogTag, "Successfully stopped all services")
+
+	return sortJobStopResults(results), nil
 }
 
 func (m monitJobSupervisor) Unmonitor() error {
@@ -284,8 +702,15 @@ func (m monitJobSupervisor) Status() (status string) {
 	if m.fs.FileExists(m.stoppedFilePath()) {
 		status = "stopped"
 	} else {
+		stopped := m.stoppedServices().load()
 		services := monitStatus.ServicesInGroup("vcap")
+		supervised := 0
 		for _, service := range services {
+			// Services stopped on their own are not failing
+			if stopped[service.Name] {
+				continue
+			}
+			supervised++
 			if service.Status == "starting" {
 				return "starting"
 			}
@@ -293,11 +718,50 @@ func (m monitJobSupervisor) Status() (status string) {
 				status = "failing"
 			}
 		}
+		if len(services) > 0 && supervised == 0 {
+			status = "stopped"
+		}
 	}
 
 	return
 }
 
+func (m monitJobSupervisor) JobStatuses() map[string]string {
+	jobs, err := loadJobServices(m.fs, m.jobServicesPath())
+	if err != nil {
+		m.logger.Warn(monitJobSupervisorLogTag, "Failed to load job services: %s", err.Error())
+		return map[string]string{}
+	}
+
+	monitStatus, err := m.client.Status()
+	if err != nil {
+		statuses := map[string]string{}
+		for job := range jobs {
+			statuses[job] = "unknown"
+		}
+		return statuses
+	}
+
+	allStopped := m.fs.FileExists(m.stoppedFilePath())
+	stopped := m.stoppedServices().load()
+	states := map[string]string{}
+
+	for _, service := range monitStatus.ServicesInGroup("vcap") {
+		switch {
+		case allStopped || stopped[service.Name]:
+			states[service.Name] = "stopped"
+		case service.Status == "starting":
+			states[service.Name] = "starting"
+		case !service.Monitored || service.Status != "running":
+			states[service.Name] = "failing"
+		default:
+			states[service.Name] = "running"
+		}
+	}
+
+	return jobs.statuses(states)
+}
+
 func (m monitJobSupervisor) Processes() (processes []Process, err error) {
 	processes = []Process{}
 
@@ -306,10 +770,17 @@ func (m monitJobSupervisor) Processes() (processes []Process, err error) {
 		return processes, bosherr.WrapError(err, "Getting service status")
 	}
 
+	jobs, err := loadJobServices(m.fs, m.jobServicesPath())
+	if err != nil {
+		m.logger.Warn(monitJobSupervisorLogTag, "Failed to load job services: %s", err.Error())
+	}
+
 	for _, service := range monitStatus.ServicesInGroup("vcap") {
 		process := Process{
 			Name:  service.Name,
+			Job:   jobs.jobOf(service.Name),
 			State: service.Status,
+			PID:   service.PID,
 			Uptime: UptimeVitals{
 				Secs: service.Uptime,
 			},
@@ -345,18 +816,56 @@ func (m monitJobSupervisor) AddJob(jobName string, jobIndex int, configPath stri
 		return bosherr.WrapError(err, "Reading job config from file")
 	}
 
-	err = m.fs.WriteFile(targetConfigPath, configContent)
+	job := jobNameForConfig(jobName, configPath)
+	launcher := m.cgroupManager.JobLauncher(job)
+
+	err = m.fs.WriteFileString(targetConfigPath, confineStartPrograms(string(configContent), launcher))
 	if err != nil {
 		return bosherr.WrapError(err, "Writing to job config file")
 	}
 
-	return nil
+	jobs, err := loadJobServices(m.fs, m.jobServicesPath())
+	if err != nil {
+		return err
+	}
+
+	jobs.add(job, monitProcessNames(string(configContent))...)
+
+	return jobs.save(m.fs, m.jobServicesPath())
 }
 
 func (m monitJobSupervisor) RemoveAllJobs() error {
+	err := m.fs.RemoveAll(m.jobServicesPath())
+	if err != nil {
+		return bosherr.WrapError(err, "Removing job services")
+	}
+
+	err = m.fs.RemoveAll(m.jobDependenciesPath())
+	if err != nil {
+		return bosherr.WrapError(err, "Removing job dependencies")
+	}
+
+	err = m.fs.RemoveAll(m.jobStopPoliciesPath())
+	if err != nil {
+		return bosherr.WrapError(err, "Removing job stop policies")
+	}
+
+	err = m.stoppedServices().clear()
+	if err != nil {
+		return bosherr.WrapError(err, "Removing stopped services")
+	}
+
 	return m.fs.RemoveAll(m.dirProvider.MonitJobsDir())
 }
 
+func (m monitJobSupervisor) SetJobDependencies(dependencies JobDependencies) error {
+	return dependencies.save(m.fs, m.jobDependenciesPath())
+}
+
+func (m monitJobSupervisor) SetJobStopPolicies(policies JobStopPolicies) error {
+	return policies.save(m.fs, m.jobStopPoliciesPath())
+}
+
 func (m monitJobSupervisor) MonitorJobFailures(handler JobFailureHandler) (err error) {
 	alertHandler := func(smtpd.Connection, smtpd.MailAddress) (env smtpd.Envelope, err error) {
 		env = &alertEnvelope{
@@ -383,6 +892,31 @@ func (m monitJobSupervisor) stoppedFilePath() string {
 	return path.Join(m.dirProvider.MonitDir(), "stopped")
 }
 
+func (m monitJobSupervisor) jobServicesPath() string {
+	return path.Join(m.dirProvider.MonitDir(), "jobs.json")
+}
+
+func (m monitJobSupervisor) jobDependenciesPath() string {
+	return path.Join(m.dirProvider.MonitDir(), "job_dependencies.json")
+}
+
+func (m monitJobSupervisor) jobStopPoliciesPath() string {
+	return path.Join(m.dirProvider.MonitDir(), "job_stop_policies.json")
+}
+
+func (m monitJobSupervisor) stoppedServices() stoppedServices {
+	return stoppedServices{fs: m.fs, path: path.Join(m.dirProvider.MonitDir(), "stopped_services")}
+}
+
+func (m monitJobSupervisor) jobServices(name string) ([]string, error) {
+	jobs, err := loadJobServices(m.fs, m.jobServicesPath())
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs.resolve(name)
+}
+
 func (m monitJobSupervisor) filterServices(services []boshmonit.Service, fn func(boshmonit.Service) bool) []string {
 	matchingServices := []string{}
 	for _, service := range services {
@@ -406,3 +940,23 @@ func (m monitJobSupervisor) checkServices() ([]boshmonit.Service, error) {
 
 func (m monitJobSupervisor) HealthRecorder(status string) {
 }
+
+// confineStartPrograms has monit start the programs of a job with launcher
+// so they start in the cgroup of their job
+func confineStartPrograms(config string, launcher string) string {
+	if launcher == "" {
+		return config
+	}
+
+	return monitStartProgramRegexp.ReplaceAllStringFunc(config, func(startProgram string) string {
+		matches := monitStartProgramRegexp.FindStringSubmatchIndex(startProgram)
+
+		// Quotes of the launcher must not end the quoted program early
+		programStart, programLauncher := matches[2], strings.ReplaceAll(launcher, `"`, `'`)
+		if programStart < 0 {
+			programStart, programLauncher = matches[4], strings.ReplaceAll(launcher, `'`, `"`)
+		}
+
+		return startProgram[:programStart] + programLauncher + " " + startProgram[programStart:]
+	})
+}
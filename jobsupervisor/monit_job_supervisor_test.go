@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"net/smtp"
 	"os"
+	"slices"
 	"time"
 
 	"code.cloudfoundry.org/clock/fakeclock"
@@ -18,8 +19,10 @@ import (
 
 	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
 	. "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup/cgroupfakes"
 	boshmonit "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/monit"
 	fakemonit "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/monit/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe/probefakes"
 	"github.com/cloudfoundry/bosh-agent/v2/servicemanager/servicemanagerfakes"
 	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -37,6 +40,8 @@ var _ = Describe("monitJobSupervisor", func() {
 		monit                 JobSupervisor
 		timeService           *fakeclock.FakeClock
 		serviceManager        *servicemanagerfakes.FakeServiceManager
+		cgroupManager         *cgroupfakes.FakeManager
+		probeManager          *probefakes.FakeManager
 	)
 
 	var jobFailureServerPort = 5000
@@ -58,6 +63,9 @@ var _ = Describe("monitJobSupervisor", func() {
 		jobFailuresServerPort = getJobFailureServerPort()
 		timeService = fakeclock.NewFakeClock(time.Now())
 		serviceManager = &servicemanagerfakes.FakeServiceManager{}
+		cgroupManager = &cgroupfakes.FakeManager{}
+		probeManager = &probefakes.FakeManager{}
+		probeManager.ReadyReturns(true)
 
 		monit = NewMonitJobSupervisor(
 			fs,
@@ -73,6 +81,8 @@ var _ = Describe("monitJobSupervisor", func() {
 			},
 			timeService,
 			serviceManager,
+			cgroupManager,
+			probeManager,
 		)
 	})
 
@@ -247,7 +257,7 @@ var _ = Describe("monitJobSupervisor", func() {
 
 	Describe("StopAndWait", func() {
 		It("stop stops each monit service in group vcap", func() {
-			err := monit.StopAndWait()
+			_, err := monit.StopAndWait()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(runner.RunCommands)).To(Equal(1))
 			Expect(runner.RunCommands[0]).To(Equal([]string{"monit", "stop", "-g", "vcap"}))
@@ -294,9 +304,11 @@ var _ = Describe("monitJobSupervisor", func() {
 				},
 				timeService,
 				serviceManager,
+				cgroupManager,
+				probeManager,
 			)
 
-			err := monit.StopAndWait()
+			_, err := monit.StopAndWait()
 			Expect(err).To(BeNil())
 		})
 
@@ -311,7 +323,8 @@ var _ = Describe("monitJobSupervisor", func() {
 
 				errchan := make(chan error)
 				go func() {
-					errchan <- monit.StopAndWait()
+					_, err := monit.StopAndWait()
+					errchan <- err
 				}()
 
 				Eventually(timeService.WatcherCount).Should(Equal(2)) // we hit the sleep
@@ -354,7 +367,8 @@ var _ = Describe("monitJobSupervisor", func() {
 
 				errchan := make(chan error)
 				go func() {
-					errchan <- monit.StopAndWait()
+					_, err := monit.StopAndWait()
+					errchan <- err
 				}()
 
 				failureMessage := "Timed out waiting for services 'foo' to no longer be pending after 5 minutes"
@@ -374,7 +388,8 @@ var _ = Describe("monitJobSupervisor", func() {
 
 				errchan := make(chan error)
 				go func() {
-					errchan <- monit.StopAndWait()
+					_, err := monit.StopAndWait()
+					errchan <- err
 				}()
 
 				Eventually(timeService.WatcherCount).Should(Equal(2)) // we hit the pending sleep
@@ -404,7 +419,8 @@ var _ = Describe("monitJobSupervisor", func() {
 
 				errchan := make(chan error)
 				go func() {
-					errchan <- monit.StopAndWait()
+					_, err := monit.StopAndWait()
+					errchan <- err
 				}()
 
 				Eventually(timeService.WatcherCount).Should(Equal(2)) // we hit the sleep
@@ -428,7 +444,8 @@ var _ = Describe("monitJobSupervisor", func() {
 
 				errchan := make(chan error)
 				go func() {
-					errchan <- monit.StopAndWait()
+					_, err := monit.StopAndWait()
+					errchan <- err
 				}()
 
 				Eventually(timeService.WatcherCount).Should(Equal(2)) // we hit the sleep
@@ -452,7 +469,7 @@ var _ = Describe("monitJobSupervisor", func() {
 
 				runner.AddCmdResult("monit stop -g vcap", fakeErrorResult)
 
-				err := monit.StopAndWait()
+				_, err := monit.StopAndWait()
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal(fmt.Sprintf("%s%s", "Stop all services: ", fakeErrorResult.Error)))
 			})
@@ -500,9 +517,11 @@ var _ = Describe("monitJobSupervisor", func() {
 					},
 					timeService,
 					serviceManager,
+					cgroupManager,
+					probeManager,
 				)
 
-				err := monit.StopAndWait()
+				_, err := monit.StopAndWait()
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("Stopping services '[test-service]' errored"))
 			})
@@ -552,11 +571,14 @@ var _ = Describe("monitJobSupervisor", func() {
 					MonitReloadOptions{},
 					timeService,
 					serviceManager,
+					cgroupManager,
+					probeManager,
 				)
 
 				errchan := make(chan error)
 				go func() {
-					errchan <- monit.StopAndWait()
+					_, err := monit.StopAndWait()
+					errchan <- err
 				}()
 
 				failureMessage := "Timed out waiting for services 'unmonitored-start-pending, initializing, running, running-stop-pending, unmonitored-stop-pending, failing' to stop after 5 minutes"
@@ -569,7 +591,7 @@ var _ = Describe("monitJobSupervisor", func() {
 		})
 
 		It("creates stopped file", func() {
-			err := monit.StopAndWait()
+			_, err := monit.StopAndWait()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(fs.FileExists("/var/vcap/monit/stopped")).To(BeTrue())
 		})
@@ -656,6 +678,417 @@ var _ = Describe("monitJobSupervisor", func() {
 		})
 	})
 
+	Describe("StartJob and StopJob", func() {
+		BeforeEach(func() {
+			err := fs.WriteFileString("/var/vcap/jobs/router/monit", "check process router\n  group vcap\ncheck process router-worker\n  group vcap\n")
+			Expect(err).NotTo(HaveOccurred())
+			err = fs.WriteFileString("/var/vcap/jobs/router/metrics.monit", "check process router-metrics\n  group vcap\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(monit.AddJob("router", 0, "/var/vcap/jobs/router/monit")).To(Succeed())
+			Expect(monit.AddJob("router_metrics", 1, "/var/vcap/jobs/router/metrics.monit")).To(Succeed())
+		})
+
+		It("stops the services of a job and waits for them to stop", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "router", Monitored: false},
+					{Name: "router-worker", Monitored: false},
+					{Name: "router-metrics", Monitored: false},
+					{Name: "other", Monitored: true, Status: "running"},
+				},
+			}
+
+			Expect(monit.StopJob("router")).To(Succeed())
+			Expect(client.StopServiceNames).To(Equal([]string{"router", "router-worker", "router-metrics"}))
+			Expect(monit.Status()).To(Equal("running"))
+		})
+
+		It("stops a single service", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{{Name: "router-worker", Monitored: false}},
+			}
+
+			Expect(monit.StopJob("router-worker")).To(Succeed())
+			Expect(client.StopServiceNames).To(Equal([]string{"router-worker"}))
+		})
+
+		It("returns an error for unknown jobs", func() {
+			err := monit.StopJob("unknown")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unknown job or process 'unknown'"))
+		})
+
+		It("returns an error when a service errors while stopping", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{{Name: "router", Monitored: true, Errored: true}},
+			}
+
+			err := monit.StopJob("router")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Stopping services '[router]' errored"))
+		})
+
+		It("starts the services of a job, keeping other jobs stopped", func() {
+			client.ServicesInGroupServices = []string{"router", "router-worker", "router-metrics", "other"}
+			Expect(fs.WriteFileString("/var/vcap/monit/stopped", "")).To(Succeed())
+
+			Expect(monit.StartJob("router")).To(Succeed())
+			Expect(client.StartServiceNames).To(Equal([]string{"router", "router-worker", "router-metrics"}))
+			Expect(fs.FileExists("/var/vcap/monit/stopped")).To(BeFalse())
+
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "router", Monitored: true, Status: "running"},
+					{Name: "router-worker", Monitored: true, Status: "running"},
+					{Name: "router-metrics", Monitored: true, Status: "running"},
+					{Name: "other", Monitored: false},
+				},
+			}
+			Expect(monit.Status()).To(Equal("running"))
+		})
+
+		It("reports the job of each process", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "router-metrics", Monitored: true, Status: "running", PID: 42},
+					{Name: "other", Monitored: true, Status: "running"},
+				},
+			}
+
+			processes, err := monit.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes).To(Equal([]Process{
+				{Name: "router-metrics", Job: "router", State: "running", PID: 42},
+				{Name: "other", State: "running"},
+			}))
+		})
+
+		It("reports per-job statuses", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "router", Monitored: true, Status: "running"},
+					{Name: "router-worker", Monitored: true, Status: "failing"},
+					{Name: "router-metrics", Monitored: true, Status: "running"},
+				},
+			}
+			Expect(monit.JobStatuses()).To(Equal(map[string]string{"router": "failing"}))
+
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "router", Monitored: false},
+					{Name: "router-worker", Monitored: false},
+					{Name: "router-metrics", Monitored: false},
+				},
+			}
+			Expect(monit.StopJob("router")).To(Succeed())
+			Expect(monit.JobStatuses()).To(Equal(map[string]string{"router": "stopped"}))
+			Expect(monit.Status()).To(Equal("stopped"))
+		})
+
+		It("forgets jobs and stopped services when all jobs are removed", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{{Name: "router", Monitored: false}},
+			}
+			Expect(monit.StopJob("router")).To(Succeed())
+
+			Expect(monit.RemoveAllJobs()).To(Succeed())
+			Expect(fs.FileExists("/var/vcap/monit/jobs.json")).To(BeFalse())
+			Expect(fs.FileExists("/var/vcap/monit/stopped_services")).To(BeFalse())
+			Expect(monit.JobStatuses()).To(BeEmpty())
+		})
+	})
+
+	Describe("job dependencies", func() {
+		BeforeEach(func() {
+			Expect(fs.WriteFileString("/var/vcap/jobs/db/monit", "check process postgres\n  group vcap\n")).To(Succeed())
+			Expect(fs.WriteFileString("/var/vcap/jobs/proxy/monit", "check process haproxy\n  group vcap\n")).To(Succeed())
+			Expect(fs.WriteFileString("/var/vcap/jobs/app/monit", "check process app\n  group vcap\n")).To(Succeed())
+
+			Expect(monit.AddJob("app", 0, "/var/vcap/jobs/app/monit")).To(Succeed())
+			Expect(monit.AddJob("proxy", 1, "/var/vcap/jobs/proxy/monit")).To(Succeed())
+			Expect(monit.AddJob("db", 2, "/var/vcap/jobs/db/monit")).To(Succeed())
+
+			Expect(monit.SetJobDependencies(JobDependencies{
+				"app":   {"proxy"},
+				"proxy": {"db"},
+				"db":    nil,
+			})).To(Succeed())
+
+			client.ServicesInGroupServices = []string{"app", "haproxy", "postgres", "other"}
+		})
+
+		It("starts jobs after the jobs they depend on are running", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "postgres", Monitored: true, Status: "running"},
+					{Name: "haproxy", Monitored: true, Status: "running"},
+				},
+			}
+
+			Expect(monit.Start()).To(Succeed())
+			Expect(client.StartServiceNames).To(Equal([]string{"postgres", "haproxy", "app", "other"}))
+		})
+
+		It("starts jobs after the probes of the jobs they depend on pass", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "postgres", Monitored: true, Status: "running"},
+					{Name: "haproxy", Monitored: true, Status: "running"},
+				},
+			}
+			probeManager.ReadyStub = func(jobName string) bool {
+				return jobName != "db" || probeManager.ReadyCallCount() > 1
+			}
+
+			errCh := make(chan error)
+			go func() {
+				errCh <- monit.Start()
+			}()
+
+			Eventually(probeManager.ReadyCallCount).Should(Equal(1))
+			Expect(client.StartServiceNames).To(Equal([]string{"postgres"}))
+
+			advanceTime(timeService, 500*time.Millisecond, 2)
+
+			Eventually(errCh).Should(Receive(BeNil()))
+			Expect(client.StartServiceNames).To(Equal([]string{"postgres", "haproxy", "app", "other"}))
+			Expect(probeManager.ReadyArgsForCall(0)).To(Equal("db"))
+			Expect(probeManager.ReadyArgsForCall(1)).To(Equal("db"))
+		})
+
+		It("times out when the probes of a job others depend on do not pass", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{{Name: "postgres", Monitored: true, Status: "running"}},
+			}
+			probeManager.ReadyReturns(false)
+
+			errCh := make(chan error)
+			go func() {
+				errCh <- monit.Start()
+			}()
+
+			advanceTime(timeService, 5*time.Minute, 2)
+
+			var err error
+			Eventually(errCh).Should(Receive(&err))
+			Expect(err.Error()).To(Equal("Timed out waiting for probes of 'db' to pass after 5 minutes"))
+			Expect(client.StartServiceNames).To(Equal([]string{"postgres"}))
+		})
+
+		It("times out when a job others depend on does not run", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{{Name: "postgres", Monitored: true, Status: "initializing"}},
+			}
+
+			errCh := make(chan error)
+			go func() {
+				errCh <- monit.Start()
+			}()
+
+			advanceTime(timeService, 5*time.Minute, 2)
+
+			var err error
+			Eventually(errCh).Should(Receive(&err))
+			Expect(err.Error()).To(Equal("Timed out waiting for services 'postgres' of 'db' to run after 5 minutes"))
+			Expect(client.StartServiceNames).To(Equal([]string{"postgres"}))
+		})
+
+		It("stops jobs in reverse order before stopping all services", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "postgres", Monitored: false},
+					{Name: "haproxy", Monitored: false},
+					{Name: "app", Monitored: false},
+				},
+			}
+
+			_, err := monit.StopAndWait()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.StopServiceNames).To(Equal([]string{"app", "haproxy", "postgres"}))
+			Expect(runner.RunCommands).To(ContainElement([]string{"monit", "stop", "-g", "vcap"}))
+		})
+
+		It("gives each job stopped in order its own time to stop", func() {
+			stoppedAt := map[string]time.Time{}
+			client.StatusStub = func() (boshmonit.Status, error) {
+				services := []boshmonit.Service{}
+				for _, name := range []string{"postgres", "haproxy", "app"} {
+					if _, found := stoppedAt[name]; !found && slices.Contains(client.StopServiceNames, name) {
+						stoppedAt[name] = timeService.Now()
+					}
+
+					// Every job takes 4 of its 5 minutes to stop
+					at, stopping := stoppedAt[name]
+					monitored := !stopping || timeService.Since(at) < 4*time.Minute
+					services = append(services, boshmonit.Service{Name: name, Monitored: monitored})
+				}
+				return fakemonit.FakeMonitStatus{Services: services}, nil
+			}
+
+			errCh := make(chan error, 1)
+			go func() {
+				_, err := monit.StopAndWait()
+				errCh <- err
+			}()
+
+			var err error
+			Eventually(func() bool {
+				select {
+				case err = <-errCh:
+					return true
+				default:
+				}
+
+				if timeService.WatcherCount() == 2 {
+					timeService.Increment(time.Minute)
+				}
+				return false
+			}).Should(BeTrue())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.StopServiceNames).To(Equal([]string{"app", "haproxy", "postgres"}))
+		})
+
+		It("forgets the dependencies when all jobs are removed", func() {
+			Expect(monit.RemoveAllJobs()).To(Succeed())
+			Expect(fs.FileExists("/var/vcap/monit/job_dependencies.json")).To(BeFalse())
+		})
+	})
+
+	Describe("job stop policies", func() {
+		BeforeEach(func() {
+			Expect(fs.WriteFileString("/var/vcap/jobs/db/monit", "check process postgres\n  group vcap\n")).To(Succeed())
+			Expect(fs.WriteFileString("/var/vcap/jobs/app/monit", "check process app\n  group vcap\n")).To(Succeed())
+
+			Expect(monit.AddJob("app", 0, "/var/vcap/jobs/app/monit")).To(Succeed())
+			Expect(monit.AddJob("db", 1, "/var/vcap/jobs/db/monit")).To(Succeed())
+		})
+
+		It("reports how long each job took to stop", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "postgres", Monitored: true},
+					{Name: "app", Monitored: false},
+				},
+			}
+
+			resultsCh := make(chan []JobStopResult)
+			go func() {
+				defer GinkgoRecover()
+				results, err := monit.StopAndWait()
+				Expect(err).ToNot(HaveOccurred())
+				resultsCh <- results
+			}()
+
+			Eventually(timeService.WatcherCount).Should(Equal(2)) // we hit the stop sleep
+
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "postgres", Monitored: false},
+					{Name: "app", Monitored: false},
+				},
+			}
+			timeService.Increment(30 * time.Second)
+
+			Eventually(resultsCh).Should(Receive(Equal([]JobStopResult{
+				{Job: "app", Result: "stopped", Duration: 0},
+				{Job: "db", Result: "stopped", Duration: 30},
+			})))
+		})
+
+		It("times out waiting for a job after the timeout of its policy", func() {
+			Expect(monit.SetJobStopPolicies(JobStopPolicies{"db": {Timeout: 90}})).To(Succeed())
+
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{{Name: "postgres", Monitored: true}},
+			}
+
+			errchan := make(chan error)
+			go func() {
+				_, err := monit.StopAndWait()
+				errchan <- err
+			}()
+
+			advanceTime(timeService, 90*time.Second, 2)
+
+			Eventually(errchan).Should(Receive(Equal(errors.New("Timed out waiting for services 'postgres' of 'db' to stop after 1m30s"))))
+			Expect(runner.RunCommands).To(Equal([][]string{{"monit", "stop", "-g", "vcap"}}))
+		})
+
+		It("waits longer than 5 minutes for jobs with a longer timeout", func() {
+			Expect(monit.SetJobStopPolicies(JobStopPolicies{"db": {Timeout: 600}})).To(Succeed())
+
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{{Name: "postgres", Monitored: true}},
+			}
+
+			errchan := make(chan error)
+			go func() {
+				_, err := monit.StopAndWait()
+				errchan <- err
+			}()
+
+			advanceTime(timeService, 6*time.Minute, 2)
+			Eventually(timeService.WatcherCount).Should(Equal(2)) // still waiting
+			Consistently(errchan).ShouldNot(Receive())
+
+			timeService.Increment(4 * time.Minute)
+
+			Eventually(errchan).Should(Receive(Equal(errors.New("Timed out waiting for services 'postgres' of 'db' to stop after 10 minutes"))))
+		})
+
+		It("kills the process trees of jobs that do not stop in time", func() {
+			Expect(monit.SetJobStopPolicies(JobStopPolicies{
+				"db": {Timeout: 60, Escalation: "kill", GracePeriod: 5},
+			})).To(Succeed())
+
+			Expect(fs.WriteFileString("/proc/100/stat", "100 (postgres) S 1 100 100")).To(Succeed())
+			Expect(fs.WriteFileString("/proc/101/stat", "101 (postgres: writer) S 100 100 100")).To(Succeed())
+			Expect(fs.WriteFileString("/proc/102/stat", "102 (bash) S 1 102 102")).To(Succeed())
+			fs.SetGlob("/proc/[0-9]*/stat", []string{"/proc/100/stat", "/proc/101/stat", "/proc/102/stat"})
+
+			client.UnmonitorServiceErrs = []error{nil}
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					{Name: "postgres", Monitored: true, PID: 100},
+					{Name: "app", Monitored: false},
+				},
+			}
+
+			resultsCh := make(chan []JobStopResult)
+			go func() {
+				defer GinkgoRecover()
+				results, err := monit.StopAndWait()
+				Expect(err).ToNot(HaveOccurred())
+				resultsCh <- results
+			}()
+
+			advanceTime(timeService, 60*time.Second, 2)
+
+			Eventually(timeService.WatcherCount).Should(Equal(2)) // we hit the grace period sleep
+			Expect(runner.RunCommands).To(ContainElement([]string{"kill", "-TERM", "100", "101"}))
+			Expect(fs.RemoveAll("/proc/100")).To(Succeed())
+
+			timeService.Increment(5 * time.Second)
+
+			Eventually(resultsCh).Should(Receive(Equal([]JobStopResult{
+				{Job: "app", Result: "stopped", Duration: 0},
+				{Job: "db", Result: "killed", Duration: 65},
+			})))
+			Expect(runner.RunCommands).To(ContainElement([]string{"kill", "-KILL", "101"}))
+			Expect(client.UnmonitorServiceNames).To(Equal([]string{"postgres"}))
+		})
+
+		It("forgets the stop policies when all jobs are removed", func() {
+			Expect(monit.SetJobStopPolicies(JobStopPolicies{"db": {Timeout: 60}})).To(Succeed())
+			Expect(fs.FileExists("/var/vcap/monit/job_stop_policies.json")).To(BeTrue())
+
+			Expect(monit.RemoveAllJobs()).To(Succeed())
+			Expect(fs.FileExists("/var/vcap/monit/job_stop_policies.json")).To(BeFalse())
+		})
+	})
+
 	Describe("Processes", func() {
 		It("returns all processes", func() {
 			client.StatusStatus = fakemonit.FakeMonitStatus{
@@ -664,6 +1097,7 @@ var _ = Describe("monitJobSupervisor", func() {
 						Name:                 "fake-service-1",
 						Monitored:            true,
 						Status:               "running",
+						PID:                  4321,
 						Uptime:               1234,
 						MemoryPercentTotal:   0.4,
 						MemoryKilobytesTotal: 100,
@@ -687,6 +1121,7 @@ var _ = Describe("monitJobSupervisor", func() {
 				Process{
 					Name:  "fake-service-1",
 					State: "running",
+					PID:   4321,
 					Uptime: UptimeVitals{
 						Secs: 1234,
 					},
@@ -798,6 +1233,57 @@ var _ = Describe("monitJobSupervisor", func() {
 					Expect(err).ToNot(HaveOccurred())
 					Expect(writtenConfig).To(Equal("fake-config"))
 				})
+
+				It("has monit start the programs of the job in the cgroup of the job", func() {
+					Expect(fs.WriteFileString("/var/vcap/jobs/router/monit", `check process router
+  with pidfile /var/vcap/sys/run/router/router.pid
+  start program "/var/vcap/jobs/router/bin/ctl start" with timeout 60 seconds
+  stop program "/var/vcap/jobs/router/bin/ctl stop"
+  group vcap
+`)).To(Succeed())
+					cgroupManager.JobLauncherReturns("fake-launcher /fake/cgroup.procs")
+
+					Expect(monit.AddJob("router", 0, "/var/vcap/jobs/router/monit")).To(Succeed())
+
+					Expect(cgroupManager.JobLauncherArgsForCall(0)).To(Equal("router"))
+
+					writtenConfig, err := fs.ReadFileString(dirProvider.MonitJobsDir() + "/0000_router.monitrc")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(writtenConfig).To(Equal(`check process router
+  with pidfile /var/vcap/sys/run/router/router.pid
+  start program "fake-launcher /fake/cgroup.procs /var/vcap/jobs/router/bin/ctl start" with timeout 60 seconds
+  stop program "/var/vcap/jobs/router/bin/ctl stop"
+  group vcap
+`))
+				})
+				It("confines start programs in any case and quoted either way", func() {
+					Expect(fs.WriteFileString("/var/vcap/jobs/router/monit", `check process router
+  with pidfile /var/vcap/sys/run/router/router.pid
+  START PROGRAM = '/var/vcap/jobs/router/bin/ctl start'
+  stop program '/var/vcap/jobs/router/bin/ctl stop'
+`)).To(Succeed())
+					cgroupManager.JobLauncherReturns(`/bin/sh -c 'exec $@' /fake/cgroup.procs`)
+
+					Expect(monit.AddJob("router", 0, "/var/vcap/jobs/router/monit")).To(Succeed())
+
+					writtenConfig, err := fs.ReadFileString(dirProvider.MonitJobsDir() + "/0000_router.monitrc")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(writtenConfig).To(Equal(`check process router
+  with pidfile /var/vcap/sys/run/router/router.pid
+  START PROGRAM = '/bin/sh -c "exec $@" /fake/cgroup.procs /var/vcap/jobs/router/bin/ctl start'
+  stop program '/var/vcap/jobs/router/bin/ctl stop'
+`))
+				})
+
+				It("leaves start programs of jobs without a launcher as they are", func() {
+					Expect(fs.WriteFileString("/var/vcap/jobs/router/monit", `start program "/var/vcap/jobs/router/bin/ctl start"`)).To(Succeed())
+
+					Expect(monit.AddJob("router", 0, "/var/vcap/jobs/router/monit")).To(Succeed())
+
+					writtenConfig, err := fs.ReadFileString(dirProvider.MonitJobsDir() + "/0000_router.monitrc")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(writtenConfig).To(Equal(`start program "/var/vcap/jobs/router/bin/ctl start"`))
+				})
 			})
 
 			Context("when writing job configuration fails", func() {
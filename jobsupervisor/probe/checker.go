@@ -0,0 +1,82 @@
+package probe
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+type concreteChecker struct {
+	runner boshsys.CmdRunner
+}
+
+func NewChecker(runner boshsys.CmdRunner) Checker {
+	return concreteChecker{runner: runner}
+}
+
+func (c concreteChecker) Check(config Config) error {
+	switch config.Type {
+	case TypeHTTP:
+		return c.checkHTTP(config)
+	case TypeTCP:
+		return c.checkTCP(config)
+	case TypeExec:
+		return c.checkExec(config)
+	}
+
+	return bosherr.Errorf("Unknown probe type '%s'", config.Type)
+}
+
+func (c concreteChecker) checkHTTP(config Config) error {
+	client := &http.Client{Timeout: config.Timeout()}
+
+	resp, err := client.Get(config.URL)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Requesting %s", config.URL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return bosherr.Errorf("Unexpected response status %d from %s", resp.StatusCode, config.URL)
+	}
+
+	return nil
+}
+
+func (c concreteChecker) checkTCP(config Config) error {
+	conn, err := net.DialTimeout("tcp", config.Address, config.Timeout())
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Connecting to %s", config.Address)
+	}
+
+	return conn.Close()
+}
+
+func (c concreteChecker) checkExec(config Config) error {
+	command := boshsys.Command{
+		Name: config.Command[0],
+		Args: config.Command[1:],
+	}
+
+	process, err := c.runner.RunComplexCommandAsync(command)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Running %s", command.Name)
+	}
+
+	timer := time.NewTimer(config.Timeout())
+	defer timer.Stop()
+
+	select {
+	case result := <-process.Wait():
+		if result.Error != nil {
+			return bosherr.WrapErrorf(result.Error, "Running %s exited with %d", command.Name, result.ExitStatus)
+		}
+		return nil
+	case <-timer.C:
+		_ = process.TerminateNicely(time.Second)
+		return bosherr.Errorf("Running %s timed out after %s", command.Name, config.Timeout())
+	}
+}
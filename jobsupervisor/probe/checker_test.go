@@ -0,0 +1,109 @@
+package probe_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+
+	. "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
+)
+
+var _ = Describe("Checker", func() {
+	var (
+		runner  *fakesys.FakeCmdRunner
+		checker Checker
+	)
+
+	BeforeEach(func() {
+		runner = fakesys.NewFakeCmdRunner()
+		checker = NewChecker(runner)
+	})
+
+	Describe("http probes", func() {
+		var (
+			status int
+			server *httptest.Server
+		)
+
+		BeforeEach(func() {
+			status = http.StatusOK
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(status)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("succeeds on a successful response", func() {
+			err := checker.Check(Config{Name: "api", Type: TypeHTTP, URL: server.URL, TimeoutSeconds: 1})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fails on an error response", func() {
+			status = http.StatusInternalServerError
+
+			err := checker.Check(Config{Name: "api", Type: TypeHTTP, URL: server.URL, TimeoutSeconds: 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("500"))
+		})
+	})
+
+	Describe("tcp probes", func() {
+		It("succeeds when a connection can be established", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			defer listener.Close()
+
+			err = checker.Check(Config{Name: "port", Type: TypeTCP, Address: listener.Addr().String(), TimeoutSeconds: 1})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fails when nothing is listening", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			address := listener.Addr().String()
+			listener.Close()
+
+			err = checker.Check(Config{Name: "port", Type: TypeTCP, Address: address, TimeoutSeconds: 1})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("exec probes", func() {
+		It("succeeds when the command exits successfully", func() {
+			runner.AddProcess("/bin/check arg", &fakesys.FakeProcess{})
+
+			err := checker.Check(Config{Name: "cmd", Type: TypeExec, Command: []string{"/bin/check", "arg"}, TimeoutSeconds: 1})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fails when the command fails", func() {
+			runner.AddProcess("/bin/check", &fakesys.FakeProcess{
+				WaitResult: boshsys.Result{ExitStatus: 1, Error: errors.New("fake-exit-err")},
+			})
+
+			err := checker.Check(Config{Name: "cmd", Type: TypeExec, Command: []string{"/bin/check"}, TimeoutSeconds: 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-exit-err"))
+		})
+
+		It("terminates the command when it times out", func() {
+			process := &fakesys.FakeProcess{TerminatedNicelyCallBack: func(*fakesys.FakeProcess) {}}
+			runner.AddProcess("/bin/check", process)
+
+			err := checker.Check(Config{Name: "cmd", Type: TypeExec, Command: []string{"/bin/check"}, TimeoutSeconds: 1})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("timed out"))
+			Expect(process.TerminatedNicely).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,211 @@
+package probe
+
+import (
+	"sort"
+	"sync"
+
+	"code.cloudfoundry.org/clock"
+	"gopkg.in/yaml.v3"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const managerLogTag = "probeManager"
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Manager
+
+type Manager interface {
+	// AddJob starts running the probes declared in healthFilePath for jobName.
+	// Jobs without a health file have no probes and are always healthy.
+	AddJob(jobName string, healthFilePath string) error
+	RemoveAllJobs()
+
+	Healthy() bool
+	// Ready is true when every probe of jobName passed its latest check,
+	// jobs without probes are always ready
+	Ready(jobName string) bool
+	Results() []Result
+}
+
+type concreteManager struct {
+	checker     Checker
+	fs          boshsys.FileSystem
+	timeService clock.Clock
+	logger      boshlog.Logger
+
+	mutex   sync.Mutex
+	probes  []*runningProbe
+	stopChs []chan struct{}
+}
+
+type runningProbe struct {
+	config Config
+	result Result
+
+	consecutiveSuccesses int
+}
+
+func NewManager(checker Checker, fs boshsys.FileSystem, timeService clock.Clock, logger boshlog.Logger) Manager {
+	return &concreteManager{
+		checker:     checker,
+		fs:          fs,
+		timeService: timeService,
+		logger:      logger,
+	}
+}
+
+func (m *concreteManager) AddJob(jobName string, healthFilePath string) error {
+	if !m.fs.FileExists(healthFilePath) {
+		return nil
+	}
+
+	contents, err := m.fs.ReadFile(healthFilePath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading health file for job %s", jobName)
+	}
+
+	var healthFile HealthFile
+
+	err = yaml.Unmarshal(contents, &healthFile)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Parsing health file for job %s", jobName)
+	}
+
+	for _, config := range healthFile.Probes {
+		err = config.Validate()
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Validating probes for job %s", jobName)
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, config := range healthFile.Probes {
+		probe := &runningProbe{
+			config: config.WithDefaults(),
+			result: Result{Job: jobName, Name: config.Name, Type: config.Type, Healthy: true},
+		}
+		stopCh := make(chan struct{})
+
+		m.probes = append(m.probes, probe)
+		m.stopChs = append(m.stopChs, stopCh)
+
+		m.logger.Debug(managerLogTag, "Starting %s probe '%s' for job %s", config.Type, config.Name, jobName)
+
+		go m.run(probe, stopCh)
+	}
+
+	return nil
+}
+
+func (m *concreteManager) RemoveAllJobs() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, stopCh := range m.stopChs {
+		close(stopCh)
+	}
+
+	m.probes = nil
+	m.stopChs = nil
+}
+
+func (m *concreteManager) Healthy() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, probe := range m.probes {
+		if !probe.result.Healthy {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *concreteManager) Ready(jobName string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, probe := range m.probes {
+		if probe.result.Job != jobName {
+			continue
+		}
+
+		// Probes are considered healthy until they fail often enough
+		// so they have to have been checked to tell the job is ready
+		if probe.result.LastCheckedAt == 0 || probe.result.ConsecutiveFailures > 0 || !probe.result.Healthy {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *concreteManager) Results() []Result {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	results := []Result{}
+	for _, probe := range m.probes {
+		results = append(results, probe.result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Job < results[j].Job
+	})
+
+	return results
+}
+
+func (m *concreteManager) run(probe *runningProbe, stopCh chan struct{}) {
+	defer m.logger.HandlePanic("Probe Manager Run")
+
+	ticker := m.timeService.NewTicker(probe.config.Interval())
+	defer ticker.Stop()
+
+	// The first check runs right away so jobs waiting
+	// for the probe do not wait for a whole interval
+	for {
+		err := m.checker.Check(probe.config)
+
+		m.mutex.Lock()
+		m.record(probe, err)
+		m.mutex.Unlock()
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+func (m *concreteManager) record(probe *runningProbe, err error) {
+	result := &probe.result
+	result.LastCheckedAt = m.timeService.Now().Unix()
+
+	if err != nil {
+		probe.consecutiveSuccesses = 0
+		result.ConsecutiveFailures++
+		result.LastError = err.Error()
+
+		if result.Healthy && result.ConsecutiveFailures >= probe.config.FailureThreshold {
+			m.logger.Info(managerLogTag, "Probe '%s' for job %s became unhealthy: %s", result.Name, result.Job, err.Error())
+			result.Healthy = false
+		}
+		return
+	}
+
+	probe.consecutiveSuccesses++
+	result.ConsecutiveFailures = 0
+	result.LastError = ""
+
+	if !result.Healthy && probe.consecutiveSuccesses >= probe.config.SuccessThreshold {
+		m.logger.Info(managerLogTag, "Probe '%s' for job %s became healthy", result.Name, result.Job)
+		result.Healthy = true
+	}
+}
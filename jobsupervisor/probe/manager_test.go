@@ -0,0 +1,154 @@
+package probe_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+
+	. "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe/probefakes"
+)
+
+var _ = Describe("Manager", func() {
+	var (
+		checker     *probefakes.FakeChecker
+		fs          *fakesys.FakeFileSystem
+		timeService *fakeclock.FakeClock
+		manager     Manager
+	)
+
+	BeforeEach(func() {
+		checker = &probefakes.FakeChecker{}
+		fs = fakesys.NewFakeFileSystem()
+		timeService = fakeclock.NewFakeClock(time.Unix(1700000000, 0))
+		manager = NewManager(checker, fs, timeService, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	AfterEach(func() {
+		manager.RemoveAllJobs()
+	})
+
+	tick := func(times int) {
+		for i := 0; i < times; i++ {
+			calls := checker.CheckCallCount()
+			Eventually(timeService.WatcherCount).Should(Equal(1))
+			timeService.Increment(10 * time.Second)
+			Eventually(checker.CheckCallCount).Should(Equal(calls + 1))
+		}
+	}
+
+	It("has no probes and is healthy when the job has no health file", func() {
+		Expect(manager.AddJob("fake-job", "/fake/jobs/fake-job/health.yml")).To(Succeed())
+		Expect(manager.Healthy()).To(BeTrue())
+		Expect(manager.Ready("fake-job")).To(BeTrue())
+		Expect(manager.Results()).To(BeEmpty())
+	})
+
+	It("returns an error when the health file declares an invalid probe", func() {
+		err := fs.WriteFileString("/fake/health.yml", "probes:\n- name: api\n  type: http\n")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = manager.AddJob("fake-job", "/fake/health.yml")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("requires a url"))
+	})
+
+	Context("when the job declares probes", func() {
+		BeforeEach(func() {
+			err := fs.WriteFileString("/fake/health.yml", `
+probes:
+- name: api
+  type: http
+  url: http://127.0.0.1:8080/healthz
+  failure_threshold: 2
+  success_threshold: 2
+`)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		addJob := func() {
+			Expect(manager.AddJob("fake-job", "/fake/health.yml")).To(Succeed())
+			Eventually(checker.CheckCallCount).Should(Equal(1))
+		}
+
+		It("runs the probe with defaults applied right away", func() {
+			addJob()
+
+			Expect(checker.CheckArgsForCall(0)).To(Equal(Config{
+				Name:             "api",
+				Type:             TypeHTTP,
+				URL:              "http://127.0.0.1:8080/healthz",
+				IntervalSeconds:  10,
+				TimeoutSeconds:   5,
+				FailureThreshold: 2,
+				SuccessThreshold: 2,
+			}))
+		})
+
+		It("runs the probe again after its interval", func() {
+			addJob()
+
+			tick(1)
+			Expect(checker.CheckCallCount()).To(Equal(2))
+		})
+
+		It("becomes unhealthy only after reaching the failure threshold", func() {
+			checker.CheckReturns(errors.New("fake-probe-err"))
+
+			addJob()
+			Expect(manager.Healthy()).To(BeTrue())
+
+			tick(1)
+			Eventually(manager.Healthy).Should(BeFalse())
+			Expect(manager.Results()).To(Equal([]Result{{
+				Job:                 "fake-job",
+				Name:                "api",
+				Type:                TypeHTTP,
+				Healthy:             false,
+				ConsecutiveFailures: 2,
+				LastCheckedAt:       timeService.Now().Unix(),
+				LastError:           "fake-probe-err",
+			}}))
+		})
+
+		It("recovers only after reaching the success threshold", func() {
+			checker.CheckReturns(errors.New("fake-probe-err"))
+			addJob()
+			tick(1)
+			Eventually(manager.Healthy).Should(BeFalse())
+
+			checker.CheckReturns(nil)
+			tick(1)
+			Consistently(manager.Healthy).Should(BeFalse())
+
+			tick(1)
+			Eventually(manager.Healthy).Should(BeTrue())
+		})
+
+		It("is ready only once the probes of the job passed their latest check", func() {
+			Expect(manager.Ready("other-job")).To(BeTrue())
+
+			checker.CheckReturns(errors.New("fake-probe-err"))
+			addJob()
+			Consistently(func() bool { return manager.Ready("fake-job") }).Should(BeFalse())
+
+			checker.CheckReturns(nil)
+			tick(1)
+			Eventually(func() bool { return manager.Ready("fake-job") }).Should(BeTrue())
+		})
+
+		It("stops running probes when all jobs are removed", func() {
+			addJob()
+			manager.RemoveAllJobs()
+
+			Expect(manager.Results()).To(BeEmpty())
+			Eventually(timeService.WatcherCount).Should(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,112 @@
+package probe
+
+import (
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	TypeHTTP = "http"
+	TypeTCP  = "tcp"
+	TypeExec = "exec"
+
+	defaultIntervalSeconds  = 10
+	defaultTimeoutSeconds   = 5
+	defaultFailureThreshold = 3
+	defaultSuccessThreshold = 1
+)
+
+// HealthFile is the contents of a job's health.yml, e.g.
+//
+//	probes:
+//	- name: api
+//	  type: http
+//	  url: http://127.0.0.1:8080/healthz
+//	  interval: 10
+//	  timeout: 5
+//	  failure_threshold: 3
+//	  success_threshold: 1
+type HealthFile struct {
+	Probes []Config `yaml:"probes"`
+}
+
+type Config struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+
+	// Used by http probes; any 2xx or 3xx response is considered healthy
+	URL string `yaml:"url"`
+
+	// Used by tcp probes, e.g. 127.0.0.1:4222
+	Address string `yaml:"address"`
+
+	// Used by exec probes; zero exit status is considered healthy
+	Command []string `yaml:"command"`
+
+	IntervalSeconds  int `yaml:"interval"`
+	TimeoutSeconds   int `yaml:"timeout"`
+	FailureThreshold int `yaml:"failure_threshold"`
+	SuccessThreshold int `yaml:"success_threshold"`
+}
+
+func (c Config) WithDefaults() Config {
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultIntervalSeconds
+	}
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = defaultFailureThreshold
+	}
+	if c.SuccessThreshold <= 0 {
+		c.SuccessThreshold = defaultSuccessThreshold
+	}
+	return c
+}
+
+func (c Config) Interval() time.Duration { return time.Duration(c.IntervalSeconds) * time.Second }
+func (c Config) Timeout() time.Duration  { return time.Duration(c.TimeoutSeconds) * time.Second }
+
+func (c Config) Validate() error {
+	if c.Name == "" {
+		return bosherr.Error("Missing probe name")
+	}
+
+	switch c.Type {
+	case TypeHTTP:
+		if c.URL == "" {
+			return bosherr.Errorf("Probe '%s' requires a url", c.Name)
+		}
+	case TypeTCP:
+		if c.Address == "" {
+			return bosherr.Errorf("Probe '%s' requires an address", c.Name)
+		}
+	case TypeExec:
+		if len(c.Command) == 0 {
+			return bosherr.Errorf("Probe '%s' requires a command", c.Name)
+		}
+	default:
+		return bosherr.Errorf("Probe '%s' has unknown type '%s'", c.Name, c.Type)
+	}
+
+	return nil
+}
+
+type Result struct {
+	Job     string `json:"job"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Healthy bool   `json:"healthy"`
+
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	LastCheckedAt       int64  `json:"last_checked_at,omitempty"`
+	LastError           string `json:"last_error,omitempty"`
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Checker
+
+type Checker interface {
+	Check(config Config) error
+}
@@ -0,0 +1,347 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package probefakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
+)
+
+type FakeManager struct {
+	AddJobStub        func(string, string) error
+	addJobMutex       sync.RWMutex
+	addJobArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	addJobReturns struct {
+		result1 error
+	}
+	addJobReturnsOnCall map[int]struct {
+		result1 error
+	}
+	HealthyStub        func() bool
+	healthyMutex       sync.RWMutex
+	healthyArgsForCall []struct {
+	}
+	healthyReturns struct {
+		result1 bool
+	}
+	healthyReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	ReadyStub        func(string) bool
+	readyMutex       sync.RWMutex
+	readyArgsForCall []struct {
+		arg1 string
+	}
+	readyReturns struct {
+		result1 bool
+	}
+	readyReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	RemoveAllJobsStub        func()
+	removeAllJobsMutex       sync.RWMutex
+	removeAllJobsArgsForCall []struct {
+	}
+	ResultsStub        func() []probe.Result
+	resultsMutex       sync.RWMutex
+	resultsArgsForCall []struct {
+	}
+	resultsReturns struct {
+		result1 []probe.Result
+	}
+	resultsReturnsOnCall map[int]struct {
+		result1 []probe.Result
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeManager) AddJob(arg1 string, arg2 string) error {
+	fake.addJobMutex.Lock()
+	ret, specificReturn := fake.addJobReturnsOnCall[len(fake.addJobArgsForCall)]
+	fake.addJobArgsForCall = append(fake.addJobArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.AddJobStub
+	fakeReturns := fake.addJobReturns
+	fake.recordInvocation("AddJob", []interface{}{arg1, arg2})
+	fake.addJobMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeManager) AddJobCallCount() int {
+	fake.addJobMutex.RLock()
+	defer fake.addJobMutex.RUnlock()
+	return len(fake.addJobArgsForCall)
+}
+
+func (fake *FakeManager) AddJobCalls(stub func(string, string) error) {
+	fake.addJobMutex.Lock()
+	defer fake.addJobMutex.Unlock()
+	fake.AddJobStub = stub
+}
+
+func (fake *FakeManager) AddJobArgsForCall(i int) (string, string) {
+	fake.addJobMutex.RLock()
+	defer fake.addJobMutex.RUnlock()
+	argsForCall := fake.addJobArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeManager) AddJobReturns(result1 error) {
+	fake.addJobMutex.Lock()
+	defer fake.addJobMutex.Unlock()
+	fake.AddJobStub = nil
+	fake.addJobReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeManager) AddJobReturnsOnCall(i int, result1 error) {
+	fake.addJobMutex.Lock()
+	defer fake.addJobMutex.Unlock()
+	fake.AddJobStub = nil
+	if fake.addJobReturnsOnCall == nil {
+		fake.addJobReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.addJobReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeManager) Healthy() bool {
+	fake.healthyMutex.Lock()
+	ret, specificReturn := fake.healthyReturnsOnCall[len(fake.healthyArgsForCall)]
+	fake.healthyArgsForCall = append(fake.healthyArgsForCall, struct {
+	}{})
+	stub := fake.HealthyStub
+	fakeReturns := fake.healthyReturns
+	fake.recordInvocation("Healthy", []interface{}{})
+	fake.healthyMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeManager) HealthyCallCount() int {
+	fake.healthyMutex.RLock()
+	defer fake.healthyMutex.RUnlock()
+	return len(fake.healthyArgsForCall)
+}
+
+func (fake *FakeManager) HealthyCalls(stub func() bool) {
+	fake.healthyMutex.Lock()
+	defer fake.healthyMutex.Unlock()
+	fake.HealthyStub = stub
+}
+
+func (fake *FakeManager) HealthyReturns(result1 bool) {
+	fake.healthyMutex.Lock()
+	defer fake.healthyMutex.Unlock()
+	fake.HealthyStub = nil
+	fake.healthyReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeManager) HealthyReturnsOnCall(i int, result1 bool) {
+	fake.healthyMutex.Lock()
+	defer fake.healthyMutex.Unlock()
+	fake.HealthyStub = nil
+	if fake.healthyReturnsOnCall == nil {
+		fake.healthyReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.healthyReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeManager) Ready(arg1 string) bool {
+	fake.readyMutex.Lock()
+	ret, specificReturn := fake.readyReturnsOnCall[len(fake.readyArgsForCall)]
+	fake.readyArgsForCall = append(fake.readyArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReadyStub
+	fakeReturns := fake.readyReturns
+	fake.recordInvocation("Ready", []interface{}{arg1})
+	fake.readyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeManager) ReadyCallCount() int {
+	fake.readyMutex.RLock()
+	defer fake.readyMutex.RUnlock()
+	return len(fake.readyArgsForCall)
+}
+
+func (fake *FakeManager) ReadyCalls(stub func(string) bool) {
+	fake.readyMutex.Lock()
+	defer fake.readyMutex.Unlock()
+	fake.ReadyStub = stub
+}
+
+func (fake *FakeManager) ReadyArgsForCall(i int) string {
+	fake.readyMutex.RLock()
+	defer fake.readyMutex.RUnlock()
+	argsForCall := fake.readyArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeManager) ReadyReturns(result1 bool) {
+	fake.readyMutex.Lock()
+	defer fake.readyMutex.Unlock()
+	fake.ReadyStub = nil
+	fake.readyReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeManager) ReadyReturnsOnCall(i int, result1 bool) {
+	fake.readyMutex.Lock()
+	defer fake.readyMutex.Unlock()
+	fake.ReadyStub = nil
+	if fake.readyReturnsOnCall == nil {
+		fake.readyReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.readyReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeManager) RemoveAllJobs() {
+	fake.removeAllJobsMutex.Lock()
+	fake.removeAllJobsArgsForCall = append(fake.removeAllJobsArgsForCall, struct {
+	}{})
+	stub := fake.RemoveAllJobsStub
+	fake.recordInvocation("RemoveAllJobs", []interface{}{})
+	fake.removeAllJobsMutex.Unlock()
+	if stub != nil {
+		fake.RemoveAllJobsStub()
+	}
+}
+
+func (fake *FakeManager) RemoveAllJobsCallCount() int {
+	fake.removeAllJobsMutex.RLock()
+	defer fake.removeAllJobsMutex.RUnlock()
+	return len(fake.removeAllJobsArgsForCall)
+}
+
+func (fake *FakeManager) RemoveAllJobsCalls(stub func()) {
+	fake.removeAllJobsMutex.Lock()
+	defer fake.removeAllJobsMutex.Unlock()
+	fake.RemoveAllJobsStub = stub
+}
+
+func (fake *FakeManager) Results() []probe.Result {
+	fake.resultsMutex.Lock()
+	ret, specificReturn := fake.resultsReturnsOnCall[len(fake.resultsArgsForCall)]
+	fake.resultsArgsForCall = append(fake.resultsArgsForCall, struct {
+	}{})
+	stub := fake.ResultsStub
+	fakeReturns := fake.resultsReturns
+	fake.recordInvocation("Results", []interface{}{})
+	fake.resultsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeManager) ResultsCallCount() int {
+	fake.resultsMutex.RLock()
+	defer fake.resultsMutex.RUnlock()
+	return len(fake.resultsArgsForCall)
+}
+
+func (fake *FakeManager) ResultsCalls(stub func() []probe.Result) {
+	fake.resultsMutex.Lock()
+	defer fake.resultsMutex.Unlock()
+	fake.ResultsStub = stub
+}
+
+func (fake *FakeManager) ResultsReturns(result1 []probe.Result) {
+	fake.resultsMutex.Lock()
+	defer fake.resultsMutex.Unlock()
+	fake.ResultsStub = nil
+	fake.resultsReturns = struct {
+		result1 []probe.Result
+	}{result1}
+}
+
+func (fake *FakeManager) ResultsReturnsOnCall(i int, result1 []probe.Result) {
+	fake.resultsMutex.Lock()
+	defer fake.resultsMutex.Unlock()
+	fake.ResultsStub = nil
+	if fake.resultsReturnsOnCall == nil {
+		fake.resultsReturnsOnCall = make(map[int]struct {
+			result1 []probe.Result
+		})
+	}
+	fake.resultsReturnsOnCall[i] = struct {
+		result1 []probe.Result
+	}{result1}
+}
+
+func (fake *FakeManager) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.addJobMutex.RLock()
+	defer fake.addJobMutex.RUnlock()
+	fake.healthyMutex.RLock()
+	defer fake.healthyMutex.RUnlock()
+	fake.readyMutex.RLock()
+	defer fake.readyMutex.RUnlock()
+	fake.removeAllJobsMutex.RLock()
+	defer fake.removeAllJobsMutex.RUnlock()
+	fake.resultsMutex.RLock()
+	defer fake.resultsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeManager) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ probe.Manager = new(FakeManager)
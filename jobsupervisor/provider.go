@@ -4,19 +4,41 @@
 package jobsupervisor
 
 import (
+	"path/filepath"
 	"time"
 
 	"code.cloudfoundry.org/clock"
 
 	boshhandler "github.com/cloudfoundry/bosh-agent/v2/handler"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/alertserver"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
 	boshmonit "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/monit"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
 	boshplatform "github.com/cloudfoundry/bosh-agent/v2/platform"
 	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
-const jobSupervisorListenPort = 2825
+const (
+	jobSupervisorListenPort = 2825
+	alertServerListenPort   = 2826
+	systemdUnitsDir         = "/etc/systemd/system"
+	systemdRuntimeDir       = "/run/systemd/system"
+	cgroupRoot              = "/sys/fs/cgroup"
+)
+
+// NewCgroupManager returns the manager limiting the jobs of the named job supervisor.
+// Jobs are limited with slices wherever systemd runs since it owns the cgroup
+// hierarchy then, even when monit supervises the jobs.
+func NewCgroupManager(name string, fs boshsys.FileSystem, runner boshsys.CmdRunner, logger boshlog.Logger) cgroup.Manager {
+	if name == "systemd" || fs.FileExists(systemdRuntimeDir) {
+		return cgroup.NewSystemdManager(fs, runner, systemdUnitsDir, logger)
+	}
+
+	return cgroup.NewManager(fs, cgroupRoot, logger)
+}
 
 type Provider struct {
 	supervisors map[string]JobSupervisor
@@ -28,6 +50,8 @@ func NewProvider(
 	logger boshlog.Logger,
 	dirProvider boshdir.Provider,
 	handler boshhandler.Handler,
+	probeManager probe.Manager,
+	cgroupManager cgroup.Manager,
 ) Provider {
 	timeService := clock.NewClock()
 	fs := platform.GetFs()
@@ -46,11 +70,18 @@ func NewProvider(
 		},
 		timeService,
 		platform.GetServiceManager(),
+		cgroupManager,
+		probeManager,
 	)
 
+	systemdJobSupervisor := NewSystemdJobSupervisor(fs, runner, logger, dirProvider, systemdUnitsDir, timeService)
+
+	alertServer := alertserver.NewServer(fs, filepath.Join(dirProvider.BoshDir(), "alert_token"), alertServerListenPort, timeService, logger)
+
 	return Provider{
 		supervisors: map[string]JobSupervisor{
-			"monit":      NewWrapperJobSupervisor(monitJobSupervisor, fs, dirProvider, logger),
+			"monit":      NewWrapperJobSupervisor(monitJobSupervisor, fs, dirProvider, probeManager, cgroupManager, alertServer, timeService, logger),
+			"systemd":    NewWrapperJobSupervisor(systemdJobSupervisor, fs, dirProvider, probeManager, cgroupManager, alertServer, timeService, logger),
 			"dummy":      NewDummyJobSupervisor(),
 			"dummy-nats": NewDummyNatsJobSupervisor(handler),
 		},
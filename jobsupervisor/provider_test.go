@@ -11,7 +11,10 @@ import (
 	. "github.com/onsi/gomega"
 
 	. "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/alertserver"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup/cgroupfakes"
 	fakemonit "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/monit/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe/probefakes"
 	fakembus "github.com/cloudfoundry/bosh-agent/v2/mbus/fakes"
 	"github.com/cloudfoundry/bosh-agent/v2/platform/platformfakes"
 	"github.com/cloudfoundry/bosh-agent/v2/servicemanager/servicemanagerfakes"
@@ -34,6 +37,9 @@ func init() { //nolint:gochecknoinits
 			timeService           clock.Clock
 			jobSupervisorName     string
 			serviceManager        *servicemanagerfakes.FakeServiceManager
+			probeManager          *probefakes.FakeManager
+			cgroupManager         *cgroupfakes.FakeManager
+			alertServer           alertserver.Server
 		)
 
 		BeforeEach(func() {
@@ -47,6 +53,9 @@ func init() { //nolint:gochecknoinits
 			handler = &fakembus.FakeHandler{}
 			timeService = clock.NewClock()
 			serviceManager = &servicemanagerfakes.FakeServiceManager{}
+			probeManager = &probefakes.FakeManager{}
+			cgroupManager = &cgroupfakes.FakeManager{}
+			alertServer = alertserver.NewServer(fileSystem, "/fake-base-dir/bosh/alert_token", 2826, timeService, logger)
 
 			platform.GetFsReturns(fileSystem)
 			platform.GetRunnerReturns(cmdRunner)
@@ -58,6 +67,8 @@ func init() { //nolint:gochecknoinits
 				logger,
 				dirProvider,
 				handler,
+				probeManager,
+				cgroupManager,
 			)
 			if runtime.GOOS == "windows" {
 				jobSupervisorName = "windows"
@@ -85,12 +96,18 @@ func init() { //nolint:gochecknoinits
 					},
 					timeService,
 					serviceManager,
+					cgroupManager,
+					probeManager,
 				)
 
 				expectedSupervisor := NewWrapperJobSupervisor(
 					delegateSupervisor,
 					fileSystem,
 					dirProvider,
+					probeManager,
+					cgroupManager,
+					alertServer,
+					timeService,
 					logger,
 				)
 
@@ -98,6 +115,28 @@ func init() { //nolint:gochecknoinits
 			}
 		})
 
+		It("provides a systemd job supervisor", func() {
+			if runtime.GOOS == "windows" {
+				Skip("systemd is not available on windows")
+			}
+
+			actualSupervisor, err := provider.Get("systemd")
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedSupervisor := NewWrapperJobSupervisor(
+				NewSystemdJobSupervisor(fileSystem, cmdRunner, logger, dirProvider, "/etc/systemd/system", timeService),
+				fileSystem,
+				dirProvider,
+				probeManager,
+				cgroupManager,
+				alertServer,
+				timeService,
+				logger,
+			)
+
+			Expect(actualSupervisor).To(Equal(expectedSupervisor))
+		})
+
 		It("provides a dummy job supervisor", func() {
 			actualSupervisor, err := provider.Get("dummy")
 			Expect(err).ToNot(HaveOccurred())
@@ -6,16 +6,27 @@ package jobsupervisor
 import (
 	"os"
 
+	"code.cloudfoundry.org/clock"
+
 	boshhandler "github.com/cloudfoundry/bosh-agent/v2/handler"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
 	boshmonit "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/monit"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
 	boshplatform "github.com/cloudfoundry/bosh-agent/v2/platform"
 	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 )
 
 const jobSupervisorListenPort = 2825
 
+// NewCgroupManager returns a manager which only reports job limits since
+// windows has no cgroups
+func NewCgroupManager(name string, fs boshsys.FileSystem, runner boshsys.CmdRunner, logger boshlog.Logger) cgroup.Manager {
+	return cgroup.NewManager(fs, "", logger)
+}
+
 type Provider struct {
 	supervisors map[string]JobSupervisor
 }
@@ -26,7 +37,10 @@ func NewProvider(
 	logger boshlog.Logger,
 	dirProvider boshdir.Provider,
 	handler boshhandler.Handler,
+	probeManager probe.Manager,
+	cgroupManager cgroup.Manager,
 ) (p Provider) {
+	timeService := clock.NewClock()
 	fs := platform.GetFs()
 	runner := platform.GetRunner()
 
@@ -40,10 +54,10 @@ func NewProvider(
 	}
 
 	p.supervisors = map[string]JobSupervisor{
-		"monit":      NewWrapperJobSupervisor(NewWindowsJobSupervisor(runner, dirProvider, fs, logger, jobSupervisorListenPort, make(chan bool), machineIP), fs, dirProvider, logger),
+		"monit":      NewWrapperJobSupervisor(NewWindowsJobSupervisor(runner, dirProvider, fs, logger, jobSupervisorListenPort, make(chan bool), machineIP), fs, dirProvider, probeManager, cgroupManager, nil, timeService, logger),
 		"dummy":      NewDummyJobSupervisor(),
 		"dummy-nats": NewDummyNatsJobSupervisor(handler),
-		"windows":    NewWrapperJobSupervisor(NewWindowsJobSupervisor(runner, dirProvider, fs, logger, jobSupervisorListenPort, make(chan bool), machineIP), fs, dirProvider, logger),
+		"windows":    NewWrapperJobSupervisor(NewWindowsJobSupervisor(runner, dirProvider, fs, logger, jobSupervisorListenPort, make(chan bool), machineIP), fs, dirProvider, probeManager, cgroupManager, nil, timeService, logger),
 	}
 
 	return
@@ -0,0 +1,1082 @@
+package jobsupervisor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"gopkg.in/yaml.v3"
+
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	boshcgroup "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const (
+	systemdJobSupervisorLogTag = "systemdJobSupervisor"
+
+	// Tells the units of job processes apart from other units of the
+	// agent, e.g. bosh-agent.service, which must never be matched
+	systemdUnitPrefix   = "bosh-job-"
+	systemdJobKey       = "X-BoshJob"
+	systemdProcessesYML = "processes.yml"
+
+	// Message ids systemd logs about units, see systemd/sd-messages.h
+	systemdMessageUnitProcessExit = "98e322203f7a4ed290d09fe03c09fe15"
+	systemdMessageUnitFailed      = "d9b373ed55a64feb8242e02dbe79a49c"
+)
+
+var (
+	monitCheckProcessRegexp = regexp.MustCompile(`^check\s+process\s+(\S+)`)
+	monitPidfileRegexp      = regexp.MustCompile(`with\s+pidfile\s+"?([^"\s]+)"?`)
+	monitStartProgramRegexp = regexp.MustCompile(`(?i)start\s+program\s*=?\s*(?:"([^"]+)"|'([^']+)')`)
+	monitStopProgramRegexp  = regexp.MustCompile(`(?i)stop\s+program\s*=?\s*(?:"([^"]+)"|'([^']+)')`)
+)
+
+// SystemdProcessConfig is the contents of a job's processes.yml, e.g.
+//
+//	processes:
+//	- name: nats
+//	  executable: /var/vcap/packages/nats/bin/nats-server
+//	  args: [-c, /var/vcap/jobs/nats/config/nats.conf]
+//	  env: {GOMAXPROCS: "2"}
+//
+// Jobs without a processes.yml have their monit file translated instead.
+type SystemdProcessConfig struct {
+	Processes []SystemdProcess `yaml:"processes"`
+}
+
+type SystemdProcess struct {
+	Name       string            `yaml:"name"`
+	Executable string            `yaml:"executable"`
+	Args       []string          `yaml:"args"`
+	Env        map[string]string `yaml:"env"`
+	WorkingDir string            `yaml:"working_directory"`
+	User       string            `yaml:"user"`
+
+	// Only set for processes translated from monit, which daemonize
+	// themselves and are tracked by their pidfile
+	PidFile     string `yaml:"-"`
+	StopCommand string `yaml:"-"`
+}
+
+type systemdJobSupervisor struct {
+	fs          boshsys.FileSystem
+	runner      boshsys.CmdRunner
+	logger      boshlog.Logger
+	dirProvider boshdir.Provider
+	unitsDir    string
+	timeService clock.Clock
+}
+
+// NewSystemdJobSupervisor supervises job processes as systemd units
+// generated into unitsDir and placed in a slice per job within bosh.slice.
+func NewSystemdJobSupervisor(
+	fs boshsys.FileSystem,
+	runner boshsys.CmdRunner,
+	logger boshlog.Logger,
+	dirProvider boshdir.Provider,
+	unitsDir string,
+	timeService clock.Clock,
+) JobSupervisor {
+	return &systemdJobSupervisor{
+		fs:          fs,
+		runner:      runner,
+		logger:      logger,
+		dirProvider: dirProvider,
+		unitsDir:    unitsDir,
+		timeService: timeService,
+	}
+}
+
+func (s systemdJobSupervisor) Reload() error {
+	return s.systemctl("daemon-reload")
+}
+
+func (s systemdJobSupervisor) Start() error {
+	units, err := s.units()
+	if err != nil {
+		return err
+	}
+
+	// Re-monitor units that were unmonitored
+	for _, unit := range units {
+		err = s.fs.RemoveAll(s.unmonitorDropInPath(unit))
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Removing unmonitor drop-in for %s", unit)
+		}
+	}
+
+	if len(units) > 0 {
+		err = s.Reload()
+		if err != nil {
+			return err
+		}
+
+		started, err := s.startInOrder()
+		if err != nil {
+			return err
+		}
+
+		remainingUnits := []string{}
+		for _, unit := range units {
+			if !containsString(started, unit) {
+				remainingUnits = append(remainingUnits, unit)
+			}
+		}
+
+		if len(remainingUnits) > 0 {
+			s.logger.Debug(systemdJobSupervisorLogTag, "Starting units %v", remainingUnits)
+
+			err = s.systemctl(append([]string{"start"}, remainingUnits...)...)
+			if err != nil {
+				return bosherr.WrapError(err, "Starting units")
+			}
+		}
+	}
+
+	err = s.fs.RemoveAll(s.stoppedFilePath())
+	if err != nil {
+		return bosherr.WrapError(err, "Removing stopped File")
+	}
+
+	err = s.stoppedServices().clear()
+	if err != nil {
+		return bosherr.WrapError(err, "Removing stopped units")
+	}
+
+	return nil
+}
+
+// startInOrder starts the jobs with declared dependencies one after another
+// and returns the units it started. Starting units blocks until systemd
+// considers them started so jobs are up before their dependents start.
+func (s systemdJobSupervisor) startInOrder() ([]string, error) {
+	order, orderedUnits, err := s.orderedUnits()
+	if err != nil {
+		return nil, err
+	}
+
+	started := []string{}
+
+	for i, job := range order {
+		if len(orderedUnits[i]) == 0 {
+			continue
+		}
+
+		s.logger.Debug(systemdJobSupervisorLogTag, "Starting units %v of %s", orderedUnits[i], job)
+
+		err = s.systemctl(append([]string{"start"}, orderedUnits[i]...)...)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Starting units of %s", job)
+		}
+
+		started = append(started, orderedUnits[i]...)
+	}
+
+	return started, nil
+}
+
+// stopInOrder stops the jobs with declared dependencies in reverse start
+// order and returns how long stopping the units of each job took
+func (s systemdJobSupervisor) stopInOrder() (map[string]time.Duration, error) {
+	order, orderedUnits, err := s.orderedUnits()
+	if err != nil {
+		return nil, err
+	}
+
+	durations := map[string]time.Duration{}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		if len(orderedUnits[i]) == 0 {
+			continue
+		}
+
+		s.logger.Debug(systemdJobSupervisorLogTag, "Stopping units %v of %s", orderedUnits[i], order[i])
+
+		stoppedAt := s.timeService.Now()
+
+		err = s.systemctl(append([]string{"stop"}, orderedUnits[i]...)...)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Stopping units of %s", order[i])
+		}
+
+		for _, unit := range orderedUnits[i] {
+			durations[unit] = s.timeService.Since(stoppedAt)
+		}
+	}
+
+	return durations, nil
+}
+
+func (s systemdJobSupervisor) orderedUnits() ([]string, [][]string, error) {
+	dependencies, err := loadJobDependencies(s.fs, s.jobDependenciesPath())
+	if err != nil || !dependencies.Declared() {
+		return nil, nil, err
+	}
+
+	jobs, err := s.jobServices()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order, orderedServices, err := orderedServices(dependencies, jobs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orderedUnits := [][]string{}
+	for _, services := range orderedServices {
+		units := []string{}
+		for _, service := range services {
+			units = append(units, systemdUnitName(service))
+		}
+		orderedUnits = append(orderedUnits, units)
+	}
+
+	return order, orderedUnits, nil
+}
+
+func (s systemdJobSupervisor) StartJob(name string) error {
+	jobs, err := s.jobServices()
+	if err != nil {
+		return err
+	}
+
+	services, err := jobs.resolve(name)
+	if err != nil {
+		return err
+	}
+
+	// Units of other jobs stay stopped
+	if s.fs.FileExists(s.stoppedFilePath()) {
+		allServices := []string{}
+		for _, jobServices := range jobs {
+			allServices = append(allServices, jobServices...)
+		}
+
+		err = s.stoppedServices().update(allServices, nil)
+		if err != nil {
+			return err
+		}
+
+		err = s.fs.RemoveAll(s.stoppedFilePath())
+		if err != nil {
+			return bosherr.WrapError(err, "Removing stopped File")
+		}
+	}
+
+	units := []string{}
+	for _, service := range services {
+		unit := systemdUnitName(service)
+
+		err = s.fs.RemoveAll(s.unmonitorDropInPath(unit))
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Removing unmonitor drop-in for %s", unit)
+		}
+
+		units = append(units, unit)
+	}
+
+	err = s.Reload()
+	if err != nil {
+		return err
+	}
+
+	s.logger.Debug(systemdJobSupervisorLogTag, "Starting units %v", units)
+
+	err = s.systemctl(append([]string{"start"}, units...)...)
+	if err != nil {
+		return bosherr.WrapError(err, "Starting units")
+	}
+
+	return s.stoppedServices().update(nil, services)
+}
+
+// StopJob stops the units of a job and waits for them to stop
+func (s systemdJobSupervisor) StopJob(name string) error {
+	jobs, err := s.jobServices()
+	if err != nil {
+		return err
+	}
+
+	services, err := jobs.resolve(name)
+	if err != nil {
+		return err
+	}
+
+	units := []string{}
+	for _, service := range services {
+		units = append(units, systemdUnitName(service))
+	}
+
+	s.logger.Debug(systemdJobSupervisorLogTag, "Stopping units %v", units)
+
+	err = s.systemctl(append([]string{"stop"}, units...)...)
+	if err != nil {
+		return bosherr.WrapError(err, "Stopping units")
+	}
+
+	return s.stoppedServices().update(services, nil)
+}
+
+func (s systemdJobSupervisor) Stop() error {
+	_, err := s.stop(true)
+	return err
+}
+
+// StopAndWait blocks until systemd stopped all units. Systemd itself sends
+// SIGKILL to the units that do not stop within their TimeoutStopSec.
+func (s systemdJobSupervisor) StopAndWait() ([]JobStopResult, error) {
+	return s.stop(false)
+}
+
+func (s systemdJobSupervisor) stop(noBlock bool) ([]JobStopResult, error) {
+	units, err := s.units()
+	if err != nil {
+		return nil, err
+	}
+
+	durations := map[string]time.Duration{}
+
+	if len(units) > 0 {
+		args := []string{"stop"}
+		if noBlock {
+			args = append(args, "--no-block")
+		} else {
+			durations, err = s.stopInOrder()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		s.logger.Debug(systemdJobSupervisorLogTag, "Stopping units %v", units)
+
+		stoppedAt := s.timeService.Now()
+
+		err = s.systemctl(append(args, units...)...)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Stopping units")
+		}
+
+		for _, unit := range units {
+			if _, found := durations[unit]; !found {
+				durations[unit] = s.timeService.Since(stoppedAt)
+			}
+		}
+	}
+
+	err = s.fs.WriteFileString(s.stoppedFilePath(), "")
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Creating stopped File")
+	}
+
+	if noBlock {
+		return nil, nil
+	}
+
+	return s.stopResults(durations), nil
+}
+
+// stopResults reports a job as killed when systemd timed out stopping
+// any of its units and as taking as long as its slowest unit
+func (s systemdJobSupervisor) stopResults(durations map[string]time.Duration) []JobStopResult {
+	results := []JobStopResult{}
+
+	if len(durations) == 0 {
+		return results
+	}
+
+	// The units are stopped at this point so failing to
+	// report on them must not fail stopping the jobs
+	jobs, err := s.jobServices()
+	if err != nil {
+		s.logger.Warn(systemdJobSupervisorLogTag, "Not reporting stopped jobs: %s", err.Error())
+		return results
+	}
+
+	unitResults := map[string]string{}
+
+	units := []string{}
+	for unit := range durations {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+
+	stdout, _, _, err := s.runner.RunCommandQuietly("systemctl", append([]string{"show", "--property=Id,Result"}, units...)...)
+	if err != nil {
+		s.logger.Warn(systemdJobSupervisorLogTag, "Not reporting killed jobs: %s", err.Error())
+	}
+
+	for _, block := range strings.Split(strings.TrimSpace(stdout), "\n\n") {
+		unit := map[string]string{}
+		for _, line := range strings.Split(block, "\n") {
+			if key, value, found := strings.Cut(line, "="); found {
+				unit[key] = value
+			}
+		}
+		unitResults[unit["Id"]] = unit["Result"]
+	}
+
+	for job, services := range jobs {
+		result := newJobStopResult(job, JobStopped, 0)
+
+		for _, service := range services {
+			unit := systemdUnitName(service)
+
+			if durations[unit].Seconds() > result.Duration {
+				result.Duration = durations[unit].Seconds()
+			}
+
+			if unitResults[unit] == "timeout" {
+				result.Result = JobKilled
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return sortJobStopResults(results)
+}
+
+// Unmonitor keeps units running but stops systemd from restarting them
+func (s systemdJobSupervisor) Unmonitor() error {
+	units, err := s.units()
+	if err != nil {
+		return err
+	}
+
+	if len(units) == 0 {
+		return nil
+	}
+
+	for _, unit := range units {
+		s.logger.Debug(systemdJobSupervisorLogTag, "Unmonitoring unit %s", unit)
+
+		err = s.fs.WriteFileString(s.unmonitorDropInPath(unit), "[Service]\nRestart=no\n")
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Writing unmonitor drop-in for %s", unit)
+		}
+	}
+
+	return s.Reload()
+}
+
+func (s systemdJobSupervisor) Status() string {
+	if s.fs.FileExists(s.stoppedFilePath()) {
+		return "stopped"
+	}
+
+	units, err := s.showUnits()
+	if err != nil {
+		s.logger.Debug(systemdJobSupervisorLogTag, "Getting unit status: %s", err.Error())
+		return "unknown"
+	}
+
+	status := "running"
+	stopped := s.stoppedServices().load()
+	supervised := 0
+
+	for _, unit := range units {
+		// Units stopped on their own are not failing
+		if stopped[systemdServiceName(unit["Id"])] {
+			continue
+		}
+		supervised++
+
+		switch unit["ActiveState"] {
+		case "active":
+		case "activating", "reloading":
+			return "starting"
+		default:
+			status = "failing"
+		}
+	}
+
+	if len(units) > 0 && supervised == 0 {
+		status = "stopped"
+	}
+
+	return status
+}
+
+func (s systemdJobSupervisor) JobStatuses() map[string]string {
+	jobs, err := s.jobServices()
+	if err != nil {
+		s.logger.Warn(systemdJobSupervisorLogTag, "Failed to get job units: %s", err.Error())
+		return map[string]string{}
+	}
+
+	units, err := s.showUnits()
+	if err != nil {
+		statuses := map[string]string{}
+		for job := range jobs {
+			statuses[job] = "unknown"
+		}
+		return statuses
+	}
+
+	allStopped := s.fs.FileExists(s.stoppedFilePath())
+	stopped := s.stoppedServices().load()
+	states := map[string]string{}
+
+	for _, unit := range units {
+		service := systemdServiceName(unit["Id"])
+
+		switch {
+		case allStopped || stopped[service]:
+			states[service] = "stopped"
+		case unit["ActiveState"] == "active":
+			states[service] = "running"
+		case unit["ActiveState"] == "activating" || unit["ActiveState"] == "reloading":
+			states[service] = "starting"
+		default:
+			states[service] = "failing"
+		}
+	}
+
+	return jobs.statuses(states)
+}
+
+func (s systemdJobSupervisor) Processes() ([]Process, error) {
+	processes := []Process{}
+
+	units, err := s.showUnits()
+	if err != nil {
+		return processes, err
+	}
+
+	jobs, err := s.jobServices()
+	if err != nil {
+		s.logger.Warn(systemdJobSupervisorLogTag, "Failed to get job units: %s", err.Error())
+	}
+
+	uptime := s.uptime()
+
+	for _, unit := range units {
+		process := Process{
+			Name:  systemdServiceName(unit["Id"]),
+			Job:   jobs.jobOf(systemdServiceName(unit["Id"])),
+			State: systemdProcessState(unit["ActiveState"]),
+		}
+
+		process.PID, _ = strconv.Atoi(unit["MainPID"])
+
+		if memory, err := strconv.ParseUint(unit["MemoryCurrent"], 10, 64); err == nil {
+			process.Memory.Kb = int(memory / 1024)
+		}
+
+		if unit["ActiveState"] == "active" {
+			if activeSince, err := strconv.ParseInt(unit["ActiveEnterTimestampMonotonic"], 10, 64); err == nil && activeSince > 0 {
+				process.Uptime.Secs = int((uptime - time.Duration(activeSince)*time.Microsecond) / time.Second)
+			}
+		}
+
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+func (s systemdJobSupervisor) AddJob(jobName string, jobIndex int, configPath string) error {
+	processes, err := s.jobProcesses(jobName, configPath)
+	if err != nil {
+		return err
+	}
+
+	err = s.fs.MkdirAll(s.unitsDir, 0755)
+	if err != nil {
+		return bosherr.WrapError(err, "Creating units directory")
+	}
+
+	for _, process := range processes {
+		if process.Name == "" || process.Executable == "" {
+			return bosherr.Errorf("Process for job %s requires a name and an executable", jobName)
+		}
+
+		unit := systemdUnitName(process.Name)
+
+		s.logger.Debug(systemdJobSupervisorLogTag, "Writing unit %s for job %s", unit, jobName)
+
+		err = s.fs.WriteFileString(path.Join(s.unitsDir, unit), systemdUnitFile(jobNameForConfig(jobName, configPath), process))
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Writing unit %s", unit)
+		}
+	}
+
+	return nil
+}
+
+func (s systemdJobSupervisor) RemoveAllJobs() error {
+	units, err := s.units()
+	if err != nil {
+		return err
+	}
+
+	for _, unit := range units {
+		err = s.fs.RemoveAll(path.Join(s.unitsDir, unit))
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Removing unit %s", unit)
+		}
+
+		err = s.fs.RemoveAll(path.Join(s.unitsDir, unit+".d"))
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Removing drop-ins for unit %s", unit)
+		}
+	}
+
+	err = s.stoppedServices().clear()
+	if err != nil {
+		return bosherr.WrapError(err, "Removing stopped units")
+	}
+
+	err = s.fs.RemoveAll(s.jobDependenciesPath())
+	if err != nil {
+		return bosherr.WrapError(err, "Removing job dependencies")
+	}
+
+	return nil
+}
+
+func (s systemdJobSupervisor) SetJobDependencies(dependencies JobDependencies) error {
+	return dependencies.save(s.fs, s.jobDependenciesPath())
+}
+
+// SetJobStopPolicies configures how long systemd waits for the units of each
+// job to stop. Jobs without a policy get the default one rather than systemd's
+// own default. Systemd sends SIGTERM when stopping a unit, so the grace period
+// before SIGKILL is added to the stop timeout.
+func (s systemdJobSupervisor) SetJobStopPolicies(policies JobStopPolicies) error {
+	jobs, err := s.jobServices()
+	if err != nil {
+		return err
+	}
+
+	for job, services := range jobs {
+		policy := policies[job]
+		timeout := policy.timeout()
+		sendSIGKILL := "no"
+
+		if policy.kills() {
+			timeout += policy.gracePeriod()
+			sendSIGKILL = "yes"
+		}
+
+		for _, service := range services {
+			unit := systemdUnitName(service)
+
+			dropIn := fmt.Sprintf("[Service]\nTimeoutStopSec=%d\nSendSIGKILL=%s\n", int(timeout.Seconds()), sendSIGKILL)
+
+			err = s.fs.WriteFileString(s.stopDropInPath(unit), dropIn)
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Writing stop drop-in for %s", unit)
+			}
+		}
+	}
+
+	return nil
+}
+
+// MonitorJobFailures follows the journal for systemd reporting that
+// a job process exited or failed and reports it like a monit alert
+func (s systemdJobSupervisor) MonitorJobFailures(handler JobFailureHandler) error {
+	writer := &lineWriter{handleLine: func(line []byte) {
+		alert, found := s.journalAlert(line)
+		if !found {
+			return
+		}
+
+		err := handler(alert)
+		if err != nil {
+			s.logger.Error(systemdJobSupervisorLogTag, "Failed to handle job failure: %s", err.Error())
+		}
+	}}
+
+	_, _, _, err := s.runner.RunComplexCommand(boshsys.Command{
+		Name:   "journalctl",
+		Args:   []string{"--follow", "--lines=0", "--output=json", "--unit", systemdUnitPrefix + "*.service"},
+		Stdout: writer,
+		Quiet:  true,
+	})
+	if err != nil {
+		return bosherr.WrapError(err, "Following journal")
+	}
+
+	return bosherr.Error("Stopped following journal")
+}
+
+func (s systemdJobSupervisor) HealthRecorder(status string) {
+}
+
+func (s systemdJobSupervisor) journalAlert(line []byte) (boshalert.MonitAlert, bool) {
+	var entry map[string]string
+
+	// Entries containing binary fields are not all strings, none of these are interesting
+	err := json.Unmarshal(line, &entry)
+	if err != nil {
+		return boshalert.MonitAlert{}, false
+	}
+
+	var event, action string
+
+	switch entry["MESSAGE_ID"] {
+	case systemdMessageUnitProcessExit:
+		event, action = "does not exist", "restart"
+	case systemdMessageUnitFailed:
+		event, action = "execution failed", "alert"
+	default:
+		return boshalert.MonitAlert{}, false
+	}
+
+	unit := entry["UNIT"]
+	if !strings.HasPrefix(unit, systemdUnitPrefix) {
+		return boshalert.MonitAlert{}, false
+	}
+
+	date := s.timeService.Now()
+	if usec, err := strconv.ParseInt(entry["__REALTIME_TIMESTAMP"], 10, 64); err == nil {
+		date = time.UnixMicro(usec)
+	}
+
+	return boshalert.MonitAlert{
+		ID:          entry["__CURSOR"],
+		Service:     systemdServiceName(unit),
+		Event:       event,
+		Action:      action,
+		Date:        date.Format(time.RFC1123Z),
+		Description: entry["MESSAGE"],
+	}, true
+}
+
+// jobProcesses reads processes.yml next to the monit file, or else
+// translates each `check process` of the monit file
+func (s systemdJobSupervisor) jobProcesses(jobName string, configPath string) ([]SystemdProcess, error) {
+	processesPath := filepath.Join(filepath.Dir(configPath), systemdProcessesYML)
+
+	if s.fs.FileExists(processesPath) {
+		contents, err := s.fs.ReadFile(processesPath)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Reading processes for job %s", jobName)
+		}
+
+		var config SystemdProcessConfig
+
+		err = yaml.Unmarshal(contents, &config)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Parsing processes for job %s", jobName)
+		}
+
+		return config.Processes, nil
+	}
+
+	contents, err := s.fs.ReadFileString(configPath)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading job config from file")
+	}
+
+	return translateMonitFile(contents), nil
+}
+
+func translateMonitFile(contents string) []SystemdProcess {
+	processes := []SystemdProcess{}
+
+	var current *SystemdProcess
+
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+
+		if matches := monitCheckProcessRegexp.FindStringSubmatch(line); matches != nil {
+			processes = append(processes, SystemdProcess{Name: matches[1]})
+			current = &processes[len(processes)-1]
+		} else if strings.HasPrefix(line, "check ") {
+			// Other checks (file, host, ...) have no systemd equivalent
+			current = nil
+		}
+
+		if current == nil {
+			continue
+		}
+
+		if matches := monitPidfileRegexp.FindStringSubmatch(line); matches != nil {
+			current.PidFile = matches[1]
+		}
+		if matches := monitStartProgramRegexp.FindStringSubmatch(line); matches != nil {
+			current.Executable = matches[1] + matches[2]
+		}
+		if matches := monitStopProgramRegexp.FindStringSubmatch(line); matches != nil {
+			current.StopCommand = matches[1] + matches[2]
+		}
+	}
+
+	return processes
+}
+
+// systemdUnitName escapes characters not allowed in unit names like
+// systemd-escape does, so systemdServiceName can recover the process name
+func systemdUnitName(processName string) string {
+	var escaped strings.Builder
+
+	for i := 0; i < len(processName); i++ {
+		c := processName[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-' || c == ':' || c == '.' {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, `\x%02x`, c)
+		}
+	}
+
+	return systemdUnitPrefix + escaped.String() + ".service"
+}
+
+// systemdServiceName returns the name of the process of a unit named by systemdUnitName
+func systemdServiceName(unit string) string {
+	escaped := strings.TrimSuffix(strings.TrimPrefix(unit, systemdUnitPrefix), ".service")
+
+	var processName strings.Builder
+
+	for i := 0; i < len(escaped); i++ {
+		if escaped[i] == '\\' && i+3 < len(escaped) && escaped[i+1] == 'x' {
+			if c, err := strconv.ParseUint(escaped[i+2:i+4], 16, 8); err == nil {
+				processName.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+
+		processName.WriteByte(escaped[i])
+	}
+
+	return processName.String()
+}
+
+func systemdUnitFile(jobName string, process SystemdProcess) string {
+	var unit bytes.Buffer
+
+	fmt.Fprintf(&unit, "[Unit]\n")
+	fmt.Fprintf(&unit, "Description=BOSH job %s process %s\n", jobName, process.Name)
+	fmt.Fprintf(&unit, "%s=%s\n", systemdJobKey, jobName)
+	fmt.Fprintf(&unit, "\n[Service]\n")
+	fmt.Fprintf(&unit, "Slice=%s\n", boshcgroup.SliceName(jobName))
+
+	execStart := []string{process.Executable}
+	for _, arg := range process.Args {
+		execStart = append(execStart, systemdQuote(arg))
+	}
+
+	if process.PidFile != "" {
+		fmt.Fprintf(&unit, "Type=forking\n")
+		fmt.Fprintf(&unit, "PIDFile=%s\n", process.PidFile)
+	}
+
+	fmt.Fprintf(&unit, "ExecStart=%s\n", strings.Join(execStart, " "))
+
+	if process.StopCommand != "" {
+		fmt.Fprintf(&unit, "ExecStop=%s\n", process.StopCommand)
+	}
+
+	envNames := []string{}
+	for name := range process.Env {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+
+	for _, name := range envNames {
+		fmt.Fprintf(&unit, "Environment=%s\n", systemdQuote(name+"="+process.Env[name]))
+	}
+
+	if process.WorkingDir != "" {
+		fmt.Fprintf(&unit, "WorkingDirectory=%s\n", process.WorkingDir)
+	}
+	if process.User != "" {
+		fmt.Fprintf(&unit, "User=%s\n", process.User)
+	}
+
+	fmt.Fprintf(&unit, "Restart=always\n")
+	fmt.Fprintf(&unit, "RestartSec=1\n")
+
+	return unit.String()
+}
+
+func systemdQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\"'\\$%") {
+		return value
+	}
+
+	return strconv.Quote(strings.ReplaceAll(strings.ReplaceAll(value, "%", "%%"), "$", "$$"))
+}
+
+func systemdProcessState(activeState string) string {
+	switch activeState {
+	case "active":
+		return "running"
+	case "activating", "reloading":
+		return "starting"
+	case "inactive":
+		return "not monitored"
+	}
+
+	return "failing"
+}
+
+// units returns the names of all generated units
+func (s systemdJobSupervisor) units() ([]string, error) {
+	paths, err := s.fs.Glob(path.Join(s.unitsDir, systemdUnitPrefix+"*.service"))
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Listing units")
+	}
+
+	units := []string{}
+	for _, unitPath := range paths {
+		units = append(units, path.Base(unitPath))
+	}
+	sort.Strings(units)
+
+	return units, nil
+}
+
+// showUnits returns the properties systemd reports for each generated unit
+func (s systemdJobSupervisor) showUnits() ([]map[string]string, error) {
+	units, err := s.units()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(units) == 0 {
+		return []map[string]string{}, nil
+	}
+
+	args := []string{"show", "--property=Id,ActiveState,SubState,MainPID,MemoryCurrent,ActiveEnterTimestampMonotonic"}
+
+	stdout, _, _, err := s.runner.RunCommandQuietly("systemctl", append(args, units...)...)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Showing units")
+	}
+
+	properties := []map[string]string{}
+
+	// Units are separated by a blank line
+	for _, block := range strings.Split(strings.TrimSpace(stdout), "\n\n") {
+		unit := map[string]string{}
+		for _, line := range strings.Split(block, "\n") {
+			if key, value, found := strings.Cut(line, "="); found {
+				unit[key] = value
+			}
+		}
+		if unit["Id"] != "" {
+			properties = append(properties, unit)
+		}
+	}
+
+	return properties, nil
+}
+
+// uptime returns time since boot, which monotonic timestamps are relative to
+func (s systemdJobSupervisor) uptime() time.Duration {
+	contents, err := s.fs.ReadFileString("/proc/uptime")
+	if err != nil {
+		return 0
+	}
+
+	fields := strings.Fields(contents)
+	if len(fields) == 0 {
+		return 0
+	}
+
+	secs, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(secs * float64(time.Second))
+}
+
+func (s systemdJobSupervisor) systemctl(args ...string) error {
+	_, stderr, _, err := s.runner.RunCommand("systemctl", args...)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Running systemctl %s: %s", args[0], strings.TrimSpace(stderr))
+	}
+
+	return nil
+}
+
+func (s systemdJobSupervisor) unmonitorDropInPath(unit string) string {
+	return path.Join(s.unitsDir, unit+".d", "bosh-unmonitor.conf")
+}
+
+func (s systemdJobSupervisor) stopDropInPath(unit string) string {
+	return path.Join(s.unitsDir, unit+".d", "bosh-stop.conf")
+}
+
+func (s systemdJobSupervisor) stoppedFilePath() string {
+	return path.Join(s.dirProvider.BoshDir(), "systemd_stopped")
+}
+
+func (s systemdJobSupervisor) jobDependenciesPath() string {
+	return path.Join(s.dirProvider.BoshDir(), "systemd_job_dependencies.json")
+}
+
+func (s systemdJobSupervisor) stoppedServices() stoppedServices {
+	return stoppedServices{fs: s.fs, path: path.Join(s.dirProvider.BoshDir(), "systemd_stopped_units")}
+}
+
+// jobServices reads the job each generated unit belongs to
+func (s systemdJobSupervisor) jobServices() (jobServices, error) {
+	units, err := s.units()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := jobServices{}
+
+	for _, unit := range units {
+		contents, err := s.fs.ReadFileString(path.Join(s.unitsDir, unit))
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Reading unit %s", unit)
+		}
+
+		for _, line := range strings.Split(contents, "\n") {
+			if job, found := strings.CutPrefix(line, systemdJobKey+"="); found {
+				jobs.add(job, systemdServiceName(unit))
+				break
+			}
+		}
+	}
+
+	return jobs, nil
+}
+
+// lineWriter calls handleLine for every complete line written to it
+type lineWriter struct {
+	buffer     []byte
+	handleLine func([]byte)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+
+	consumed := 0
+	for {
+		index := bytes.IndexByte(w.buffer[consumed:], '\n')
+		if index < 0 {
+			break
+		}
+
+		w.handleLine(w.buffer[consumed : consumed+index])
+		consumed += index + 1
+	}
+
+	w.buffer = w.buffer[consumed:]
+
+	return len(p), nil
+}
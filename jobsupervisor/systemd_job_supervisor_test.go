@@ -0,0 +1,441 @@
+package jobsupervisor_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	. "github.com/cloudfoundry/bosh-agent/v2/jobsupervisor"
+	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("systemdJobSupervisor", func() {
+	var (
+		fs          *fakesys.FakeFileSystem
+		runner      *fakesys.FakeCmdRunner
+		timeService *fakeclock.FakeClock
+		systemd     JobSupervisor
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		runner = fakesys.NewFakeCmdRunner()
+		timeService = fakeclock.NewFakeClock(time.Unix(1700000000, 0))
+
+		systemd = NewSystemdJobSupervisor(
+			fs,
+			runner,
+			boshlog.NewLogger(boshlog.LevelNone),
+			boshdir.NewProvider("/var/vcap"),
+			"/etc/systemd/system",
+			timeService,
+		)
+	})
+
+	withUnits := func(units ...string) {
+		paths := []string{}
+		for _, unit := range units {
+			paths = append(paths, "/etc/systemd/system/"+unit)
+		}
+		fs.GlobStub = func(pattern string) ([]string, error) {
+			Expect(pattern).To(Equal("/etc/systemd/system/bosh-job-*.service"))
+			return paths, nil
+		}
+	}
+
+	Describe("AddJob", func() {
+		It("generates units from the job's processes.yml", func() {
+			err := fs.WriteFileString("/var/vcap/jobs/nats/processes.yml", `
+processes:
+- name: nats
+  executable: /var/vcap/packages/nats/bin/nats-server
+  args: [-c, /var/vcap/jobs/nats/config/nats.conf, "--name=my server"]
+  env:
+    GOMAXPROCS: "2"
+  user: vcap
+`)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = systemd.AddJob("nats", 0, "/var/vcap/jobs/nats/monit")
+			Expect(err).ToNot(HaveOccurred())
+
+			unit, err := fs.ReadFileString("/etc/systemd/system/bosh-job-nats.service")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unit).To(Equal(`[Unit]
+Description=BOSH job nats process nats
+X-BoshJob=nats
+
+[Service]
+Slice=bosh-nats.slice
+ExecStart=/var/vcap/packages/nats/bin/nats-server -c /var/vcap/jobs/nats/config/nats.conf "--name=my server"
+Environment=GOMAXPROCS=2
+User=vcap
+Restart=always
+RestartSec=1
+`))
+		})
+
+		It("translates monit process checks when the job has no processes.yml", func() {
+			err := fs.WriteFileString("/var/vcap/jobs/redis/monit", `
+check process redis
+  with pidfile /var/vcap/sys/run/redis/redis.pid
+  start program "/var/vcap/jobs/redis/bin/ctl start"
+  STOP PROGRAM = '/var/vcap/jobs/redis/bin/ctl stop'
+  group vcap
+
+check file redis-config with path /var/vcap/jobs/redis/config/redis.conf
+  start program "/bin/true"
+`)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = systemd.AddJob("redis", 0, "/var/vcap/jobs/redis/monit")
+			Expect(err).ToNot(HaveOccurred())
+
+			unit, err := fs.ReadFileString("/etc/systemd/system/bosh-job-redis.service")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unit).To(ContainSubstring("Type=forking\nPIDFile=/var/vcap/sys/run/redis/redis.pid\n"))
+			Expect(unit).To(ContainSubstring("ExecStart=/var/vcap/jobs/redis/bin/ctl start\n"))
+			Expect(unit).To(ContainSubstring("ExecStop=/var/vcap/jobs/redis/bin/ctl stop\n"))
+
+			Expect(fs.FileExists("/etc/systemd/system/bosh-job-redis-config.service")).To(BeFalse())
+		})
+
+		It("returns an error when a process has no executable", func() {
+			err := fs.WriteFileString("/var/vcap/jobs/nats/processes.yml", "processes:\n- name: nats\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = systemd.AddJob("nats", 0, "/var/vcap/jobs/nats/monit")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("requires a name and an executable"))
+		})
+	})
+
+	Describe("Start", func() {
+		It("reloads and starts all units, re-monitoring them", func() {
+			withUnits("bosh-job-nats.service", "bosh-job-redis.service")
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-nats.service.d/bosh-unmonitor.conf", "")).To(Succeed())
+			Expect(fs.WriteFileString("/var/vcap/bosh/systemd_stopped", "")).To(Succeed())
+
+			Expect(systemd.Start()).To(Succeed())
+
+			Expect(runner.RunCommands).To(Equal([][]string{
+				{"systemctl", "daemon-reload"},
+				{"systemctl", "start", "bosh-job-nats.service", "bosh-job-redis.service"},
+			}))
+			Expect(fs.FileExists("/etc/systemd/system/bosh-job-nats.service.d/bosh-unmonitor.conf")).To(BeFalse())
+			Expect(fs.FileExists("/var/vcap/bosh/systemd_stopped")).To(BeFalse())
+		})
+
+		It("returns an error when starting fails", func() {
+			withUnits("bosh-job-nats.service")
+			runner.AddCmdResult("systemctl start bosh-job-nats.service", fakesys.FakeCmdResult{Error: errors.New("fake-start-err")})
+
+			err := systemd.Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-start-err"))
+		})
+	})
+
+	Describe("Stop and StopAndWait", func() {
+		It("stops units without waiting", func() {
+			withUnits("bosh-job-nats.service")
+
+			Expect(systemd.Stop()).To(Succeed())
+
+			Expect(runner.RunCommands).To(Equal([][]string{{"systemctl", "stop", "--no-block", "bosh-job-nats.service"}}))
+			Expect(systemd.Status()).To(Equal("stopped"))
+		})
+
+		It("waits for units to stop", func() {
+			withUnits("bosh-job-nats.service")
+
+			_, err := systemd.StopAndWait()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(runner.RunCommands).To(Equal([][]string{{"systemctl", "stop", "bosh-job-nats.service"}}))
+			Expect(systemd.Status()).To(Equal("stopped"))
+		})
+	})
+
+	Describe("Unmonitor", func() {
+		It("disables restarts with a drop-in", func() {
+			withUnits("bosh-job-nats.service")
+
+			Expect(systemd.Unmonitor()).To(Succeed())
+
+			dropIn, err := fs.ReadFileString("/etc/systemd/system/bosh-job-nats.service.d/bosh-unmonitor.conf")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dropIn).To(Equal("[Service]\nRestart=no\n"))
+			Expect(runner.RunCommands).To(Equal([][]string{{"systemctl", "daemon-reload"}}))
+		})
+	})
+
+	Describe("Status and Processes", func() {
+		const showCmd = "systemctl show --property=Id,ActiveState,SubState,MainPID,MemoryCurrent,ActiveEnterTimestampMonotonic bosh-job-nats.service bosh-job-redis.service"
+
+		BeforeEach(func() {
+			withUnits("bosh-job-nats.service", "bosh-job-redis.service")
+			Expect(fs.WriteFileString("/proc/uptime", "1000.50 2000.00\n")).To(Succeed())
+		})
+
+		showUnits := func(natsState, redisState string) {
+			runner.AddCmdResult(showCmd, fakesys.FakeCmdResult{
+				Stdout: "Id=bosh-job-nats.service\nActiveState=" + natsState + "\nSubState=running\nMainPID=123\nMemoryCurrent=2097152\nActiveEnterTimestampMonotonic=400000000\n\n" +
+					"Id=bosh-job-redis.service\nActiveState=" + redisState + "\nSubState=dead\nMainPID=0\nMemoryCurrent=[not set]\nActiveEnterTimestampMonotonic=0\n",
+			})
+		}
+
+		It("is running when all units are active", func() {
+			showUnits("active", "active")
+			Expect(systemd.Status()).To(Equal("running"))
+		})
+
+		It("is failing when a unit is not active", func() {
+			showUnits("active", "failed")
+			Expect(systemd.Status()).To(Equal("failing"))
+		})
+
+		It("is starting when a unit is activating", func() {
+			showUnits("activating", "failed")
+			Expect(systemd.Status()).To(Equal("starting"))
+		})
+
+		It("is unknown when units cannot be shown", func() {
+			runner.AddCmdResult(showCmd, fakesys.FakeCmdResult{Error: errors.New("fake-show-err")})
+			Expect(systemd.Status()).To(Equal("unknown"))
+		})
+
+		It("returns processes", func() {
+			showUnits("active", "inactive")
+
+			processes, err := systemd.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes).To(Equal([]Process{
+				{Name: "nats", State: "running", PID: 123, Uptime: UptimeVitals{Secs: 600}, Memory: MemoryVitals{Kb: 2048}},
+				{Name: "redis", State: "not monitored"},
+			}))
+		})
+	})
+
+	Describe("job dependencies", func() {
+		BeforeEach(func() {
+			withUnits("bosh-job-nats-tls.service", "bosh-job-nats.service", "bosh-job-redis.service", "bosh-job-other.service")
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-nats.service", "[Unit]\nX-BoshJob=nats\n")).To(Succeed())
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-nats-tls.service", "[Unit]\nX-BoshJob=nats\n")).To(Succeed())
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-redis.service", "[Unit]\nX-BoshJob=redis\n")).To(Succeed())
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-other.service", "[Unit]\nX-BoshJob=other\n")).To(Succeed())
+
+			Expect(systemd.SetJobDependencies(JobDependencies{"redis": {"nats"}, "nats": nil, "other": nil})).To(Succeed())
+		})
+
+		It("starts jobs after the jobs they depend on", func() {
+			Expect(systemd.Start()).To(Succeed())
+
+			Expect(runner.RunCommands).To(Equal([][]string{
+				{"systemctl", "daemon-reload"},
+				{"systemctl", "start", "bosh-job-nats-tls.service", "bosh-job-nats.service"},
+				{"systemctl", "start", "bosh-job-other.service"},
+				{"systemctl", "start", "bosh-job-redis.service"},
+			}))
+		})
+
+		It("stops jobs in reverse order when waiting for them to stop", func() {
+			_, err := systemd.StopAndWait()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(runner.RunCommands).To(Equal([][]string{
+				{"systemctl", "stop", "bosh-job-redis.service"},
+				{"systemctl", "stop", "bosh-job-other.service"},
+				{"systemctl", "stop", "bosh-job-nats-tls.service", "bosh-job-nats.service"},
+				{"systemctl", "stop", "bosh-job-nats-tls.service", "bosh-job-nats.service", "bosh-job-other.service", "bosh-job-redis.service"},
+			}))
+		})
+
+		It("forgets the dependencies when all jobs are removed", func() {
+			Expect(systemd.RemoveAllJobs()).To(Succeed())
+			Expect(fs.FileExists("/var/vcap/bosh/systemd_job_dependencies.json")).To(BeFalse())
+		})
+	})
+
+	Describe("job stop policies", func() {
+		BeforeEach(func() {
+			withUnits("bosh-job-nats-tls.service", "bosh-job-nats.service", "bosh-job-redis.service")
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-nats.service", "[Unit]\nX-BoshJob=nats\n")).To(Succeed())
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-nats-tls.service", "[Unit]\nX-BoshJob=nats\n")).To(Succeed())
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-redis.service", "[Unit]\nX-BoshJob=redis\n")).To(Succeed())
+		})
+
+		It("sets the stop timeout of the units of each job with a drop-in", func() {
+			Expect(systemd.SetJobStopPolicies(JobStopPolicies{
+				"nats":  {Timeout: 120, Escalation: "kill", GracePeriod: 15},
+				"redis": {Timeout: 60},
+			})).To(Succeed())
+
+			for _, unit := range []string{"bosh-job-nats.service", "bosh-job-nats-tls.service"} {
+				dropIn, err := fs.ReadFileString("/etc/systemd/system/" + unit + ".d/bosh-stop.conf")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(dropIn).To(Equal("[Service]\nTimeoutStopSec=135\nSendSIGKILL=yes\n"))
+			}
+
+			dropIn, err := fs.ReadFileString("/etc/systemd/system/bosh-job-redis.service.d/bosh-stop.conf")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dropIn).To(Equal("[Service]\nTimeoutStopSec=60\nSendSIGKILL=no\n"))
+		})
+
+		It("sets the default stop timeout of the units of jobs without a policy", func() {
+			Expect(systemd.SetJobStopPolicies(JobStopPolicies{"nats": {Timeout: 120}})).To(Succeed())
+
+			dropIn, err := fs.ReadFileString("/etc/systemd/system/bosh-job-redis.service.d/bosh-stop.conf")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dropIn).To(Equal("[Service]\nTimeoutStopSec=300\nSendSIGKILL=no\n"))
+		})
+
+		It("reports jobs with units that systemd timed out stopping as killed", func() {
+			runner.AddCmdResult("systemctl show --property=Id,Result bosh-job-nats-tls.service bosh-job-nats.service bosh-job-redis.service", fakesys.FakeCmdResult{
+				Stdout: "Id=bosh-job-nats-tls.service\nResult=success\n\nId=bosh-job-nats.service\nResult=timeout\n\nId=bosh-job-redis.service\nResult=success\n",
+			})
+
+			results, err := systemd.StopAndWait()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(Equal([]JobStopResult{
+				{Job: "nats", Result: "killed", Duration: 0},
+				{Job: "redis", Result: "stopped", Duration: 0},
+			}))
+		})
+	})
+
+	Describe("StartJob and StopJob", func() {
+		const showCmd = "systemctl show --property=Id,ActiveState,SubState,MainPID,MemoryCurrent,ActiveEnterTimestampMonotonic bosh-job-nats-tls.service bosh-job-nats.service bosh-job-redis.service"
+
+		BeforeEach(func() {
+			withUnits("bosh-job-nats-tls.service", "bosh-job-nats.service", "bosh-job-redis.service")
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-nats.service", "[Unit]\nX-BoshJob=nats\n")).To(Succeed())
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-nats-tls.service", "[Unit]\nX-BoshJob=nats\n")).To(Succeed())
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-redis.service", "[Unit]\nX-BoshJob=redis\n")).To(Succeed())
+
+			runner.AddCmdResult(showCmd, fakesys.FakeCmdResult{
+				Sticky: true,
+				Stdout: "Id=bosh-job-nats.service\nActiveState=inactive\n\nId=bosh-job-nats-tls.service\nActiveState=inactive\n\nId=bosh-job-redis.service\nActiveState=active\n",
+			})
+		})
+
+		It("stops the units of a job and waits for them", func() {
+			Expect(systemd.StopJob("nats")).To(Succeed())
+
+			Expect(runner.RunCommands).To(Equal([][]string{{"systemctl", "stop", "bosh-job-nats-tls.service", "bosh-job-nats.service"}}))
+			Expect(systemd.Status()).To(Equal("running"))
+			Expect(systemd.JobStatuses()).To(Equal(map[string]string{"nats": "stopped", "redis": "running"}))
+		})
+
+		It("starts the units of a single process, keeping other jobs stopped", func() {
+			Expect(fs.WriteFileString("/var/vcap/bosh/systemd_stopped", "")).To(Succeed())
+
+			Expect(systemd.StartJob("redis")).To(Succeed())
+
+			Expect(runner.RunCommands).To(Equal([][]string{
+				{"systemctl", "daemon-reload"},
+				{"systemctl", "start", "bosh-job-redis.service"},
+			}))
+			Expect(systemd.Status()).To(Equal("running"))
+			Expect(systemd.JobStatuses()).To(Equal(map[string]string{"nats": "stopped", "redis": "running"}))
+		})
+
+		It("returns an error for unknown jobs", func() {
+			err := systemd.StartJob("unknown")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unknown job or process 'unknown'"))
+		})
+	})
+
+	Describe("process names not allowed in unit names", func() {
+		const unit = `bosh-job-nats\x20server\x2f1.service`
+
+		BeforeEach(func() {
+			err := fs.WriteFileString("/var/vcap/jobs/nats/processes.yml", "processes:\n- name: nats server/1\n  executable: /bin/nats\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(systemd.AddJob("nats", 0, "/var/vcap/jobs/nats/monit")).To(Succeed())
+			withUnits(unit)
+
+			runner.AddCmdResult("systemctl show --property=Id,ActiveState,SubState,MainPID,MemoryCurrent,ActiveEnterTimestampMonotonic "+unit, fakesys.FakeCmdResult{
+				Sticky: true,
+				Stdout: "Id=" + unit + "\nActiveState=inactive\n",
+			})
+		})
+
+		It("escapes them in the unit name", func() {
+			Expect(fs.FileExists("/etc/systemd/system/" + unit)).To(BeTrue())
+		})
+
+		It("reports and resolves processes by their own name", func() {
+			Expect(systemd.StopJob("nats server/1")).To(Succeed())
+			Expect(runner.RunCommands).To(Equal([][]string{{"systemctl", "stop", unit}}))
+
+			Expect(systemd.Status()).To(Equal("stopped"))
+			Expect(systemd.JobStatuses()).To(Equal(map[string]string{"nats": "stopped"}))
+
+			processes, err := systemd.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes).To(Equal([]Process{{Name: "nats server/1", Job: "nats", State: "not monitored"}}))
+		})
+	})
+
+	Describe("RemoveAllJobs", func() {
+		It("removes all units and their drop-ins", func() {
+			withUnits("bosh-job-nats.service")
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-nats.service", "")).To(Succeed())
+			Expect(fs.WriteFileString("/etc/systemd/system/bosh-job-nats.service.d/bosh-unmonitor.conf", "")).To(Succeed())
+
+			Expect(systemd.RemoveAllJobs()).To(Succeed())
+
+			Expect(fs.FileExists("/etc/systemd/system/bosh-job-nats.service")).To(BeFalse())
+			Expect(fs.FileExists("/etc/systemd/system/bosh-job-nats.service.d/bosh-unmonitor.conf")).To(BeFalse())
+		})
+	})
+
+	Describe("MonitorJobFailures", func() {
+		It("reports process exits and unit failures from the journal", func() {
+			runner.AddCmdResult("journalctl --follow --lines=0 --output=json --unit bosh-job-*.service", fakesys.FakeCmdResult{
+				Stdout: `{"MESSAGE_ID":"98e322203f7a4ed290d09fe03c09fe15","UNIT":"bosh-job-nats.service","MESSAGE":"bosh-job-nats.service: Main process exited, code=killed, status=9/KILL","__CURSOR":"c1","__REALTIME_TIMESTAMP":"1700000000000000"}
+{"MESSAGE":"unrelated","__CURSOR":"c2"}
+{"MESSAGE_ID":"d9b373ed55a64feb8242e02dbe79a49c","UNIT":"ssh.service","MESSAGE":"ssh failed","__CURSOR":"c3"}
+{"MESSAGE_ID":"d9b373ed55a64feb8242e02dbe79a49c","UNIT":"bosh-agent.service","MESSAGE":"bosh-agent.service: Failed with result 'exit-code'.","__CURSOR":"c3a"}
+{"MESSAGE_ID":"d9b373ed55a64feb8242e02dbe79a49c","UNIT":"bosh-job-nats.service","MESSAGE":"bosh-job-nats.service: Failed with result 'signal'.","__CURSOR":"c4","__REALTIME_TIMESTAMP":"1700000001000000"}
+`,
+			})
+
+			alerts := []boshalert.MonitAlert{}
+			err := systemd.MonitorJobFailures(func(alert boshalert.MonitAlert) error {
+				alerts = append(alerts, alert)
+				return nil
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Stopped following journal"))
+
+			Expect(alerts).To(Equal([]boshalert.MonitAlert{
+				{
+					ID:          "c1",
+					Service:     "nats",
+					Event:       "does not exist",
+					Action:      "restart",
+					Date:        time.Unix(1700000000, 0).Format(time.RFC1123Z),
+					Description: "bosh-job-nats.service: Main process exited, code=killed, status=9/KILL",
+				},
+				{
+					ID:          "c4",
+					Service:     "nats",
+					Event:       "execution failed",
+					Action:      "alert",
+					Date:        time.Unix(1700000001, 0).Format(time.RFC1123Z),
+					Description: "bosh-job-nats.service: Failed with result 'signal'.",
+				},
+			}))
+		})
+	})
+})
@@ -249,9 +249,9 @@ func (w *windowsJobSupervisor) Stop() error {
 	return nil
 }
 
-func (w *windowsJobSupervisor) StopAndWait() error {
+func (w *windowsJobSupervisor) StopAndWait() ([]JobStopResult, error) {
 	// Stop already does this for us
-	return w.Stop()
+	return []JobStopResult{}, w.Stop()
 }
 
 func (w *windowsJobSupervisor) Unmonitor() error {
@@ -259,6 +259,18 @@ func (w *windowsJobSupervisor) Unmonitor() error {
 	return w.mgr.Unmonitor()
 }
 
+func (w *windowsJobSupervisor) StartJob(name string) error {
+	return bosherr.Error("Starting a single job is not supported on windows")
+}
+
+func (w *windowsJobSupervisor) StopJob(name string) error {
+	return bosherr.Error("Stopping a single job is not supported on windows")
+}
+
+func (w *windowsJobSupervisor) JobStatuses() map[string]string {
+	return map[string]string{}
+}
+
 func (w *windowsJobSupervisor) Status() (status string) {
 	if w.fs.FileExists(w.stoppedFilePath()) {
 		return "stopped"
@@ -396,6 +408,16 @@ func (w *windowsJobSupervisor) RemoveAllJobs() error {
 	return w.mgr.Delete()
 }
 
+// SetJobDependencies is a no-op as windows services are started together
+func (w *windowsJobSupervisor) SetJobDependencies(dependencies JobDependencies) error {
+	return nil
+}
+
+// SetJobStopPolicies is a no-op as windows services are stopped by the service manager
+func (w *windowsJobSupervisor) SetJobStopPolicies(policies JobStopPolicies) error {
+	return nil
+}
+
 type windowsServiceEvent struct {
 	Event       string `json:"event"`
 	ProcessName string `json:"processName"`
@@ -746,7 +746,8 @@ var _ = Describe("WindowsJobSupervisor", func() {
 
 				It("waits for the services to be stopped", func() {
 					Expect(jobSupervisor.Start()).To(Succeed())
-					Expect(jobSupervisor.StopAndWait()).To(Succeed())
+					_, err := jobSupervisor.StopAndWait()
+					Expect(err).ToNot(HaveOccurred())
 
 					for _, proc := range conf.Processes {
 						st, err := GetServiceState(proc.Name)
@@ -944,7 +945,8 @@ var _ = Describe("WindowsJobSupervisor", func() {
 
 				Context("StopAndWait", func() {
 					It("stops flapping service", func() {
-						Expect(jobSupervisor.StopAndWait()).To(Succeed())
+						_, err := jobSupervisor.StopAndWait()
+						Expect(err).ToNot(HaveOccurred())
 
 						Consistently(func() bool {
 							stopped := true
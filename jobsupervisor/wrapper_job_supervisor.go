@@ -1,9 +1,14 @@
 package jobsupervisor
 
 import (
-	"encoding/json"
 	"path/filepath"
 
+	"code.cloudfoundry.org/clock"
+
+	boshalert "github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/alertserver"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
 	"github.com/cloudfoundry/bosh-agent/v2/settings/directories"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/cloudfoundry/bosh-utils/system"
@@ -12,18 +17,39 @@ import (
 const wrapperJobSupervisorLogTag = "wrapperJobSupervisor"
 
 type wrapperJobSupervisor struct {
-	delegate    JobSupervisor
-	fs          system.FileSystem
-	dirProvider directories.Provider
-	logger      boshlog.Logger
+	delegate      JobSupervisor
+	fs            system.FileSystem
+	dirProvider   directories.Provider
+	probeManager  probe.Manager
+	cgroupManager cgroup.Manager
+	alertServer   alertserver.Server
+	logger        boshlog.Logger
+
+	probedJobDirs map[string]bool
+	health        *healthTracker
 }
 
-func NewWrapperJobSupervisor(delegate JobSupervisor, fs system.FileSystem, dirProvider directories.Provider, logger boshlog.Logger) JobSupervisor {
+func NewWrapperJobSupervisor(
+	delegate JobSupervisor,
+	fs system.FileSystem,
+	dirProvider directories.Provider,
+	probeManager probe.Manager,
+	cgroupManager cgroup.Manager,
+	alertServer alertserver.Server,
+	timeService clock.Clock,
+	logger boshlog.Logger,
+) JobSupervisor {
 	return &wrapperJobSupervisor{
-		delegate:    delegate,
-		fs:          fs,
-		dirProvider: dirProvider,
-		logger:      logger,
+		delegate:      delegate,
+		fs:            fs,
+		dirProvider:   dirProvider,
+		probeManager:  probeManager,
+		cgroupManager: cgroupManager,
+		alertServer:   alertServer,
+		logger:        logger,
+
+		probedJobDirs: map[string]bool{},
+		health:        newHealthTracker(timeService),
 	}
 }
 
@@ -32,50 +58,138 @@ func (w *wrapperJobSupervisor) Reload() error {
 }
 func (w *wrapperJobSupervisor) Start() error {
 	err := w.delegate.Start()
-	w.HealthRecorder(w.delegate.Status())
+	w.HealthRecorder(w.Status())
 
 	return err
 }
 func (w *wrapperJobSupervisor) Stop() error {
 	err := w.delegate.Stop()
-	w.HealthRecorder(w.delegate.Status())
+	w.HealthRecorder(w.Status())
 
 	return err
 }
-func (w *wrapperJobSupervisor) StopAndWait() error {
+func (w *wrapperJobSupervisor) StopAndWait() ([]JobStopResult, error) {
 	return w.delegate.StopAndWait()
 }
+func (w *wrapperJobSupervisor) StartJob(name string) error {
+	err := w.delegate.StartJob(name)
+	w.HealthRecorder(w.Status())
+
+	return err
+}
+func (w *wrapperJobSupervisor) StopJob(name string) error {
+	err := w.delegate.StopJob(name)
+	w.HealthRecorder(w.Status())
+
+	return err
+}
 func (w *wrapperJobSupervisor) Unmonitor() error {
 	err := w.delegate.Unmonitor()
 	if err != nil {
 		return err
 	}
 
-	w.HealthRecorder(w.delegate.Status())
+	w.HealthRecorder(w.Status())
 	return err
 }
+
+// Status reports "unhealthy" instead of "running" when any of
+// the health probes declared by the jobs is failing
 func (w *wrapperJobSupervisor) Status() string {
-	return w.delegate.Status()
+	status := w.delegate.Status()
+	if status == "running" && !w.probeManager.Healthy() {
+		return "unhealthy"
+	}
+	return status
+}
+func (w *wrapperJobSupervisor) JobStatuses() map[string]string {
+	return w.delegate.JobStatuses()
 }
 func (w *wrapperJobSupervisor) Processes() ([]Process, error) {
 	return w.delegate.Processes()
 }
 func (w *wrapperJobSupervisor) AddJob(jobName string, jobIndex int, configPath string) error {
+	// Jobs with additional *.monit files are added multiple times
+	// but their probes and cgroup should only be set up once.
+	// The cgroup is set up first since it decides how the job starts.
+	jobDir := filepath.Dir(configPath)
+	if !w.probedJobDirs[jobDir] {
+		job := jobNameForConfig(jobName, configPath)
+
+		err := w.cgroupManager.AddJob(job, filepath.Join(jobDir, "resources.yml"))
+		if err != nil {
+			return err
+		}
+
+		err = w.probeManager.AddJob(job, filepath.Join(jobDir, "health.yml"))
+		if err != nil {
+			return err
+		}
+
+		w.probedJobDirs[jobDir] = true
+	}
+
 	return w.delegate.AddJob(jobName, jobIndex, configPath)
 }
 func (w *wrapperJobSupervisor) RemoveAllJobs() error {
+	w.probeManager.RemoveAllJobs()
+	w.probedJobDirs = map[string]bool{}
+
+	err := w.cgroupManager.RemoveAllJobs()
+	if err != nil {
+		return err
+	}
+
 	return w.delegate.RemoveAllJobs()
 }
+func (w *wrapperJobSupervisor) SetJobDependencies(dependencies JobDependencies) error {
+	return w.delegate.SetJobDependencies(dependencies)
+}
+func (w *wrapperJobSupervisor) SetJobStopPolicies(policies JobStopPolicies) error {
+	return w.delegate.SetJobStopPolicies(policies)
+}
+
+// MonitorJobFailures records the last failure of each process for the health
+// document. Alerts raised by jobs through the alert server, when there is one,
+// are handled like the alerts of the delegate.
 func (w *wrapperJobSupervisor) MonitorJobFailures(handler JobFailureHandler) error {
-	return w.delegate.MonitorJobFailures(handler)
+	recordingHandler := func(alert boshalert.MonitAlert) error {
+		w.health.recordFailure(alert)
+		return handler(alert)
+	}
+
+	if w.alertServer != nil {
+		go func() {
+			err := w.alertServer.Serve(recordingHandler)
+			if err != nil {
+				w.logger.Error(wrapperJobSupervisorLogTag, "Stopped accepting job alerts: %s", err.Error())
+			}
+		}()
+	}
+
+	return w.delegate.MonitorJobFailures(recordingHandler)
 }
 
+// HealthRecorder is called with every heartbeat
 func (w *wrapperJobSupervisor) HealthRecorder(status string) {
-	healthRaw, err := json.Marshal(Health{State: status})
+	healthPath := filepath.Join(w.dirProvider.InstanceDir(), "health.json")
+
+	if !w.health.loaded() {
+		previous, err := ReadHealth(w.fs, healthPath)
+		if err != nil {
+			w.logger.Warn(wrapperJobSupervisorLogTag, "Ignoring previous health: %s", err.Error())
+		}
+		w.health.load(previous)
+	}
+
+	processes, err := w.delegate.Processes()
 	if err != nil {
-		w.logger.Error(wrapperJobSupervisorLogTag, err.Error())
+		w.logger.Debug(wrapperJobSupervisorLogTag, "Not recording process health: %s", err.Error())
 	}
-	err = w.fs.WriteFile(filepath.Join(w.dirProvider.InstanceDir(), "health.json"), healthRaw)
+
+	health := w.health.observe(status, w.delegate.JobStatuses(), processes, w.probeManager.Results())
+
+	err = writeHealth(w.fs, healthPath, health)
 	if err != nil {
 		w.logger.Error(wrapperJobSupervisorLogTag, err.Error())
 	}
@@ -6,9 +6,17 @@ import (
 	"encoding/json"
 	"errors"
 	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
 
 	"github.com/cloudfoundry/bosh-agent/v2/agent/alert"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/alertserver"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/alertserver/alertserverfakes"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/cgroup/cgroupfakes"
 	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/fakes"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe"
+	"github.com/cloudfoundry/bosh-agent/v2/jobsupervisor/probe/probefakes"
 	boshdir "github.com/cloudfoundry/bosh-agent/v2/settings/directories"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
@@ -23,6 +31,10 @@ var _ = Describe("WrapperJobSupervisor", func() {
 		logger         boshlog.Logger
 		dirProvider    boshdir.Provider
 		fakeSupervisor *fakes.FakeJobSupervisor
+		probeManager   *probefakes.FakeManager
+		cgroupManager  *cgroupfakes.FakeManager
+		alertServer    *alertserverfakes.FakeServer
+		timeService    *fakeclock.FakeClock
 		wrapper        JobSupervisor
 	)
 
@@ -34,11 +46,20 @@ var _ = Describe("WrapperJobSupervisor", func() {
 		dirProvider = boshdir.NewProvider("/var/vcap")
 
 		fakeSupervisor = fakes.NewFakeJobSupervisor()
+		probeManager = &probefakes.FakeManager{}
+		cgroupManager = &cgroupfakes.FakeManager{}
+		alertServer = &alertserverfakes.FakeServer{}
+		probeManager.HealthyReturns(true)
+		timeService = fakeclock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
 
 		wrapper = NewWrapperJobSupervisor(
 			fakeSupervisor,
 			fs,
 			dirProvider,
+			probeManager,
+			cgroupManager,
+			alertServer,
+			timeService,
 			logger,
 		)
 	})
@@ -86,7 +107,7 @@ var _ = Describe("WrapperJobSupervisor", func() {
 	It("StopAndWait should delegate to the underlying job supervisor", func() {
 		boomError := errors.New("BOOM")
 		fakeSupervisor.StopErr = boomError
-		err := wrapper.StopAndWait()
+		_, err := wrapper.StopAndWait()
 		Expect(fakeSupervisor.StoppedAndWaited).To(BeTrue())
 		Expect(err).To(Equal(boomError))
 	})
@@ -115,12 +136,71 @@ var _ = Describe("WrapperJobSupervisor", func() {
 
 	})
 
+	Describe("StartJob and StopJob", func() {
+		It("should delegate to the underlying job supervisor", func() {
+			boomError := errors.New("BOOM")
+			fakeSupervisor.StopJobErr = boomError
+			Expect(wrapper.StartJob("fake-job")).To(Succeed())
+			Expect(wrapper.StopJob("fake-job")).To(Equal(boomError))
+			Expect(fakeSupervisor.StartedJobs).To(Equal([]string{"fake-job"}))
+			Expect(fakeSupervisor.StoppedJobs).To(Equal([]string{"fake-job"}))
+		})
+
+		It("writes per-job state to the health json", func() {
+			fakeSupervisor.StatusStatus = "running"
+			fakeSupervisor.JobStatusesMap = map[string]string{"fake-job": "stopped", "other-job": "running"}
+			Expect(wrapper.StopJob("fake-job")).To(Succeed())
+
+			healthFile := filepath.Join(dirProvider.InstanceDir(), "health.json")
+			healthRaw, err := fs.ReadFile(healthFile)
+			Expect(err).ToNot(HaveOccurred())
+			health := &Health{}
+			err = json.Unmarshal(healthRaw, health)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(health.State).To(Equal("running"))
+			Expect(health.Jobs).To(HaveLen(2))
+			Expect(health.Jobs["fake-job"].State).To(Equal("stopped"))
+			Expect(health.Jobs["other-job"].State).To(Equal("running"))
+		})
+	})
+
 	It("Status should delegate to the underlying job supervisor", func() {
 		fakeSupervisor.StatusStatus = "my-status"
 		status := wrapper.Status()
 		Expect(status).To(Equal(fakeSupervisor.StatusStatus))
 	})
 
+	Context("when health probes are failing", func() {
+		BeforeEach(func() {
+			probeManager.HealthyReturns(false)
+			probeManager.ResultsReturns([]probe.Result{{Job: "fake-job", Name: "api", Type: probe.TypeHTTP}})
+		})
+
+		It("reports running jobs as unhealthy", func() {
+			fakeSupervisor.StatusStatus = "running"
+			Expect(wrapper.Status()).To(Equal("unhealthy"))
+		})
+
+		It("does not override other states", func() {
+			fakeSupervisor.StatusStatus = "failing"
+			Expect(wrapper.Status()).To(Equal("failing"))
+		})
+
+		It("writes the probe results to the health json", func() {
+			fakeSupervisor.StatusStatus = "running"
+			err := wrapper.Start()
+			Expect(err).NotTo(HaveOccurred())
+
+			healthRaw, err := fs.ReadFile(filepath.Join(dirProvider.InstanceDir(), "health.json"))
+			Expect(err).ToNot(HaveOccurred())
+			health := &Health{}
+			err = json.Unmarshal(healthRaw, health)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(health.State).To(Equal("unhealthy"))
+			Expect(health.Probes).To(Equal([]probe.Result{{Job: "fake-job", Name: "api", Type: probe.TypeHTTP}}))
+		})
+	})
+
 	It("Processes should delegate to the underlying job supervisor", func() {
 		fakeSupervisor.ProcessesStatus = []Process{
 			{},
@@ -144,6 +224,207 @@ var _ = Describe("WrapperJobSupervisor", func() {
 		}))
 	})
 
+	It("AddJob starts the health probes declared next to the job's monit file once per job", func() {
+		err := wrapper.AddJob("fake-job", 0, "/var/vcap/jobs/fake-job/monit")
+		Expect(err).NotTo(HaveOccurred())
+		err = wrapper.AddJob("fake-job_extra", 0, "/var/vcap/jobs/fake-job/extra.monit")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(probeManager.AddJobCallCount()).To(Equal(1))
+		jobName, healthFilePath := probeManager.AddJobArgsForCall(0)
+		Expect(jobName).To(Equal("fake-job"))
+		Expect(healthFilePath).To(Equal("/var/vcap/jobs/fake-job/health.yml"))
+	})
+
+	It("AddJob starts the health probes of jobs with only additional monit files under the job's name", func() {
+		err := wrapper.AddJob("fake-job_extra", 0, "/var/vcap/jobs/fake-job/extra.monit")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(probeManager.AddJobCallCount()).To(Equal(1))
+		jobName, _ := probeManager.AddJobArgsForCall(0)
+		Expect(jobName).To(Equal("fake-job"))
+	})
+
+	It("AddJob returns an error when health probes cannot be started", func() {
+		probeManager.AddJobReturns(errors.New("fake-probe-err"))
+
+		err := wrapper.AddJob("fake-job", 0, "/var/vcap/jobs/fake-job/monit")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-probe-err"))
+	})
+
+	It("AddJob limits resources with the limits declared next to the job's monit file once per job", func() {
+		err := wrapper.AddJob("fake-job", 0, "/var/vcap/jobs/fake-job/monit")
+		Expect(err).NotTo(HaveOccurred())
+		err = wrapper.AddJob("fake-job_extra", 0, "/var/vcap/jobs/fake-job/extra.monit")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cgroupManager.AddJobCallCount()).To(Equal(1))
+		jobName, resourcesFilePath := cgroupManager.AddJobArgsForCall(0)
+		Expect(jobName).To(Equal("fake-job"))
+		Expect(resourcesFilePath).To(Equal("/var/vcap/jobs/fake-job/resources.yml"))
+	})
+
+	It("AddJob returns an error when resources cannot be limited", func() {
+		cgroupManager.AddJobReturns(errors.New("fake-cgroup-err"))
+
+		err := wrapper.AddJob("fake-job", 0, "/var/vcap/jobs/fake-job/monit")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-cgroup-err"))
+	})
+
+	Describe("HealthRecorder", func() {
+		var healthFile string
+
+		readHealth := func() Health {
+			health, err := ReadHealth(fs, healthFile)
+			Expect(err).NotTo(HaveOccurred())
+			return health
+		}
+
+		BeforeEach(func() {
+			healthFile = filepath.Join(dirProvider.InstanceDir(), "health.json")
+			fakeSupervisor.JobStatusesMap = map[string]string{"fake-job": "running"}
+			fakeSupervisor.ProcessesStatus = []Process{
+				{Name: "fake-process", Job: "fake-job", State: "running", PID: 1234},
+			}
+		})
+
+		It("writes the health json atomically", func() {
+			wrapper.HealthRecorder("running")
+
+			Expect(fs.FileExists(healthFile)).To(BeTrue())
+			Expect(fs.FileExists(healthFile + ".tmp")).To(BeFalse())
+		})
+
+		It("keeps the previous health json when the new one cannot be moved into place", func() {
+			Expect(fs.WriteFileString(healthFile, `{"state":"stopped"}`)).To(Succeed())
+			fs.RenameError = errors.New("fake-rename-err")
+
+			wrapper.HealthRecorder("running")
+
+			Expect(readHealth().State).To(Equal("stopped"))
+		})
+
+		It("records the state of each process", func() {
+			wrapper.HealthRecorder("running")
+
+			health := readHealth()
+			Expect(*health.UpdatedAt).To(Equal(timeService.Now()))
+			Expect(health.Jobs["fake-job"].Processes).To(Equal(map[string]ProcessHealth{
+				"fake-process": {State: "running", LastTransition: timeService.Now(), PID: 1234},
+			}))
+		})
+
+		It("keeps the last transition time while the state does not change", func() {
+			startedAt := timeService.Now()
+			wrapper.HealthRecorder("running")
+
+			timeService.Increment(time.Minute)
+			wrapper.HealthRecorder("running")
+
+			health := readHealth()
+			Expect(health.Jobs["fake-job"].LastTransition).To(Equal(startedAt))
+			Expect(health.Jobs["fake-job"].History).To(BeEmpty())
+		})
+
+		It("records a short history of state transitions", func() {
+			wrapper.HealthRecorder("running")
+
+			for i := 0; i < 6; i++ {
+				timeService.Increment(time.Minute)
+				fakeSupervisor.JobStatusesMap = map[string]string{"fake-job": "failing"}
+				wrapper.HealthRecorder("failing")
+
+				timeService.Increment(time.Minute)
+				fakeSupervisor.JobStatusesMap = map[string]string{"fake-job": "running"}
+				wrapper.HealthRecorder("running")
+			}
+
+			job := readHealth().Jobs["fake-job"]
+			Expect(job.LastTransition).To(Equal(timeService.Now()))
+			Expect(job.History).To(HaveLen(10))
+			Expect(job.History[9]).To(Equal(Transition{From: "failing", To: "running", At: timeService.Now()}))
+		})
+
+		It("counts the restarts of processes reported by alerts", func() {
+			wrapper.HealthRecorder("running")
+
+			fakeSupervisor.JobFailureAlert = &alert.MonitAlert{
+				Service: "fake-process",
+				Event:   "does not exist",
+				Action:  "restart",
+			}
+			Expect(wrapper.MonitorJobFailures(func(alert.MonitAlert) error { return nil })).To(Succeed())
+
+			fakeSupervisor.ProcessesStatus = []Process{
+				{Name: "fake-process", Job: "fake-job", State: "running", PID: 5678},
+			}
+			wrapper.HealthRecorder("running")
+			wrapper.HealthRecorder("running")
+
+			job := readHealth().Jobs["fake-job"]
+			Expect(job.Restarts).To(Equal(1))
+			Expect(job.Processes["fake-process"].Restarts).To(Equal(1))
+			Expect(job.Processes["fake-process"].PID).To(Equal(5678))
+		})
+
+		It("does not count a new pid without a reported restart as a restart", func() {
+			wrapper.HealthRecorder("running")
+
+			fakeSupervisor.ProcessesStatus = []Process{
+				{Name: "fake-process", Job: "fake-job", State: "running", PID: 5678},
+			}
+			wrapper.HealthRecorder("running")
+
+			job := readHealth().Jobs["fake-job"]
+			Expect(job.Restarts).To(Equal(0))
+			Expect(job.Processes["fake-process"].Restarts).To(Equal(0))
+		})
+
+		It("records the last failure reported by alerts", func() {
+			fakeSupervisor.JobFailureAlert = &alert.MonitAlert{
+				Service:     "fake-process",
+				Event:       "pid failed",
+				Description: "process is not running",
+			}
+			Expect(wrapper.MonitorJobFailures(func(alert.MonitAlert) error { return nil })).To(Succeed())
+
+			wrapper.HealthRecorder("failing")
+
+			failure := &Failure{Event: "pid failed", Description: "process is not running", At: timeService.Now()}
+			job := readHealth().Jobs["fake-job"]
+			Expect(job.LastFailure).To(Equal(failure))
+			Expect(job.Processes["fake-process"].LastFailure).To(Equal(failure))
+		})
+
+		It("continues from the health recorded before the agent restarted", func() {
+			recordedAt := timeService.Now().Add(-time.Hour)
+			previous, err := json.Marshal(Health{
+				State: "running",
+				Jobs: map[string]JobHealth{
+					"fake-job": {
+						State:          "running",
+						LastTransition: recordedAt,
+						Restarts:       2,
+						Processes: map[string]ProcessHealth{
+							"fake-process": {State: "running", LastTransition: recordedAt, PID: 1000, Restarts: 2},
+						},
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fs.WriteFile(healthFile, previous)).To(Succeed())
+
+			wrapper.HealthRecorder("running")
+
+			job := readHealth().Jobs["fake-job"]
+			Expect(job.LastTransition).To(Equal(recordedAt))
+			Expect(job.Restarts).To(Equal(2))
+			Expect(job.Processes["fake-process"].Restarts).To(Equal(2))
+		})
+	})
+
 	It("RemoveAllJobs should delegate to the underlying job supervisor", func() {
 		fakeSupervisor.RemovedAllJobsErr = errors.New("BOOM")
 		err := wrapper.RemoveAllJobs()
@@ -151,6 +432,16 @@ var _ = Describe("WrapperJobSupervisor", func() {
 		Expect(err).To(Equal(fakeSupervisor.RemovedAllJobsErr))
 	})
 
+	It("RemoveAllJobs stops all health probes", func() {
+		_ = wrapper.AddJob("fake-job", 0, "/var/vcap/jobs/fake-job/monit")
+		_ = wrapper.RemoveAllJobs()
+		Expect(probeManager.RemoveAllJobsCallCount()).To(Equal(1))
+		Expect(cgroupManager.RemoveAllJobsCallCount()).To(Equal(1))
+
+		_ = wrapper.AddJob("fake-job", 0, "/var/vcap/jobs/fake-job/monit")
+		Expect(probeManager.AddJobCallCount()).To(Equal(2))
+	})
+
 	It("MonitorJobFailures should delegate to the underlying job supervisor", func() {
 		var testAlert *alert.MonitAlert
 
@@ -162,4 +453,18 @@ var _ = Describe("WrapperJobSupervisor", func() {
 		})
 		Expect(testAlert).To(Equal(fakeSupervisor.JobFailureAlert))
 	})
+	It("MonitorJobFailures passes alerts raised through the alert server to the handler", func() {
+		alerts := make(chan alert.MonitAlert, 1)
+
+		alertServer.ServeStub = func(handler alertserver.AlertHandler) error {
+			return handler(alert.MonitAlert{ID: "job-alert", Service: "fake-process", Event: "job alert"})
+		}
+
+		Expect(wrapper.MonitorJobFailures(func(a alert.MonitAlert) error {
+			alerts <- a
+			return nil
+		})).To(Succeed())
+
+		Eventually(alerts).Should(Receive(Equal(alert.MonitAlert{ID: "job-alert", Service: "fake-process", Event: "job alert"})))
+	})
 })
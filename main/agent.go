@@ -60,6 +60,8 @@ func startAgent(logger logger.Logger) error {
 		os.Exit(0)
 	}
 
+	opts.VersionLabel = VersionLabel
+
 	sigCh := make(chan os.Signal, 8)
 	// `os.Kill` can not be intercepted on UNIX OS's, possibly necessary for Windows?
 	signal.Notify(sigCh, syscall.SIGTERM, os.Interrupt, os.Kill) //nolint:staticcheck
@@ -23,6 +23,14 @@ type FakeHandler struct {
 
 	SendCallback func(SendInput)
 	SendErr      error
+
+	publishInputs []PublishInput
+	PublishErr    error
+}
+
+type PublishInput struct {
+	Subject string
+	Message interface{}
 }
 
 type SendInput struct {
@@ -83,6 +91,22 @@ func (h *FakeHandler) Send(target boshhandler.Target, topic boshhandler.Topic, m
 	return h.SendErr
 }
 
+func (h *FakeHandler) Publish(subject string, message interface{}) error {
+	h.sendLock.Lock()
+	defer h.sendLock.Unlock()
+
+	h.publishInputs = append(h.publishInputs, PublishInput{Subject: subject, Message: message})
+
+	return h.PublishErr
+}
+
+func (h *FakeHandler) PublishInputs() []PublishInput {
+	h.sendLock.Lock()
+	defer h.sendLock.Unlock()
+
+	return h.publishInputs
+}
+
 func (h *FakeHandler) SendInputs() []SendInput {
 	h.sendLock.Lock()
 	defer h.sendLock.Unlock()
@@ -3,9 +3,11 @@ package mbus
 import (
 	"net/url"
 
+	"code.cloudfoundry.org/clock"
 	"github.com/nats-io/nats.go"
 
 	boshagentblobstore "github.com/cloudfoundry/bosh-agent/v2/agent/blobstore"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/logtail"
 	boshhandler "github.com/cloudfoundry/bosh-agent/v2/handler"
 	boshplatform "github.com/cloudfoundry/bosh-agent/v2/platform"
 	boshsettings "github.com/cloudfoundry/bosh-agent/v2/settings"
@@ -52,7 +54,8 @@ func (p HandlerProvider) Get(
 		return NewNatsHandler(p.settingsService, f, p.logger, platform), nil
 	case "https":
 		mbusKeyPair := p.settingsService.GetSettings().GetMbusCerts()
-		return NewHTTPSHandler(mbusURL, mbusKeyPair, blobManager, p.logger, p.auditLogger), nil
+		logTailer := logtail.NewTailer(platform.GetFs(), platform.GetDirProvider().LogsDir(), clock.NewClock(), p.logger)
+		return NewHTTPSHandler(mbusURL, mbusKeyPair, blobManager, logTailer, p.logger, p.auditLogger), nil
 	default:
 		return nil, bosherr.Errorf("Message Bus Handler with scheme %s could not be found", mbusURL.Scheme)
 	}
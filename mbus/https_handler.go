@@ -2,15 +2,20 @@ package mbus
 
 import (
 	"bufio"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
+	"strings"
 
 	"github.com/cloudfoundry/bosh-agent/v2/platform"
 	"github.com/cloudfoundry/bosh-agent/v2/settings"
 
 	boshagentblobstore "github.com/cloudfoundry/bosh-agent/v2/agent/blobstore"
+	"github.com/cloudfoundry/bosh-agent/v2/agent/logtail"
 	boshhandler "github.com/cloudfoundry/bosh-agent/v2/handler"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -21,6 +26,7 @@ const httpsHandlerLogTag = "https_handler"
 type HTTPSHandler struct {
 	parsedURL   *url.URL
 	blobManager boshagentblobstore.BlobManagerInterface
+	logTailer   logtail.Tailer
 	logger      boshlog.Logger
 	dispatcher  *HTTPSDispatcher
 	auditLogger platform.AuditLogger
@@ -30,6 +36,7 @@ func NewHTTPSHandler(
 	parsedURL *url.URL,
 	keyPair settings.CertKeyPair,
 	blobManager boshagentblobstore.BlobManagerInterface,
+	logTailer logtail.Tailer,
 	logger boshlog.Logger,
 	auditLogger platform.AuditLogger,
 ) HTTPSHandler {
@@ -37,6 +44,7 @@ func NewHTTPSHandler(
 		parsedURL:   parsedURL,
 		logger:      logger,
 		blobManager: blobManager,
+		logTailer:   logTailer,
 		dispatcher:  NewHTTPSDispatcher(parsedURL, keyPair, logger),
 		auditLogger: auditLogger,
 	}
@@ -53,6 +61,7 @@ func (h HTTPSHandler) Run(handlerFunc boshhandler.Func) error {
 func (h HTTPSHandler) Start(handlerFunc boshhandler.Func) error {
 	h.dispatcher.AddRoute("/agent", h.agentHandler(handlerFunc))
 	h.dispatcher.AddRoute("/blobs/", h.blobsHandler())
+	h.dispatcher.AddRoute("/logs", h.logsHandler())
 	return h.dispatcher.Start()
 }
 
@@ -68,6 +77,10 @@ func (h HTTPSHandler) Send(target boshhandler.Target, topic boshhandler.Topic, m
 	return nil
 }
 
+func (h HTTPSHandler) Publish(subject string, message interface{}) error {
+	return nil
+}
+
 func (h HTTPSHandler) agentHandler(handlerFunc boshhandler.Func) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
@@ -163,6 +176,111 @@ func (h HTTPSHandler) getBlob(w http.ResponseWriter, r *http.Request) {
 	h.generateCEFLog(r, statusCode, "")
 }
 
+// logsHandler streams the lines appended to the log files selected by the
+// filter, offset (<file>:<offset>) and duration query parameters. Lines are
+// sent as server-sent events when requested and as JSON lines otherwise.
+func (h HTTPSHandler) logsHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(404)
+			h.generateCEFLog(r, 404, "")
+			return
+		}
+
+		opts, err := tailOptions(r.URL.Query())
+		if err != nil {
+			w.WriteHeader(400)
+			h.generateCEFLog(r, 400, "")
+			if _, wErr := w.Write([]byte(err.Error())); wErr != nil {
+				h.logger.Error(httpsHandlerLogTag, "Failed to write response body: %s", wErr.Error())
+			}
+			return
+		}
+
+		flusher, _ := w.(http.Flusher)
+		eventStream := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+
+		if eventStream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+		} else {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+		}
+		w.WriteHeader(200)
+		h.generateCEFLog(r, 200, "")
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		err = h.logTailer.Tail(opts, r.Context().Done(), func(lines []logtail.Line) error {
+			for _, line := range lines {
+				lineJSON, err := json.Marshal(line)
+				if err != nil {
+					return bosherr.WrapError(err, "Marshalling log line")
+				}
+
+				if eventStream {
+					_, err = fmt.Fprintf(w, "data: %s\n\n", lineJSON)
+				} else {
+					_, err = fmt.Fprintf(w, "%s\n", lineJSON)
+				}
+				if err != nil {
+					return bosherr.WrapError(err, "Writing log line")
+				}
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			h.logger.Error(httpsHandlerLogTag, "Failed to tail logs: %s", err.Error())
+			return
+		}
+
+		// Tells event stream clients not to reconnect once the tail's lifetime has passed
+		if eventStream {
+			if _, wErr := fmt.Fprint(w, "event: done\ndata: {}\n\n"); wErr != nil {
+				h.logger.Error(httpsHandlerLogTag, "Failed to write response body: %s", wErr.Error())
+			}
+		}
+	}
+}
+
+func tailOptions(query url.Values) (logtail.Options, error) {
+	opts := logtail.Options{
+		Filters: query["filter"],
+		Offsets: map[string]int64{},
+	}
+
+	for _, offset := range query["offset"] {
+		i := strings.LastIndex(offset, ":")
+		if i < 0 {
+			return opts, bosherr.Errorf("Offset '%s' must be <file>:<offset>", offset)
+		}
+
+		value, err := strconv.ParseInt(offset[i+1:], 10, 64)
+		if err != nil {
+			return opts, bosherr.WrapErrorf(err, "Parsing offset '%s'", offset)
+		}
+
+		opts.Offsets[offset[:i]] = value
+	}
+
+	if duration := query.Get("duration"); duration != "" {
+		value, err := strconv.Atoi(duration)
+		if err != nil {
+			return opts, bosherr.WrapErrorf(err, "Parsing duration '%s'", duration)
+		}
+
+		opts.Duration = value
+	}
+
+	return opts, opts.Validate()
+}
+
 func (h HTTPSHandler) generateCEFLog(r *http.Request, respStatusCode int, respJSON string) {
 	cef := boshhandler.NewCommonEventFormat()
 
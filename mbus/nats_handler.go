@@ -40,6 +40,7 @@ type Handler interface {
 	Start(boshhandler.Func) error
 	RegisterAdditionalFunc(boshhandler.Func)
 	Send(target boshhandler.Target, topic boshhandler.Topic, message interface{}) error
+	Publish(subject string, message interface{}) error
 	Stop()
 }
 
@@ -220,6 +221,21 @@ func (h *natsHandler) Send(target boshhandler.Target, topic boshhandler.Topic, m
 	return nil
 }
 
+func (h *natsHandler) Publish(subject string, message interface{}) error {
+	bytes, err := json.Marshal(message)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Marshalling message (subject=%s)", subject)
+	}
+
+	// Log streams publish several messages a second, so only log the subject
+	h.logger.Debug(h.logTag, "Publishing message to '%s'", subject)
+
+	if h.connection != nil {
+		return h.connection.Publish(subject, bytes)
+	}
+	return nil
+}
+
 func (h *natsHandler) Stop() {
 	if h.connection != nil {
 		h.connection.Close()
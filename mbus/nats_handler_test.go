@@ -427,6 +427,24 @@ func init() { //nolint:funlen,gochecknoinits
 				Expect(message).To(Equal([]byte("{\"key1\":\"value1\",\"keyA\":\"valueA\"}")))
 			})
 		})
+
+		Describe("Publish", func() {
+			It("publishes the message over nats to the subject as is", func() {
+				err := handler.Start(func(req boshhandler.Request) (resp boshhandler.Response) {
+					return nil
+				})
+				Expect(err).ToNot(HaveOccurred())
+				defer handler.Stop()
+
+				err = handler.Publish("director.tail_logs.my-agent-id.fake-stream-id", map[string]string{"key1": "value1"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(connection.PublishCallCount()).To(Equal(1))
+				subj, message := connection.PublishArgsForCall(0)
+				Expect(subj).To(Equal("director.tail_logs.my-agent-id.fake-stream-id"))
+				Expect(message).To(Equal([]byte("{\"key1\":\"value1\"}")))
+			})
+		})
 	})
 }
 
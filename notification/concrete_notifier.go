@@ -15,3 +15,8 @@ func NewNotifier(handler boshhandler.Handler) Notifier {
 func (n concreteNotifier) NotifyShutdown() error {
 	return n.handler.Send(boshhandler.HealthMonitor, boshhandler.Shutdown, nil)
 }
+
+// NotifyLogLines publishes to the subject of a log stream which clients subscribe to
+func (n concreteNotifier) NotifyLogLines(subject string, message interface{}) error {
+	return n.handler.Publish(subject, message)
+}
@@ -44,4 +44,29 @@ var _ = Describe("concreteNotifier", func() {
 			Expect(err.Error()).To(ContainSubstring("fake-send-error"))
 		})
 	})
+
+	Describe("NotifyLogLines", func() {
+		var (
+			handler  *fakembus.FakeHandler
+			notifier Notifier
+		)
+
+		BeforeEach(func() {
+			handler = fakembus.NewFakeHandler()
+			notifier = NewNotifier(handler)
+		})
+
+		It("publishes the message to the subject of the log stream", func() {
+			err := notifier.NotifyLogLines("director.tail_logs.fake-agent-id.fake-stream-id", "fake-message")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(handler.PublishInputs()).To(Equal([]fakembus.PublishInput{
+				{
+					Subject: "director.tail_logs.fake-agent-id.fake-stream-id",
+					Message: "fake-message",
+				},
+			}))
+			Expect(handler.SendInputs()).To(BeEmpty())
+		})
+	})
 })
@@ -1,8 +1,22 @@
 package fakes
 
+import (
+	"sync"
+)
+
 type FakeNotifier struct {
 	NotifiedShutdown  bool
 	NotifyShutdownErr error
+
+	NotifyLogLinesErr error
+
+	logLinesLock sync.Mutex
+	logLines     []NotifyLogLinesInput
+}
+
+type NotifyLogLinesInput struct {
+	Subject string
+	Message interface{}
 }
 
 func NewFakeNotifier() *FakeNotifier {
@@ -13,3 +27,18 @@ func (n *FakeNotifier) NotifyShutdown() error {
 	n.NotifiedShutdown = true
 	return n.NotifyShutdownErr
 }
+
+func (n *FakeNotifier) NotifyLogLines(subject string, message interface{}) error {
+	n.logLinesLock.Lock()
+	defer n.logLinesLock.Unlock()
+
+	n.logLines = append(n.logLines, NotifyLogLinesInput{Subject: subject, Message: message})
+	return n.NotifyLogLinesErr
+}
+
+func (n *FakeNotifier) NotifiedLogLines() []NotifyLogLinesInput {
+	n.logLinesLock.Lock()
+	defer n.logLinesLock.Unlock()
+
+	return append([]NotifyLogLinesInput{}, n.logLines...)
+}
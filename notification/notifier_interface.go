@@ -2,4 +2,5 @@ package notification
 
 type Notifier interface {
 	NotifyShutdown() (err error)
+	NotifyLogLines(subject string, message interface{}) (err error)
 }
@@ -0,0 +1,254 @@
+package vitals
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+type Sample struct {
+	Timestamp int64  `json:"timestamp"`
+	Vitals    Vitals `json:"vitals"`
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . History
+
+type History interface {
+	Record(vitals Vitals) error
+
+	// Samples returns recorded samples taken between from and to (inclusive),
+	// oldest first; a zero to means no upper bound. When step is greater
+	// than zero only the latest sample of every step-long bucket is returned.
+	Samples(from, to time.Time, step time.Duration) ([]Sample, error)
+}
+
+type fileHistory struct {
+	fs          boshsys.FileSystem
+	path        string
+	retention   time.Duration
+	capacity    int
+	timeService clock.Clock
+
+	mutex   sync.Mutex
+	loaded  bool
+	samples []Sample
+
+	// persisted counts the samples in the file, which only has samples
+	// appended to it until it holds twice as many as are kept
+	persisted int
+	compact   bool
+}
+
+// NewFileHistory returns a History that keeps at most retention/interval
+// samples, persisting them to path so that they survive agent restarts.
+// Samples are appended to the file one per line as they are recorded.
+func NewFileHistory(
+	fs boshsys.FileSystem,
+	path string,
+	retention time.Duration,
+	interval time.Duration,
+	timeService clock.Clock,
+) History {
+	capacity := 1
+	if interval > 0 && retention > interval {
+		capacity = int(retention / interval)
+	}
+
+	return &fileHistory{
+		fs:          fs,
+		path:        path,
+		retention:   retention,
+		capacity:    capacity,
+		timeService: timeService,
+	}
+}
+
+func (h *fileHistory) Record(vitals Vitals) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	err := h.load()
+	if err != nil {
+		return err
+	}
+
+	now := h.timeService.Now()
+
+	sample := Sample{Timestamp: now.Unix(), Vitals: vitals}
+
+	h.samples = append(h.samples, sample)
+	h.expire(now)
+
+	if h.compact || h.persisted >= 2*h.capacity {
+		return h.save()
+	}
+
+	return h.append(sample)
+}
+
+func (h *fileHistory) Samples(from, to time.Time, step time.Duration) ([]Sample, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	err := h.load()
+	if err != nil {
+		return nil, err
+	}
+
+	samples := []Sample{}
+
+	for _, sample := range h.samples {
+		if sample.Timestamp < from.Unix() || (!to.IsZero() && sample.Timestamp > to.Unix()) {
+			continue
+		}
+
+		if step >= time.Second && len(samples) > 0 {
+			lastBucket := (samples[len(samples)-1].Timestamp - from.Unix()) / int64(step.Seconds())
+			bucket := (sample.Timestamp - from.Unix()) / int64(step.Seconds())
+			if bucket == lastBucket {
+				samples[len(samples)-1] = sample
+				continue
+			}
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+func (h *fileHistory) expire(now time.Time) {
+	oldest := now.Add(-h.retention).Unix()
+
+	start := 0
+	for start < len(h.samples) && h.samples[start].Timestamp < oldest {
+		start++
+	}
+
+	if len(h.samples)-start > h.capacity {
+		start = len(h.samples) - h.capacity
+	}
+
+	h.samples = append([]Sample{}, h.samples[start:]...)
+}
+
+func (h *fileHistory) load() error {
+	if h.loaded {
+		return nil
+	}
+
+	h.loaded = true
+	h.samples = []Sample{}
+
+	if !h.fs.FileExists(h.path) {
+		return nil
+	}
+
+	contents, err := h.fs.ReadFile(h.path)
+	if err != nil {
+		return bosherr.WrapError(err, "Reading vitals history")
+	}
+
+	for _, line := range bytes.Split(contents, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		h.persisted++
+
+		var sample Sample
+
+		err = json.Unmarshal(line, &sample)
+		if err != nil {
+			// Skip corrupted samples, e.g. one partially written before a
+			// crash, and rewrite the file so that they do not stay around
+			h.compact = true
+			continue
+		}
+
+		h.samples = append(h.samples, sample)
+	}
+
+	if len(contents) > 0 && contents[len(contents)-1] != '\n' {
+		h.compact = true
+	}
+
+	return nil
+}
+
+func (h *fileHistory) append(sample Sample) error {
+	line, err := json.Marshal(sample)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling vitals sample")
+	}
+
+	err = h.fs.MkdirAll(filepath.Dir(h.path), os.FileMode(0750))
+	if err != nil {
+		return bosherr.WrapError(err, "Creating vitals history directory")
+	}
+
+	file, err := h.fs.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, os.FileMode(0640))
+	if err != nil {
+		return bosherr.WrapError(err, "Opening vitals history")
+	}
+
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		_ = file.Close()
+		h.compact = true
+		return bosherr.WrapError(err, "Appending to vitals history")
+	}
+
+	err = file.Close()
+	if err != nil {
+		return bosherr.WrapError(err, "Closing vitals history")
+	}
+
+	h.persisted++
+
+	return nil
+}
+
+func (h *fileHistory) save() error {
+	var contents []byte
+
+	for _, sample := range h.samples {
+		line, err := json.Marshal(sample)
+		if err != nil {
+			return bosherr.WrapError(err, "Marshalling vitals sample")
+		}
+
+		contents = append(contents, line...)
+		contents = append(contents, '\n')
+	}
+
+	err := h.fs.MkdirAll(filepath.Dir(h.path), os.FileMode(0750))
+	if err != nil {
+		return bosherr.WrapError(err, "Creating vitals history directory")
+	}
+
+	tmpPath := h.path + ".tmp"
+
+	err = h.fs.WriteFile(tmpPath, contents)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing vitals history")
+	}
+
+	err = h.fs.Rename(tmpPath, h.path)
+	if err != nil {
+		return bosherr.WrapError(err, "Renaming vitals history")
+	}
+
+	h.persisted = len(h.samples)
+	h.compact = false
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package vitals_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	. "github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
+)
+
+var _ = Describe("fileHistory", func() {
+	var (
+		fs          boshsys.FileSystem
+		historyPath string
+		timeService *fakeclock.FakeClock
+		start       time.Time
+		history     History
+	)
+
+	BeforeEach(func() {
+		fs = boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+
+		baseDir, err := os.MkdirTemp("", "vitals")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, baseDir)
+
+		historyPath = filepath.Join(baseDir, "bosh", "vitals_history.json")
+		start = time.Unix(1700000000, 0)
+		timeService = fakeclock.NewFakeClock(start)
+		history = NewFileHistory(fs, historyPath, 5*time.Minute, time.Minute, timeService)
+	})
+
+	persistedSamples := func() []Sample {
+		contents, err := fs.ReadFileString(historyPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		samples := []Sample{}
+		for _, line := range strings.Split(strings.TrimSuffix(contents, "\n"), "\n") {
+			var sample Sample
+			Expect(json.Unmarshal([]byte(line), &sample)).To(Succeed())
+			samples = append(samples, sample)
+		}
+		return samples
+	}
+
+	recordEveryMinute := func(count int) {
+		for i := 0; i < count; i++ {
+			err := history.Record(Vitals{Load: []string{string(rune('a' + i))}})
+			Expect(err).ToNot(HaveOccurred())
+			timeService.Increment(time.Minute)
+		}
+	}
+
+	It("returns recorded samples within the requested range", func() {
+		recordEveryMinute(3)
+
+		samples, err := history.Samples(start.Add(time.Minute), start.Add(2*time.Minute), 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(Equal([]Sample{
+			{Timestamp: start.Add(time.Minute).Unix(), Vitals: Vitals{Load: []string{"b"}}},
+			{Timestamp: start.Add(2 * time.Minute).Unix(), Vitals: Vitals{Load: []string{"c"}}},
+		}))
+	})
+
+	It("keeps only as many samples as fit in the retention period", func() {
+		recordEveryMinute(8)
+
+		samples, err := history.Samples(start, timeService.Now(), 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(HaveLen(5))
+		Expect(samples[0].Vitals.Load).To(Equal([]string{"d"}))
+		Expect(samples[4].Vitals.Load).To(Equal([]string{"h"}))
+	})
+
+	It("drops samples older than the retention period", func() {
+		recordEveryMinute(2)
+		timeService.Increment(10 * time.Minute)
+		recordEveryMinute(1)
+
+		samples, err := history.Samples(start, timeService.Now(), 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(HaveLen(1))
+		Expect(samples[0].Vitals.Load).To(Equal([]string{"a"}))
+	})
+
+	It("downsamples to the latest sample of every step", func() {
+		recordEveryMinute(5)
+
+		samples, err := history.Samples(start, timeService.Now(), 2*time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(HaveLen(3))
+		Expect(samples[0].Vitals.Load).To(Equal([]string{"b"}))
+		Expect(samples[1].Vitals.Load).To(Equal([]string{"d"}))
+		Expect(samples[2].Vitals.Load).To(Equal([]string{"e"}))
+	})
+
+	It("persists samples so that they can be loaded after a restart", func() {
+		recordEveryMinute(2)
+
+		persisted := persistedSamples()
+		Expect(persisted).To(HaveLen(2))
+
+		reloaded := NewFileHistory(fs, historyPath, 5*time.Minute, time.Minute, timeService)
+		samples, err := reloaded.Samples(start, timeService.Now(), 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(Equal(persisted))
+	})
+
+	It("appends samples to the file until it holds twice as many as are kept", func() {
+		recordEveryMinute(10)
+		Expect(persistedSamples()).To(HaveLen(10))
+
+		recordEveryMinute(1)
+		persisted := persistedSamples()
+		Expect(persisted).To(HaveLen(5))
+		Expect(persisted[4].Vitals.Load).To(Equal([]string{"a"}))
+	})
+
+	It("starts over when the persisted history is corrupted", func() {
+		Expect(fs.MkdirAll(filepath.Dir(historyPath), 0750)).To(Succeed())
+		err := fs.WriteFileString(historyPath, "not-json")
+		Expect(err).ToNot(HaveOccurred())
+
+		samples, err := history.Samples(start, timeService.Now(), 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(BeEmpty())
+	})
+
+	It("skips a partially written sample and rewrites the file without it", func() {
+		recordEveryMinute(2)
+
+		file, err := os.OpenFile(historyPath, os.O_WRONLY|os.O_APPEND, 0640)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = file.WriteString(`{"timestamp":`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file.Close()).To(Succeed())
+
+		reloaded := NewFileHistory(fs, historyPath, 5*time.Minute, time.Minute, timeService)
+		Expect(reloaded.Record(Vitals{Load: []string{"z"}})).To(Succeed())
+
+		persisted := persistedSamples()
+		Expect(persisted).To(HaveLen(3))
+		Expect(persisted[2].Vitals.Load).To(Equal([]string{"z"}))
+	})
+})
@@ -0,0 +1,195 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package vitalsfakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-agent/v2/platform/vitals"
+)
+
+type FakeHistory struct {
+	RecordStub        func(vitals.Vitals) error
+	recordMutex       sync.RWMutex
+	recordArgsForCall []struct {
+		arg1 vitals.Vitals
+	}
+	recordReturns struct {
+		result1 error
+	}
+	recordReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SamplesStub        func(time.Time, time.Time, time.Duration) ([]vitals.Sample, error)
+	samplesMutex       sync.RWMutex
+	samplesArgsForCall []struct {
+		arg1 time.Time
+		arg2 time.Time
+		arg3 time.Duration
+	}
+	samplesReturns struct {
+		result1 []vitals.Sample
+		result2 error
+	}
+	samplesReturnsOnCall map[int]struct {
+		result1 []vitals.Sample
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeHistory) Record(arg1 vitals.Vitals) error {
+	fake.recordMutex.Lock()
+	ret, specificReturn := fake.recordReturnsOnCall[len(fake.recordArgsForCall)]
+	fake.recordArgsForCall = append(fake.recordArgsForCall, struct {
+		arg1 vitals.Vitals
+	}{arg1})
+	stub := fake.RecordStub
+	fakeReturns := fake.recordReturns
+	fake.recordInvocation("Record", []interface{}{arg1})
+	fake.recordMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeHistory) RecordCallCount() int {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return len(fake.recordArgsForCall)
+}
+
+func (fake *FakeHistory) RecordCalls(stub func(vitals.Vitals) error) {
+	fake.recordMutex.Lock()
+	defer fake.recordMutex.Unlock()
+	fake.RecordStub = stub
+}
+
+func (fake *FakeHistory) RecordArgsForCall(i int) vitals.Vitals {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	argsForCall := fake.recordArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeHistory) RecordReturns(result1 error) {
+	fake.recordMutex.Lock()
+	defer fake.recordMutex.Unlock()
+	fake.RecordStub = nil
+	fake.recordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeHistory) RecordReturnsOnCall(i int, result1 error) {
+	fake.recordMutex.Lock()
+	defer fake.recordMutex.Unlock()
+	fake.RecordStub = nil
+	if fake.recordReturnsOnCall == nil {
+		fake.recordReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.recordReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeHistory) Samples(arg1 time.Time, arg2 time.Time, arg3 time.Duration) ([]vitals.Sample, error) {
+	fake.samplesMutex.Lock()
+	ret, specificReturn := fake.samplesReturnsOnCall[len(fake.samplesArgsForCall)]
+	fake.samplesArgsForCall = append(fake.samplesArgsForCall, struct {
+		arg1 time.Time
+		arg2 time.Time
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.SamplesStub
+	fakeReturns := fake.samplesReturns
+	fake.recordInvocation("Samples", []interface{}{arg1, arg2, arg3})
+	fake.samplesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeHistory) SamplesCallCount() int {
+	fake.samplesMutex.RLock()
+	defer fake.samplesMutex.RUnlock()
+	return len(fake.samplesArgsForCall)
+}
+
+func (fake *FakeHistory) SamplesCalls(stub func(time.Time, time.Time, time.Duration) ([]vitals.Sample, error)) {
+	fake.samplesMutex.Lock()
+	defer fake.samplesMutex.Unlock()
+	fake.SamplesStub = stub
+}
+
+func (fake *FakeHistory) SamplesArgsForCall(i int) (time.Time, time.Time, time.Duration) {
+	fake.samplesMutex.RLock()
+	defer fake.samplesMutex.RUnlock()
+	argsForCall := fake.samplesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeHistory) SamplesReturns(result1 []vitals.Sample, result2 error) {
+	fake.samplesMutex.Lock()
+	defer fake.samplesMutex.Unlock()
+	fake.SamplesStub = nil
+	fake.samplesReturns = struct {
+		result1 []vitals.Sample
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHistory) SamplesReturnsOnCall(i int, result1 []vitals.Sample, result2 error) {
+	fake.samplesMutex.Lock()
+	defer fake.samplesMutex.Unlock()
+	fake.SamplesStub = nil
+	if fake.samplesReturnsOnCall == nil {
+		fake.samplesReturnsOnCall = make(map[int]struct {
+			result1 []vitals.Sample
+			result2 error
+		})
+	}
+	fake.samplesReturnsOnCall[i] = struct {
+		result1 []vitals.Sample
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHistory) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	fake.samplesMutex.RLock()
+	defer fake.samplesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeHistory) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ vitals.History = new(FakeHistory)
@@ -118,6 +118,12 @@ func (s *settingsService) LoadSettings() error {
 	}
 
 	s.logger.Debug(settingsServiceLogTag, "Successfully received settings from fetcher")
+
+	err := newSettings.Env.Bosh.Agent.Alerts.Monit.Validate()
+	if err != nil {
+		return bosherr.WrapError(err, "Validating monit alert settings")
+	}
+
 	newUpdateSettings, err := s.getUpdateSettings(newSettings.Env.Bosh.Agent.Settings.TmpFS)
 	if err != nil {
 		s.logger.Error(settingsServiceLogTag, err.Error())
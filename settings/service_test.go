@@ -131,6 +131,24 @@ var _ = Describe("settingsService", func() {
 					Expect(err.Error()).To(ContainSubstring("fs-write-file-error"))
 				})
 			})
+
+			Context("when settings override monit alerts with an unknown severity", func() {
+				BeforeEach(func() {
+					fetchedSettings.Env.Bosh.Agent.Alerts.Monit.Severities = map[string]string{
+						"pid changed":            "ignored",
+						"resource limit matched": "page",
+					}
+				})
+
+				It("returns an error without using the settings", func() {
+					err := service.LoadSettings()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Unknown severity 'page' of monit event 'resource limit matched'"))
+
+					Expect(service.GetSettings().AgentID).To(BeEmpty())
+					Expect(fs.FileExists("/setting/path.json")).To(BeFalse())
+				})
+			})
 		})
 
 		Context("when tmpfs is disabled", func() {
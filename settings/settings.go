@@ -3,7 +3,11 @@ package settings
 import (
 	"fmt"
 	"net"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry/bosh-agent/v2/platform/disk"
 )
@@ -278,6 +282,74 @@ func (e Env) GetParallel() *int {
 	return &result
 }
 
+func (e Env) GetVitalsHistoryRetention() time.Duration {
+	retention := 24 * 60 * 60
+	if e.Bosh.Agent.VitalsHistory.RetentionSeconds != nil && *e.Bosh.Agent.VitalsHistory.RetentionSeconds > 0 {
+		retention = *e.Bosh.Agent.VitalsHistory.RetentionSeconds
+	}
+	return time.Duration(retention) * time.Second
+}
+
+func (e Env) GetVitalsHistoryInterval() time.Duration {
+	interval := 60
+	if e.Bosh.Agent.VitalsHistory.IntervalSeconds != nil && *e.Bosh.Agent.VitalsHistory.IntervalSeconds > 0 {
+		interval = *e.Bosh.Agent.VitalsHistory.IntervalSeconds
+	}
+	return time.Duration(interval) * time.Second
+}
+
+func (e Env) GetJobStatePollInterval() time.Duration {
+	interval := 5
+	if e.Bosh.Agent.JobState.PollIntervalSeconds != nil && *e.Bosh.Agent.JobState.PollIntervalSeconds >= 0 {
+		interval = *e.Bosh.Agent.JobState.PollIntervalSeconds
+	}
+	return time.Duration(interval) * time.Second
+}
+
+func (e Env) GetJobStateDebounce() time.Duration {
+	debounce := 10
+	if e.Bosh.Agent.JobState.DebounceSeconds != nil && *e.Bosh.Agent.JobState.DebounceSeconds >= 0 {
+		debounce = *e.Bosh.Agent.JobState.DebounceSeconds
+	}
+	return time.Duration(debounce) * time.Second
+}
+
+func (e Env) GetAlertDedupWindow() time.Duration {
+	window := 5 * 60
+	if e.Bosh.Agent.Alerts.DedupWindowSeconds != nil {
+		window = *e.Bosh.Agent.Alerts.DedupWindowSeconds
+	}
+	return time.Duration(window) * time.Second
+}
+
+func (e Env) GetAlertMaxPerService() int {
+	if e.Bosh.Agent.Alerts.MaxPerService != nil {
+		return *e.Bosh.Agent.Alerts.MaxPerService
+	}
+	return 5
+}
+
+func (e Env) GetAlertThresholds() map[string]AlertThreshold {
+	diskThreshold := AlertThreshold{Warning: 80, Critical: 90, Hysteresis: 5}
+
+	thresholds := map[string]AlertThreshold{
+		"system_disk":      diskThreshold,
+		"ephemeral_disk":   diskThreshold,
+		"persistent_disk":  diskThreshold,
+		"system_inode":     diskThreshold,
+		"ephemeral_inode":  diskThreshold,
+		"persistent_inode": diskThreshold,
+		"mem":              {Warning: 90, Critical: 95, Hysteresis: 5},
+		"swap":             {Warning: 50, Critical: 80, Hysteresis: 5},
+	}
+
+	for metric, threshold := range e.Bosh.Agent.Alerts.Thresholds {
+		thresholds[metric] = threshold
+	}
+
+	return thresholds
+}
+
 type BoshEnv struct {
 	Agent                 AgentEnv    `json:"agent"`
 	Password              string      `json:"password"`
@@ -296,7 +368,80 @@ type BoshEnv struct {
 }
 
 type AgentEnv struct {
-	Settings AgentSettings `json:"settings"`
+	Settings      AgentSettings `json:"settings"`
+	VitalsHistory VitalsHistory `json:"vitals_history"`
+	JobState      JobStateEnv   `json:"job_state"`
+	Alerts        AlertsEnv     `json:"alerts"`
+}
+
+type JobStateEnv struct {
+	// How often the job state is checked for changes that are sent as an
+	// out-of-band heartbeat; zero only checks on job failure alerts
+	PollIntervalSeconds *int `json:"poll_interval_seconds"`
+
+	// Minimum time between two out-of-band heartbeats
+	DebounceSeconds *int `json:"debounce_seconds"`
+}
+
+type AlertsEnv struct {
+	// Duplicate alerts (same service and event) within the window are
+	// aggregated into a single summary alert; zero disables aggregation
+	DedupWindowSeconds *int `json:"dedup_window_seconds"`
+	MaxPerService      *int `json:"max_per_service"`
+
+	// Thresholds on vitals keyed by metric (e.g. ephemeral_disk, system_inode, mem),
+	// configured entries replace the defaults for that metric
+	Thresholds map[string]AlertThreshold `json:"thresholds"`
+
+	Monit MonitAlerts `json:"monit"`
+}
+
+type MonitAlerts struct {
+	// Severities override the default severity of monit events,
+	// e.g. {"pid changed": "ignored", "resource limit matched": "critical"}
+	Severities map[string]string `json:"severities"`
+
+	// Ignore lists monit events that are never sent
+	Ignore []string `json:"ignore"`
+
+	// TitleFormat is a text/template for alert titles with .Service
+	// (including the instance IPs), .Name, .Event, .Action and .Description
+	TitleFormat string `json:"title_format"`
+}
+
+var monitAlertSeverities = []string{"alert", "critical", "error", "warning", "ignored"}
+
+// Validate fails when a severity is not one of alert, critical, error, warning or ignored
+func (m MonitAlerts) Validate() error {
+	events := []string{}
+	for event := range m.Severities {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+
+	for _, event := range events {
+		severity := m.Severities[event]
+		if !slices.Contains(monitAlertSeverities, strings.ToLower(strings.TrimSpace(severity))) {
+			return fmt.Errorf("Unknown severity '%s' of monit event '%s', expected one of %s", severity, event, strings.Join(monitAlertSeverities, ", "))
+		}
+	}
+
+	return nil
+}
+
+// AlertThreshold levels are percentages; a zero level is disabled.
+// Once raised, a level is only cleared after the value drops
+// Hysteresis percentage points below it.
+type AlertThreshold struct {
+	Warning    float64 `json:"warning"`
+	Critical   float64 `json:"critical"`
+	Hysteresis float64 `json:"hysteresis"`
+}
+
+type VitalsHistory struct {
+	Disabled         bool `json:"disabled"`
+	RetentionSeconds *int `json:"retention_seconds"`
+	IntervalSeconds  *int `json:"interval_seconds"`
 }
 
 type AgentSettings struct {
@@ -2,6 +2,7 @@ package settings_test
 
 import (
 	"encoding/json"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -998,6 +999,135 @@ var _ = Describe("Settings", func() {
 			})
 		})
 
+		Context("#GetJobStatePollInterval and #GetJobStateDebounce", func() {
+			It("defaults to polling every 5 seconds with a 10 second debounce", func() {
+				var env Env
+				err := json.Unmarshal([]byte(`{"bosh": {}}`), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(env.GetJobStatePollInterval()).To(Equal(5 * time.Second))
+				Expect(env.GetJobStateDebounce()).To(Equal(10 * time.Second))
+			})
+
+			It("uses the configured values", func() {
+				var env Env
+				envJSON := `{"bosh": {"agent": {"job_state": {"poll_interval_seconds": 0, "debounce_seconds": 30}}}}`
+
+				err := json.Unmarshal([]byte(envJSON), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(env.GetJobStatePollInterval()).To(BeZero())
+				Expect(env.GetJobStateDebounce()).To(Equal(30 * time.Second))
+			})
+
+			It("uses the defaults instead of negative values", func() {
+				var env Env
+				envJSON := `{"bosh": {"agent": {"job_state": {"poll_interval_seconds": -1, "debounce_seconds": -1}}}}`
+
+				err := json.Unmarshal([]byte(envJSON), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(env.GetJobStatePollInterval()).To(Equal(5 * time.Second))
+				Expect(env.GetJobStateDebounce()).To(Equal(10 * time.Second))
+			})
+		})
+
+		Context("#GetAlertDedupWindow and #GetAlertMaxPerService", func() {
+			It("defaults to a 5 minute window with at most 5 alerts per service", func() {
+				var env Env
+				err := json.Unmarshal([]byte(`{"bosh": {}}`), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(env.GetAlertDedupWindow()).To(Equal(5 * time.Minute))
+				Expect(env.GetAlertMaxPerService()).To(Equal(5))
+			})
+
+			It("uses the configured values", func() {
+				var env Env
+				envJSON := `{"bosh": {"agent": {"alerts": {"dedup_window_seconds": 0, "max_per_service": 2}}}}`
+
+				err := json.Unmarshal([]byte(envJSON), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(env.GetAlertDedupWindow()).To(BeZero())
+				Expect(env.GetAlertMaxPerService()).To(Equal(2))
+			})
+		})
+
+		It("parses monit alert overrides", func() {
+			var env Env
+			envJSON := `{"bosh": {"agent": {"alerts": {"monit": {"severities": {"pid changed": "ignored"}, "ignore": ["action done"], "title_format": "{{.Name}}"}}}}}`
+
+			err := json.Unmarshal([]byte(envJSON), &env)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(env.Bosh.Agent.Alerts.Monit).To(Equal(MonitAlerts{
+				Severities:  map[string]string{"pid changed": "ignored"},
+				Ignore:      []string{"action done"},
+				TitleFormat: "{{.Name}}",
+			}))
+		})
+
+		Context("#GetAlertThresholds", func() {
+			It("returns default thresholds", func() {
+				var env Env
+				err := json.Unmarshal([]byte(`{"bosh": {}}`), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				thresholds := env.GetAlertThresholds()
+				Expect(thresholds).To(HaveLen(8))
+				Expect(thresholds["ephemeral_disk"]).To(Equal(AlertThreshold{Warning: 80, Critical: 90, Hysteresis: 5}))
+				Expect(thresholds["mem"]).To(Equal(AlertThreshold{Warning: 90, Critical: 95, Hysteresis: 5}))
+			})
+
+			It("replaces defaults with configured thresholds", func() {
+				var env Env
+				envJSON := `{"bosh": {"agent": {"alerts": {"thresholds": {"mem": {"critical": 99}, "custom": {"warning": 10}}}}}}`
+
+				err := json.Unmarshal([]byte(envJSON), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				thresholds := env.GetAlertThresholds()
+				Expect(thresholds).To(HaveLen(9))
+				Expect(thresholds["mem"]).To(Equal(AlertThreshold{Critical: 99}))
+				Expect(thresholds["custom"]).To(Equal(AlertThreshold{Warning: 10}))
+				Expect(thresholds["swap"]).To(Equal(AlertThreshold{Warning: 50, Critical: 80, Hysteresis: 5}))
+			})
+		})
+
+		Context("#GetVitalsHistoryRetention and #GetVitalsHistoryInterval", func() {
+			It("defaults to 24 hours at 1 minute resolution", func() {
+				var env Env
+				err := json.Unmarshal([]byte(`{"bosh": {}}`), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(env.GetVitalsHistoryRetention()).To(Equal(24 * time.Hour))
+				Expect(env.GetVitalsHistoryInterval()).To(Equal(time.Minute))
+			})
+
+			It("uses the configured values", func() {
+				var env Env
+				envJSON := `{"bosh": {"agent": {"vitals_history": {"retention_seconds": 3600, "interval_seconds": 10}}}}`
+
+				err := json.Unmarshal([]byte(envJSON), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(env.GetVitalsHistoryRetention()).To(Equal(time.Hour))
+				Expect(env.GetVitalsHistoryInterval()).To(Equal(10 * time.Second))
+			})
+
+			It("uses the defaults instead of values that are not positive", func() {
+				var env Env
+				envJSON := `{"bosh": {"agent": {"vitals_history": {"retention_seconds": 0, "interval_seconds": -10}}}}`
+
+				err := json.Unmarshal([]byte(envJSON), &env)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(env.GetVitalsHistoryRetention()).To(Equal(24 * time.Hour))
+				Expect(env.GetVitalsHistoryInterval()).To(Equal(time.Minute))
+			})
+		})
+
 		Context("#GetBlobstore", func() {
 			blobstoreLocal := Blobstore{
 				Type: "local",